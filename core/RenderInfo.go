@@ -12,4 +12,5 @@ import (
 type RenderInfo struct {
 	ViewMatrix math32.Matrix4 // Current camera view matrix
 	ProjMatrix math32.Matrix4 // Current camera projection matrix
+	Fog        *Fog           // Current scene fog or nil
 }
@@ -0,0 +1,202 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// FogMode specifies how the fog increases with the distance to the camera.
+type FogMode int
+
+// The possible fog modes.
+const (
+	FogLinear = FogMode(iota) // From no fog at the near distance to full fog at the far distance
+	FogExp                    // Exponential with the density
+	FogExp2                   // Exponential with the square of the density
+)
+
+// Fog is the fog of a scene, set in the renderer, which blends the colors of the objects
+// rendered by the built-in shaders toward the fog color with their distance to the camera.
+// Its density can also decrease exponentially with the height above a base height, and
+// skies are blended toward the fog color from the horizon up to an elevation.
+// The renderer adds its shader defines to all the programs and sends its uniforms.
+type Fog struct {
+	mode    FogMode           // Fog mode
+	color   math32.Color      // Fog color
+	near    float32           // Distance where the linear fog starts
+	far     float32           // Distance where the linear fog is full
+	density float32           // Density of the exponential fogs
+	height  float32           // Base height of the height falloff
+	falloff float32           // Decrease rate of the density with the height (0 for uniform fog)
+	sky     float32           // Elevation in radians up to which skies are blended
+	defines gls.ShaderDefines // Shader defines of the fog
+	udata   [12]float32       // FogParams uniform data (3 vec4)
+	uni     gls.Uniform       // FogParams uniform location cache
+}
+
+// NewFog creates and returns a pointer to a new linear fog with the specified
+// color, starting at the near distance and full at the far distance.
+func NewFog(color *math32.Color, near, far float32) *Fog {
+
+	f := newFog(FogLinear, color)
+	f.near = near
+	f.far = far
+	return f
+}
+
+// NewFogExp creates and returns a pointer to a new exponential fog with the specified color
+// and density, which is the inverse of the distance at which the fog is about 63%.
+func NewFogExp(color *math32.Color, density float32) *Fog {
+
+	f := newFog(FogExp, color)
+	f.density = density
+	return f
+}
+
+// NewFogExp2 creates and returns a pointer to a new squared exponential fog with the
+// specified color and density, which is clearer near the camera than the exponential fog.
+func NewFogExp2(color *math32.Color, density float32) *Fog {
+
+	f := newFog(FogExp2, color)
+	f.density = density
+	return f
+}
+
+// newFog creates and returns a pointer to a new fog with the specified mode and color.
+func newFog(mode FogMode, color *math32.Color) *Fog {
+
+	f := new(Fog)
+	f.mode = mode
+	f.color = *color
+	f.near = 1
+	f.far = 1000
+	f.density = 0.01
+	f.sky = math32.DegToRad(10)
+	f.uni.Init("FogParams")
+	f.updateDefines()
+	return f
+}
+
+// SetMode sets the fog mode.
+func (f *Fog) SetMode(mode FogMode) {
+
+	f.mode = mode
+	f.updateDefines()
+}
+
+// Mode returns the fog mode.
+func (f *Fog) Mode() FogMode {
+
+	return f.mode
+}
+
+// SetColor sets the fog color.
+func (f *Fog) SetColor(color *math32.Color) {
+
+	f.color = *color
+}
+
+// Color returns the fog color.
+func (f *Fog) Color() math32.Color {
+
+	return f.color
+}
+
+// SetRange sets the distances where the linear fog starts and where it is full.
+func (f *Fog) SetRange(near, far float32) {
+
+	f.near = near
+	f.far = far
+}
+
+// Range returns the distances where the linear fog starts and where it is full.
+func (f *Fog) Range() (float32, float32) {
+
+	return f.near, f.far
+}
+
+// SetDensity sets the density of the exponential fogs.
+func (f *Fog) SetDensity(density float32) {
+
+	f.density = density
+}
+
+// Density returns the density of the exponential fogs.
+func (f *Fog) Density() float32 {
+
+	return f.density
+}
+
+// SetHeightFalloff sets the base height in world coordinates above which the fog
+// thins out and the rate at which its density decreases exponentially with the height.
+// A falloff of 0, the default, makes the fog uniform.
+func (f *Fog) SetHeightFalloff(height, falloff float32) {
+
+	f.height = height
+	f.falloff = falloff
+	f.updateDefines()
+}
+
+// HeightFalloff returns the base height and the rate of decrease of the fog density with the height.
+func (f *Fog) HeightFalloff() (float32, float32) {
+
+	return f.height, f.falloff
+}
+
+// SetSkyElevation sets the elevation in radians up to which skies are blended toward the
+// fog color from the horizon. The default is 10 degrees and 0 leaves the skies without fog.
+func (f *Fog) SetSkyElevation(elevation float32) {
+
+	f.sky = elevation
+}
+
+// SkyElevation returns the elevation in radians up to which skies are blended toward the fog color.
+func (f *Fog) SkyElevation() float32 {
+
+	return f.sky
+}
+
+// Defines returns the shader defines of the fog, which are added by the renderer to all
+// the programs: FOG and either FOG_LINEAR, FOG_EXP or FOG_EXP2, with FOG_HEIGHT if the
+// density decreases with the height.
+func (f *Fog) Defines() *gls.ShaderDefines {
+
+	return &f.defines
+}
+
+// updateDefines updates the shader defines from the fog mode and height falloff.
+func (f *Fog) updateDefines() {
+
+	f.defines = *gls.NewShaderDefines()
+	f.defines.Set("FOG", "")
+	switch f.mode {
+	case FogLinear:
+		f.defines.Set("FOG_LINEAR", "")
+	case FogExp:
+		f.defines.Set("FOG_EXP", "")
+	case FogExp2:
+		f.defines.Set("FOG_EXP2", "")
+	}
+	if f.falloff != 0 {
+		f.defines.Set("FOG_HEIGHT", "")
+	}
+}
+
+// RenderSetup sends the uniforms of the fog to the current program.
+// The up direction and the height of the camera are sent in camera coordinates
+// so that the shaders can find the height of the fragments from their position.
+func (f *Fog) RenderSetup(gs *gls.GLS, rinfo *RenderInfo) {
+
+	var camWorld math32.Matrix4
+	camWorld.GetInverse(&rinfo.ViewMatrix)
+	up := math32.Vector3{rinfo.ViewMatrix[4], rinfo.ViewMatrix[5], rinfo.ViewMatrix[6]}
+	up.Normalize()
+	f.udata[0], f.udata[1], f.udata[2], f.udata[3] = f.color.R, f.color.G, f.color.B, math32.Sin(f.sky)
+	f.udata[4], f.udata[5], f.udata[6], f.udata[7] = f.near, f.far, f.density, f.falloff
+	f.udata[8], f.udata[9], f.udata[10], f.udata[11] = up.X, up.Y, up.Z, camWorld[13]-f.height
+	gs.Uniform4fv(f.uni.Location(gs), 3, &f.udata[0])
+}
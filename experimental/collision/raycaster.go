@@ -118,6 +118,8 @@ func (rc *Raycaster) intersectObject(inode core.INode, intersects *[]Intersect,
 		rc.RaycastLines(in, intersects)
 	case *graphic.LineStrip:
 		rc.RaycastLineStrip(in, intersects)
+	case *graphic.Polyline:
+		rc.RaycastPolyline(in, intersects)
 	}
 
 	if recursive {
@@ -368,6 +370,44 @@ func (rc *Raycaster) RaycastLineStrip(l *graphic.LineStrip, intersects *[]Inters
 	lineRaycast(l, rc, intersects, 1)
 }
 
+// RaycastPolyline checks intersections between the raycaster and the segments of the
+// specified polyline, within half of their width as last rendered with the view matrix
+// of the raycaster, and appends the ones found to the specified intersects array.
+// The index of the intersects is the index of the first point of the segment.
+// The line precision is used if the polyline has pixel widths and was not rendered.
+func (rc *Raycaster) RaycastPolyline(l *graphic.Polyline, intersects *[]Intersect) {
+
+	// The width is in world units so the segments are transformed to world coordinates
+	matrixWorld := l.MatrixWorld()
+	origin := rc.Ray.Origin()
+	var vstart, vend, interSegment, interRay math32.Vector3
+	l.VisitSegments(func(index int, a, b *math32.Vector3) bool {
+		vstart = *a
+		vend = *b
+		vstart.ApplyMatrix4(&matrixWorld)
+		vend.ApplyMatrix4(&matrixWorld)
+		distSq := rc.DistanceSqToSegment(&vstart, &vend, &interRay, &interSegment)
+		precision := l.WidthAt(&interSegment, &rc.ViewMatrix) / 2
+		if precision <= 0 {
+			precision = rc.LinePrecision
+		}
+		if distSq > precision*precision {
+			return false
+		}
+		distance := origin.DistanceTo(&interRay)
+		if distance < rc.Near || distance > rc.Far {
+			return false
+		}
+		*intersects = append(*intersects, Intersect{
+			Distance: distance,
+			Point:    interSegment,
+			Index:    uint32(index),
+			Object:   l,
+		})
+		return false
+	})
+}
+
 // Internal function used by raycasting for Lines and LineStrip.
 func lineRaycast(igr graphic.IGraphic, rc *Raycaster, intersects *[]Intersect, step int) {
 
@@ -3,3 +3,226 @@
 // license that can be found in the LICENSE file.
 
 package shape
+
+import "github.com/g3n/engine/math32"
+
+// Heightfield is a collision heightfield: a regular grid of heights over the XZ plane,
+// centered at the origin. Each cell of the grid is split in two triangles
+// along the diagonal from its (x+1, z) corner to its (x, z+1) corner.
+type Heightfield struct {
+	heights []float32 // heights in row major order (X varies fastest)
+	cols    int       // number of samples along the X axis
+	rows    int       // number of samples along the Z axis
+	width   float32   // size along the X axis
+	depth   float32   // size along the Z axis
+	min     float32   // minimum height
+	max     float32   // maximum height
+}
+
+// NewHeightfield creates and returns a pointer to a new collision heightfield with the specified
+// heights in row major order, number of samples along the X and Z axes and total width and depth.
+func NewHeightfield(heights []float32, cols, rows int, width, depth float32) *Heightfield {
+
+	if cols < 2 || rows < 2 || len(heights) != cols*rows {
+		panic("Invalid argument(s). Heightfield needs at least 2x2 samples and cols*rows heights.")
+	}
+	h := new(Heightfield)
+	h.heights = heights
+	h.cols = cols
+	h.rows = rows
+	h.width = width
+	h.depth = depth
+	h.Update()
+	return h
+}
+
+// Update updates the height range of the heightfield after its heights were changed.
+func (h *Heightfield) Update() {
+
+	h.min = math32.Infinity
+	h.max = -math32.Infinity
+	for _, v := range h.heights {
+		h.min = math32.Min(h.min, v)
+		h.max = math32.Max(h.max, v)
+	}
+}
+
+// Heights returns the heights of the heightfield in row major order.
+func (h *Heightfield) Heights() []float32 {
+
+	return h.heights
+}
+
+// Size returns the number of samples of the heightfield along the X and Z axes.
+func (h *Heightfield) Size() (int, int) {
+
+	return h.cols, h.rows
+}
+
+// Dimensions returns the width and depth of the heightfield.
+func (h *Heightfield) Dimensions() (float32, float32) {
+
+	return h.width, h.depth
+}
+
+// Sample returns the height of the sample at the specified column and row, clamped to the grid.
+func (h *Heightfield) Sample(col, row int) float32 {
+
+	if col < 0 {
+		col = 0
+	} else if col >= h.cols {
+		col = h.cols - 1
+	}
+	if row < 0 {
+		row = 0
+	} else if row >= h.rows {
+		row = h.rows - 1
+	}
+	return h.heights[row*h.cols+col]
+}
+
+// cell returns the cell containing the specified local point and the fractional position inside it.
+func (h *Heightfield) cell(x, z float32) (int, int, float32, float32) {
+
+	gx := math32.Clamp((x/h.width+0.5)*float32(h.cols-1), 0, float32(h.cols-1))
+	gz := math32.Clamp((z/h.depth+0.5)*float32(h.rows-1), 0, float32(h.rows-1))
+	col := int(math32.Min(math32.Floor(gx), float32(h.cols-2)))
+	row := int(math32.Min(math32.Floor(gz), float32(h.rows-2)))
+	return col, row, gx - float32(col), gz - float32(row)
+}
+
+// Vertex returns the local position of the sample at the specified column and row, clamped to the grid.
+func (h *Heightfield) Vertex(col, row int) math32.Vector3 {
+
+	col = int(math32.Clamp(float32(col), 0, float32(h.cols-1)))
+	row = int(math32.Clamp(float32(row), 0, float32(h.rows-1)))
+	x := float32(col)*h.width/float32(h.cols-1) - h.width/2
+	z := float32(row)*h.depth/float32(h.rows-1) - h.depth/2
+	return math32.Vector3{x, h.heights[row*h.cols+col], z}
+}
+
+// CellRange returns the first and last columns and rows of the cells overlapping the specified
+// local X and Z bounds, and false if the bounds are outside of the grid.
+func (h *Heightfield) CellRange(minX, minZ, maxX, maxZ float32) (int, int, int, int, bool) {
+
+	if maxX < -h.width/2 || minX > h.width/2 || maxZ < -h.depth/2 || minZ > h.depth/2 {
+		return 0, 0, 0, 0, false
+	}
+	col0, row0, _, _ := h.cell(minX, minZ)
+	col1, row1, _, _ := h.cell(maxX, maxZ)
+	return col0, row0, col1, row1, true
+}
+
+// CellTriangles returns the local vertices of the two triangles of the cell at the specified
+// column and row, with counterclockwise order seen from above.
+func (h *Heightfield) CellTriangles(col, row int) [2][3]math32.Vector3 {
+
+	p00 := h.Vertex(col, row)
+	p10 := h.Vertex(col+1, row)
+	p01 := h.Vertex(col, row+1)
+	p11 := h.Vertex(col+1, row+1)
+	return [2][3]math32.Vector3{{p00, p01, p10}, {p10, p01, p11}}
+}
+
+// HeightAt returns the height of the surface of the heightfield at the specified local X and Z coordinates,
+// interpolated over the triangles of the grid. Points outside of the grid are clamped to its border.
+func (h *Heightfield) HeightAt(x, z float32) float32 {
+
+	col, row, fx, fz := h.cell(x, z)
+	h00 := h.Sample(col, row)
+	h10 := h.Sample(col+1, row)
+	h01 := h.Sample(col, row+1)
+	if fx+fz <= 1 {
+		return h00 + fx*(h10-h00) + fz*(h01-h00)
+	}
+	h11 := h.Sample(col+1, row+1)
+	return h11 + (1-fx)*(h01-h11) + (1-fz)*(h10-h11)
+}
+
+// NormalAt returns the normal of the surface of the heightfield at the specified local X and Z coordinates,
+// which is the normal of the triangle of the grid containing the point.
+func (h *Heightfield) NormalAt(x, z float32) math32.Vector3 {
+
+	col, row, fx, fz := h.cell(x, z)
+	dx := h.width / float32(h.cols-1)
+	dz := h.depth / float32(h.rows-1)
+	var sx, sz float32
+	if fx+fz <= 1 {
+		sx = h.Sample(col+1, row) - h.Sample(col, row)
+		sz = h.Sample(col, row+1) - h.Sample(col, row)
+	} else {
+		sx = h.Sample(col+1, row+1) - h.Sample(col, row+1)
+		sz = h.Sample(col+1, row+1) - h.Sample(col+1, row)
+	}
+	n := math32.Vector3{-sx / dx, 1, -sz / dz}
+	n.Normalize()
+	return n
+}
+
+// IShape =============================================================
+
+// BoundingBox computes and returns the bounding box of the collision heightfield.
+func (h *Heightfield) BoundingBox() math32.Box3 {
+
+	return math32.Box3{math32.Vector3{-h.width / 2, h.min, -h.depth / 2}, math32.Vector3{h.width / 2, h.max, h.depth / 2}}
+}
+
+// BoundingSphere computes and returns the bounding sphere of the collision heightfield.
+func (h *Heightfield) BoundingSphere() math32.Sphere {
+
+	box := h.BoundingBox()
+	var center math32.Vector3
+	box.Center(&center)
+	return *math32.NewSphere(&center, box.Max.DistanceTo(&center))
+}
+
+// Area computes and returns the surface area of the collision heightfield.
+func (h *Heightfield) Area() float32 {
+
+	dx := h.width / float32(h.cols-1)
+	dz := h.depth / float32(h.rows-1)
+	var area float32
+	for row := 0; row < h.rows-1; row++ {
+		for col := 0; col < h.cols-1; col++ {
+			h00 := h.Sample(col, row)
+			h10 := h.Sample(col+1, row)
+			h01 := h.Sample(col, row+1)
+			h11 := h.Sample(col+1, row+1)
+			var n1, n2 math32.Vector3
+			n1.Set(-(h10-h00)*dz, dx*dz, -(h01-h00)*dx)
+			n2.Set(-(h11-h01)*dz, dx*dz, -(h11-h10)*dx)
+			area += (n1.Length() + n2.Length()) / 2
+		}
+	}
+	return area
+}
+
+// Volume returns zero since the collision heightfield is an open surface.
+func (h *Heightfield) Volume() float32 {
+
+	return 0
+}
+
+// RotationalInertia returns a zero matrix since the collision heightfield is meant for static bodies.
+func (h *Heightfield) RotationalInertia(mass float32) math32.Matrix3 {
+
+	return *math32.NewMatrix3().Zero()
+}
+
+// ProjectOntoAxis computes and returns the minimum and maximum distances of the collision heightfield projected onto the specified local axis.
+func (h *Heightfield) ProjectOntoAxis(localAxis *math32.Vector3) (float32, float32) {
+
+	min := math32.Infinity
+	max := -math32.Infinity
+	dx := h.width / float32(h.cols-1)
+	dz := h.depth / float32(h.rows-1)
+	for row := 0; row < h.rows; row++ {
+		for col := 0; col < h.cols; col++ {
+			p := math32.Vector3{float32(col)*dx - h.width/2, h.heights[row*h.cols+col], float32(row)*dz - h.depth/2}
+			d := p.Dot(localAxis)
+			min = math32.Min(min, d)
+			max = math32.Max(max, d)
+		}
+	}
+	return min, max
+}
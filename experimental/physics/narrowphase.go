@@ -183,6 +183,8 @@ func (n *Narrowphase) ResolveCollision(bodyA, bodyB *object.Body) ([]*equation.C
 			return n.SpherePlane(bodyA, bodyB, sA, sB, &posA, &posB, quatA, quatB)
 		case *shape.ConvexHull:
 			return n.SphereConvex(bodyA, bodyB, sA, sB, &posA, &posB, quatA, quatB)
+		case *shape.Heightfield:
+			return n.SphereHeightfield(bodyA, bodyB, sA, sB, &posA, &posB, quatA, quatB)
 		}
 	case *shape.Plane:
 		switch sB := shapeB.(type) {
@@ -201,6 +203,15 @@ func (n *Narrowphase) ResolveCollision(bodyA, bodyB *object.Body) ([]*equation.C
 			return n.PlaneConvex(bodyB, bodyA, sB, sA, &posB, &posA, quatB, quatA)
 		case *shape.ConvexHull:
 			return n.ConvexConvex(bodyA, bodyB, sA, sB, &posA, &posB, quatA, quatB)
+		case *shape.Heightfield:
+			return n.ConvexHeightfield(bodyA, bodyB, sA, sB, &posA, &posB, quatA, quatB)
+		}
+	case *shape.Heightfield:
+		switch sB := shapeB.(type) {
+		case *shape.Sphere:
+			return n.SphereHeightfield(bodyB, bodyA, sB, sA, &posB, &posA, quatB, quatA)
+		case *shape.ConvexHull:
+			return n.ConvexHeightfield(bodyB, bodyA, sB, sA, &posB, &posA, quatB, quatA)
 		}
 	}
 
@@ -416,6 +427,172 @@ func (n *Narrowphase) SphereConvex(bodyA, bodyB *object.Body, sphereA *shape.Sph
 	return contactEqs, frictionEqs
 }
 
+// SphereHeightfield resolves the collision between a sphere and a heightfield by testing the
+// triangles of the cells under the sphere, with one contact per triangle closer than the radius.
+func (n *Narrowphase) SphereHeightfield(bodyA, bodyB *object.Body, sphereA *shape.Sphere, hfB *shape.Heightfield, posA, posB *math32.Vector3, quatA, quatB *math32.Quaternion) ([]*equation.Contact, []*equation.Friction) {
+
+	contactEqs := make([]*equation.Contact, 0)
+	frictionEqs := make([]*equation.Friction, 0)
+
+	// Sphere center in the local coordinates of the heightfield
+	invQuatB := quatB.Clone().Inverse()
+	center := posA.Clone().Sub(posB).ApplyQuaternion(invQuatB)
+	radius := sphereA.Radius()
+	col0, row0, col1, row1, ok := hfB.CellRange(center.X-radius, center.Z-radius, center.X+radius, center.Z+radius)
+	if !ok {
+		return contactEqs, frictionEqs
+	}
+
+	found := make([]math32.Vector3, 0)
+	for row := row0; row <= row1; row++ {
+		for col := col0; col <= col1; col++ {
+			for _, tri := range hfB.CellTriangles(col, row) {
+				var closest math32.Vector3
+				math32.ClosestPointToPoint(center, &tri[0], &tri[1], &tri[2], &closest)
+				if closest.DistanceToSquared(center) >= radius*radius {
+					continue
+				}
+				// Triangles sharing the closest vertex or edge give the same contact
+				duplicate := false
+				for i := range found {
+					if found[i].DistanceToSquared(&closest) < 1e-8 {
+						duplicate = true
+						break
+					}
+				}
+				if duplicate {
+					continue
+				}
+				found = append(found, closest)
+
+				// Contact normal from the sphere center toward the closest point, in world coordinates
+				worldPoint := closest.Clone().ApplyQuaternion(quatB).Add(posB)
+				normal := worldPoint.Clone().Sub(posA)
+				if normal.LengthSq() == 0 {
+					normal = math32.Normal(&tri[0], &tri[1], &tri[2], nil).ApplyQuaternion(quatB).Negate()
+				}
+				normal.Normalize()
+				contactEq := equation.NewContact(bodyA, bodyB, 0, 1e6)
+				contactEq.SetSpookParams(1e6, 3, n.simulation.dt)
+				contactEq.SetEnabled(bodyA.CollisionResponse() && bodyB.CollisionResponse())
+				contactEq.SetNormal(normal)
+				contactEq.SetRA(normal.Clone().MultiplyScalar(radius))
+				contactEq.SetRB(worldPoint.Clone().Sub(posB))
+				contactEqs = append(contactEqs, contactEq)
+
+				// Create friction equations
+				fEq1, fEq2 := n.createFrictionEquationsFromContact(contactEq)
+				frictionEqs = append(frictionEqs, fEq1, fEq2)
+			}
+		}
+	}
+
+	return contactEqs, frictionEqs
+}
+
+// ConvexHeightfield resolves the collision between a convex hull and a heightfield.
+// The vertices of the hull below the triangles of the heightfield under them give contacts
+// along the triangle normals, and the samples of the heightfield under the hull which are
+// inside of it give contacts along the normal of the hull face they are nearest to.
+func (n *Narrowphase) ConvexHeightfield(bodyA, bodyB *object.Body, convexA *shape.ConvexHull, hfB *shape.Heightfield, posA, posB *math32.Vector3, quatA, quatB *math32.Quaternion) ([]*equation.Contact, []*equation.Friction) {
+
+	contactEqs := make([]*equation.Contact, 0)
+	frictionEqs := make([]*equation.Friction, 0)
+
+	// Hull vertices in world coordinates and in the local coordinates of the heightfield
+	invQuatB := quatB.Clone().Inverse()
+	worldVerts := make([]math32.Vector3, 0)
+	localVerts := make([]math32.Vector3, 0)
+	var bbox math32.Box3
+	bbox.MakeEmpty()
+	convexA.Geometry.ReadVertices(func(vertex math32.Vector3) bool {
+		vertex.ApplyQuaternion(quatA).Add(posA)
+		worldVerts = append(worldVerts, vertex)
+		local := vertex
+		local.Sub(posB).ApplyQuaternion(invQuatB)
+		localVerts = append(localVerts, local)
+		bbox.ExpandByPoint(&local)
+		return false
+	})
+	minHeight, maxHeight := hfB.BoundingBox().Min.Y, hfB.BoundingBox().Max.Y
+	col0, row0, col1, row1, ok := hfB.CellRange(bbox.Min.X, bbox.Min.Z, bbox.Max.X, bbox.Max.Z)
+	if !ok || bbox.Min.Y > maxHeight {
+		return contactEqs, frictionEqs
+	}
+
+	addContact := func(normal, pointA, pointB *math32.Vector3) {
+		contactEq := equation.NewContact(bodyA, bodyB, 0, 1e6)
+		contactEq.SetSpookParams(1e6, 3, n.simulation.dt)
+		contactEq.SetEnabled(bodyA.CollisionResponse() && bodyB.CollisionResponse())
+		contactEq.SetNormal(normal)
+		contactEq.SetRA(pointA.Clone().Sub(posA))
+		contactEq.SetRB(pointB.Clone().Sub(posB))
+		contactEqs = append(contactEqs, contactEq)
+		if !n.enableFrictionReduction {
+			fEq1, fEq2 := n.createFrictionEquationsFromContact(contactEq)
+			frictionEqs = append(frictionEqs, fEq1, fEq2)
+		}
+	}
+
+	// Hull vertices below the surface of the heightfield
+	w, d := hfB.Dimensions()
+	for i := range localVerts {
+		local := &localVerts[i]
+		if local.Y > maxHeight || math32.Abs(local.X) > w/2 || math32.Abs(local.Z) > d/2 {
+			continue
+		}
+		height := hfB.HeightAt(local.X, local.Z)
+		if local.Y >= height {
+			continue
+		}
+		// Depth along the normal of the triangle under the vertex
+		localNormal := hfB.NormalAt(local.X, local.Z)
+		depth := (local.Y - height) * localNormal.Y
+		normal := localNormal.Clone().ApplyQuaternion(quatB)
+		surfacePoint := normal.Clone().MultiplyScalar(-depth).Add(&worldVerts[i])
+		addContact(normal.Negate(), &worldVerts[i], surfacePoint)
+	}
+
+	// Samples of the heightfield inside the hull
+	faces := convexA.Faces()
+	worldNormals := convexA.WorldFaceNormals()
+	if len(faces) == 0 || len(worldNormals) != len(faces) || bbox.Max.Y < minHeight {
+		return contactEqs, frictionEqs
+	}
+	for row := row0; row <= row1+1; row++ {
+		for col := col0; col <= col1+1; col++ {
+			local := hfB.Vertex(col, row)
+			if !bbox.ContainsPoint(&local) {
+				continue
+			}
+			point := local.Clone().ApplyQuaternion(quatB).Add(posB)
+			// Inside if behind all the faces, pushed out through the nearest one
+			inside := true
+			bestDepth := -math32.Infinity
+			var bestNormal math32.Vector3
+			for j := range faces {
+				facePoint := faces[j][0].Clone().ApplyQuaternion(quatA).Add(posA)
+				dist := worldNormals[j].Dot(point.Clone().Sub(facePoint))
+				if dist >= 0 {
+					inside = false
+					break
+				}
+				if dist > bestDepth {
+					bestDepth = dist
+					bestNormal = worldNormals[j]
+				}
+			}
+			if !inside {
+				continue
+			}
+			hullPoint := bestNormal.Clone().MultiplyScalar(-bestDepth).Add(point)
+			addContact(&bestNormal, hullPoint, point)
+		}
+	}
+
+	return contactEqs, frictionEqs
+}
+
 func (n *Narrowphase) pointBehindFace(worldFace [3]math32.Vector3, faceNormal, point *math32.Vector3) bool {
 
 	pointInFace := worldFace[0].Clone()
@@ -0,0 +1,26 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// NewArrow creates an arrow geometry pointing up the Y axis from the origin with the specified
+// total length, radius of its cylindrical shaft, length and radius of its conical head,
+// and number of radial segments. The head length is limited to the total length.
+func NewArrow(length, shaftRadius, headLength, headRadius float32, radialSegments int) *Geometry {
+
+	headLength = math32.Min(headLength, length)
+	shaftLength := length - headLength
+	profile := []math32.Vector2{
+		{0, 0},
+		{shaftRadius, 0},
+		{shaftRadius, shaftLength},
+		{headRadius, shaftLength},
+		{0, length},
+	}
+	return NewLathe(profile, radialSegments, 0, 2*math32.Pi)
+}
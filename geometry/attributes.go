@@ -0,0 +1,196 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// AttribData returns a copy of the values of the attribute with the specified type,
+// with the values of consecutive vertices packed together regardless of how the
+// attribute is stored in the geometry's VBOs, and the number of elements per vertex.
+// Returns nil and 0 if the geometry has no such attribute.
+func (g *Geometry) AttribData(atype gls.AttribType) (math32.ArrayF32, int) {
+
+	vbo := g.VBO(atype)
+	if vbo == nil {
+		return nil, 0
+	}
+	size := int(vbo.Attrib(atype).NumElements)
+	stride := vbo.Stride()
+	offset := vbo.AttribOffset(atype)
+	buffer := vbo.Buffer()
+
+	data := math32.NewArrayF32(0, (buffer.Size()/stride)*size)
+	for i := offset; i+size <= buffer.Size(); i += stride {
+		data.Append((*buffer)[i : i+size]...)
+	}
+	return data, size
+}
+
+// SetAttribData sets the values of the attribute with the specified type
+// from packed per vertex values with the specified number of elements per vertex.
+// If the geometry has a VBO with only this attribute its buffer is replaced,
+// if the attribute is interleaved with others its values are overwritten in place,
+// and if the geometry doesn't have the attribute a new VBO is added.
+func (g *Geometry) SetAttribData(atype gls.AttribType, data math32.ArrayF32, size int) {
+
+	vbo := g.VBO(atype)
+	if vbo == nil {
+		vbo = gls.NewVBO(data).AddAttrib(atype)
+		vbo.Attrib(atype).NumElements = int32(size)
+		g.AddVBO(vbo)
+		g.invalidate()
+		return
+	}
+	attrib := vbo.Attrib(atype)
+	if vbo.AttribCount() == 1 {
+		attrib.NumElements = int32(size)
+		vbo.SetBuffer(data)
+		g.invalidate()
+		return
+	}
+
+	// Interleaved attribute: copies the values which fit the existing layout
+	stride := vbo.Stride()
+	offset := vbo.AttribOffset(atype)
+	buffer := vbo.Buffer()
+	n := int(attrib.NumElements)
+	if size < n {
+		n = size
+	}
+	for i, j := offset, 0; i+n <= buffer.Size() && j+n <= data.Size(); i, j = i+stride, j+size {
+		copy((*buffer)[i:i+n], data[j:j+n])
+	}
+	vbo.Update()
+	g.invalidate()
+}
+
+// invalidate marks all the cached geometric properties as invalid.
+func (g *Geometry) invalidate() {
+
+	g.boundingBoxValid = false
+	g.boundingSphereValid = false
+	g.areaValid = false
+	g.volumeValid = false
+	g.rotInertiaValid = false
+}
+
+// triangleIndices returns the vertex indices of all the triangles of the geometry,
+// generating sequential indices if the geometry is not indexed.
+func (g *Geometry) triangleIndices() math32.ArrayU32 {
+
+	if g.Indexed() {
+		return g.indices
+	}
+	count := 0
+	if vbo := g.VBO(gls.VertexPosition); vbo != nil {
+		count = vbo.Buffer().Size() / vbo.Stride()
+	}
+	indices := math32.NewArrayU32(0, count)
+	for i := 0; i < count-count%3; i++ {
+		indices.Append(uint32(i))
+	}
+	return indices
+}
+
+// setCornerAttrib sets the values of the attribute with the specified type from
+// per triangle corner values, in the order returned by triangleIndices().
+// The vertices of indexed geometries which are shared by corners with
+// different values are split into new vertices copied from the original.
+func (g *Geometry) setCornerAttrib(atype gls.AttribType, corners math32.ArrayF32, size int) {
+
+	vertexCount := 0
+	if vbo := g.VBO(gls.VertexPosition); vbo != nil {
+		vertexCount = vbo.Buffer().Size() / vbo.Stride()
+	}
+
+	// Non indexed geometry: each corner is a vertex
+	if !g.Indexed() {
+		data := math32.NewArrayF32(vertexCount*size, vertexCount*size)
+		copy(data, corners)
+		g.SetAttribData(atype, data, size)
+		return
+	}
+
+	// Assigns each corner to a vertex with the same value, creating new vertices as needed
+	newToOld := make([]int, vertexCount)
+	for i := range newToOld {
+		newToOld[i] = i
+	}
+	variants := make(map[uint32][]uint32)
+	values := make(map[uint32][]float32)
+	indices := math32.NewArrayU32(0, g.indices.Size())
+	indices.Append(g.indices...)
+	for c := 0; c+size <= corners.Size() && c/size < indices.Size(); c += size {
+		ci := c / size
+		vi := indices[ci]
+		value := corners[c : c+size]
+		found := false
+		for _, nv := range variants[vi] {
+			if equalValues(values[nv], value) {
+				indices[ci] = nv
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		nv := vi
+		if len(variants[vi]) > 0 {
+			nv = uint32(len(newToOld))
+			newToOld = append(newToOld, int(vi))
+		}
+		variants[vi] = append(variants[vi], nv)
+		values[nv] = value
+		indices[ci] = nv
+	}
+	if len(newToOld) > vertexCount {
+		g.remapVertices(newToOld)
+		g.SetIndices(indices)
+	}
+
+	// Builds the vertex values keeping the previous values of unreferenced vertices
+	data := math32.NewArrayF32(len(newToOld)*size, len(newToOld)*size)
+	if old, oldSize := g.AttribData(atype); old != nil && oldSize == size {
+		copy(data, old)
+	}
+	for nv, value := range values {
+		copy(data[int(nv)*size:], value)
+	}
+	g.SetAttribData(atype, data, size)
+}
+
+// remapVertices rebuilds the buffers of all the VBOs of the geometry so
+// that the new vertex i is a copy of the previous vertex newToOld[i].
+func (g *Geometry) remapVertices(newToOld []int) {
+
+	for _, vbo := range g.vbos {
+		stride := vbo.Stride()
+		buffer := vbo.Buffer()
+		data := math32.NewArrayF32(0, len(newToOld)*stride)
+		for _, old := range newToOld {
+			data.Append((*buffer)[old*stride : old*stride+stride]...)
+		}
+		vbo.SetBuffer(data)
+	}
+	g.invalidate()
+}
+
+// equalValues returns whether the two slices have the same values within a small tolerance.
+func equalValues(a, b []float32) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math32.Abs(a[i]-b[i]) > 1e-6 {
+			return false
+		}
+	}
+	return true
+}
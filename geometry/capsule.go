@@ -0,0 +1,79 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// NewCapsule creates a capsule geometry along the Y axis with the specified radius, height
+// of its cylindrical part, which is the distance between the centers of its hemispherical caps,
+// number of radial segments and number of segments of each cap from its pole to its equator.
+// The total height of the capsule is height + 2*radius.
+func NewCapsule(radius, height float32, radialSegments, capSegments int) *Geometry {
+
+	c := NewGeometry()
+
+	// Validate arguments
+	if radialSegments < 3 || capSegments < 1 {
+		panic("Invalid argument(s). Capsule needs at least 3 radial segments and 1 cap segment.")
+	}
+
+	// Each row of vertices is a ring with the same polar angle from the top,
+	// with the rings of the two equators at the ends of the cylindrical part.
+	rows := 2*capSegments + 2
+	arc := math32.Pi / 2 * radius
+	total := 2*arc + height
+	positions := math32.NewArrayF32(0, rows*(radialSegments+1)*3)
+	normals := math32.NewArrayF32(0, rows*(radialSegments+1)*3)
+	uvs := math32.NewArrayF32(0, rows*(radialSegments+1)*2)
+	indices := math32.NewArrayU32(0, 0)
+	for row := 0; row < rows; row++ {
+		var theta, y, dist float32
+		if row <= capSegments {
+			theta = float32(row) / float32(capSegments) * math32.Pi / 2
+			y = height / 2
+			dist = theta * radius
+		} else {
+			theta = math32.Pi/2 + float32(row-capSegments-1)/float32(capSegments)*math32.Pi/2
+			y = -height / 2
+			dist = arc + height + (theta-math32.Pi/2)*radius
+		}
+		sinTheta, cosTheta := math32.Sin(theta), math32.Cos(theta)
+		for x := 0; x <= radialSegments; x++ {
+			u := float32(x) / float32(radialSegments)
+			phi := u * 2 * math32.Pi
+			nx := -math32.Cos(phi) * sinTheta
+			nz := math32.Sin(phi) * sinTheta
+			positions.Append(radius*nx, y+radius*cosTheta, radius*nz)
+			normals.Append(nx, cosTheta, nz)
+			uvs.Append(u, dist/total)
+		}
+	}
+
+	// Skips the triangles which degenerate at the poles
+	stride := uint32(radialSegments + 1)
+	for row := uint32(0); row < uint32(rows-1); row++ {
+		for x := uint32(0); x < uint32(radialSegments); x++ {
+			v1 := row*stride + x + 1
+			v2 := row*stride + x
+			v3 := (row+1)*stride + x
+			v4 := (row+1)*stride + x + 1
+			if row != 0 {
+				indices.Append(v1, v2, v4)
+			}
+			if row != uint32(rows-2) {
+				indices.Append(v2, v3, v4)
+			}
+		}
+	}
+
+	c.SetIndices(indices)
+	c.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	c.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	c.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	return c
+}
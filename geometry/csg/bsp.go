@@ -0,0 +1,257 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package csg
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// epsilon is the tolerance used to decide whether a point is on a plane.
+const epsilon = 1e-5
+
+// vertex is a polygon vertex with its attributes.
+type vertex struct {
+	pos    math32.Vector3
+	normal math32.Vector3
+	uv     math32.Vector2
+}
+
+// interpolate returns the vertex between this one and other at the specified fraction.
+func (v *vertex) interpolate(other *vertex, t float32) vertex {
+
+	var r vertex
+	r.pos = v.pos
+	r.pos.Lerp(&other.pos, t)
+	r.normal = v.normal
+	r.normal.Lerp(&other.normal, t).Normalize()
+	r.uv = v.uv
+	r.uv.Lerp(&other.uv, t)
+	return r
+}
+
+// plane is a plane with unit normal and signed distance from the origin.
+type plane struct {
+	normal math32.Vector3
+	w      float32
+}
+
+// flip reverses the orientation of the plane.
+func (p *plane) flip() {
+
+	p.normal.Negate()
+	p.w = -p.w
+}
+
+// Classification of points and polygons relative to a plane
+const (
+	coplanar = 0
+	front    = 1
+	back     = 2
+	spanning = 3
+)
+
+// splitPolygon adds the polygon, or its parts if it spans the plane, to the lists of
+// coplanar front, coplanar back, front and back polygons.
+func (p *plane) splitPolygon(poly *polygon, coplanarFront, coplanarBack, fronts, backs *[]*polygon) {
+
+	// Classifies each vertex and the whole polygon
+	polyType := 0
+	types := make([]int, len(poly.vertices))
+	for i := range poly.vertices {
+		t := p.normal.Dot(&poly.vertices[i].pos) - p.w
+		vt := coplanar
+		if t < -epsilon {
+			vt = back
+		} else if t > epsilon {
+			vt = front
+		}
+		polyType |= vt
+		types[i] = vt
+	}
+
+	switch polyType {
+	case coplanar:
+		if p.normal.Dot(&poly.plane.normal) > 0 {
+			*coplanarFront = append(*coplanarFront, poly)
+		} else {
+			*coplanarBack = append(*coplanarBack, poly)
+		}
+	case front:
+		*fronts = append(*fronts, poly)
+	case back:
+		*backs = append(*backs, poly)
+	case spanning:
+		var f, b []vertex
+		n := len(poly.vertices)
+		for i := 0; i < n; i++ {
+			j := (i + 1) % n
+			ti, tj := types[i], types[j]
+			vi, vj := &poly.vertices[i], &poly.vertices[j]
+			if ti != back {
+				f = append(f, *vi)
+			}
+			if ti != front {
+				b = append(b, *vi)
+			}
+			if (ti | tj) == spanning {
+				var d math32.Vector3
+				d.SubVectors(&vj.pos, &vi.pos)
+				t := (p.w - p.normal.Dot(&vi.pos)) / p.normal.Dot(&d)
+				v := vi.interpolate(vj, t)
+				f = append(f, v)
+				b = append(b, v)
+			}
+		}
+		if len(f) >= 3 {
+			*fronts = append(*fronts, &polygon{vertices: f, plane: poly.plane, matIndex: poly.matIndex})
+		}
+		if len(b) >= 3 {
+			*backs = append(*backs, &polygon{vertices: b, plane: poly.plane, matIndex: poly.matIndex})
+		}
+	}
+}
+
+// polygon is a convex planar polygon.
+type polygon struct {
+	vertices []vertex
+	plane    plane
+	matIndex int
+}
+
+// newPolygon returns a new polygon with the specified vertices in counterclockwise
+// order and material index, or nil if the vertices are degenerate.
+func newPolygon(vertices []vertex, matIndex int) *polygon {
+
+	var e1, e2, n math32.Vector3
+	e1.SubVectors(&vertices[1].pos, &vertices[0].pos)
+	e2.SubVectors(&vertices[2].pos, &vertices[0].pos)
+	n.CrossVectors(&e1, &e2)
+	if n.Length() < epsilon*epsilon {
+		return nil
+	}
+	n.Normalize()
+	return &polygon{vertices: vertices, plane: plane{n, n.Dot(&vertices[0].pos)}, matIndex: matIndex}
+}
+
+// flip reverses the orientation of the polygon.
+func (p *polygon) flip() {
+
+	for i, j := 0, len(p.vertices)-1; i < j; i, j = i+1, j-1 {
+		p.vertices[i], p.vertices[j] = p.vertices[j], p.vertices[i]
+	}
+	for i := range p.vertices {
+		p.vertices[i].normal.Negate()
+	}
+	p.plane.flip()
+}
+
+// node is a node of a BSP tree. The polygons in front of the node plane are in the front
+// subtree, the ones behind it in the back subtree and the coplanar ones in the node.
+type node struct {
+	plane    *plane
+	front    *node
+	back     *node
+	polygons []*polygon
+}
+
+// newNode returns a new BSP tree built from the specified polygons.
+func newNode(polygons []*polygon) *node {
+
+	n := new(node)
+	n.build(polygons)
+	return n
+}
+
+// invert converts the solid space into empty space and vice versa.
+func (n *node) invert() {
+
+	for _, p := range n.polygons {
+		p.flip()
+	}
+	if n.plane != nil {
+		n.plane.flip()
+	}
+	if n.front != nil {
+		n.front.invert()
+	}
+	if n.back != nil {
+		n.back.invert()
+	}
+	n.front, n.back = n.back, n.front
+}
+
+// clipPolygons returns the parts of the specified polygons which are outside the solid of this tree.
+func (n *node) clipPolygons(polygons []*polygon) []*polygon {
+
+	if n.plane == nil {
+		return append([]*polygon(nil), polygons...)
+	}
+	var fronts, backs []*polygon
+	for _, p := range polygons {
+		n.plane.splitPolygon(p, &fronts, &backs, &fronts, &backs)
+	}
+	if n.front != nil {
+		fronts = n.front.clipPolygons(fronts)
+	}
+	if n.back != nil {
+		backs = n.back.clipPolygons(backs)
+	} else {
+		backs = nil
+	}
+	return append(fronts, backs...)
+}
+
+// clipTo removes the polygons of this tree which are inside the solid of the other tree.
+func (n *node) clipTo(other *node) {
+
+	n.polygons = other.clipPolygons(n.polygons)
+	if n.front != nil {
+		n.front.clipTo(other)
+	}
+	if n.back != nil {
+		n.back.clipTo(other)
+	}
+}
+
+// allPolygons appends all the polygons of this tree to the specified list and returns it.
+func (n *node) allPolygons(list []*polygon) []*polygon {
+
+	list = append(list, n.polygons...)
+	if n.front != nil {
+		list = n.front.allPolygons(list)
+	}
+	if n.back != nil {
+		list = n.back.allPolygons(list)
+	}
+	return list
+}
+
+// build adds the specified polygons to this tree, splitting them by the node planes.
+func (n *node) build(polygons []*polygon) {
+
+	if len(polygons) == 0 {
+		return
+	}
+	if n.plane == nil {
+		pl := polygons[0].plane
+		n.plane = &pl
+	}
+	var fronts, backs []*polygon
+	for _, p := range polygons {
+		n.plane.splitPolygon(p, &n.polygons, &n.polygons, &fronts, &backs)
+	}
+	if len(fronts) > 0 {
+		if n.front == nil {
+			n.front = new(node)
+		}
+		n.front.build(fronts)
+	}
+	if len(backs) > 0 {
+		if n.back == nil {
+			n.back = new(node)
+		}
+		n.back.build(backs)
+	}
+}
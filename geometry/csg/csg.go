@@ -0,0 +1,207 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package csg implements constructive solid geometry operations on geometries
+// (union, subtraction and intersection) using binary space partitioning trees.
+// The operands should be closed (watertight) meshes.
+package csg
+
+import (
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Union returns a new geometry with the union of the specified geometries
+// transformed by their respective world matrices, which may be nil.
+// See Subtract for how the vertex attributes and groups are kept.
+func Union(a *geometry.Geometry, ma *math32.Matrix4, b *geometry.Geometry, mb *math32.Matrix4) *geometry.Geometry {
+
+	na, nb, offset := operands(a, ma, b, mb)
+	na.clipTo(nb)
+	nb.clipTo(na)
+	nb.invert()
+	nb.clipTo(na)
+	nb.invert()
+	na.build(nb.allPolygons(nil))
+	return toGeometry(na.allPolygons(nil), offset)
+}
+
+// Subtract returns a new geometry with the geometry b subtracted from the geometry a,
+// both transformed by their respective world matrices, which may be nil.
+// The result is in world coordinates and keeps the normals and texture coordinates of the
+// faces of both geometries. Each face is in a group with the material index of its source
+// face, with the material indices of b offset by the number of material indices of a.
+func Subtract(a *geometry.Geometry, ma *math32.Matrix4, b *geometry.Geometry, mb *math32.Matrix4) *geometry.Geometry {
+
+	na, nb, offset := operands(a, ma, b, mb)
+	na.invert()
+	na.clipTo(nb)
+	nb.clipTo(na)
+	nb.invert()
+	nb.clipTo(na)
+	nb.invert()
+	na.build(nb.allPolygons(nil))
+	na.invert()
+	return toGeometry(na.allPolygons(nil), offset)
+}
+
+// Intersect returns a new geometry with the intersection of the specified geometries
+// transformed by their respective world matrices, which may be nil.
+// See Subtract for how the vertex attributes and groups are kept.
+func Intersect(a *geometry.Geometry, ma *math32.Matrix4, b *geometry.Geometry, mb *math32.Matrix4) *geometry.Geometry {
+
+	na, nb, offset := operands(a, ma, b, mb)
+	na.invert()
+	nb.clipTo(na)
+	nb.invert()
+	na.clipTo(nb)
+	nb.clipTo(na)
+	na.build(nb.allPolygons(nil))
+	na.invert()
+	return toGeometry(na.allPolygons(nil), offset)
+}
+
+// operands builds the BSP trees of both geometries and returns
+// the material index offset of the faces of the second one.
+func operands(a *geometry.Geometry, ma *math32.Matrix4, b *geometry.Geometry, mb *math32.Matrix4) (*node, *node, int) {
+
+	pa, maxA := polygons(a, ma, 0)
+	pb, _ := polygons(b, mb, maxA+1)
+	return newNode(pa), newNode(pb), maxA + 1
+}
+
+// polygons returns the triangles of the geometry transformed by the specified
+// matrix as polygons and the maximum material index of its groups.
+func polygons(g *geometry.Geometry, m *math32.Matrix4, matOffset int) ([]*polygon, int) {
+
+	positions, _ := g.AttribData(gls.VertexPosition)
+	normals, _ := g.AttribData(gls.VertexNormal)
+	uvs, _ := g.AttribData(gls.VertexTexcoord)
+	if positions == nil {
+		return nil, 0
+	}
+	var world math32.Matrix4
+	var normalMatrix math32.Matrix3
+	world.Identity()
+	if m != nil {
+		world = *m
+	}
+	normalMatrix.GetNormalMatrix(&world)
+
+	// Vertex indices of the triangles
+	indices := g.Indices()
+	if !g.Indexed() {
+		count := positions.Size() / 3
+		indices = math32.NewArrayU32(0, count)
+		for i := 0; i < count; i++ {
+			indices.Append(uint32(i))
+		}
+	}
+
+	// Material index of each triangle from the geometry groups
+	matIndex := make([]int, indices.Size()/3)
+	maxMat := 0
+	for gi := 0; gi < g.GroupCount(); gi++ {
+		group := g.GroupAt(gi)
+		for i := group.Start / 3; i < (group.Start+group.Count)/3 && i < len(matIndex); i++ {
+			matIndex[i] = group.Matindex
+		}
+		if group.Matindex > maxMat {
+			maxMat = group.Matindex
+		}
+	}
+
+	var polys []*polygon
+	for f := 0; f+2 < indices.Size(); f += 3 {
+		verts := make([]vertex, 3)
+		for c := 0; c < 3; c++ {
+			vi := int(indices[f+c])
+			v := &verts[c]
+			positions.GetVector3(3*vi, &v.pos)
+			v.pos.ApplyMatrix4(&world)
+			if normals != nil {
+				normals.GetVector3(3*vi, &v.normal)
+				v.normal.ApplyMatrix3(&normalMatrix).Normalize()
+			}
+			if uvs != nil {
+				uvs.GetVector2(2*vi, &v.uv)
+			}
+		}
+		p := newPolygon(verts, matIndex[f/3]+matOffset)
+		if p == nil {
+			continue
+		}
+		// Uses the face normal for geometries without normals
+		if normals == nil {
+			for i := range p.vertices {
+				p.vertices[i].normal = p.plane.normal
+			}
+		}
+		polys = append(polys, p)
+	}
+	return polys, maxMat
+}
+
+// toGeometry returns a new indexed geometry with the specified polygons grouped by material index.
+func toGeometry(polys []*polygon, matCount int) *geometry.Geometry {
+
+	g := geometry.NewGeometry()
+	positions := math32.NewArrayF32(0, 0)
+	normals := math32.NewArrayF32(0, 0)
+	uvs := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, 0)
+
+	// Welds the vertices with the same attributes
+	type vertexKey struct {
+		pos, normal [3]float32
+		uv          [2]float32
+	}
+	vertexIndex := make(map[vertexKey]uint32)
+	index := func(v *vertex) uint32 {
+		key := vertexKey{
+			[3]float32{v.pos.X, v.pos.Y, v.pos.Z},
+			[3]float32{v.normal.X, v.normal.Y, v.normal.Z},
+			[2]float32{v.uv.X, v.uv.Y},
+		}
+		if idx, ok := vertexIndex[key]; ok {
+			return idx
+		}
+		idx := uint32(positions.Size() / 3)
+		positions.AppendVector3(&v.pos)
+		normals.AppendVector3(&v.normal)
+		uvs.AppendVector2(&v.uv)
+		vertexIndex[key] = idx
+		return idx
+	}
+
+	// Collects the material indices present and triangulates the polygons of each one
+	maxMat := matCount
+	for _, p := range polys {
+		if p.matIndex > maxMat {
+			maxMat = p.matIndex
+		}
+	}
+	for mat := 0; mat <= maxMat; mat++ {
+		start := indices.Size()
+		for _, p := range polys {
+			if p.matIndex != mat {
+				continue
+			}
+			i0 := index(&p.vertices[0])
+			for i := 2; i < len(p.vertices); i++ {
+				indices.Append(i0, index(&p.vertices[i-1]), index(&p.vertices[i]))
+			}
+		}
+		if indices.Size() > start {
+			g.AddGroup(start, indices.Size()-start, mat)
+		}
+	}
+
+	g.SetIndices(indices)
+	g.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	g.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	g.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	return g
+}
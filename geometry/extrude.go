@@ -0,0 +1,133 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// creaseAngle is the minimum angle in radians between adjacent faces
+// of generated geometries for their common edge to be rendered sharp.
+const creaseAngle = math32.Pi / 6
+
+// NewExtrude creates an extruded geometry from the specified shape, which is in the XY plane
+// and extruded along the Z axis from 0 to depth. If bevelSegments is greater than zero the edges
+// of the caps are rounded with a quarter circle profile which extends the sides outwards by
+// bevelSize and the caps beyond the extrusion by bevelThickness.
+// The geometry has two groups: the caps (0) and the sides (1).
+// The texture coordinates of the caps are the shape coordinates and the ones of the sides
+// are the length along the contour and the depth.
+func NewExtrude(shape *Shape, depth, bevelThickness, bevelSize float32, bevelSegments int) *Geometry {
+
+	e := NewGeometry()
+
+	// Layers of the sides as Z position and outward offset of the contours
+	type layer struct{ z, offset float32 }
+	var layers []layer
+	if bevelSegments > 0 && (bevelThickness > 0 || bevelSize > 0) {
+		for s := 0; s <= bevelSegments; s++ {
+			a := float32(s) / float32(bevelSegments) * math32.Pi / 2
+			layers = append(layers, layer{-bevelThickness * math32.Cos(a), bevelSize * math32.Sin(a)})
+		}
+		for s := bevelSegments; s >= 0; s-- {
+			a := float32(s) / float32(bevelSegments) * math32.Pi / 2
+			layers = append(layers, layer{depth + bevelThickness*math32.Cos(a), bevelSize * math32.Sin(a)})
+		}
+	} else {
+		layers = []layer{{0, 0}, {depth, 0}}
+	}
+
+	positions := math32.NewArrayF32(0, 0)
+	uvs := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, 0)
+
+	// Back and front caps
+	points, triangles := shape.Triangulate()
+	zBack, zFront := layers[0].z, layers[len(layers)-1].z
+	for _, z := range []float32{zBack, zFront} {
+		base := uint32(positions.Size() / 3)
+		for _, p := range points {
+			positions.Append(p.X, p.Y, z)
+			uvs.Append(p.X, p.Y)
+		}
+		for i := 0; i+2 < len(triangles); i += 3 {
+			if z == zBack {
+				indices.Append(base+triangles[i], base+triangles[i+2], base+triangles[i+1])
+			} else {
+				indices.Append(base+triangles[i], base+triangles[i+1], base+triangles[i+2])
+			}
+		}
+	}
+	e.AddGroup(0, indices.Size(), 0)
+	sidesStart := indices.Size()
+
+	// Sides of each contour with a duplicated first point to close the texture coordinates
+	for _, contour := range shape.Contours() {
+		n := len(contour)
+		if n < 3 {
+			continue
+		}
+		miters := contourMiters(contour)
+		base := uint32(positions.Size() / 3)
+		for _, l := range layers {
+			var length float32
+			for i := 0; i <= n; i++ {
+				p := &contour[i%n]
+				if i > 0 {
+					length += p.DistanceTo(&contour[i-1])
+				}
+				m := &miters[i%n]
+				positions.Append(p.X+m.X*l.offset, p.Y+m.Y*l.offset, l.z)
+				uvs.Append(length, l.z)
+			}
+		}
+		row := uint32(n + 1)
+		for l := uint32(0); l+1 < uint32(len(layers)); l++ {
+			for i := uint32(0); i < uint32(n); i++ {
+				a := base + l*row + i
+				b := a + 1
+				c := b + row
+				d := a + row
+				indices.Append(a, b, c, a, c, d)
+			}
+		}
+	}
+	e.AddGroup(sidesStart, indices.Size()-sidesStart, 1)
+
+	e.SetIndices(indices)
+	e.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	e.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	e.ComputeVertexNormals(creaseAngle)
+	return e
+}
+
+// contourMiters returns for each point of a closed contour the direction along which it
+// is moved to offset the contour outwards by one unit, considering the contour outside
+// to be at the right of its edges.
+func contourMiters(contour []math32.Vector2) []math32.Vector2 {
+
+	n := len(contour)
+	miters := make([]math32.Vector2, n)
+	for i := range contour {
+		prev, p, next := &contour[(i+n-1)%n], &contour[i], &contour[(i+1)%n]
+		var e0, e1 math32.Vector2
+		e0.SubVectors(p, prev).Normalize()
+		e1.SubVectors(next, p).Normalize()
+		n0 := math32.Vector2{e0.Y, -e0.X}
+		n1 := math32.Vector2{e1.Y, -e1.X}
+		m := n0
+		m.Add(&n1).Normalize()
+		// Scales the bisector so the offset edges stay parallel, limited at sharp corners
+		cos := m.Dot(&n0)
+		if cos > 0.5 {
+			m.MultiplyScalar(1 / cos)
+		} else {
+			m.MultiplyScalar(2)
+		}
+		miters[i] = m
+	}
+	return miters
+}
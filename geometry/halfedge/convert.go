@@ -0,0 +1,143 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package halfedge
+
+import (
+	"errors"
+	"math"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// NewMeshFromGeometry creates and returns a pointer to a new half-edge mesh with the
+// triangles of the specified geometry. Vertices with the same position, within a tolerance
+// relative to the size of the geometry, are merged,
+// so the mesh is connected across the seams of the geometry, whose texture coordinates
+// are kept in the half-edges. See NewMesh for the errors returned.
+func NewMeshFromGeometry(geom *geometry.Geometry) (*Mesh, error) {
+
+	positions, _ := geom.AttribData(gls.VertexPosition)
+	if positions == nil {
+		return nil, errors.New("geometry has no vertex positions")
+	}
+	uvs, _ := geom.AttribData(gls.VertexTexcoord)
+
+	// Merges the vertices with the same position using a spatial hash
+	vertexCount := positions.Size() / 3
+	bbox := geom.BoundingBox()
+	tolerance := bbox.Min.DistanceTo(&bbox.Max) * 1e-6
+	if tolerance == 0 {
+		tolerance = 1e-6
+	}
+	// Cells with 64 bits coordinates clamped far beyond the float32 precision of the positions
+	type cell [3]int64
+	const maxCell = 1 << 62
+	cellCoord := func(x float32) int64 {
+		return int64(math.Max(-maxCell, math.Min(maxCell, math.Floor(float64(x)/float64(tolerance)))))
+	}
+	cellOf := func(p *math32.Vector3) cell {
+		return cell{cellCoord(p.X), cellCoord(p.Y), cellCoord(p.Z)}
+	}
+	grid := make(map[cell][]int)
+	vmap := make([]int, vertexCount)
+	var points []math32.Vector3
+	for i := 0; i < vertexCount; i++ {
+		var p math32.Vector3
+		positions.GetVector3(3*i, &p)
+		c := cellOf(&p)
+		v := -1
+		for dx := int64(-1); dx <= 1 && v < 0; dx++ {
+			for dy := int64(-1); dy <= 1 && v < 0; dy++ {
+				for dz := int64(-1); dz <= 1 && v < 0; dz++ {
+					for _, pv := range grid[cell{c[0] + dx, c[1] + dy, c[2] + dz}] {
+						if points[pv].DistanceTo(&p) <= tolerance {
+							v = pv
+							break
+						}
+					}
+				}
+			}
+		}
+		if v < 0 {
+			v = len(points)
+			points = append(points, p)
+			grid[c] = append(grid[c], v)
+		}
+		vmap[i] = v
+	}
+
+	// Builds the triangles with the indices of the merged vertices
+	indices := geom.Indices()
+	if !geom.Indexed() {
+		indices = math32.NewArrayU32(0, vertexCount)
+		for i := 0; i < vertexCount; i++ {
+			indices.Append(uint32(i))
+		}
+	}
+	var faces [][]int
+	var corners [][3]uint32
+	for i := 0; i+2 < indices.Size(); i += 3 {
+		tri := [3]uint32{indices[i], indices[i+1], indices[i+2]}
+		faces = append(faces, []int{vmap[tri[0]], vmap[tri[1]], vmap[tri[2]]})
+		corners = append(corners, tri)
+	}
+	m, err := NewMesh(points, faces)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sets the texture coordinates of the face corners, skipping the ignored degenerate triangles
+	if uvs != nil {
+		m.HasUV = true
+		f := 0
+		for i, face := range faces {
+			if face[0] == face[1] || face[1] == face[2] || face[2] == face[0] {
+				continue
+			}
+			h := m.Faces[f].HalfEdge
+			for _, idx := range corners[i] {
+				uvs.GetVector2(2*int(idx), &m.HalfEdges[h].UV)
+				h = m.HalfEdges[h].Next
+			}
+			f++
+		}
+	}
+	return m, nil
+}
+
+// ToGeometry creates and returns a new indexed geometry with the faces of the mesh
+// triangulated as fans, and with texture coordinates if the mesh has them.
+// Smooth vertex normals are computed with the specified maximum angle in radians
+// between the faces sharing them, as in geometry.ComputeVertexNormals.
+func (m *Mesh) ToGeometry(maxAngle float32) *geometry.Geometry {
+
+	positions := math32.NewArrayF32(0, 0)
+	uvs := math32.NewArrayF32(0, 0)
+	for f := range m.Faces {
+		if m.Faces[f].Removed {
+			continue
+		}
+		hs := m.FaceHalfEdges(f)
+		for i := 1; i+1 < len(hs); i++ {
+			for _, h := range []int{hs[0], hs[i], hs[i+1]} {
+				positions.AppendVector3(&m.Vertices[m.HalfEdges[h].Vertex].Position)
+				if m.HasUV {
+					uvs.AppendVector2(&m.HalfEdges[h].UV)
+				}
+			}
+		}
+	}
+
+	geom := geometry.NewGeometry()
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	if m.HasUV {
+		geom.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	}
+	geom.ComputeVertexNormals(maxAngle)
+	geom.Reindex()
+	return geom
+}
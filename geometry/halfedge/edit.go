@@ -0,0 +1,344 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package halfedge
+
+// FlipEdge replaces the edge of the specified half-edge, shared by two triangles,
+// by the edge connecting the opposite vertices of the triangles.
+// Returns false, without changing the mesh, if the half-edge was removed, if the edge is on a border, if its faces are
+// not triangles or if the opposite vertices are already connected.
+func (m *Mesh) FlipEdge(h int) bool {
+
+	if m.HalfEdges[h].Removed {
+		return false
+	}
+	t := m.HalfEdges[h].Twin
+	f1 := m.HalfEdges[h].Face
+	f2 := m.HalfEdges[t].Face
+	if f1 < 0 || f2 < 0 || len(m.FaceHalfEdges(f1)) != 3 || len(m.FaceHalfEdges(f2)) != 3 {
+		return false
+	}
+
+	// Triangles (a, b, c) and (b, a, d) become (c, a, d) and (d, b, c)
+	h1 := m.HalfEdges[h].Next
+	h2 := m.HalfEdges[h1].Next
+	t1 := m.HalfEdges[t].Next
+	t2 := m.HalfEdges[t1].Next
+	a := m.HalfEdges[h].Vertex
+	b := m.HalfEdges[t].Vertex
+	c := m.HalfEdges[h2].Vertex
+	d := m.HalfEdges[t2].Vertex
+	if c == d || m.FindHalfEdge(c, d) >= 0 {
+		return false
+	}
+
+	m.HalfEdges[h].Vertex = d
+	m.HalfEdges[h].UV = m.HalfEdges[t2].UV
+	m.HalfEdges[t].Vertex = c
+	m.HalfEdges[t].UV = m.HalfEdges[h2].UV
+	m.HalfEdges[h].Sharp = false
+	m.HalfEdges[t].Sharp = false
+	m.link(f1, h2, t1, h)
+	m.link(f2, t2, h1, t)
+	if m.Vertices[a].HalfEdge == h {
+		m.Vertices[a].HalfEdge = t1
+	}
+	if m.Vertices[b].HalfEdge == t {
+		m.Vertices[b].HalfEdge = h1
+	}
+	return true
+}
+
+// SplitEdge inserts a new vertex in the edge of the specified half-edge at the specified
+// parameter along it, from 0 at its origin to 1 at its target, and returns the new vertex.
+// The triangles sharing the edge are split in two, while other polygons get an additional vertex.
+// After the split the specified half-edge ends at the new vertex.
+func (m *Mesh) SplitEdge(h int, t float32) int {
+
+	tw := m.HalfEdges[h].Twin
+	a := m.HalfEdges[h].Vertex
+	b := m.HalfEdges[tw].Vertex
+	hn := m.HalfEdges[h].Next
+	tn := m.HalfEdges[tw].Next
+	hTriangle := m.HalfEdges[h].Face >= 0 && len(m.FaceHalfEdges(m.HalfEdges[h].Face)) == 3
+	tTriangle := m.HalfEdges[tw].Face >= 0 && len(m.FaceHalfEdges(m.HalfEdges[tw].Face)) == 3
+
+	// Creates the new vertex
+	v := len(m.Vertices)
+	pos := m.Vertices[a].Position
+	pos.Lerp(&m.Vertices[b].Position, t)
+	m.Vertices = append(m.Vertices, Vertex{Position: pos})
+
+	// Creates the half-edges from the new vertex: h2 to b after h and t2 to a after tw
+	h2 := len(m.HalfEdges)
+	t2 := h2 + 1
+	uvh := m.HalfEdges[h].UV
+	uvh.Lerp(&m.HalfEdges[hn].UV, t)
+	uvt := m.HalfEdges[tn].UV
+	uvt.Lerp(&m.HalfEdges[tw].UV, t)
+	m.HalfEdges = append(m.HalfEdges,
+		HalfEdge{Vertex: v, Face: m.HalfEdges[h].Face, Next: hn, Prev: h, Twin: tw, UV: uvh, Sharp: m.HalfEdges[h].Sharp},
+		HalfEdge{Vertex: v, Face: m.HalfEdges[tw].Face, Next: tn, Prev: tw, Twin: h, UV: uvt, Sharp: m.HalfEdges[h].Sharp},
+	)
+	m.HalfEdges[h].Next = h2
+	m.HalfEdges[hn].Prev = h2
+	m.HalfEdges[h].Twin = t2
+	m.HalfEdges[tw].Next = t2
+	m.HalfEdges[tn].Prev = t2
+	m.HalfEdges[tw].Twin = h2
+	m.Vertices[v].HalfEdge = h2
+	if m.HalfEdges[tw].Face < 0 {
+		m.Vertices[v].HalfEdge = t2
+	}
+
+	// Splits the triangles connecting the new vertex to their opposite vertices
+	if hTriangle {
+		m.SplitFace(h2, m.HalfEdges[h].Prev)
+	}
+	if tTriangle {
+		m.SplitFace(t2, m.HalfEdges[tw].Prev)
+	}
+	return v
+}
+
+// SplitFace splits the face of the specified half-edges, which must be different and
+// not consecutive, by a new edge connecting their origin vertices, and returns the new face,
+// which is the part of the face starting with h1.
+func (m *Mesh) SplitFace(h1, h2 int) int {
+
+	f := m.HalfEdges[h1].Face
+	nf := len(m.Faces)
+	p1 := m.HalfEdges[h1].Prev
+	p2 := m.HalfEdges[h2].Prev
+	e := len(m.HalfEdges)
+	et := e + 1
+
+	// e goes from the origin of h1 to the origin of h2 and stays in the face,
+	// and its twin et goes back and closes the new face.
+	m.HalfEdges = append(m.HalfEdges,
+		HalfEdge{Vertex: m.HalfEdges[h1].Vertex, Face: f, Next: h2, Prev: p1, Twin: et, UV: m.HalfEdges[h1].UV},
+		HalfEdge{Vertex: m.HalfEdges[h2].Vertex, Face: nf, Next: h1, Prev: p2, Twin: e, UV: m.HalfEdges[h2].UV},
+	)
+	m.HalfEdges[p1].Next = e
+	m.HalfEdges[h2].Prev = e
+	m.HalfEdges[p2].Next = et
+	m.HalfEdges[h1].Prev = et
+	m.Faces = append(m.Faces, Face{HalfEdge: h1})
+	m.Faces[f].HalfEdge = h2
+	for h := h1; h != et; h = m.HalfEdges[h].Next {
+		m.HalfEdges[h].Face = nf
+	}
+	m.HalfEdges[et].Face = nf
+	return nf
+}
+
+// DissolveEdge removes the edge of the specified half-edge merging its two faces.
+// Returns false, without changing the mesh, if the half-edge was removed,
+// if the edge is on a border or if both sides of the edge are the same face.
+func (m *Mesh) DissolveEdge(h int) bool {
+
+	if m.HalfEdges[h].Removed {
+		return false
+	}
+	t := m.HalfEdges[h].Twin
+	f1 := m.HalfEdges[h].Face
+	f2 := m.HalfEdges[t].Face
+	if f1 < 0 || f2 < 0 || f1 == f2 {
+		return false
+	}
+	hn := m.HalfEdges[h].Next
+	hp := m.HalfEdges[h].Prev
+	tn := m.HalfEdges[t].Next
+	tp := m.HalfEdges[t].Prev
+	for _, he := range m.FaceHalfEdges(f2) {
+		m.HalfEdges[he].Face = f1
+	}
+	m.HalfEdges[hp].Next = tn
+	m.HalfEdges[tn].Prev = hp
+	m.HalfEdges[tp].Next = hn
+	m.HalfEdges[hn].Prev = tp
+	m.HalfEdges[h].Removed = true
+	m.HalfEdges[t].Removed = true
+	m.Faces[f1].HalfEdge = hn
+	m.Faces[f2].Removed = true
+	if a := m.HalfEdges[h].Vertex; m.Vertices[a].HalfEdge == h {
+		m.Vertices[a].HalfEdge = tn
+	}
+	if b := m.HalfEdges[t].Vertex; m.Vertices[b].HalfEdge == t {
+		m.Vertices[b].HalfEdge = hn
+	}
+	return true
+}
+
+// CollapseEdge collapses the edge of the specified half-edge, shared by triangles,
+// merging its target vertex into its origin vertex, which is moved to the middle of the edge,
+// and removing the triangles sharing the edge.
+// Returns false, without changing the mesh, if the half-edge was removed, if the faces of the edge are not triangles or
+// if the collapse would change the topology of the mesh or make it non manifold.
+func (m *Mesh) CollapseEdge(h int) bool {
+
+	if m.HalfEdges[h].Removed {
+		return false
+	}
+	t := m.HalfEdges[h].Twin
+	a := m.HalfEdges[h].Vertex
+	b := m.HalfEdges[t].Vertex
+	f1 := m.HalfEdges[h].Face
+	f2 := m.HalfEdges[t].Face
+	if (f1 >= 0 && len(m.FaceHalfEdges(f1)) != 3) || (f2 >= 0 && len(m.FaceHalfEdges(f2)) != 3) {
+		return false
+	}
+
+	// Checks the link condition: the only common neighbors of a and b
+	// must be the opposite vertices of the triangles sharing the edge
+	opposite := make(map[int]bool)
+	if f1 >= 0 {
+		opposite[m.HalfEdges[m.HalfEdges[h].Prev].Vertex] = true
+	}
+	if f2 >= 0 {
+		opposite[m.HalfEdges[m.HalfEdges[t].Prev].Vertex] = true
+	}
+	neighbors := make(map[int]bool)
+	for _, v := range m.VertexNeighbors(a) {
+		neighbors[v] = true
+	}
+	for _, v := range m.VertexNeighbors(b) {
+		if neighbors[v] && !opposite[v] {
+			return false
+		}
+	}
+	if m.IsBoundaryVertex(a) && m.IsBoundaryVertex(b) && !m.IsBoundaryEdge(h) {
+		return false
+	}
+	for _, he := range []int{h, t} {
+		if m.HalfEdges[he].Face >= 0 {
+			n := m.HalfEdges[he].Next
+			p := m.HalfEdges[he].Prev
+			if m.HalfEdges[m.HalfEdges[n].Twin].Face < 0 && m.HalfEdges[m.HalfEdges[p].Twin].Face < 0 {
+				return false
+			}
+		}
+	}
+
+	// Moves the outgoing half-edges of b to a
+	outgoing := m.VertexHalfEdges(b)
+	for _, he := range outgoing {
+		m.HalfEdges[he].Vertex = a
+	}
+	m.Vertices[a].Position.Lerp(&m.Vertices[b].Position, 0.5)
+	m.Vertices[b].Removed = true
+	m.Vertices[b].HalfEdge = -1
+
+	// Removes the triangles merging the twins of their other edges,
+	// or removes the half-edge from its boundary loop,
+	// and then fixes the outgoing half-edges of the affected vertices.
+	var fix [][2]int
+	for _, he := range []int{h, t} {
+		p := m.HalfEdges[he].Prev
+		n := m.HalfEdges[he].Next
+		m.HalfEdges[he].Removed = true
+		f := m.HalfEdges[he].Face
+		if f < 0 {
+			m.HalfEdges[p].Next = n
+			m.HalfEdges[n].Prev = p
+			fix = append(fix, [2]int{m.HalfEdges[n].Vertex, n})
+			continue
+		}
+		m.Faces[f].Removed = true
+		m.HalfEdges[n].Removed = true
+		m.HalfEdges[p].Removed = true
+		nt := m.HalfEdges[n].Twin
+		pt := m.HalfEdges[p].Twin
+		m.HalfEdges[nt].Twin = pt
+		m.HalfEdges[pt].Twin = nt
+		fix = append(fix, [2]int{m.HalfEdges[nt].Vertex, nt}, [2]int{m.HalfEdges[pt].Vertex, pt})
+	}
+	for _, vh := range fix {
+		m.fixVertex(vh[0], vh[1])
+	}
+	return true
+}
+
+// fixVertex sets the outgoing half-edge of the specified vertex to the specified
+// half-edge if the current one was removed, and then to its boundary half-edge if any.
+func (m *Mesh) fixVertex(v, h int) {
+
+	if cur := m.Vertices[v].HalfEdge; cur < 0 || m.HalfEdges[cur].Removed {
+		m.Vertices[v].HalfEdge = h
+	}
+	start := m.Vertices[v].HalfEdge
+	he := start
+	for i := 0; i < len(m.HalfEdges); i++ {
+		if m.HalfEdges[he].Face < 0 {
+			m.Vertices[v].HalfEdge = he
+			return
+		}
+		he = m.HalfEdges[m.HalfEdges[he].Prev].Twin
+		if he == start {
+			return
+		}
+	}
+}
+
+// link links the specified half-edges in a loop for the specified face.
+func (m *Mesh) link(f int, hs ...int) {
+
+	for i, h := range hs {
+		m.HalfEdges[h].Face = f
+		m.HalfEdges[h].Next = hs[(i+1)%len(hs)]
+		m.HalfEdges[h].Prev = hs[(i+len(hs)-1)%len(hs)]
+	}
+	m.Faces[f].HalfEdge = hs[0]
+}
+
+// Compact discards the elements removed by editing operations and renumbers the remaining ones.
+func (m *Mesh) Compact() {
+
+	vmap := make([]int, len(m.Vertices))
+	hmap := make([]int, len(m.HalfEdges))
+	fmap := make([]int, len(m.Faces))
+	vertices := m.Vertices[:0]
+	for v, vert := range m.Vertices {
+		vmap[v] = len(vertices)
+		if !vert.Removed {
+			vertices = append(vertices, vert)
+		}
+	}
+	halfEdges := m.HalfEdges[:0]
+	for h, he := range m.HalfEdges {
+		hmap[h] = len(halfEdges)
+		if !he.Removed {
+			halfEdges = append(halfEdges, he)
+		}
+	}
+	faces := m.Faces[:0]
+	for f, face := range m.Faces {
+		fmap[f] = len(faces)
+		if !face.Removed {
+			faces = append(faces, face)
+		}
+	}
+
+	for i := range vertices {
+		if vertices[i].HalfEdge >= 0 {
+			vertices[i].HalfEdge = hmap[vertices[i].HalfEdge]
+		}
+	}
+	for i := range halfEdges {
+		he := &halfEdges[i]
+		he.Vertex = vmap[he.Vertex]
+		he.Next = hmap[he.Next]
+		he.Prev = hmap[he.Prev]
+		he.Twin = hmap[he.Twin]
+		if he.Face >= 0 {
+			he.Face = fmap[he.Face]
+		}
+	}
+	for i := range faces {
+		faces[i].HalfEdge = hmap[faces[i].HalfEdge]
+	}
+	m.Vertices = vertices
+	m.HalfEdges = halfEdges
+	m.Faces = faces
+}
@@ -0,0 +1,381 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package halfedge implements a half-edge mesh data structure which can be built from
+// and converted back to a geometry, supporting adjacency queries, boundary and
+// manifold checks, topological editing operations and subdivision surfaces.
+package halfedge
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Mesh is a polygonal mesh represented by half-edges.
+// Each edge of the mesh is made of two half-edges with opposite directions which are twins.
+// The half-edges of each face form a counterclockwise loop, and the edges on the
+// border of the mesh have a boundary half-edge, without a face, which form the loops
+// of the holes of the mesh. Vertices, half-edges and faces are referenced by their indices.
+// Editing operations mark the removed elements, which are discarded by Compact.
+type Mesh struct {
+	Vertices  []Vertex   // Vertices
+	HalfEdges []HalfEdge // Half-edges
+	Faces     []Face     // Faces
+	HasUV     bool       // Whether the half-edges have texture coordinates
+}
+
+// Vertex is a vertex of a half-edge mesh.
+type Vertex struct {
+	Position math32.Vector3 // Vertex position
+	HalfEdge int            // Outgoing half-edge, a boundary one if the vertex is on a border, or -1 if isolated
+	Removed  bool           // Whether the vertex was removed by an editing operation
+}
+
+// HalfEdge is a directed edge of a face or of a boundary loop of a half-edge mesh.
+type HalfEdge struct {
+	Vertex  int            // Origin vertex
+	Face    int            // Face to the left of the half-edge, or -1 for boundary half-edges
+	Next    int            // Next half-edge of the face or boundary loop
+	Prev    int            // Previous half-edge of the face or boundary loop
+	Twin    int            // Opposite half-edge
+	UV      math32.Vector2 // Texture coordinates of the origin vertex in the face
+	Sharp   bool           // Whether the edge is a crease kept sharp by subdivision
+	Removed bool           // Whether the half-edge was removed by an editing operation
+}
+
+// Face is a polygonal face of a half-edge mesh.
+type Face struct {
+	HalfEdge int  // One of the half-edges of the face
+	Removed  bool // Whether the face was removed by an editing operation
+}
+
+// NewMesh creates and returns a pointer to a new half-edge mesh with the specified vertex
+// positions and faces, each one a list of at least three vertex indices in counterclockwise order.
+// Faces with repeated consecutive vertices are ignored. Returns an error if a face references an
+// invalid vertex or if an edge is shared by more than two faces or by two faces with inconsistent
+// orientations, as the mesh must be an orientable manifold surface along its edges.
+func NewMesh(positions []math32.Vector3, faces [][]int) (*Mesh, error) {
+
+	m := new(Mesh)
+	m.Vertices = make([]Vertex, len(positions))
+	for i, pos := range positions {
+		m.Vertices[i] = Vertex{Position: pos, HalfEdge: -1}
+	}
+
+	edges := make(map[[2]int]int)
+	for fi, face := range faces {
+		if len(face) < 3 {
+			return nil, fmt.Errorf("face %d has less than 3 vertices", fi)
+		}
+		degenerate := false
+		for i, v := range face {
+			if v < 0 || v >= len(positions) {
+				return nil, fmt.Errorf("face %d has invalid vertex index %d", fi, v)
+			}
+			if v == face[(i+1)%len(face)] {
+				degenerate = true
+			}
+		}
+		if degenerate {
+			continue
+		}
+		for i, v := range face {
+			if _, ok := edges[[2]int{v, face[(i+1)%len(face)]}]; ok {
+				return nil, fmt.Errorf("face %d makes edge %d-%d non manifold", fi, v, face[(i+1)%len(face)])
+			}
+		}
+
+		// Creates the loop of half-edges of the face
+		f := len(m.Faces)
+		first := len(m.HalfEdges)
+		n := len(face)
+		for i, v := range face {
+			h := first + i
+			m.HalfEdges = append(m.HalfEdges, HalfEdge{
+				Vertex: v,
+				Face:   f,
+				Next:   first + (i+1)%n,
+				Prev:   first + (i+n-1)%n,
+				Twin:   -1,
+			})
+			key := [2]int{v, face[(i+1)%n]}
+			edges[key] = h
+			if twin, ok := edges[[2]int{key[1], key[0]}]; ok {
+				m.HalfEdges[h].Twin = twin
+				m.HalfEdges[twin].Twin = h
+			}
+			m.Vertices[v].HalfEdge = h
+		}
+		m.Faces = append(m.Faces, Face{HalfEdge: first})
+	}
+
+	// Creates the boundary half-edges of the edges with only one face
+	boundaryFrom := make(map[int][]int)
+	count := len(m.HalfEdges)
+	for h := 0; h < count; h++ {
+		if m.HalfEdges[h].Twin >= 0 {
+			continue
+		}
+		b := len(m.HalfEdges)
+		origin := m.HalfEdges[m.HalfEdges[h].Next].Vertex
+		m.HalfEdges = append(m.HalfEdges, HalfEdge{Vertex: origin, Face: -1, Next: -1, Prev: -1, Twin: h})
+		m.HalfEdges[h].Twin = b
+		boundaryFrom[origin] = append(boundaryFrom[origin], b)
+		m.Vertices[origin].HalfEdge = b
+	}
+
+	// Links the boundary half-edges in loops
+	for b := count; b < len(m.HalfEdges); b++ {
+		target := m.HalfEdges[m.HalfEdges[b].Twin].Vertex
+		candidates := boundaryFrom[target]
+		next := candidates[len(candidates)-1]
+		boundaryFrom[target] = candidates[:len(candidates)-1]
+		m.HalfEdges[b].Next = next
+		m.HalfEdges[next].Prev = b
+	}
+	return m, nil
+}
+
+// Target returns the vertex the specified half-edge points to.
+func (m *Mesh) Target(h int) int {
+
+	return m.HalfEdges[m.HalfEdges[h].Twin].Vertex
+}
+
+// FindHalfEdge returns the half-edge from vertex a to vertex b or -1 if they are not connected.
+func (m *Mesh) FindHalfEdge(a, b int) int {
+
+	for _, h := range m.VertexHalfEdges(a) {
+		if m.Target(h) == b {
+			return h
+		}
+	}
+	return -1
+}
+
+// VertexHalfEdges returns the outgoing half-edges of the specified vertex in counterclockwise order,
+// starting with the boundary half-edge if the vertex is on a border.
+func (m *Mesh) VertexHalfEdges(v int) []int {
+
+	start := m.Vertices[v].HalfEdge
+	if start < 0 {
+		return nil
+	}
+	var result []int
+	h := start
+	for i := 0; i < len(m.HalfEdges); i++ {
+		result = append(result, h)
+		h = m.HalfEdges[m.HalfEdges[h].Prev].Twin
+		if h == start {
+			break
+		}
+	}
+	return result
+}
+
+// VertexNeighbors returns the vertices connected by an edge to the specified vertex.
+func (m *Mesh) VertexNeighbors(v int) []int {
+
+	hs := m.VertexHalfEdges(v)
+	result := make([]int, len(hs))
+	for i, h := range hs {
+		result[i] = m.Target(h)
+	}
+	return result
+}
+
+// VertexFaces returns the faces around the specified vertex.
+func (m *Mesh) VertexFaces(v int) []int {
+
+	var result []int
+	for _, h := range m.VertexHalfEdges(v) {
+		if f := m.HalfEdges[h].Face; f >= 0 {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// Valence returns the number of edges connected to the specified vertex.
+func (m *Mesh) Valence(v int) int {
+
+	return len(m.VertexHalfEdges(v))
+}
+
+// IsBoundaryVertex returns whether the specified vertex is on a border of the mesh.
+func (m *Mesh) IsBoundaryVertex(v int) bool {
+
+	h := m.Vertices[v].HalfEdge
+	return h >= 0 && m.HalfEdges[h].Face < 0
+}
+
+// IsBoundaryEdge returns whether the edge of the specified half-edge is on a border of the mesh.
+func (m *Mesh) IsBoundaryEdge(h int) bool {
+
+	return m.HalfEdges[h].Face < 0 || m.HalfEdges[m.HalfEdges[h].Twin].Face < 0
+}
+
+// loopHalfEdges returns the half-edges of the face or boundary loop
+// of the specified half-edge starting with it.
+func (m *Mesh) loopHalfEdges(start int) []int {
+
+	var result []int
+	h := start
+	for i := 0; i < len(m.HalfEdges); i++ {
+		result = append(result, h)
+		h = m.HalfEdges[h].Next
+		if h == start {
+			break
+		}
+	}
+	return result
+}
+
+// FaceHalfEdges returns the half-edges of the specified face in counterclockwise order.
+func (m *Mesh) FaceHalfEdges(f int) []int {
+
+	return m.loopHalfEdges(m.Faces[f].HalfEdge)
+}
+
+// FaceVertices returns the vertices of the specified face in counterclockwise order.
+func (m *Mesh) FaceVertices(f int) []int {
+
+	hs := m.FaceHalfEdges(f)
+	for i, h := range hs {
+		hs[i] = m.HalfEdges[h].Vertex
+	}
+	return hs
+}
+
+// FaceNeighbors returns the faces which share an edge with the specified face.
+func (m *Mesh) FaceNeighbors(f int) []int {
+
+	var result []int
+	for _, h := range m.FaceHalfEdges(f) {
+		if nf := m.HalfEdges[m.HalfEdges[h].Twin].Face; nf >= 0 {
+			result = append(result, nf)
+		}
+	}
+	return result
+}
+
+// FaceNormal returns the unit normal of the specified face, calculated with
+// Newell's method so it is also valid for non planar polygons.
+func (m *Mesh) FaceNormal(f int) math32.Vector3 {
+
+	var normal math32.Vector3
+	vs := m.FaceVertices(f)
+	for i, v := range vs {
+		p := m.Vertices[v].Position
+		q := m.Vertices[vs[(i+1)%len(vs)]].Position
+		normal.X += (p.Y - q.Y) * (p.Z + q.Z)
+		normal.Y += (p.Z - q.Z) * (p.X + q.X)
+		normal.Z += (p.X - q.X) * (p.Y + q.Y)
+	}
+	return *normal.Normalize()
+}
+
+// FaceCentroid returns the average position of the vertices of the specified face.
+func (m *Mesh) FaceCentroid(f int) math32.Vector3 {
+
+	var center math32.Vector3
+	vs := m.FaceVertices(f)
+	for _, v := range vs {
+		center.Add(&m.Vertices[v].Position)
+	}
+	return *center.MultiplyScalar(1 / float32(len(vs)))
+}
+
+// IsTriangleMesh returns whether all the faces of the mesh are triangles.
+func (m *Mesh) IsTriangleMesh() bool {
+
+	for f := range m.Faces {
+		if !m.Faces[f].Removed && len(m.FaceHalfEdges(f)) != 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// Boundaries returns the loops of boundary half-edges of the mesh, each one the border of a hole
+// or of an open surface. Returns nil if the mesh is closed.
+func (m *Mesh) Boundaries() [][]int {
+
+	var loops [][]int
+	visited := make(map[int]bool)
+	for h := range m.HalfEdges {
+		he := &m.HalfEdges[h]
+		if he.Removed || he.Face >= 0 || visited[h] {
+			continue
+		}
+		loop := m.loopHalfEdges(h)
+		for _, lh := range loop {
+			visited[lh] = true
+		}
+		loops = append(loops, loop)
+	}
+	return loops
+}
+
+// IsClosed returns whether the mesh has no borders.
+func (m *Mesh) IsClosed() bool {
+
+	for h := range m.HalfEdges {
+		if !m.HalfEdges[h].Removed && m.HalfEdges[h].Face < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsManifold returns whether the mesh is a manifold surface, that is, whether the faces around
+// each vertex form a single fan. As the edges are always manifold, this checks that no vertices
+// are shared by separate fans of faces, such as the shared vertex of two cones touching at their tips.
+func (m *Mesh) IsManifold() bool {
+
+	outgoing := make([]int, len(m.Vertices))
+	boundary := make([]int, len(m.Vertices))
+	for h := range m.HalfEdges {
+		if !m.HalfEdges[h].Removed {
+			outgoing[m.HalfEdges[h].Vertex]++
+			if m.HalfEdges[h].Face < 0 {
+				boundary[m.HalfEdges[h].Vertex]++
+			}
+		}
+	}
+	for v := range m.Vertices {
+		if m.Vertices[v].Removed {
+			continue
+		}
+		if boundary[v] > 1 || len(m.VertexHalfEdges(v)) != outgoing[v] {
+			return false
+		}
+	}
+	return true
+}
+
+// EulerCharacteristic returns the Euler characteristic of the mesh:
+// the number of vertices minus the number of edges plus the number of faces.
+// It is 2 for a closed mesh with the topology of a sphere.
+func (m *Mesh) EulerCharacteristic() int {
+
+	chi := 0
+	for v := range m.Vertices {
+		if !m.Vertices[v].Removed && m.Vertices[v].HalfEdge >= 0 {
+			chi++
+		}
+	}
+	for h := range m.HalfEdges {
+		if !m.HalfEdges[h].Removed && h < m.HalfEdges[h].Twin {
+			chi--
+		}
+	}
+	for f := range m.Faces {
+		if !m.Faces[f].Removed {
+			chi++
+		}
+	}
+	return chi
+}
@@ -0,0 +1,172 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package halfedge
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/math32"
+)
+
+// cubeMesh returns a mesh of a unit cube with quad faces.
+func cubeMesh(t *testing.T) *Mesh {
+
+	positions := []math32.Vector3{
+		{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0},
+		{0, 0, 1}, {1, 0, 1}, {1, 1, 1}, {0, 1, 1},
+	}
+	faces := [][]int{
+		{0, 3, 2, 1}, {4, 5, 6, 7}, {0, 1, 5, 4},
+		{2, 3, 7, 6}, {1, 2, 6, 5}, {0, 4, 7, 3},
+	}
+	m, err := NewMesh(positions, faces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// squareMesh returns a mesh of a unit square made of two triangles.
+func squareMesh(t *testing.T) *Mesh {
+
+	positions := []math32.Vector3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}}
+	m, err := NewMesh(positions, [][]int{{0, 1, 2}, {0, 2, 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// Test the topology of a closed mesh
+func TestClosed(t *testing.T) {
+
+	m := cubeMesh(t)
+	if !m.IsClosed() || !m.IsManifold() {
+		t.Error("Cube is not a closed manifold")
+	}
+	if m.IsTriangleMesh() {
+		t.Error("Cube of quads is a triangle mesh")
+	}
+	if e := m.EulerCharacteristic(); e != 2 {
+		t.Errorf("EulerCharacteristic = %d, expected 2", e)
+	}
+	for v := range m.Vertices {
+		if n := m.Valence(v); n != 3 {
+			t.Errorf("Valence(%d) = %d, expected 3", v, n)
+		}
+		if m.IsBoundaryVertex(v) {
+			t.Errorf("Vertex %d is on a boundary", v)
+		}
+	}
+	for f := range m.Faces {
+		if n := len(m.FaceNeighbors(f)); n != 4 {
+			t.Errorf("Face %d has %d neighbors, expected 4", f, n)
+		}
+	}
+	n := m.FaceNormal(0)
+	if n.Z > -0.99 {
+		t.Errorf("FaceNormal(0) = %v, expected (0, 0, -1)", n)
+	}
+}
+
+// Test the boundary of an open mesh
+func TestBoundary(t *testing.T) {
+
+	m := squareMesh(t)
+	if m.IsClosed() {
+		t.Error("Square is closed")
+	}
+	boundaries := m.Boundaries()
+	if len(boundaries) != 1 || len(boundaries[0]) != 4 {
+		t.Errorf("Boundaries = %v, expected one loop of 4 half-edges", boundaries)
+	}
+	if m.IsBoundaryEdge(m.FindHalfEdge(0, 2)) {
+		t.Error("Diagonal is a boundary edge")
+	}
+	if !m.IsBoundaryEdge(m.FindHalfEdge(0, 1)) {
+		t.Error("Side is not a boundary edge")
+	}
+	if e := m.EulerCharacteristic(); e != 1 {
+		t.Errorf("EulerCharacteristic = %d, expected 1", e)
+	}
+}
+
+// Test that faces with inconsistent orientations are rejected
+func TestInconsistent(t *testing.T) {
+
+	positions := []math32.Vector3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}}
+	_, err := NewMesh(positions, [][]int{{0, 1, 2}, {0, 3, 2}})
+	if err == nil {
+		t.Error("NewMesh accepted faces with inconsistent orientations")
+	}
+	_, err = NewMesh(positions, [][]int{{0, 1, 4}})
+	if err == nil {
+		t.Error("NewMesh accepted an invalid vertex")
+	}
+}
+
+// Test edge flip and split
+func TestEdit(t *testing.T) {
+
+	m := squareMesh(t)
+	if !m.FlipEdge(m.FindHalfEdge(0, 2)) {
+		t.Fatal("FlipEdge failed")
+	}
+	if m.FindHalfEdge(0, 2) >= 0 {
+		t.Error("Diagonal still exists after flip")
+	}
+	if m.FindHalfEdge(1, 3) < 0 && m.FindHalfEdge(3, 1) < 0 {
+		t.Error("Flipped diagonal doesn't exist")
+	}
+
+	m = squareMesh(t)
+	v := m.SplitEdge(m.FindHalfEdge(0, 2), 0.5)
+	if p := m.Vertices[v].Position; p.X != 0.5 || p.Y != 0.5 {
+		t.Errorf("Split vertex at %v, expected (0.5, 0.5, 0)", p)
+	}
+	m.Compact()
+	if len(m.Faces) != 4 || !m.IsTriangleMesh() || !m.IsManifold() {
+		t.Errorf("Split square has %d faces, expected 4 triangles", len(m.Faces))
+	}
+}
+
+// Test that splitting an edge interpolates the texture coordinates on both sides of the edge
+func TestSplitEdgeUV(t *testing.T) {
+
+	m := squareMesh(t)
+	m.HasUV = true
+	for h := range m.HalfEdges {
+		p := m.Vertices[m.HalfEdges[h].Vertex].Position
+		m.HalfEdges[h].UV.Set(p.X, p.Y)
+	}
+	v := m.SplitEdge(m.FindHalfEdge(0, 2), 0.25)
+	hs := m.VertexHalfEdges(v)
+	if len(hs) != 4 {
+		t.Fatalf("Split vertex has %d half-edges, expected 4", len(hs))
+	}
+	for _, h := range hs {
+		uv := m.HalfEdges[h].UV
+		if math32.Abs(uv.X-0.25) > 1e-6 || math32.Abs(uv.Y-0.25) > 1e-6 {
+			t.Errorf("Split vertex has UV %v in face %d, expected (0.25, 0.25)", uv, m.HalfEdges[h].Face)
+		}
+	}
+}
+
+// Test that the vertices of a geometry far from the origin are merged across its seams
+func TestFromGeometryFar(t *testing.T) {
+
+	geom := geometry.NewCube(1)
+	var m4 math32.Matrix4
+	m4.MakeTranslation(1e5, -1e5, 1e5)
+	geom.ApplyMatrix(&m4)
+	m, err := NewMeshFromGeometry(geom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Vertices) != 8 || !m.IsClosed() {
+		t.Errorf("Cube far from the origin has %d vertices, expected 8 and closed", len(m.Vertices))
+	}
+}
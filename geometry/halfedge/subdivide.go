@@ -0,0 +1,321 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package halfedge
+
+import (
+	"sort"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/math32"
+)
+
+// Scheme is a subdivision scheme.
+type Scheme int
+
+// Subdivision schemes.
+const (
+	Loop         = Scheme(iota) // Loop subdivision of triangle meshes
+	CatmullClark                // Catmull-Clark subdivision of quad meshes
+)
+
+// joinAngle is the maximum angle in radians between the normals of two triangles
+// joined into a quad before Catmull-Clark subdivision of a geometry.
+const joinAngle = math32.Pi / 36
+
+// Subdivide returns a new geometry with the specified geometry subdivided the specified number
+// of times with the specified scheme, with interpolated texture coordinates and smooth normals.
+// Edges between faces whose normals differ by more than creaseAngle radians are kept sharp,
+// as well as the borders of the geometry. Pass math32.Pi to smooth all edges.
+// Before Catmull-Clark subdivision pairs of nearly coplanar triangles are joined into quads,
+// so quad-dominant geometries made of triangles are subdivided as quads.
+func Subdivide(geom *geometry.Geometry, levels int, scheme Scheme, creaseAngle float32) (*geometry.Geometry, error) {
+
+	m, err := NewMeshFromGeometry(geom)
+	if err != nil {
+		return nil, err
+	}
+	m.MarkCreases(creaseAngle)
+	if scheme == CatmullClark {
+		m.JoinTriangles(joinAngle)
+	}
+	for i := 0; i < levels; i++ {
+		m, err = m.Subdivide(scheme)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m.ToGeometry(creaseAngle), nil
+}
+
+// MarkCreases marks as sharp the edges between faces whose normals differ by more than
+// the specified angle in radians, and clears the mark of the other edges.
+func (m *Mesh) MarkCreases(angle float32) {
+
+	for h := range m.HalfEdges {
+		he := &m.HalfEdges[h]
+		t := &m.HalfEdges[he.Twin]
+		if he.Removed || he.Face < 0 || t.Face < 0 || h > he.Twin {
+			continue
+		}
+		n1 := m.FaceNormal(he.Face)
+		n2 := m.FaceNormal(t.Face)
+		he.Sharp = n1.AngleTo(&n2) > angle
+		t.Sharp = he.Sharp
+	}
+}
+
+// JoinTriangles joins pairs of adjacent triangles whose normals differ by at most the
+// specified angle in radians into convex quads, preferring the most regular quads.
+// Sharp edges are not removed.
+func (m *Mesh) JoinTriangles(maxAngle float32) {
+
+	type candidate struct {
+		h     int
+		score float32
+	}
+	var candidates []candidate
+	for h := range m.HalfEdges {
+		he := &m.HalfEdges[h]
+		t := he.Twin
+		if he.Removed || he.Sharp || h > t || m.IsBoundaryEdge(h) {
+			continue
+		}
+		if len(m.FaceHalfEdges(he.Face)) != 3 || len(m.FaceHalfEdges(m.HalfEdges[t].Face)) != 3 {
+			continue
+		}
+		n1 := m.FaceNormal(he.Face)
+		n2 := m.FaceNormal(m.HalfEdges[t].Face)
+		if n1.AngleTo(&n2) > maxAngle {
+			continue
+		}
+
+		// The quad (a, d, b, c) must be convex; its score is how far its corners are from right angles
+		a := m.Vertices[he.Vertex].Position
+		b := m.Vertices[m.HalfEdges[t].Vertex].Position
+		c := m.Vertices[m.HalfEdges[he.Prev].Vertex].Position
+		d := m.Vertices[m.HalfEdges[m.HalfEdges[t].Prev].Vertex].Position
+		n1.Add(&n2)
+		quad := []math32.Vector3{a, d, b, c}
+		convex := true
+		var score float32
+		for i := range quad {
+			p := quad[(i+3)%4]
+			q := quad[(i+1)%4]
+			e1 := *p.Sub(&quad[i])
+			e2 := *q.Sub(&quad[i])
+			var cross math32.Vector3
+			cross.CrossVectors(&e2, &e1)
+			if cross.Dot(&n1) <= 0 {
+				convex = false
+				break
+			}
+			score += math32.Abs(e1.AngleTo(&e2) - math32.Pi/2)
+		}
+		if convex {
+			candidates = append(candidates, candidate{h, score})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score < candidates[j].score })
+	for _, c := range candidates {
+		he := &m.HalfEdges[c.h]
+		if len(m.FaceHalfEdges(he.Face)) == 3 && len(m.FaceHalfEdges(m.HalfEdges[he.Twin].Face)) == 3 {
+			m.DissolveEdge(c.h)
+		}
+	}
+}
+
+// Subdivide returns a new mesh with this mesh subdivided once with the specified scheme.
+// Loop subdivision splits each triangle in four and Catmull-Clark subdivision splits each
+// face in quads, one for each of its vertices. Faces which are not triangles are
+// triangulated as fans for Loop subdivision.
+// Sharp edges and borders are subdivided as curves which keep the subdivided edges sharp,
+// and vertices with more than two of them are kept as corners.
+// Texture coordinates are interpolated linearly in each face.
+// The removed elements of this mesh are discarded first.
+func (m *Mesh) Subdivide(scheme Scheme) (*Mesh, error) {
+
+	if scheme == Loop && !m.IsTriangleMesh() {
+		return m.triangulated().Subdivide(scheme)
+	}
+	m.Compact()
+
+	// Numbers the edges: the new vertices are the old vertices, then the edge points
+	// and then the face points for Catmull-Clark subdivision
+	nv := len(m.Vertices)
+	edgeOf := make([]int, len(m.HalfEdges))
+	var edges []int
+	for h := range m.HalfEdges {
+		if h < m.HalfEdges[h].Twin {
+			edgeOf[h] = len(edges)
+			edgeOf[m.HalfEdges[h].Twin] = len(edges)
+			edges = append(edges, h)
+		}
+	}
+	crease := func(h int) bool {
+		return m.HalfEdges[h].Sharp || m.IsBoundaryEdge(h)
+	}
+	points := make([]math32.Vector3, nv+len(edges))
+
+	// Face points
+	var facePoints []math32.Vector3
+	if scheme == CatmullClark {
+		facePoints = make([]math32.Vector3, len(m.Faces))
+		for f := range m.Faces {
+			facePoints[f] = m.FaceCentroid(f)
+		}
+		points = append(points, facePoints...)
+	}
+
+	// Edge points
+	for e, h := range edges {
+		he := &m.HalfEdges[h]
+		t := &m.HalfEdges[he.Twin]
+		p := m.Vertices[he.Vertex].Position
+		p.Add(&m.Vertices[t.Vertex].Position)
+		switch {
+		case crease(h):
+			p.MultiplyScalar(0.5)
+		case scheme == Loop:
+			p.MultiplyScalar(3.0 / 8)
+			c := m.Vertices[m.HalfEdges[he.Prev].Vertex].Position
+			c.Add(&m.Vertices[m.HalfEdges[t.Prev].Vertex].Position)
+			p.Add(c.MultiplyScalar(1.0 / 8))
+		default:
+			p.Add(&facePoints[he.Face]).Add(&facePoints[t.Face]).MultiplyScalar(0.25)
+		}
+		points[nv+e] = p
+	}
+
+	// Vertex points
+	for v := range m.Vertices {
+		pos := m.Vertices[v].Position
+		hs := m.VertexHalfEdges(v)
+		var creases []int
+		for _, h := range hs {
+			if crease(h) {
+				creases = append(creases, h)
+			}
+		}
+		n := float32(len(hs))
+		p := pos
+		switch {
+		case len(hs) == 0 || len(creases) > 2:
+		case len(creases) == 2:
+			p.MultiplyScalar(0.75)
+			for _, h := range creases {
+				q := m.Vertices[m.Target(h)].Position
+				p.Add(q.MultiplyScalar(0.125))
+			}
+		case scheme == Loop:
+			beta := 3 / (8 * n)
+			if len(hs) == 3 {
+				beta = 3.0 / 16
+			}
+			p.MultiplyScalar(1 - n*beta)
+			for _, h := range hs {
+				q := m.Vertices[m.Target(h)].Position
+				p.Add(q.MultiplyScalar(beta))
+			}
+		default:
+			// (Q + 2R + (n-3)S) / n with Q the average of the face points
+			// and R the average of the edge midpoints
+			var q, r math32.Vector3
+			for _, h := range hs {
+				q.Add(&facePoints[m.HalfEdges[h].Face])
+				mid := m.Vertices[m.Target(h)].Position
+				mid.Add(&pos).MultiplyScalar(0.5)
+				r.Add(&mid)
+			}
+			q.MultiplyScalar(1 / n)
+			r.MultiplyScalar(2 / n)
+			p.MultiplyScalar(n - 3).Add(&q).Add(&r).MultiplyScalar(1 / n)
+		}
+		points[v] = p
+	}
+
+	// New faces with the texture coordinates of their corners
+	var faces [][]int
+	var uvs [][]math32.Vector2
+	midUV := func(h int) math32.Vector2 {
+		uv := m.HalfEdges[h].UV
+		uv.Add(&m.HalfEdges[m.HalfEdges[h].Next].UV)
+		return *uv.MultiplyScalar(0.5)
+	}
+	for f := range m.Faces {
+		hs := m.FaceHalfEdges(f)
+		if scheme == Loop {
+			a, b, c := hs[0], hs[1], hs[2]
+			va, vb, vc := m.HalfEdges[a].Vertex, m.HalfEdges[b].Vertex, m.HalfEdges[c].Vertex
+			ab, bc, ca := nv+edgeOf[a], nv+edgeOf[b], nv+edgeOf[c]
+			uab, ubc, uca := midUV(a), midUV(b), midUV(c)
+			faces = append(faces, []int{va, ab, ca}, []int{ab, vb, bc}, []int{ca, bc, vc}, []int{ab, bc, ca})
+			uvs = append(uvs,
+				[]math32.Vector2{m.HalfEdges[a].UV, uab, uca},
+				[]math32.Vector2{uab, m.HalfEdges[b].UV, ubc},
+				[]math32.Vector2{uca, ubc, m.HalfEdges[c].UV},
+				[]math32.Vector2{uab, ubc, uca},
+			)
+			continue
+		}
+		fp := nv + len(edges) + f
+		var center math32.Vector2
+		for _, h := range hs {
+			center.Add(&m.HalfEdges[h].UV)
+		}
+		center.MultiplyScalar(1 / float32(len(hs)))
+		for i, h := range hs {
+			prev := hs[(i+len(hs)-1)%len(hs)]
+			faces = append(faces, []int{m.HalfEdges[h].Vertex, nv + edgeOf[h], fp, nv + edgeOf[prev]})
+			uvs = append(uvs, []math32.Vector2{m.HalfEdges[h].UV, midUV(h), center, midUV(prev)})
+		}
+	}
+
+	sub, err := NewMesh(points, faces)
+	if err != nil {
+		return nil, err
+	}
+	sub.HasUV = m.HasUV
+	for f := range sub.Faces {
+		for i, h := range sub.FaceHalfEdges(f) {
+			sub.HalfEdges[h].UV = uvs[f][i]
+		}
+	}
+
+	// The halves of the sharp edges stay sharp
+	for _, h := range edges {
+		if !m.HalfEdges[h].Sharp {
+			continue
+		}
+		mid := nv + edgeOf[h]
+		for _, v := range []int{m.HalfEdges[h].Vertex, m.Target(h)} {
+			if sh := sub.FindHalfEdge(v, mid); sh >= 0 {
+				sub.HalfEdges[sh].Sharp = true
+				sub.HalfEdges[sub.HalfEdges[sh].Twin].Sharp = true
+			}
+		}
+	}
+	return sub, nil
+}
+
+// triangulated returns a copy of this mesh with its faces triangulated as fans.
+func (m *Mesh) triangulated() *Mesh {
+
+	tri := &Mesh{
+		Vertices:  append([]Vertex(nil), m.Vertices...),
+		HalfEdges: append([]HalfEdge(nil), m.HalfEdges...),
+		Faces:     append([]Face(nil), m.Faces...),
+		HasUV:     m.HasUV,
+	}
+	for f := 0; f < len(tri.Faces); f++ {
+		if tri.Faces[f].Removed {
+			continue
+		}
+		if hs := tri.FaceHalfEdges(f); len(hs) > 3 {
+			tri.SplitFace(hs[2], hs[0])
+		}
+	}
+	return tri
+}
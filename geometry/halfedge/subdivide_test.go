@@ -0,0 +1,68 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package halfedge
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/math32"
+)
+
+// Test Catmull-Clark subdivision of a closed quad mesh
+func TestCatmullClark(t *testing.T) {
+
+	m, err := cubeMesh(t).Subdivide(CatmullClark)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Faces) != 24 {
+		t.Errorf("Subdivided cube has %d faces, expected 24", len(m.Faces))
+	}
+	if !m.IsClosed() || !m.IsManifold() || m.EulerCharacteristic() != 2 {
+		t.Error("Subdivided cube is not a closed manifold of genus 0")
+	}
+	for _, v := range m.Vertices {
+		p := v.Position
+		if p.X < 0 || p.X > 1 || p.Y < 0 || p.Y > 1 || p.Z < 0 || p.Z > 1 {
+			t.Errorf("Smoothed vertex %v is outside of the cube", p)
+		}
+		if (p.X == 0 || p.X == 1) && (p.Y == 0 || p.Y == 1) && (p.Z == 0 || p.Z == 1) {
+			t.Errorf("Corner %v was not smoothed", p)
+		}
+	}
+}
+
+// Test Loop subdivision of a closed triangle mesh
+func TestLoop(t *testing.T) {
+
+	positions := []math32.Vector3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	m, err := NewMesh(positions, [][]int{{0, 2, 1}, {0, 1, 3}, {0, 3, 2}, {1, 2, 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err = m.Subdivide(Loop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Faces) != 16 || len(m.Vertices) != 10 {
+		t.Errorf("Subdivided tetrahedron has %d faces and %d vertices, expected 16 and 10", len(m.Faces), len(m.Vertices))
+	}
+	if !m.IsTriangleMesh() || !m.IsClosed() || m.EulerCharacteristic() != 2 {
+		t.Error("Subdivided tetrahedron is not a closed triangle mesh of genus 0")
+	}
+}
+
+// Test subdivision of a geometry
+func TestSubdivideGeometry(t *testing.T) {
+
+	geom, err := Subdivide(geometry.NewCube(1), 2, CatmullClark, math32.Pi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(geom.Indices()) / 3; n != 192 {
+		t.Errorf("Subdivided cube geometry has %d triangles, expected 192", n)
+	}
+}
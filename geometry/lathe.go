@@ -0,0 +1,55 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// NewLathe creates a geometry by rotating the specified profile around the Y axis with the
+// specified number of segments, from the start angle along the length angle in radians.
+// The X coordinates of the profile points are the distances from the axis and the Y coordinates
+// are the heights. The profile should go from bottom to top for the faces to point outwards.
+func NewLathe(points []math32.Vector2, segments int, phiStart, phiLength float32) *Geometry {
+
+	l := NewGeometry()
+
+	positions := math32.NewArrayF32(0, 0)
+	uvs := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, 0)
+
+	for i := 0; i <= segments; i++ {
+		phi := phiStart + float32(i)/float32(segments)*phiLength
+		sin, cos := math32.Sin(phi), math32.Cos(phi)
+		for j, p := range points {
+			positions.Append(p.X*sin, p.Y, p.X*cos)
+			uvs.Append(float32(i)/float32(segments), float32(j)/float32(len(points)-1))
+		}
+	}
+
+	np := uint32(len(points))
+	for i := uint32(0); i < uint32(segments); i++ {
+		for j := uint32(0); j+1 < np; j++ {
+			a := i*np + j
+			b := a + np
+			c := b + 1
+			d := a + 1
+			// Skips the triangles which degenerate on the axis
+			if points[j].X != 0 {
+				indices.Append(a, b, d)
+			}
+			if points[j+1].X != 0 {
+				indices.Append(b, c, d)
+			}
+		}
+	}
+
+	l.SetIndices(indices)
+	l.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	l.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	l.ComputeVertexNormals(creaseAngle)
+	return l
+}
@@ -0,0 +1,215 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"sort"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// vertexAttribTypes lists the standard vertex attribute types handled by the mesh utilities.
+var vertexAttribTypes = []gls.AttribType{
+	gls.VertexPosition,
+	gls.VertexNormal,
+	gls.VertexTangent,
+	gls.VertexColor,
+	gls.VertexTexcoord,
+	gls.VertexTexcoord2,
+}
+
+// Merge returns a new indexed geometry with the triangles of all the specified geometries,
+// each transformed by the matrix with the same index in matrices if not nil.
+// Only the vertex attributes present in all the geometries are kept.
+// The groups of each geometry are kept with their material indices offset by the number of
+// material indices of the previous geometries, where a geometry without groups has one.
+// So merging geometries without groups creates a group per geometry with its index as material index.
+func Merge(geoms []*Geometry, matrices []*math32.Matrix4) *Geometry {
+
+	// Finds the attributes common to all geometries with the same size
+	var types []gls.AttribType
+	var sizes []int
+	for _, atype := range vertexAttribTypes {
+		size := 0
+		for i, g := range geoms {
+			vbo := g.VBO(atype)
+			if vbo == nil {
+				size = 0
+				break
+			}
+			n := int(vbo.Attrib(atype).NumElements)
+			if i > 0 && n != size {
+				size = 0
+				break
+			}
+			size = n
+		}
+		if size > 0 {
+			types = append(types, atype)
+			sizes = append(sizes, size)
+		}
+	}
+
+	merged := NewGeometry()
+	data := make([]math32.ArrayF32, len(types))
+	indices := math32.NewArrayU32(0, 0)
+	matBase := 0
+	var groups []Group
+	for gi, g := range geoms {
+		base := uint32(0)
+		if len(types) > 0 {
+			base = uint32(data[0].Size() / sizes[0])
+		}
+		var m *math32.Matrix4
+		if gi < len(matrices) {
+			m = matrices[gi]
+		}
+		for ai, atype := range types {
+			values, _ := g.AttribData(atype)
+			if m != nil {
+				transformAttrib(atype, values, sizes[ai], m)
+			}
+			data[ai] = append(data[ai], values...)
+		}
+
+		// Appends the triangles and the groups with the offset material indices
+		start := indices.Size()
+		for _, idx := range g.triangleIndices() {
+			indices.Append(base + idx)
+		}
+		if g.GroupCount() == 0 {
+			groups = append(groups, Group{Start: start, Count: indices.Size() - start, Matindex: matBase})
+			matBase++
+			continue
+		}
+		maxMat := 0
+		for i := 0; i < g.GroupCount(); i++ {
+			group := *g.GroupAt(i)
+			group.Start += start
+			if group.Matindex > maxMat {
+				maxMat = group.Matindex
+			}
+			group.Matindex += matBase
+			groups = append(groups, group)
+		}
+		matBase += maxMat + 1
+	}
+
+	merged.SetIndices(indices)
+	for ai, atype := range types {
+		vbo := gls.NewVBO(data[ai]).AddAttrib(atype)
+		vbo.Attrib(atype).NumElements = int32(sizes[ai])
+		merged.AddVBO(vbo)
+	}
+	merged.AddGroupList(groups)
+	if len(geoms) > 0 {
+		if _, ok := geoms[0].ShaderDefines["HAS_TANGENTS"]; ok && merged.VBO(gls.VertexTangent) != nil {
+			merged.ShaderDefines.Set("HAS_TANGENTS", "")
+		}
+	}
+	return merged
+}
+
+// transformAttrib transforms the packed values of an attribute by the specified matrix:
+// positions as points, normals by the normal matrix and tangents as directions.
+func transformAttrib(atype gls.AttribType, values math32.ArrayF32, size int, m *math32.Matrix4) {
+
+	var v math32.Vector3
+	switch atype {
+	case gls.VertexPosition:
+		for i := 0; i+2 < values.Size(); i += size {
+			values.GetVector3(i, &v)
+			v.ApplyMatrix4(m)
+			values.SetVector3(i, &v)
+		}
+	case gls.VertexNormal:
+		var nm math32.Matrix3
+		nm.GetNormalMatrix(m)
+		for i := 0; i+2 < values.Size(); i += size {
+			values.GetVector3(i, &v)
+			v.ApplyMatrix3(&nm).Normalize()
+			values.SetVector3(i, &v)
+		}
+	case gls.VertexTangent:
+		var rm math32.Matrix3
+		rm.SetFromMatrix4(m)
+		for i := 0; i+2 < values.Size(); i += size {
+			values.GetVector3(i, &v)
+			v.ApplyMatrix3(&rm).Normalize()
+			values.SetVector3(i, &v)
+		}
+	}
+}
+
+// SplitGroups returns a new indexed geometry for each material index of the groups
+// of this geometry with the triangles of those groups, keyed by material index.
+// A geometry without groups is returned whole with material index 0.
+// Only the vertices used by the triangles of each group are kept.
+func (g *Geometry) SplitGroups() map[int]*Geometry {
+
+	indices := g.triangleIndices()
+	byMat := make(map[int]math32.ArrayU32)
+	if g.GroupCount() == 0 {
+		byMat[0] = indices
+	}
+	for i := 0; i < g.GroupCount(); i++ {
+		group := g.GroupAt(i)
+		end := group.Start + group.Count
+		if end > indices.Size() {
+			end = indices.Size()
+		}
+		byMat[group.Matindex] = append(byMat[group.Matindex], indices[group.Start:end]...)
+	}
+
+	mats := make([]int, 0, len(byMat))
+	for mat := range byMat {
+		mats = append(mats, mat)
+	}
+	sort.Ints(mats)
+	result := make(map[int]*Geometry, len(mats))
+	for _, mat := range mats {
+		result[mat] = g.subset(byMat[mat])
+	}
+	return result
+}
+
+// subset returns a new indexed geometry with the vertex attributes of this geometry and
+// the specified triangles, keeping only the vertices they use in order of first use.
+func (g *Geometry) subset(indices math32.ArrayU32) *Geometry {
+
+	remap := make(map[uint32]uint32)
+	var newToOld []int
+	newIndices := math32.NewArrayU32(0, indices.Size())
+	for _, idx := range indices {
+		ni, ok := remap[idx]
+		if !ok {
+			ni = uint32(len(newToOld))
+			remap[idx] = ni
+			newToOld = append(newToOld, int(idx))
+		}
+		newIndices.Append(ni)
+	}
+
+	sub := NewGeometry()
+	sub.SetIndices(newIndices)
+	for _, atype := range vertexAttribTypes {
+		values, size := g.AttribData(atype)
+		if values == nil {
+			continue
+		}
+		data := math32.NewArrayF32(0, len(newToOld)*size)
+		for _, old := range newToOld {
+			data.Append(values[old*size : old*size+size]...)
+		}
+		vbo := gls.NewVBO(data).AddAttrib(atype)
+		vbo.Attrib(atype).NumElements = int32(size)
+		sub.AddVBO(vbo)
+	}
+	if _, ok := g.ShaderDefines["HAS_TANGENTS"]; ok {
+		sub.ShaderDefines.Set("HAS_TANGENTS", "")
+	}
+	return sub
+}
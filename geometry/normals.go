@@ -0,0 +1,103 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// ComputeVertexNormals computes smooth vertex normals and stores them in the VertexNormal attribute.
+// The normal of each triangle corner is the angle weighted average of the normals of the
+// triangles which share the corner's position and whose normals differ from the normal of
+// the corner's triangle by at most maxAngle radians, so edges sharper than maxAngle stay hard.
+// Pass math32.Pi to smooth across all edges.
+// Vertices of indexed geometries shared by corners with different normals are split.
+func (g *Geometry) ComputeVertexNormals(maxAngle float32) {
+
+	positions, _ := g.AttribData(gls.VertexPosition)
+	if positions == nil {
+		log.Warn("Geometry.ComputeVertexNormals: geometry has no positions")
+		return
+	}
+	indices := g.triangleIndices()
+	corners := indices.Size() - indices.Size()%3
+	faceNormals, cornerAngles := g.faceNormals(positions, indices)
+
+	// Groups the corners by position
+	type posKey [3]float32
+	byPosition := make(map[posKey][]int)
+	var p math32.Vector3
+	for c := 0; c < corners; c++ {
+		positions.GetVector3(3*int(indices[c]), &p)
+		key := posKey{p.X, p.Y, p.Z}
+		byPosition[key] = append(byPosition[key], c)
+	}
+
+	cosMax := math32.Cos(maxAngle) - 1e-6
+	normals := math32.NewArrayF32(0, 3*corners)
+	var n, fn math32.Vector3
+	for c := 0; c < corners; c++ {
+		positions.GetVector3(3*int(indices[c]), &p)
+		own := &faceNormals[c/3]
+		n.Set(0, 0, 0)
+		for _, other := range byPosition[posKey{p.X, p.Y, p.Z}] {
+			fn = faceNormals[other/3]
+			// Corners of degenerate triangles use the normals of all the triangles
+			if own.Dot(&fn) < cosMax && own.LengthSq() > 0 {
+				continue
+			}
+			n.Add(fn.MultiplyScalar(cornerAngles[other]))
+		}
+		if n.LengthSq() < 1e-20 {
+			n = *own
+		}
+		n.Normalize()
+		normals.Append(n.X, n.Y, n.Z)
+	}
+	g.setCornerAttrib(gls.VertexNormal, normals, 3)
+}
+
+// ComputeFlatNormals sets the normal of each vertex to the normal of its triangle
+// and stores them in the VertexNormal attribute.
+// Vertices of indexed geometries shared by non coplanar triangles are split.
+func (g *Geometry) ComputeFlatNormals() {
+
+	positions, _ := g.AttribData(gls.VertexPosition)
+	if positions == nil {
+		log.Warn("Geometry.ComputeFlatNormals: geometry has no positions")
+		return
+	}
+	indices := g.triangleIndices()
+	faceNormals, _ := g.faceNormals(positions, indices)
+	normals := math32.NewArrayF32(0, 9*len(faceNormals))
+	for _, n := range faceNormals {
+		normals.Append(n.X, n.Y, n.Z, n.X, n.Y, n.Z, n.X, n.Y, n.Z)
+	}
+	g.setCornerAttrib(gls.VertexNormal, normals, 3)
+}
+
+// faceNormals returns the unit normal of each triangle
+// and the angle in radians of each triangle corner.
+func (g *Geometry) faceNormals(positions math32.ArrayF32, indices math32.ArrayU32) ([]math32.Vector3, []float32) {
+
+	faces := indices.Size() / 3
+	normals := make([]math32.Vector3, faces)
+	angles := make([]float32, 3*faces)
+	var p [3]math32.Vector3
+	var e1, e2 math32.Vector3
+	for f := 0; f < faces; f++ {
+		for c := 0; c < 3; c++ {
+			positions.GetVector3(3*int(indices[3*f+c]), &p[c])
+		}
+		e1.SubVectors(&p[1], &p[0])
+		e2.SubVectors(&p[2], &p[0])
+		normals[f].CrossVectors(&e1, &e2).Normalize()
+		for c := 0; c < 3; c++ {
+			angles[3*f+c] = cornerAngle(&p[c], &p[(c+1)%3], &p[(c+2)%3])
+		}
+	}
+	return normals, angles
+}
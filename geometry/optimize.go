@@ -0,0 +1,291 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"sort"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Optimize reorders the triangles and vertices of this geometry for rendering performance
+// by calling OptimizeVertexCache, OptimizeOverdraw and OptimizeVertexFetch.
+func (g *Geometry) Optimize() {
+
+	g.OptimizeVertexCache()
+	g.OptimizeOverdraw()
+	g.OptimizeVertexFetch()
+}
+
+// OptimizeVertexCache reorders the triangles of each group of this geometry to
+// improve the reuse of transformed vertices by the GPU using Forsyth's algorithm.
+// Non indexed geometries are reindexed first.
+func (g *Geometry) OptimizeVertexCache() {
+
+	if !g.Indexed() {
+		g.Reindex()
+	}
+	vertexCount := g.vertexCount()
+	for _, r := range g.triangleRanges() {
+		forsythOrder(g.indices[r[0]:r[1]], vertexCount)
+	}
+	g.updateIndices = true
+}
+
+// OptimizeOverdraw reorders clusters of consecutive triangles of each group of this geometry
+// so that the outermost facing ones are drawn first, which reduces overdraw as the triangles
+// behind them fail the depth test. It should be called after OptimizeVertexCache, which
+// generates the local triangle order kept inside each cluster.
+func (g *Geometry) OptimizeOverdraw() {
+
+	positions, _ := g.AttribData(gls.VertexPosition)
+	if positions == nil || !g.Indexed() {
+		return
+	}
+
+	// Mesh centroid
+	var center math32.Vector3
+	var p math32.Vector3
+	count := positions.Size() / 3
+	for i := 0; i < count; i++ {
+		positions.GetVector3(3*i, &p)
+		center.Add(&p)
+	}
+	center.DivideScalar(float32(count))
+
+	const clusterSize = 64 * 3
+	type cluster struct {
+		indices []uint32
+		sortKey float32
+	}
+	var a, b, c, e1, e2, n math32.Vector3
+	for _, r := range g.triangleRanges() {
+		tris := g.indices[r[0]:r[1]]
+		var clusters []cluster
+		for start := 0; start < len(tris); start += clusterSize {
+			end := start + clusterSize
+			if end > len(tris) {
+				end = len(tris)
+			}
+			cl := cluster{indices: append([]uint32(nil), tris[start:end]...)}
+
+			// Area weighted normal and centroid of the cluster
+			var normal, centroid math32.Vector3
+			for t := 0; t+2 < len(cl.indices); t += 3 {
+				positions.GetVector3(3*int(cl.indices[t]), &a)
+				positions.GetVector3(3*int(cl.indices[t+1]), &b)
+				positions.GetVector3(3*int(cl.indices[t+2]), &c)
+				e1.SubVectors(&b, &a)
+				e2.SubVectors(&c, &a)
+				n.CrossVectors(&e1, &e2)
+				normal.Add(&n)
+				centroid.Add(&a).Add(&b).Add(&c)
+			}
+			centroid.DivideScalar(float32(len(cl.indices)))
+			normal.Normalize()
+			cl.sortKey = centroid.Sub(&center).Dot(&normal)
+			clusters = append(clusters, cl)
+		}
+		sort.SliceStable(clusters, func(i, j int) bool { return clusters[i].sortKey > clusters[j].sortKey })
+		pos := 0
+		for _, cl := range clusters {
+			pos += copy(tris[pos:], cl.indices)
+		}
+	}
+	g.updateIndices = true
+}
+
+// OptimizeVertexFetch reorders the vertices of this geometry in the order they are first
+// used by its triangles, which improves memory locality, and removes the unused vertices.
+// Non indexed geometries are reindexed first.
+func (g *Geometry) OptimizeVertexFetch() {
+
+	if !g.Indexed() {
+		g.Reindex()
+	}
+	remap := make(map[uint32]uint32)
+	var newToOld []int
+	for i, idx := range g.indices {
+		ni, ok := remap[idx]
+		if !ok {
+			ni = uint32(len(newToOld))
+			remap[idx] = ni
+			newToOld = append(newToOld, int(idx))
+		}
+		g.indices[i] = ni
+	}
+	g.remapVertices(newToOld)
+	g.updateIndices = true
+}
+
+// vertexCount returns the number of vertices of the geometry.
+func (g *Geometry) vertexCount() int {
+
+	vbo := g.VBO(gls.VertexPosition)
+	if vbo == nil {
+		return 0
+	}
+	return vbo.Buffer().Size() / vbo.Stride()
+}
+
+// triangleRanges returns the ranges of indices [start, end) of each group of the geometry,
+// or of the whole index array if it has no groups, which can be reordered independently.
+func (g *Geometry) triangleRanges() [][2]int {
+
+	size := g.indices.Size() - g.indices.Size()%3
+	if len(g.groups) == 0 {
+		return [][2]int{{0, size}}
+	}
+	ranges := make([][2]int, len(g.groups))
+	for i, group := range g.groups {
+		start := math32.ClampInt(group.Start, 0, size)
+		end := math32.ClampInt(group.Start+group.Count, start, size)
+		ranges[i] = [2]int{start, end - (end-start)%3}
+	}
+	return ranges
+}
+
+// Parameters of Forsyth's vertex cache optimization
+const (
+	forsythCacheSize         = 32
+	forsythCacheDecayPower   = 1.5
+	forsythLastTriScore      = 0.75
+	forsythValenceBoostScale = 2.0
+	forsythValenceBoostPower = 0.5
+)
+
+// forsythVertexScore returns the score of a vertex with the specified cache position
+// (-1 if not in cache) and number of remaining triangles.
+func forsythVertexScore(cachePos, remaining int) float32 {
+
+	if remaining == 0 {
+		return -1
+	}
+	var score float32
+	if cachePos >= 0 {
+		if cachePos < 3 {
+			score = forsythLastTriScore
+		} else {
+			scaler := 1 / float32(forsythCacheSize-3)
+			score = math32.Pow(1-float32(cachePos-3)*scaler, forsythCacheDecayPower)
+		}
+	}
+	return score + forsythValenceBoostScale*math32.Pow(float32(remaining), -forsythValenceBoostPower)
+}
+
+// forsythOrder reorders in place the triangles of the specified indices
+// for vertex cache efficiency using Forsyth's linear speed algorithm.
+func forsythOrder(indices []uint32, vertexCount int) {
+
+	triCount := len(indices) / 3
+	if triCount < 2 {
+		return
+	}
+
+	// Triangles of each vertex
+	remaining := make([]int, vertexCount)
+	for _, v := range indices {
+		remaining[v]++
+	}
+	offsets := make([]int, vertexCount+1)
+	for v := 0; v < vertexCount; v++ {
+		offsets[v+1] = offsets[v] + remaining[v]
+	}
+	vertexTris := make([]int, len(indices))
+	fill := append([]int(nil), offsets[:vertexCount]...)
+	for i, v := range indices {
+		vertexTris[fill[v]] = i / 3
+		fill[v]++
+	}
+
+	cachePos := make([]int, vertexCount)
+	vertexScore := make([]float32, vertexCount)
+	for v := range cachePos {
+		cachePos[v] = -1
+		vertexScore[v] = forsythVertexScore(-1, remaining[v])
+	}
+	triScore := make([]float32, triCount)
+	emitted := make([]bool, triCount)
+	for t := 0; t < triCount; t++ {
+		for k := 0; k < 3; k++ {
+			triScore[t] += vertexScore[indices[3*t+k]]
+		}
+	}
+
+	output := make([]uint32, 0, len(indices))
+	var cache []uint32
+	best := -1
+	scan := 0
+	for len(output) < len(indices) {
+		// Falls back to the best remaining triangle when the cache gives no candidate
+		if best < 0 {
+			var bestScore float32 = -1
+			for t := scan; t < triCount; t++ {
+				if !emitted[t] && triScore[t] > bestScore {
+					best, bestScore = t, triScore[t]
+				}
+			}
+			for scan < triCount && emitted[scan] {
+				scan++
+			}
+		}
+		emitted[best] = true
+		tri := indices[3*best : 3*best+3]
+		output = append(output, tri...)
+
+		// Removes the triangle from its vertices and moves them to the front of the cache
+		newCache := make([]uint32, 0, forsythCacheSize+3)
+		for _, v := range tri {
+			list := vertexTris[offsets[v] : offsets[v]+remaining[v]]
+			for i, t := range list {
+				if t == best {
+					list[i] = list[len(list)-1]
+					break
+				}
+			}
+			remaining[v]--
+			newCache = append(newCache, v)
+		}
+		for _, v := range cache {
+			if v != tri[0] && v != tri[1] && v != tri[2] {
+				newCache = append(newCache, v)
+			}
+		}
+		for i, v := range newCache {
+			if i < forsythCacheSize {
+				cachePos[v] = i
+			} else {
+				cachePos[v] = -1
+			}
+		}
+
+		// Updates the scores of the cached and evicted vertices and their triangles
+		for _, v := range newCache {
+			old := vertexScore[v]
+			vertexScore[v] = forsythVertexScore(cachePos[v], remaining[v])
+			delta := vertexScore[v] - old
+			for _, t := range vertexTris[offsets[v] : offsets[v]+remaining[v]] {
+				triScore[t] += delta
+			}
+		}
+		if len(newCache) > forsythCacheSize {
+			newCache = newCache[:forsythCacheSize]
+		}
+		cache = newCache
+
+		// Picks the best triangle using the cached vertices
+		best = -1
+		var bestScore float32 = -1
+		for _, v := range cache {
+			for _, t := range vertexTris[offsets[v] : offsets[v]+remaining[v]] {
+				if triScore[t] > bestScore {
+					best, bestScore = t, triScore[t]
+				}
+			}
+		}
+	}
+	copy(indices, output)
+}
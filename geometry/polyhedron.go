@@ -0,0 +1,201 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Polyhedron is a regular polyhedron type.
+type Polyhedron int
+
+// Regular polyhedron types.
+const (
+	Tetrahedron  = Polyhedron(iota) // 4 triangular faces
+	Octahedron                      // 8 triangular faces
+	Dodecahedron                    // 12 pentagonal faces
+	Icosahedron                     // 20 triangular faces
+)
+
+// goldenRatio is the golden ratio.
+const goldenRatio = 1.618033988749895
+
+// polyhedronData are the vertices and the triangles of the faces of each polyhedron type.
+// Pentagonal faces are split in three triangles.
+var polyhedronData = map[Polyhedron]struct {
+	vertices []float32
+	indices  []int
+}{
+	Tetrahedron: {
+		[]float32{1, 1, 1, -1, -1, 1, -1, 1, -1, 1, -1, -1},
+		[]int{2, 1, 0, 0, 3, 2, 1, 3, 0, 2, 3, 1},
+	},
+	Octahedron: {
+		[]float32{1, 0, 0, -1, 0, 0, 0, 1, 0, 0, -1, 0, 0, 0, 1, 0, 0, -1},
+		[]int{0, 2, 4, 0, 4, 3, 0, 3, 5, 0, 5, 2, 1, 2, 5, 1, 5, 3, 1, 3, 4, 1, 4, 2},
+	},
+	Dodecahedron: {
+		[]float32{
+			-1, -1, -1, -1, -1, 1, -1, 1, -1, -1, 1, 1, 1, -1, -1, 1, -1, 1, 1, 1, -1, 1, 1, 1,
+			0, -1 / goldenRatio, -goldenRatio, 0, -1 / goldenRatio, goldenRatio, 0, 1 / goldenRatio, -goldenRatio, 0, 1 / goldenRatio, goldenRatio,
+			-1 / goldenRatio, -goldenRatio, 0, -1 / goldenRatio, goldenRatio, 0, 1 / goldenRatio, -goldenRatio, 0, 1 / goldenRatio, goldenRatio, 0,
+			-goldenRatio, 0, -1 / goldenRatio, goldenRatio, 0, -1 / goldenRatio, -goldenRatio, 0, 1 / goldenRatio, goldenRatio, 0, 1 / goldenRatio,
+		},
+		[]int{
+			3, 11, 7, 3, 7, 15, 3, 15, 13, 7, 19, 17, 7, 17, 6, 7, 6, 15,
+			17, 4, 8, 17, 8, 10, 17, 10, 6, 8, 0, 16, 8, 16, 2, 8, 2, 10,
+			0, 12, 1, 0, 1, 18, 0, 18, 16, 6, 10, 2, 6, 2, 13, 6, 13, 15,
+			2, 16, 18, 2, 18, 3, 2, 3, 13, 18, 1, 9, 18, 9, 11, 18, 11, 3,
+			4, 14, 12, 4, 12, 0, 4, 0, 8, 11, 9, 5, 11, 5, 19, 11, 19, 7,
+			19, 5, 14, 19, 14, 4, 19, 4, 17, 1, 12, 14, 1, 14, 5, 1, 5, 9,
+		},
+	},
+	Icosahedron: {
+		[]float32{
+			-1, goldenRatio, 0, 1, goldenRatio, 0, -1, -goldenRatio, 0, 1, -goldenRatio, 0,
+			0, -1, goldenRatio, 0, 1, goldenRatio, 0, -1, -goldenRatio, 0, 1, -goldenRatio,
+			goldenRatio, 0, -1, goldenRatio, 0, 1, -goldenRatio, 0, -1, -goldenRatio, 0, 1,
+		},
+		[]int{
+			0, 11, 5, 0, 5, 1, 0, 1, 7, 0, 7, 10, 0, 10, 11,
+			1, 5, 9, 5, 11, 4, 11, 10, 2, 10, 7, 6, 7, 1, 8,
+			3, 9, 4, 3, 4, 2, 3, 2, 6, 3, 6, 8, 3, 8, 9,
+			4, 9, 5, 2, 4, 11, 6, 2, 10, 8, 6, 7, 9, 8, 1,
+		},
+	},
+}
+
+// NewPolyhedron creates a regular polyhedron geometry of the specified type with its
+// vertices at the specified radius from its center, with flat faces and spherical texture coordinates.
+func NewPolyhedron(kind Polyhedron, radius float32) *Geometry {
+
+	data, ok := polyhedronData[kind]
+	if !ok {
+		panic("Invalid polyhedron type")
+	}
+	triangles := make([]math32.Vector3, len(data.indices))
+	for i, idx := range data.indices {
+		triangles[i].Set(data.vertices[3*idx], data.vertices[3*idx+1], data.vertices[3*idx+2])
+		triangles[i].Normalize()
+	}
+	return newSphericalGeometry(triangles, radius, true)
+}
+
+// NewIcosphere creates a sphere geometry with the specified radius by subdividing an
+// icosahedron the specified number of times, each one splitting each triangle in four.
+// Unlike NewSphere its triangles have similar sizes over the whole sphere.
+func NewIcosphere(radius float32, subdivisions int) *Geometry {
+
+	data := polyhedronData[Icosahedron]
+	var vertices []math32.Vector3
+	for i := 0; i < len(data.vertices); i += 3 {
+		v := math32.Vector3{data.vertices[i], data.vertices[i+1], data.vertices[i+2]}
+		vertices = append(vertices, *v.Normalize())
+	}
+	indices := append([]int(nil), data.indices...)
+
+	// Splits each triangle in four using the normalized midpoints of its edges shared by adjacent triangles
+	for s := 0; s < subdivisions; s++ {
+		midpoints := make(map[[2]int]int)
+		midpoint := func(a, b int) int {
+			key := [2]int{a, b}
+			if a > b {
+				key = [2]int{b, a}
+			}
+			if m, ok := midpoints[key]; ok {
+				return m
+			}
+			m := vertices[a]
+			m.Add(&vertices[b]).Normalize()
+			vertices = append(vertices, m)
+			midpoints[key] = len(vertices) - 1
+			return len(vertices) - 1
+		}
+		next := make([]int, 0, len(indices)*4)
+		for i := 0; i < len(indices); i += 3 {
+			a, b, c := indices[i], indices[i+1], indices[i+2]
+			ab, bc, ca := midpoint(a, b), midpoint(b, c), midpoint(c, a)
+			next = append(next, a, ab, ca, ab, b, bc, ca, bc, c, ab, bc, ca)
+		}
+		indices = next
+	}
+
+	triangles := make([]math32.Vector3, len(indices))
+	for i, idx := range indices {
+		triangles[i] = vertices[idx]
+	}
+	return newSphericalGeometry(triangles, radius, false)
+}
+
+// newSphericalGeometry creates an indexed geometry from the specified triangles with vertices
+// on the unit sphere, scaled by the specified radius, with flat or smooth normals and
+// texture coordinates from the longitude and latitude, the V coordinate increasing
+// from the top like NewSphere.
+// Vertices on the seam of the texture coordinates and at the poles are split.
+func newSphericalGeometry(triangles []math32.Vector3, radius float32, flat bool) *Geometry {
+
+	g := NewGeometry()
+	positions := math32.NewArrayF32(0, len(triangles)*3)
+	normals := math32.NewArrayF32(0, len(triangles)*3)
+	uvs := math32.NewArrayF32(0, len(triangles)*2)
+	for i := 0; i < len(triangles); i += 3 {
+		tri := triangles[i : i+3]
+
+		// Ensures the triangle faces outwards
+		var e1, e2, normal, center math32.Vector3
+		e1.SubVectors(&tri[1], &tri[0])
+		e2.SubVectors(&tri[2], &tri[0])
+		normal.CrossVectors(&e1, &e2).Normalize()
+		center.Add(&tri[0]).Add(&tri[1]).Add(&tri[2])
+		if normal.Dot(&center) < 0 {
+			tri[1], tri[2] = tri[2], tri[1]
+			normal.Negate()
+		}
+
+		// Texture coordinates with the longitude fixed across the seam and at the poles
+		var us, vs [3]float32
+		for j, p := range tri {
+			us[j] = math32.Atan2(p.Z, -p.X) / (2 * math32.Pi)
+			if us[j] < 0 {
+				us[j]++
+			}
+			vs[j] = math32.Acos(math32.Clamp(p.Y, -1, 1)) / math32.Pi
+		}
+		var pole [3]bool
+		for j, p := range tri {
+			pole[j] = math32.Abs(p.Y) > 1-1e-6
+		}
+		for j := range us {
+			for k := range us {
+				if !pole[j] && !pole[k] && us[j] < 0.25 && us[k] > 0.75 {
+					us[j]++
+					break
+				}
+			}
+		}
+		for j := range us {
+			if pole[j] {
+				us[j] = (us[(j+1)%3] + us[(j+2)%3]) / 2
+			}
+		}
+
+		for j, p := range tri {
+			positions.Append(p.X*radius, p.Y*radius, p.Z*radius)
+			if flat {
+				normals.AppendVector3(&normal)
+			} else {
+				normals.AppendVector3(&p)
+			}
+			uvs.Append(us[j], vs[j])
+		}
+	}
+
+	g.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	g.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	g.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	g.Reindex()
+	return g
+}
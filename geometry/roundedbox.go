@@ -0,0 +1,53 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// NewRoundedBox creates a box geometry with the specified width, height and length whose
+// edges and corners are rounded with the specified radius and number of segments per rounded edge.
+// The radius is limited to half of the smallest dimension of the box.
+func NewRoundedBox(width, height, length, radius float32, segments int) *Geometry {
+
+	// Validate arguments
+	if segments <= 0 {
+		panic("Invalid argument(s). The number of segments should be greater than zero.")
+	}
+	radius = math32.Min(radius, math32.Min(width, math32.Min(height, length))/2)
+
+	// Starts from a unit box with an odd number of segments per side, whose middle
+	// segment becomes the flat part of each face and the others are bent around
+	// the edges and corners of the inner box with the size reduced by the radius.
+	n := 2*segments + 1
+	box := NewSegmentedBox(1, 1, 1, n, n, n)
+	half := 0.5 / float32(n)
+	inner := math32.Vector3{width/2 - radius, height/2 - radius, length/2 - radius}
+	positions, _ := box.AttribData(gls.VertexPosition)
+	normals := math32.NewArrayF32(positions.Size(), positions.Size())
+	sign := func(x float32) float32 {
+		if x < 0 {
+			return -1
+		}
+		return 1
+	}
+	for i := 0; i < positions.Size(); i += 3 {
+		var p math32.Vector3
+		positions.GetVector3(i, &p)
+		normal := math32.Vector3{p.X - sign(p.X)*half, p.Y - sign(p.Y)*half, p.Z - sign(p.Z)*half}
+		normal.Normalize()
+		normals.SetVector3(i, &normal)
+		positions.Set(i,
+			sign(p.X)*inner.X+normal.X*radius,
+			sign(p.Y)*inner.Y+normal.Y*radius,
+			sign(p.Z)*inner.Z+normal.Z*radius,
+		)
+	}
+	box.SetAttribData(gls.VertexPosition, positions, 3)
+	box.SetAttribData(gls.VertexNormal, normals, 3)
+	return box
+}
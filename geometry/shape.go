@@ -0,0 +1,380 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// Path is a 2D path built from lines, arcs and Bézier curves.
+// Curves are approximated by line segments when they are added.
+type Path struct {
+	points   []math32.Vector2 // points of the path
+	segments int              // number of line segments used to approximate each curve
+}
+
+// NewPath creates and returns a pointer to a new empty path.
+func NewPath() *Path {
+
+	p := new(Path)
+	p.segments = 12
+	return p
+}
+
+// SetCurveSegments sets the number of line segments used to approximate the curves added afterwards.
+// The default value is 12.
+func (p *Path) SetCurveSegments(segments int) *Path {
+
+	p.segments = segments
+	return p
+}
+
+// MoveTo sets the starting point of the path. It should be called before any other drawing method.
+func (p *Path) MoveTo(x, y float32) *Path {
+
+	p.points = append(p.points[:0], math32.Vector2{x, y})
+	return p
+}
+
+// LineTo adds a straight line from the current point to the specified point.
+func (p *Path) LineTo(x, y float32) *Path {
+
+	p.points = append(p.points, math32.Vector2{x, y})
+	return p
+}
+
+// QuadraticCurveTo adds a quadratic Bézier curve from the current point
+// to the specified point using the specified control point.
+func (p *Path) QuadraticCurveTo(cx, cy, x, y float32) *Path {
+
+	p0 := p.current()
+	for i := 1; i <= p.segments; i++ {
+		t := float32(i) / float32(p.segments)
+		k0, k1, k2 := (1-t)*(1-t), 2*(1-t)*t, t*t
+		p.points = append(p.points, math32.Vector2{k0*p0.X + k1*cx + k2*x, k0*p0.Y + k1*cy + k2*y})
+	}
+	return p
+}
+
+// BezierCurveTo adds a cubic Bézier curve from the current point
+// to the specified point using the specified control points.
+func (p *Path) BezierCurveTo(c1x, c1y, c2x, c2y, x, y float32) *Path {
+
+	p0 := p.current()
+	for i := 1; i <= p.segments; i++ {
+		t := float32(i) / float32(p.segments)
+		it := 1 - t
+		k0, k1, k2, k3 := it*it*it, 3*it*it*t, 3*it*t*t, t*t*t
+		p.points = append(p.points, math32.Vector2{
+			k0*p0.X + k1*c1x + k2*c2x + k3*x,
+			k0*p0.Y + k1*c1y + k2*c2y + k3*y,
+		})
+	}
+	return p
+}
+
+// Arc adds a circular arc with the specified center and radius from the start angle to the
+// end angle in radians, counterclockwise unless clockwise is true. A line is added from the
+// current point to the start of the arc if they are different.
+func (p *Path) Arc(cx, cy, radius, startAngle, endAngle float32, clockwise bool) *Path {
+
+	return p.Ellipse(cx, cy, radius, radius, startAngle, endAngle, clockwise)
+}
+
+// Ellipse adds an elliptical arc with the specified center and radii from the start angle to the
+// end angle in radians, counterclockwise unless clockwise is true. A line is added from the
+// current point to the start of the arc if they are different.
+func (p *Path) Ellipse(cx, cy, rx, ry, startAngle, endAngle float32, clockwise bool) *Path {
+
+	// Computes the signed sweep angle in the requested direction
+	sweep := endAngle - startAngle
+	for !clockwise && sweep < 0 {
+		sweep += 2 * math32.Pi
+	}
+	for clockwise && sweep > 0 {
+		sweep -= 2 * math32.Pi
+	}
+	if sweep == 0 && endAngle != startAngle {
+		sweep = 2 * math32.Pi
+	}
+
+	start := math32.Vector2{cx + rx*math32.Cos(startAngle), cy + ry*math32.Sin(startAngle)}
+	if cur := p.current(); len(p.points) == 0 || !cur.Equals(&start) {
+		p.points = append(p.points, start)
+	}
+	for i := 1; i <= p.segments; i++ {
+		a := startAngle + sweep*float32(i)/float32(p.segments)
+		p.points = append(p.points, math32.Vector2{cx + rx*math32.Cos(a), cy + ry*math32.Sin(a)})
+	}
+	return p
+}
+
+// Points returns the points of the path. The path is considered closed,
+// so the last point is removed if it is at the same position as the first one.
+func (p *Path) Points() []math32.Vector2 {
+
+	n := len(p.points)
+	if n > 1 && p.points[0].DistanceToSquared(&p.points[n-1]) < 1e-12 {
+		n--
+	}
+	return p.points[:n]
+}
+
+// current returns the last point of the path or the origin if the path is empty.
+func (p *Path) current() math32.Vector2 {
+
+	if len(p.points) == 0 {
+		return math32.Vector2{}
+	}
+	return p.points[len(p.points)-1]
+}
+
+// Shape is a closed 2D path with optional holes used to build extruded geometries.
+type Shape struct {
+	Path          // Embedded outer contour
+	holes []*Path // holes of the shape
+}
+
+// NewShape creates and returns a pointer to a new empty shape.
+func NewShape() *Shape {
+
+	s := new(Shape)
+	s.segments = 12
+	return s
+}
+
+// NewShapeFromPoints creates and returns a pointer to a new shape with the specified outer contour.
+func NewShapeFromPoints(points []math32.Vector2) *Shape {
+
+	s := NewShape()
+	s.points = append(s.points, points...)
+	return s
+}
+
+// AddHole adds a hole to the shape and returns the pointer to the shape.
+func (s *Shape) AddHole(hole *Path) *Shape {
+
+	s.holes = append(s.holes, hole)
+	return s
+}
+
+// Holes returns the holes of the shape.
+func (s *Shape) Holes() []*Path {
+
+	return s.holes
+}
+
+// Contours returns the outer contour of the shape in counterclockwise order
+// followed by the contours of its holes in clockwise order.
+func (s *Shape) Contours() [][]math32.Vector2 {
+
+	contours := [][]math32.Vector2{orientContour(s.Points(), true)}
+	for _, h := range s.holes {
+		contours = append(contours, orientContour(h.Points(), false))
+	}
+	return contours
+}
+
+// Triangulate triangulates the shape using ear clipping and returns the concatenation of
+// the points of the contours returned by Contours() and the indices of the triangles,
+// which are in counterclockwise order.
+func (s *Shape) Triangulate() ([]math32.Vector2, []uint32) {
+
+	contours := s.Contours()
+	var points []math32.Vector2
+	for _, c := range contours {
+		points = append(points, c...)
+	}
+	return points, Triangulate(contours)
+}
+
+// Triangulate triangulates the polygon with the specified outer contour in counterclockwise
+// order followed by the contours of its holes in clockwise order, using ear clipping.
+// It returns the indices of the triangles in the concatenation of the contours.
+func Triangulate(contours [][]math32.Vector2) []uint32 {
+
+	if len(contours) == 0 || len(contours[0]) < 3 {
+		return nil
+	}
+
+	// Builds the list of points and the outer polygon as indices
+	var points []math32.Vector2
+	var poly []int
+	for i, c := range contours {
+		start := len(points)
+		points = append(points, c...)
+		if i == 0 {
+			for j := range c {
+				poly = append(poly, start+j)
+			}
+		}
+	}
+
+	// Merges the holes into the outer polygon, starting with the rightmost ones
+	type hole struct{ start, count, right int }
+	var holes []hole
+	start := len(contours[0])
+	for _, c := range contours[1:] {
+		if len(c) >= 3 {
+			h := hole{start, len(c), start}
+			for j := start; j < start+len(c); j++ {
+				if points[j].X > points[h.right].X {
+					h.right = j
+				}
+			}
+			holes = append(holes, h)
+		}
+		start += len(c)
+	}
+	for len(holes) > 0 {
+		best := 0
+		for i, h := range holes {
+			if points[h.right].X > points[holes[best].right].X {
+				best = i
+			}
+		}
+		h := holes[best]
+		holes = append(holes[:best], holes[best+1:]...)
+		poly = bridgeHole(points, poly, h.start, h.count, h.right)
+	}
+	return earClip(points, poly)
+}
+
+// bridgeHole inserts a hole into the polygon through a bridge between the hole vertex
+// with index m and the nearest polygon vertex visible from it, and returns the new polygon.
+func bridgeHole(points []math32.Vector2, poly []int, start, count, m int) []int {
+
+	pm := &points[m]
+	bridge := -1
+	bestDist := math32.Infinity
+	for i, vi := range poly {
+		d := pm.DistanceToSquared(&points[vi])
+		if d >= bestDist {
+			continue
+		}
+		// Checks that the bridge doesn't cross any edge of the polygon or of the hole
+		visible := true
+		for j := range poly {
+			a, b := poly[j], poly[(j+1)%len(poly)]
+			if a != vi && b != vi && segmentsIntersect(pm, &points[vi], &points[a], &points[b]) {
+				visible = false
+				break
+			}
+		}
+		for j := 0; j < count && visible; j++ {
+			a, b := start+j, start+(j+1)%count
+			if a != m && b != m && segmentsIntersect(pm, &points[vi], &points[a], &points[b]) {
+				visible = false
+			}
+		}
+		if visible {
+			bridge, bestDist = i, d
+		}
+	}
+	if bridge < 0 {
+		return poly
+	}
+
+	// New polygon: ..., bridge vertex, hole from m around back to m, bridge vertex, ...
+	merged := make([]int, 0, len(poly)+count+2)
+	merged = append(merged, poly[:bridge+1]...)
+	for j := 0; j <= count; j++ {
+		merged = append(merged, start+(m-start+j)%count)
+	}
+	merged = append(merged, poly[bridge:]...)
+	return merged
+}
+
+// earClip triangulates a simple counterclockwise polygon specified by indices of points.
+func earClip(points []math32.Vector2, poly []int) []uint32 {
+
+	var indices []uint32
+	remaining := append([]int(nil), poly...)
+	for len(remaining) > 3 {
+		n := len(remaining)
+		ear := -1
+		for i := 0; i < n; i++ {
+			if isEar(points, remaining, remaining[(i+n-1)%n], remaining[i], remaining[(i+1)%n]) {
+				ear = i
+				break
+			}
+		}
+		// Degenerate input may have no ears: clips the first vertex anyway
+		if ear < 0 {
+			ear = 0
+		}
+		indices = append(indices, uint32(remaining[(ear+n-1)%n]), uint32(remaining[ear]), uint32(remaining[(ear+1)%n]))
+		remaining = append(remaining[:ear], remaining[ear+1:]...)
+	}
+	if len(remaining) == 3 {
+		indices = append(indices, uint32(remaining[0]), uint32(remaining[1]), uint32(remaining[2]))
+	}
+	return indices
+}
+
+// isEar returns whether the triangle formed by the specified consecutive polygon
+// vertices is convex and doesn't contain any other vertex of the polygon.
+func isEar(points []math32.Vector2, poly []int, ia, ib, ic int) bool {
+
+	a, b, c := &points[ia], &points[ib], &points[ic]
+	if cross2(a, b, c) <= 0 {
+		return false
+	}
+	for _, vi := range poly {
+		if vi == ia || vi == ib || vi == ic {
+			continue
+		}
+		p := &points[vi]
+		// Vertices duplicated by hole bridges don't block the ear
+		if p.Equals(a) || p.Equals(b) || p.Equals(c) {
+			continue
+		}
+		if cross2(a, b, p) >= 0 && cross2(b, c, p) >= 0 && cross2(c, a, p) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// cross2 returns the z component of the cross product of (b - a) and (c - a),
+// which is positive if a, b, c are in counterclockwise order.
+func cross2(a, b, c *math32.Vector2) float32 {
+
+	return (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+}
+
+// segmentsIntersect returns whether the segments p1-p2 and p3-p4 properly intersect.
+func segmentsIntersect(p1, p2, p3, p4 *math32.Vector2) bool {
+
+	d1 := cross2(p3, p4, p1)
+	d2 := cross2(p3, p4, p2)
+	d3 := cross2(p1, p2, p3)
+	d4 := cross2(p1, p2, p4)
+	return ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0))
+}
+
+// contourArea returns the signed area of a contour, positive if counterclockwise.
+func contourArea(points []math32.Vector2) float32 {
+
+	var area float32
+	for i := range points {
+		a, b := &points[i], &points[(i+1)%len(points)]
+		area += a.X*b.Y - b.X*a.Y
+	}
+	return area / 2
+}
+
+// orientContour returns the contour with the specified orientation, reversing a copy if needed.
+func orientContour(points []math32.Vector2, ccw bool) []math32.Vector2 {
+
+	if (contourArea(points) > 0) == ccw {
+		return points
+	}
+	reversed := make([]math32.Vector2, len(points))
+	for i := range points {
+		reversed[i] = points[len(points)-1-i]
+	}
+	return reversed
+}
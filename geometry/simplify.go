@@ -0,0 +1,310 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"container/heap"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Simplify reduces the number of triangles of this geometry to at most the specified target
+// by collapsing edges in order of increasing quadric error (Garland and Heckbert).
+// Each collapse moves a vertex onto a neighbor, so the remaining vertices keep their attributes.
+// Mesh borders are preserved by constraint planes and vertices on attribute seams
+// (several vertices at the same position) never move, so seams don't open.
+// It stops earlier if no more edges can be collapsed without flipping triangles.
+// The groups are kept and the unused vertices are removed. Non indexed geometries are reindexed first.
+func (g *Geometry) Simplify(targetTriangles int) {
+
+	if !g.Indexed() {
+		g.Reindex()
+	}
+	positions, _ := g.AttribData(gls.VertexPosition)
+	if positions == nil {
+		return
+	}
+	vertexCount := positions.Size() / 3
+	ranges := g.triangleRanges()
+	tris := append([]uint32(nil), g.indices[:g.indices.Size()-g.indices.Size()%3]...)
+	triCount := len(tris) / 3
+	if triCount <= targetTriangles {
+		return
+	}
+
+	pos := make([]math32.Vector3, vertexCount)
+	for v := range pos {
+		positions.GetVector3(3*v, &pos[v])
+	}
+
+	// Vertices at the same position as others are locked
+	locked := make([]bool, vertexCount)
+	first := make(map[math32.Vector3]int)
+	for v := range pos {
+		if f, ok := first[pos[v]]; ok {
+			locked[v], locked[f] = true, true
+		} else {
+			first[pos[v]] = v
+		}
+	}
+
+	// Quadrics of the planes of the triangles around each vertex
+	quadrics := make([]quadric, vertexCount)
+	adjacency := make([][]int, vertexCount)
+	edgeCount := make(map[[2]uint32]int)
+	var n math32.Vector3
+	for t := 0; t < triCount; t++ {
+		a, b, c := tris[3*t], tris[3*t+1], tris[3*t+2]
+		area := triangleNormal(&pos[a], &pos[b], &pos[c], &n)
+		q := planeQuadric(&n, n.Dot(&pos[a]), float64(area))
+		for _, v := range []uint32{a, b, c} {
+			quadrics[v].add(&q)
+			adjacency[v] = append(adjacency[v], t)
+		}
+		for _, e := range [][2]uint32{{a, b}, {b, c}, {c, a}} {
+			edgeCount[edgeKey(e[0], e[1])]++
+		}
+	}
+
+	// Constraint planes perpendicular to the border edges
+	const borderWeight = 1000
+	for t := 0; t < triCount; t++ {
+		a, b, c := tris[3*t], tris[3*t+1], tris[3*t+2]
+		triangleNormal(&pos[a], &pos[b], &pos[c], &n)
+		for _, e := range [][2]uint32{{a, b}, {b, c}, {c, a}} {
+			if edgeCount[edgeKey(e[0], e[1])] != 1 {
+				continue
+			}
+			var edge, cn math32.Vector3
+			edge.SubVectors(&pos[e[1]], &pos[e[0]])
+			cn.CrossVectors(&edge, &n).Normalize()
+			q := planeQuadric(&cn, cn.Dot(&pos[e[0]]), float64(borderWeight*edge.LengthSq()))
+			quadrics[e[0]].add(&q)
+			quadrics[e[1]].add(&q)
+		}
+	}
+
+	// Candidate collapses of all the edges
+	version := make([]int, vertexCount)
+	removed := make([]bool, vertexCount)
+	dead := make([]bool, triCount)
+	h := &collapseHeap{}
+	push := func(a, b uint32) {
+		if c, ok := collapseCost(quadrics, pos, locked, a, b); ok {
+			c.versionFrom, c.versionTo = version[c.from], version[c.to]
+			heap.Push(h, c)
+		}
+	}
+	// Edges in triangle order so that collapses of equal cost are always ordered the same way
+	pushed := make(map[[2]uint32]bool, len(edgeCount))
+	for t := 0; t < triCount; t++ {
+		a, b, c := tris[3*t], tris[3*t+1], tris[3*t+2]
+		for _, e := range [][2]uint32{{a, b}, {b, c}, {c, a}} {
+			key := edgeKey(e[0], e[1])
+			if !pushed[key] {
+				pushed[key] = true
+				push(key[0], key[1])
+			}
+		}
+	}
+
+	live := triCount
+	for live > targetTriangles && h.Len() > 0 {
+		c := heap.Pop(h).(collapse)
+		if removed[c.from] || removed[c.to] || version[c.from] != c.versionFrom || version[c.to] != c.versionTo {
+			continue
+		}
+		if collapseFlips(tris, adjacency[c.from], dead, pos, c.from, c.to) {
+			continue
+		}
+
+		// Moves the triangles of the removed vertex to the kept one
+		u, v := c.from, c.to
+		for _, t := range adjacency[u] {
+			if dead[t] {
+				continue
+			}
+			tri := tris[3*t : 3*t+3]
+			if tri[0] == v || tri[1] == v || tri[2] == v {
+				dead[t] = true
+				live--
+				continue
+			}
+			for k := range tri {
+				if tri[k] == u {
+					tri[k] = v
+				}
+			}
+			adjacency[v] = append(adjacency[v], t)
+		}
+		adjacency[u] = nil
+		removed[u] = true
+		quadrics[v].add(&quadrics[u])
+		version[v]++
+
+		// Compacts the triangles of the kept vertex and updates the costs of its edges
+		kept := adjacency[v][:0]
+		for _, t := range adjacency[v] {
+			if !dead[t] {
+				kept = append(kept, t)
+			}
+		}
+		adjacency[v] = kept
+		for _, t := range kept {
+			for _, w := range tris[3*t : 3*t+3] {
+				if w != v {
+					push(v, w)
+				}
+			}
+		}
+	}
+
+	// Rebuilds the indices and groups with the remaining triangles
+	indices := math32.NewArrayU32(0, live*3)
+	for gi, r := range ranges {
+		start := indices.Size()
+		for t := r[0] / 3; t < r[1]/3; t++ {
+			if !dead[t] {
+				indices.Append(tris[3*t : 3*t+3]...)
+			}
+		}
+		if len(g.groups) > 0 {
+			g.groups[gi].Start = start
+			g.groups[gi].Count = indices.Size() - start
+		}
+	}
+	g.SetIndices(indices)
+	g.OptimizeVertexFetch()
+}
+
+// triangleNormal sets n to the unit normal of the triangle and returns its area.
+func triangleNormal(a, b, c, n *math32.Vector3) float32 {
+
+	var e1, e2 math32.Vector3
+	e1.SubVectors(b, a)
+	e2.SubVectors(c, a)
+	n.CrossVectors(&e1, &e2)
+	area := n.Length() / 2
+	n.Normalize()
+	return area
+}
+
+// edgeKey returns the key of the undirected edge between two vertices.
+func edgeKey(a, b uint32) [2]uint32 {
+
+	if a > b {
+		a, b = b, a
+	}
+	return [2]uint32{a, b}
+}
+
+// quadric is the symmetric 4x4 matrix of a quadric error metric, storing the
+// upper triangle: a², ab, ac, ad, b², bc, bd, c², cd, d² for the plane ax+by+cz=d.
+type quadric [10]float64
+
+// planeQuadric returns the quadric of the plane with the specified unit normal and
+// distance from the origin, multiplied by the specified weight.
+func planeQuadric(n *math32.Vector3, d float32, weight float64) quadric {
+
+	a, b, c, dd := float64(n.X), float64(n.Y), float64(n.Z), -float64(d)
+	return quadric{
+		weight * a * a, weight * a * b, weight * a * c, weight * a * dd,
+		weight * b * b, weight * b * c, weight * b * dd,
+		weight * c * c, weight * c * dd,
+		weight * dd * dd,
+	}
+}
+
+// add adds other to this quadric.
+func (q *quadric) add(other *quadric) {
+
+	for i := range q {
+		q[i] += other[i]
+	}
+}
+
+// error returns the quadric error of the specified point.
+func (q *quadric) error(p *math32.Vector3) float64 {
+
+	x, y, z := float64(p.X), float64(p.Y), float64(p.Z)
+	return q[0]*x*x + 2*q[1]*x*y + 2*q[2]*x*z + 2*q[3]*x +
+		q[4]*y*y + 2*q[5]*y*z + 2*q[6]*y +
+		q[7]*z*z + 2*q[8]*z +
+		q[9]
+}
+
+// collapse is a candidate collapse of a vertex onto another one.
+type collapse struct {
+	cost        float64
+	from, to    uint32
+	versionFrom int
+	versionTo   int
+}
+
+// collapseCost returns the cheapest collapse of the edge between two vertices
+// which doesn't move a locked vertex, or false if both are locked.
+func collapseCost(quadrics []quadric, pos []math32.Vector3, locked []bool, a, b uint32) (collapse, bool) {
+
+	var q quadric
+	q = quadrics[a]
+	q.add(&quadrics[b])
+	costAB, costBA := q.error(&pos[b]), q.error(&pos[a])
+	switch {
+	case locked[a] && locked[b]:
+		return collapse{}, false
+	case locked[a]:
+		return collapse{cost: costBA, from: b, to: a}, true
+	case locked[b] || costAB <= costBA:
+		return collapse{cost: costAB, from: a, to: b}, true
+	default:
+		return collapse{cost: costBA, from: b, to: a}, true
+	}
+}
+
+// collapseFlips returns whether moving vertex u onto vertex v would flip or
+// degenerate any of the triangles of u which don't contain v.
+func collapseFlips(tris []uint32, adjacency []int, dead []bool, pos []math32.Vector3, u, v uint32) bool {
+
+	var before, after math32.Vector3
+	for _, t := range adjacency {
+		if dead[t] {
+			continue
+		}
+		tri := tris[3*t : 3*t+3]
+		if tri[0] == v || tri[1] == v || tri[2] == v {
+			continue
+		}
+		var p [3]math32.Vector3
+		for k := range tri {
+			p[k] = pos[tri[k]]
+		}
+		triangleNormal(&p[0], &p[1], &p[2], &before)
+		for k := range tri {
+			if tri[k] == u {
+				p[k] = pos[v]
+			}
+		}
+		if triangleNormal(&p[0], &p[1], &p[2], &after) == 0 || before.Dot(&after) < 0.2 {
+			return true
+		}
+	}
+	return false
+}
+
+// collapseHeap is a min heap of candidate collapses ordered by cost.
+type collapseHeap []collapse
+
+func (h collapseHeap) Len() int            { return len(h) }
+func (h collapseHeap) Less(i, j int) bool  { return h[i].cost < h[j].cost }
+func (h collapseHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *collapseHeap) Push(x interface{}) { *h = append(*h, x.(collapse)) }
+func (h *collapseHeap) Pop() interface{} {
+
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
@@ -0,0 +1,53 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// poleV returns the V texture coordinate of the first vertex of the specified
+// geometry at the specified height and whether there is such a vertex.
+func poleV(g *Geometry, y float32) (float32, bool) {
+
+	positions, _ := g.AttribData(gls.VertexPosition)
+	uvs, _ := g.AttribData(gls.VertexTexcoord)
+	for i := 0; i < positions.Size()/3; i++ {
+		if math32.Abs(positions[3*i+1]-y) < 1e-5 {
+			return uvs[2*i+1], true
+		}
+	}
+	return 0, false
+}
+
+// Test that the spherical texture coordinates follow the convention of NewSphere
+func TestSphericalUV(t *testing.T) {
+
+	sphereTop, ok := poleV(NewSphere(1, 16, 8), 1)
+	if !ok {
+		t.Fatal("Sphere has no vertex at its top pole")
+	}
+	sphereBottom, _ := poleV(NewSphere(1, 16, 8), -1)
+
+	capsule := NewCapsule(1, 2, 16, 4)
+	if v, ok := poleV(capsule, 2); !ok || v != sphereTop {
+		t.Errorf("Capsule top pole has V %v, expected %v", v, sphereTop)
+	}
+	if v, ok := poleV(capsule, -2); !ok || v != sphereBottom {
+		t.Errorf("Capsule bottom pole has V %v, expected %v", v, sphereBottom)
+	}
+
+	// The octahedron has vertices at the poles
+	octahedron := NewPolyhedron(Octahedron, 1)
+	if v, ok := poleV(octahedron, 1); !ok || math32.Abs(v-sphereTop) > 1e-6 {
+		t.Errorf("Octahedron top pole has V %v, expected %v", v, sphereTop)
+	}
+	if v, ok := poleV(octahedron, -1); !ok || math32.Abs(v-sphereBottom) > 1e-6 {
+		t.Errorf("Octahedron bottom pole has V %v, expected %v", v, sphereBottom)
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package subdivide subdivides geometries into smooth surfaces with the Loop and
+// Catmull-Clark schemes. It is a thin entry point over the halfedge package,
+// which also allows choosing the crease angle and editing the mesh between levels.
+package subdivide
+
+import (
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/geometry/halfedge"
+	"github.com/g3n/engine/math32"
+)
+
+// Scheme is a subdivision scheme.
+type Scheme = halfedge.Scheme
+
+// Subdivision schemes.
+const (
+	Loop         = halfedge.Loop         // Loop subdivision of triangle meshes
+	CatmullClark = halfedge.CatmullClark // Catmull-Clark subdivision of quad meshes
+)
+
+// DefaultCreaseAngle is the crease angle used by Subdivide, which smooths all the edges
+// except the borders of the geometry.
+const DefaultCreaseAngle = math32.Pi
+
+// Subdivide returns a new geometry with the specified geometry subdivided the specified
+// number of times with the specified scheme, with interpolated texture coordinates and
+// smooth normals. Use halfedge.Subdivide to keep the edges between faces meeting at
+// sharp angles as creases.
+func Subdivide(geom *geometry.Geometry, levels int, scheme Scheme) (*geometry.Geometry, error) {
+
+	return halfedge.Subdivide(geom, levels, scheme, DefaultCreaseAngle)
+}
@@ -0,0 +1,148 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// ComputeTangents computes the per vertex tangents of this geometry from the texture
+// coordinates of its triangles and stores them in the VertexTangent attribute
+// as 4 elements: the tangent direction and the handedness (+1 or -1) of the
+// bitangent, which is computed as cross(normal, tangent) * handedness.
+//
+// The tangent of each triangle corner is the angle weighted average of the tangents
+// of the triangles which share the corner's position, normal and texture coordinates
+// and have the same texture space orientation. Vertices of indexed geometries
+// shared by corners with different tangents (e.g. on mirrored UV seams) are split.
+// It also sets the "HAS_TANGENTS" shader define so shaders use the tangents.
+// The geometry must have normals and texture coordinates.
+func (g *Geometry) ComputeTangents() {
+
+	positions, _ := g.AttribData(gls.VertexPosition)
+	normals, _ := g.AttribData(gls.VertexNormal)
+	uvs, _ := g.AttribData(gls.VertexTexcoord)
+	if positions == nil || normals == nil || uvs == nil {
+		log.Warn("Geometry.ComputeTangents: geometry must have positions, normals and texture coordinates")
+		return
+	}
+
+	// Key which identifies the corners which share their tangent
+	type groupKey struct {
+		pos, normal [3]float32
+		uv          [2]float32
+		orient      bool
+	}
+
+	indices := g.triangleIndices()
+	corners := indices.Size() - indices.Size()%3
+	cornerGroup := make([]int, corners)
+	groups := make(map[groupKey]int)
+	var groupTan []math32.Vector3
+	var groupOrient []bool
+
+	var p [3]math32.Vector3
+	var uv [3]math32.Vector2
+	var n, sdir, tdir, e1, e2, proj math32.Vector3
+	for f := 0; f < corners; f += 3 {
+		for c := 0; c < 3; c++ {
+			positions.GetVector3(3*int(indices[f+c]), &p[c])
+			uvs.GetVector2(2*int(indices[f+c]), &uv[c])
+		}
+
+		// Computes the non normalized texture space axes of the triangle
+		e1.SubVectors(&p[1], &p[0])
+		e2.SubVectors(&p[2], &p[0])
+		s1, t1 := uv[1].X-uv[0].X, uv[1].Y-uv[0].Y
+		s2, t2 := uv[2].X-uv[0].X, uv[2].Y-uv[0].Y
+		det := s1*t2 - s2*t1
+		orient := det >= 0
+		degenerate := math32.Abs(det) < 1e-20
+		if !degenerate {
+			r := 1 / det
+			sdir.Set((t2*e1.X-t1*e2.X)*r, (t2*e1.Y-t1*e2.Y)*r, (t2*e1.Z-t1*e2.Z)*r)
+			tdir.Set((s1*e2.X-s2*e1.X)*r, (s1*e2.Y-s2*e1.Y)*r, (s1*e2.Z-s2*e1.Z)*r)
+		}
+
+		for c := 0; c < 3; c++ {
+			vi := int(indices[f+c])
+			normals.GetVector3(3*vi, &n)
+			key := groupKey{
+				pos:    [3]float32{p[c].X, p[c].Y, p[c].Z},
+				normal: [3]float32{n.X, n.Y, n.Z},
+				uv:     [2]float32{uv[c].X, uv[c].Y},
+				orient: orient,
+			}
+			gi, ok := groups[key]
+			if !ok {
+				gi = len(groupTan)
+				groups[key] = gi
+				groupTan = append(groupTan, math32.Vector3{})
+				groupOrient = append(groupOrient, orient)
+			}
+			cornerGroup[f+c] = gi
+			if degenerate {
+				continue
+			}
+			// Projects the triangle tangent onto the plane of the vertex normal
+			// and weights it by the angle of the triangle at this corner
+			proj = sdir
+			proj.Sub(n.Clone().MultiplyScalar(n.Dot(&sdir)))
+			if proj.LengthSq() < 1e-20 {
+				continue
+			}
+			proj.Normalize()
+			proj.MultiplyScalar(cornerAngle(&p[c], &p[(c+1)%3], &p[(c+2)%3]))
+			groupTan[gi].Add(&proj)
+		}
+	}
+
+	// Normalizes the tangent of each group and builds the corner tangents
+	cornerTangents := math32.NewArrayF32(0, 4*corners)
+	var t math32.Vector3
+	for c := 0; c < corners; c++ {
+		gi := cornerGroup[c]
+		t = groupTan[gi]
+		normals.GetVector3(3*int(indices[c]), &n)
+		t.Sub(n.Clone().MultiplyScalar(n.Dot(&t)))
+		if t.LengthSq() < 1e-20 {
+			orthogonal(&n, &t)
+		}
+		t.Normalize()
+		w := float32(-1)
+		if groupOrient[gi] {
+			w = 1
+		}
+		cornerTangents.Append(t.X, t.Y, t.Z, w)
+	}
+	g.setCornerAttrib(gls.VertexTangent, cornerTangents, 4)
+	g.ShaderDefines.Set("HAS_TANGENTS", "")
+}
+
+// cornerAngle returns the angle in radians of a triangle at vertex a.
+func cornerAngle(a, b, c *math32.Vector3) float32 {
+
+	var ab, ac math32.Vector3
+	ab.SubVectors(b, a)
+	ac.SubVectors(c, a)
+	lab, lac := ab.Length(), ac.Length()
+	if lab == 0 || lac == 0 {
+		return 0
+	}
+	return math32.Acos(math32.Clamp(ab.Dot(&ac)/(lab*lac), -1, 1))
+}
+
+// orthogonal sets t to an arbitrary unit vector orthogonal to n.
+func orthogonal(n, t *math32.Vector3) {
+
+	if math32.Abs(n.X) > 0.9 {
+		t.Set(0, 1, 0)
+	} else {
+		t.Set(1, 0, 0)
+	}
+	t.Sub(n.Clone().MultiplyScalar(n.Dot(t)))
+	t.Normalize()
+}
@@ -0,0 +1,80 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// NewTorusKnot creates a (p, q) torus knot geometry with the specified radius, tube radius,
+// number of tubular segments along the knot and number of radial segments around the tube.
+// The knot winds p times around its axis of rotational symmetry and q times around
+// the interior of the torus. p and q should be coprime for the knot to be a single curve.
+func NewTorusKnot(radius, tubeRadius float32, tubularSegments, radialSegments, p, q int) *Geometry {
+
+	t := NewGeometry()
+
+	positions := math32.NewArrayF32(0, 0)
+	normals := math32.NewArrayF32(0, 0)
+	uvs := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, 0)
+
+	// knotPoint returns the point of the knot curve at the specified parameter
+	knotPoint := func(u float32) math32.Vector3 {
+		qu := float32(q) / float32(p) * u
+		cs := math32.Cos(qu)
+		return math32.Vector3{
+			radius * (2 + cs) * 0.5 * math32.Cos(u),
+			radius * (2 + cs) * 0.5 * math32.Sin(u),
+			radius * math32.Sin(qu) * 0.5,
+		}
+	}
+
+	for i := 0; i <= tubularSegments; i++ {
+		u := float32(i) / float32(tubularSegments) * float32(p) * 2 * math32.Pi
+
+		// Frame of the tube from the curve point, a close point ahead and their sum
+		p1 := knotPoint(u)
+		p2 := knotPoint(u + 0.01)
+		var tangent, normal, binormal math32.Vector3
+		tangent.SubVectors(&p2, &p1)
+		normal.AddVectors(&p2, &p1)
+		binormal.CrossVectors(&tangent, &normal).Normalize()
+		normal.CrossVectors(&binormal, &tangent).Normalize()
+
+		for j := 0; j <= radialSegments; j++ {
+			v := float32(j) / float32(radialSegments) * 2 * math32.Pi
+			cx := -tubeRadius * math32.Cos(v)
+			cy := tubeRadius * math32.Sin(v)
+			vertex := p1
+			vertex.X += cx*normal.X + cy*binormal.X
+			vertex.Y += cx*normal.Y + cy*binormal.Y
+			vertex.Z += cx*normal.Z + cy*binormal.Z
+			positions.AppendVector3(&vertex)
+			var n math32.Vector3
+			n.SubVectors(&vertex, &p1).Normalize()
+			normals.AppendVector3(&n)
+			uvs.Append(float32(i)/float32(tubularSegments), float32(j)/float32(radialSegments))
+		}
+	}
+
+	stride := uint32(radialSegments + 1)
+	for i := uint32(1); i <= uint32(tubularSegments); i++ {
+		for j := uint32(1); j <= uint32(radialSegments); j++ {
+			a := stride*(i-1) + j - 1
+			b := stride*i + j - 1
+			c := stride*i + j
+			d := stride*(i-1) + j
+			indices.Append(a, b, d, b, c, d)
+		}
+	}
+
+	t.SetIndices(indices)
+	t.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	t.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	t.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	return t
+}
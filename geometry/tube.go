@@ -0,0 +1,65 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// NewTube creates a tube geometry with the specified radius along the specified curve, with the
+// specified number of segments along the curve and around it. If closed is true the curve is
+// considered a loop and the frames at its ends are made to match.
+// The frames are computed by parallel transport so the tube doesn't twist.
+func NewTube(path math32.Curve, tubularSegments int, radius float32, radialSegments int, closed bool) *Geometry {
+
+	t := NewGeometry()
+
+	// Samples the curve and computes the frames
+	points := make([]math32.Vector3, tubularSegments+1)
+	for i := range points {
+		points[i] = path.PointAt(float32(i) / float32(tubularSegments))
+	}
+	_, normals, binormals := math32.FrenetFrames(path, tubularSegments, closed)
+
+	positions := math32.NewArrayF32(0, 0)
+	vnormals := math32.NewArrayF32(0, 0)
+	uvs := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, 0)
+
+	var n math32.Vector3
+	for i := 0; i <= tubularSegments; i++ {
+		for j := 0; j <= radialSegments; j++ {
+			v := float32(j) / float32(radialSegments) * 2 * math32.Pi
+			sin, cos := math32.Sin(v), -math32.Cos(v)
+			n.Set(
+				cos*normals[i].X+sin*binormals[i].X,
+				cos*normals[i].Y+sin*binormals[i].Y,
+				cos*normals[i].Z+sin*binormals[i].Z,
+			).Normalize()
+			p := points[i]
+			positions.Append(p.X+radius*n.X, p.Y+radius*n.Y, p.Z+radius*n.Z)
+			vnormals.AppendVector3(&n)
+			uvs.Append(float32(i)/float32(tubularSegments), float32(j)/float32(radialSegments))
+		}
+	}
+
+	row := uint32(radialSegments + 1)
+	for i := uint32(0); i < uint32(tubularSegments); i++ {
+		for j := uint32(0); j < uint32(radialSegments); j++ {
+			a := i*row + j
+			b := (i+1)*row + j
+			c := b + 1
+			d := a + 1
+			indices.Append(a, b, d, b, c, d)
+		}
+	}
+
+	t.SetIndices(indices)
+	t.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	t.AddVBO(gls.NewVBO(vnormals).AddAttrib(gls.VertexNormal))
+	t.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	return t
+}
@@ -0,0 +1,135 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Weld merges the vertices of this geometry whose attributes all differ by at most the
+// specified tolerance, keeping the first of them, and makes the geometry indexed.
+// The vertices not used by any triangle are removed.
+func (g *Geometry) Weld(tolerance float32) {
+
+	positions, _ := g.AttribData(gls.VertexPosition)
+	if positions == nil {
+		return
+	}
+
+	// Packs all the standard attributes of each vertex together
+	var attribs []math32.ArrayF32
+	var sizes []int
+	stride := 0
+	for _, atype := range vertexAttribTypes {
+		values, size := g.AttribData(atype)
+		if values != nil {
+			attribs = append(attribs, values)
+			sizes = append(sizes, size)
+			stride += size
+		}
+	}
+	vertexCount := positions.Size() / 3
+	packed := make([]float32, 0, vertexCount*stride)
+	for v := 0; v < vertexCount; v++ {
+		for i, values := range attribs {
+			packed = append(packed, values[v*sizes[i]:v*sizes[i]+sizes[i]]...)
+		}
+	}
+	vertexValues := func(v int) []float32 {
+		return packed[v*stride : v*stride+stride]
+	}
+
+	// Spatial hash of the welded vertices by position cell, with 64 bits
+	// coordinates clamped far beyond the float32 precision of the positions
+	type cell [3]int64
+	cellSize := float64(tolerance)
+	if cellSize <= 0 {
+		cellSize = 1e-6
+	}
+	cellOf := func(v int) cell {
+		return cell{weldCell(positions[3*v], cellSize), weldCell(positions[3*v+1], cellSize), weldCell(positions[3*v+2], cellSize)}
+	}
+	grid := make(map[cell][]int)
+
+	remap := make(map[uint32]uint32)
+	var newToOld []int
+	indices := g.triangleIndices()
+	newIndices := math32.NewArrayU32(0, indices.Size())
+	for _, idx := range indices {
+		if ni, ok := remap[idx]; ok {
+			newIndices.Append(ni)
+			continue
+		}
+		v := int(idx)
+		c := cellOf(v)
+		found := -1
+		for dx := int64(-1); dx <= 1 && found < 0; dx++ {
+			for dy := int64(-1); dy <= 1 && found < 0; dy++ {
+				for dz := int64(-1); dz <= 1 && found < 0; dz++ {
+					for _, nv := range grid[cell{c[0] + dx, c[1] + dy, c[2] + dz}] {
+						if withinTolerance(vertexValues(newToOld[nv]), vertexValues(v), tolerance) {
+							found = nv
+							break
+						}
+					}
+				}
+			}
+		}
+		if found < 0 {
+			found = len(newToOld)
+			newToOld = append(newToOld, v)
+			grid[c] = append(grid[c], found)
+		}
+		remap[idx] = uint32(found)
+		newIndices.Append(uint32(found))
+	}
+
+	g.remapVertices(newToOld)
+	g.SetIndices(newIndices)
+}
+
+// Reindex merges the vertices of this geometry with exactly the same attributes and
+// makes the geometry indexed. See Weld.
+func (g *Geometry) Reindex() {
+
+	g.Weld(0)
+}
+
+// Deindex converts this geometry to a non indexed geometry with
+// three vertices per triangle copied from the indexed vertices.
+func (g *Geometry) Deindex() {
+
+	if !g.Indexed() {
+		return
+	}
+	newToOld := make([]int, g.indices.Size())
+	for i, idx := range g.indices {
+		newToOld[i] = int(idx)
+	}
+	g.remapVertices(newToOld)
+	g.SetIndices(math32.NewArrayU32(0, 0))
+}
+
+// weldCell returns the index of the weld cell of the specified size containing the specified
+// coordinate, clamped far beyond the float32 precision of the coordinates.
+func weldCell(x float32, cellSize float64) int64 {
+
+	const maxCell = 1 << 62
+	return int64(math.Max(-maxCell, math.Min(maxCell, math.Floor(float64(x)/cellSize))))
+}
+
+// withinTolerance returns whether the corresponding values of a and b differ by at most tolerance.
+func withinTolerance(a, b []float32, tolerance float32) bool {
+
+	for i := range a {
+		if math32.Abs(a[i]-b[i]) > tolerance {
+			return false
+		}
+	}
+	return true
+}
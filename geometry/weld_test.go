@@ -0,0 +1,89 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Test that welding a non indexed box merges the vertices of each face
+func TestWeld(t *testing.T) {
+
+	g := NewBox(1, 2, 3)
+	g.Deindex()
+	if g.Indexed() || g.vertexCount() != 36 {
+		t.Fatalf("Deindexed box has %d vertices, expected 36", g.vertexCount())
+	}
+	g.Weld(1e-4)
+	if !g.Indexed() || len(g.Indices()) != 36 {
+		t.Errorf("Welded box has %d indices, expected 36", len(g.Indices()))
+	}
+	// The normals differ at the corners of the faces
+	if n := g.vertexCount(); n != 24 {
+		t.Errorf("Welded box has %d vertices, expected 24", n)
+	}
+}
+
+// Test that reindexing works far from the origin
+func TestReindexFar(t *testing.T) {
+
+	g := NewBox(1, 1, 1)
+	var m math32.Matrix4
+	m.MakeTranslation(1e5, -1e5, 1e5)
+	g.ApplyMatrix(&m)
+	g.Deindex()
+	g.Reindex()
+	if n := g.vertexCount(); n != 24 {
+		t.Errorf("Reindexed box far from the origin has %d vertices, expected 24", n)
+	}
+	positions, _ := g.AttribData(gls.VertexPosition)
+	if positions[0] < 1e5-1 || positions[1] > -1e5+1 {
+		t.Errorf("Reindexed box at %v, expected far from the origin", positions[:3])
+	}
+
+	// Distinct coordinates far from the origin are in distinct cells
+	for _, x := range []float32{1e5, -1e5, 1e7, -1e7} {
+		c0, c1 := weldCell(x, 1e-6), weldCell(x+x/1e6, 1e-6)
+		if c0 == c1 || (c0 > 0) != (x > 0) {
+			t.Errorf("Cells of %v and %v are %d and %d", x, x+x/1e6, c0, c1)
+		}
+	}
+}
+
+// Test that simplification reaches its target and is deterministic
+func TestSimplify(t *testing.T) {
+
+	simplify := func() *Geometry {
+		g := NewSphere(1, 32, 16)
+		g.Simplify(200)
+		return g
+	}
+	g := simplify()
+	tris := len(g.Indices()) / 3
+	if tris > 200 || tris < 100 {
+		t.Errorf("Simplified sphere has %d triangles, expected between 100 and 200", tris)
+	}
+	positions, _ := g.AttribData(gls.VertexPosition)
+	for i := 0; i < 5; i++ {
+		g2 := simplify()
+		positions2, _ := g2.AttribData(gls.VertexPosition)
+		if len(g2.Indices()) != len(g.Indices()) || len(positions2) != len(positions) {
+			t.Fatal("Simplify is not deterministic")
+		}
+		for j := range positions {
+			if positions[j] != positions2[j] {
+				t.Fatal("Simplify is not deterministic")
+			}
+		}
+		for j, idx := range g.Indices() {
+			if g2.Indices()[j] != idx {
+				t.Fatal("Simplify is not deterministic")
+			}
+		}
+	}
+}
@@ -26,6 +26,7 @@ type GLS struct {
 	viewportY           int32       // cached last set viewport y
 	viewportWidth       int32       // cached last set viewport width
 	viewportHeight      int32       // cached last set viewport height
+	clearColor          [4]float32  // cached last set clear color
 	lineWidth           float32     // cached last set line width
 	sideView            int         // cached last set triangle side view mode
 	frontFace           uint32      // cached last set glFrontFace value
@@ -208,6 +209,29 @@ func (gs *GLS) BindBuffer(target int, vbo uint32) {
 	gs.checkError("BindBuffer")
 }
 
+// BindFramebuffer binds the specified framebuffer to the specified target.
+// The framebuffer 0 is the default framebuffer of the canvas.
+func (gs *GLS) BindFramebuffer(target uint32, fb uint32) {
+
+	if fb == 0 {
+		gs.gl.Call("bindFramebuffer", int(target), js.Null())
+	} else {
+		gs.gl.Call("bindFramebuffer", int(target), gs.framebufferMap[fb])
+	}
+	gs.checkError("BindFramebuffer")
+}
+
+// BindRenderbuffer binds the specified renderbuffer to the specified target.
+func (gs *GLS) BindRenderbuffer(target uint32, rb uint32) {
+
+	if rb == 0 {
+		gs.gl.Call("bindRenderbuffer", int(target), js.Null())
+	} else {
+		gs.gl.Call("bindRenderbuffer", int(target), gs.renderbufferMap[rb])
+	}
+	gs.checkError("BindRenderbuffer")
+}
+
 // BindTexture lets you create or use a named texture.
 func (gs *GLS) BindTexture(target int, tex uint32) {
 
@@ -285,12 +309,23 @@ func (gs *GLS) BufferData(target uint32, size int, data interface{}, usage uint3
 	dataTA.Release()
 }
 
+// BufferSubData updates the specified number of bytes of the data store of the buffer
+// object currently bound to target, starting at the specified byte offset.
+func (gs *GLS) BufferSubData(target uint32, offset, size int, data interface{}) {
+
+	dataTA := js.TypedArrayOf(data)
+	gs.gl.Call("bufferSubData", int(target), offset, dataTA)
+	gs.checkError("BufferSubData")
+	dataTA.Release()
+}
+
 // ClearColor specifies the red, green, blue, and alpha values
 // used by glClear to clear the color buffers.
 func (gs *GLS) ClearColor(r, g, b, a float32) {
 
 	gs.gl.Call("clearColor", r, g, b, a)
 	gs.checkError("ClearColor")
+	gs.clearColor = [4]float32{r, g, b, a}
 }
 
 // ClearDepth specifies the depth value used by Clear to clear the depth buffer.
@@ -307,6 +342,14 @@ func (gs *GLS) ClearStencil(v int32) {
 	gs.checkError("ClearStencil")
 }
 
+// CheckFramebufferStatus returns the completeness status of the framebuffer bound to the specified target.
+func (gs *GLS) CheckFramebufferStatus(target uint32) uint32 {
+
+	status := gs.gl.Call("checkFramebufferStatus", int(target)).Int()
+	gs.checkError("CheckFramebufferStatus")
+	return uint32(status)
+}
+
 // Clear sets the bitplane area of the window to values previously
 // selected by ClearColor, ClearDepth, and ClearStencil.
 func (gs *GLS) Clear(mask uint) {
@@ -357,6 +400,26 @@ func (gs *GLS) DeleteBuffers(bufs ...uint32) {
 	}
 }
 
+// DeleteFramebuffers deletes the specified framebuffer objects.
+func (gs *GLS) DeleteFramebuffers(fbs ...uint32) {
+
+	for _, fb := range fbs {
+		gs.gl.Call("deleteFramebuffer", gs.framebufferMap[fb])
+		gs.checkError("DeleteFramebuffers")
+		delete(gs.framebufferMap, fb)
+	}
+}
+
+// DeleteRenderbuffers deletes the specified renderbuffer objects.
+func (gs *GLS) DeleteRenderbuffers(rbs ...uint32) {
+
+	for _, rb := range rbs {
+		gs.gl.Call("deleteRenderbuffer", gs.renderbufferMap[rb])
+		gs.checkError("DeleteRenderbuffers")
+		delete(gs.renderbufferMap, rb)
+	}
+}
+
 // DeleteShader frees the memory and invalidates the name
 // associated with the specified shader object.
 func (gs *GLS) DeleteShader(shader uint32) {
@@ -485,6 +548,22 @@ func (gs *GLS) CullFace(mode uint32) {
 	gs.checkError("CullFace")
 }
 
+// FramebufferRenderbuffer attaches the specified renderbuffer to the specified
+// attachment point of the framebuffer bound to the specified target.
+func (gs *GLS) FramebufferRenderbuffer(target, attachment, rbtarget, rb uint32) {
+
+	gs.gl.Call("framebufferRenderbuffer", int(target), int(attachment), int(rbtarget), gs.renderbufferMap[rb])
+	gs.checkError("FramebufferRenderbuffer")
+}
+
+// FramebufferTexture2D attaches the specified level of a texture to the specified
+// attachment point of the framebuffer bound to the specified target.
+func (gs *GLS) FramebufferTexture2D(target, attachment, textarget, tex uint32, level int32) {
+
+	gs.gl.Call("framebufferTexture2D", int(target), int(attachment), int(textarget), gs.textureMap[tex], level)
+	gs.checkError("FramebufferTexture2D")
+}
+
 // FrontFace defines front- and back-facing polygons.
 func (gs *GLS) FrontFace(mode uint32) {
 
@@ -507,6 +586,26 @@ func (gs *GLS) GenBuffer() uint32 {
 	return idx
 }
 
+// GenFramebuffer generates a framebuffer object name.
+func (gs *GLS) GenFramebuffer() uint32 {
+
+	gs.framebufferMap[gs.framebufferMapIndex] = gs.gl.Call("createFramebuffer")
+	gs.checkError("GenFramebuffer")
+	idx := gs.framebufferMapIndex
+	gs.framebufferMapIndex++
+	return idx
+}
+
+// GenRenderbuffer generates a renderbuffer object name.
+func (gs *GLS) GenRenderbuffer() uint32 {
+
+	gs.renderbufferMap[gs.renderbufferMapIndex] = gs.gl.Call("createRenderbuffer")
+	gs.checkError("GenRenderbuffer")
+	idx := gs.renderbufferMapIndex
+	gs.renderbufferMapIndex++
+	return idx
+}
+
 // GenerateMipmap generates mipmaps for the specified texture target.
 func (gs *GLS) GenerateMipmap(target uint32) {
 
@@ -599,6 +698,12 @@ func (gs *GLS) GetUniformLocation(program uint32, name string) int32 {
 	return int32(idx)
 }
 
+// GetClearColor returns the current clear color.
+func (gs *GLS) GetClearColor() (r, g, b, a float32) {
+
+	return gs.clearColor[0], gs.clearColor[1], gs.clearColor[2], gs.clearColor[3]
+}
+
 // GetViewport returns the current viewport information.
 func (gs *GLS) GetViewport() (x, y, width, height int32) {
 
@@ -640,6 +745,14 @@ func (gs *GLS) GetShaderiv(shader, pname uint32, params *int32) {
 	}
 }
 
+// RenderbufferStorage creates the data store of the renderbuffer bound
+// to the specified target with the specified internal format and size.
+func (gs *GLS) RenderbufferStorage(target, iformat uint32, width, height int32) {
+
+	gs.gl.Call("renderbufferStorage", int(target), int(iformat), width, height)
+	gs.checkError("RenderbufferStorage")
+}
+
 // Scissor defines the scissor box rectangle in window coordinates.
 func (gs *GLS) Scissor(x, y int32, width, height uint32) {
 
@@ -663,6 +776,14 @@ func (gs *GLS) TexImage2D(target uint32, level int32, iformat int32, width int32
 	dataTA.Release()
 }
 
+// CopyTexSubImage2D replaces a rectangular region of the bound texture with pixels
+// read from the current read framebuffer. WebGL does not support copying to depth textures.
+func (gs *GLS) CopyTexSubImage2D(target uint32, level, xoffset, yoffset, x, y, width, height int32) {
+
+	gs.gl.Call("copyTexSubImage2D", int(target), level, xoffset, yoffset, x, y, width, height)
+	gs.checkError("CopyTexSubImage2D")
+}
+
 // TexParameteri sets the specified texture parameter on the specified texture.
 func (gs *GLS) TexParameteri(target uint32, pname uint32, param int32) {
 
@@ -52,6 +52,7 @@ type GLS struct {
 	polygonModeMode     uint32      // cached last set polygon mode mode
 	polygonOffsetFactor float32     // cached last set polygon offset factor
 	polygonOffsetUnits  float32     // cached last set polygon offset units
+	clearColor          [4]float32  // cached last set clear color
 	gobuf               []byte      // conversion buffer with GO memory
 	cbuf                []byte      // conversion buffer with C memory
 }
@@ -186,6 +187,19 @@ func (gs *GLS) BindBuffer(target int, vbo uint32) {
 	C.glBindBuffer(C.GLenum(target), C.GLuint(vbo))
 }
 
+// BindFramebuffer binds the specified framebuffer to the specified target.
+// The framebuffer 0 is the default framebuffer of the window.
+func (gs *GLS) BindFramebuffer(target uint32, fb uint32) {
+
+	C.glBindFramebuffer(C.GLenum(target), C.GLuint(fb))
+}
+
+// BindRenderbuffer binds the specified renderbuffer to the specified target.
+func (gs *GLS) BindRenderbuffer(target uint32, rb uint32) {
+
+	C.glBindRenderbuffer(C.GLenum(target), C.GLuint(rb))
+}
+
 // BindTexture lets you create or use a named texture.
 func (gs *GLS) BindTexture(target int, tex uint32) {
 
@@ -254,11 +268,19 @@ func (gs *GLS) BufferData(target uint32, size int, data interface{}, usage uint3
 	C.glBufferData(C.GLenum(target), C.GLsizeiptr(size), ptr(data), C.GLenum(usage))
 }
 
+// BufferSubData updates the specified number of bytes of the data store of the buffer
+// object currently bound to target, starting at the specified byte offset.
+func (gs *GLS) BufferSubData(target uint32, offset, size int, data interface{}) {
+
+	C.glBufferSubData(C.GLenum(target), C.GLintptr(offset), C.GLsizeiptr(size), ptr(data))
+}
+
 // ClearColor specifies the red, green, blue, and alpha values
 // used by glClear to clear the color buffers.
 func (gs *GLS) ClearColor(r, g, b, a float32) {
 
 	C.glClearColor(C.GLfloat(r), C.GLfloat(g), C.GLfloat(b), C.GLfloat(a))
+	gs.clearColor = [4]float32{r, g, b, a}
 }
 
 // ClearDepth specifies the depth value used by Clear to clear the depth buffer.
@@ -273,6 +295,12 @@ func (gs *GLS) ClearStencil(v int32) {
 	C.glClearStencil(C.GLint(v))
 }
 
+// CheckFramebufferStatus returns the completeness status of the framebuffer bound to the specified target.
+func (gs *GLS) CheckFramebufferStatus(target uint32) uint32 {
+
+	return uint32(C.glCheckFramebufferStatus(C.GLenum(target)))
+}
+
 // Clear sets the bitplane area of the window to values previously
 // selected by ClearColor, ClearDepth, and ClearStencil.
 func (gs *GLS) Clear(mask uint) {
@@ -311,6 +339,18 @@ func (gs *GLS) DeleteBuffers(bufs ...uint32) {
 	gs.stats.Buffers -= len(bufs)
 }
 
+// DeleteFramebuffers deletes the specified framebuffer objects.
+func (gs *GLS) DeleteFramebuffers(fbs ...uint32) {
+
+	C.glDeleteFramebuffers(C.GLsizei(len(fbs)), (*C.GLuint)(&fbs[0]))
+}
+
+// DeleteRenderbuffers deletes the specified renderbuffer objects.
+func (gs *GLS) DeleteRenderbuffers(rbs ...uint32) {
+
+	C.glDeleteRenderbuffers(C.GLsizei(len(rbs)), (*C.GLuint)(&rbs[0]))
+}
+
 // DeleteShader frees the memory and invalidates the name
 // associated with the specified shader object.
 func (gs *GLS) DeleteShader(shader uint32) {
@@ -452,6 +492,20 @@ func (gs *GLS) CullFace(mode uint32) {
 	C.glCullFace(C.GLenum(mode))
 }
 
+// FramebufferRenderbuffer attaches the specified renderbuffer to the specified
+// attachment point of the framebuffer bound to the specified target.
+func (gs *GLS) FramebufferRenderbuffer(target, attachment, rbtarget, rb uint32) {
+
+	C.glFramebufferRenderbuffer(C.GLenum(target), C.GLenum(attachment), C.GLenum(rbtarget), C.GLuint(rb))
+}
+
+// FramebufferTexture2D attaches the specified level of a texture to the specified
+// attachment point of the framebuffer bound to the specified target.
+func (gs *GLS) FramebufferTexture2D(target, attachment, textarget, tex uint32, level int32) {
+
+	C.glFramebufferTexture2D(C.GLenum(target), C.GLenum(attachment), C.GLenum(textarget), C.GLuint(tex), C.GLint(level))
+}
+
 // FrontFace defines front- and back-facing polygons.
 func (gs *GLS) FrontFace(mode uint32) {
 
@@ -471,6 +525,22 @@ func (gs *GLS) GenBuffer() uint32 {
 	return buf
 }
 
+// GenFramebuffer generates a framebuffer object name.
+func (gs *GLS) GenFramebuffer() uint32 {
+
+	var fb uint32
+	C.glGenFramebuffers(1, (*C.GLuint)(&fb))
+	return fb
+}
+
+// GenRenderbuffer generates a renderbuffer object name.
+func (gs *GLS) GenRenderbuffer() uint32 {
+
+	var rb uint32
+	C.glGenRenderbuffers(1, (*C.GLuint)(&rb))
+	return rb
+}
+
 // GenerateMipmap generates mipmaps for the specified texture target.
 func (gs *GLS) GenerateMipmap(target uint32) {
 
@@ -546,6 +616,12 @@ func (gs *GLS) GetUniformLocation(program uint32, name string) int32 {
 	return int32(loc)
 }
 
+// GetClearColor returns the current clear color.
+func (gs *GLS) GetClearColor() (r, g, b, a float32) {
+
+	return gs.clearColor[0], gs.clearColor[1], gs.clearColor[2], gs.clearColor[3]
+}
+
 // GetViewport returns the current viewport information.
 func (gs *GLS) GetViewport() (x, y, width, height int32) {
 
@@ -574,6 +650,13 @@ func (gs *GLS) GetShaderiv(shader, pname uint32, params *int32) {
 	C.glGetShaderiv(C.GLuint(shader), C.GLenum(pname), (*C.GLint)(params))
 }
 
+// RenderbufferStorage creates the data store of the renderbuffer bound
+// to the specified target with the specified internal format and size.
+func (gs *GLS) RenderbufferStorage(target, iformat uint32, width, height int32) {
+
+	C.glRenderbufferStorage(C.GLenum(target), C.GLenum(iformat), C.GLsizei(width), C.GLsizei(height))
+}
+
 // Scissor defines the scissor box rectangle in window coordinates.
 func (gs *GLS) Scissor(x, y int32, width, height uint32) {
 
@@ -601,6 +684,20 @@ func (gs *GLS) TexImage2D(target uint32, level int32, iformat int32, width int32
 		ptr(data))
 }
 
+// CopyTexSubImage2D replaces a rectangular region of the bound texture with pixels
+// read from the current read framebuffer, or its depth buffer for depth textures.
+func (gs *GLS) CopyTexSubImage2D(target uint32, level, xoffset, yoffset, x, y, width, height int32) {
+
+	C.glCopyTexSubImage2D(C.GLenum(target),
+		C.GLint(level),
+		C.GLint(xoffset),
+		C.GLint(yoffset),
+		C.GLint(x),
+		C.GLint(y),
+		C.GLsizei(width),
+		C.GLsizei(height))
+}
+
 // TexParameteri sets the specified texture parameter on the specified texture.
 func (gs *GLS) TexParameteri(target uint32, pname uint32, param int32) {
 
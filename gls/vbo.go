@@ -14,10 +14,15 @@ type VBO struct {
 	handle  uint32          // OpenGL handle for this VBO
 	usage   uint32          // Expected usage pattern of the buffer
 	update  bool            // Update flag
+	ranges  [][2]int        // Ranges of elements to update if not the whole buffer
+	size    int             // Size in bytes of the OpenGL data store
 	buffer  math32.ArrayF32 // Data buffer
 	attribs []VBOattrib     // List of attributes
 }
 
+// Maximum number of ranges to update before updating the whole buffer
+const maxUpdateRanges = 1024
+
 // VBOattrib describes one attribute of an OpenGL Vertex Buffer Object.
 type VBOattrib struct {
 	Type        AttribType // Type of the attribute
@@ -58,7 +63,7 @@ var attribTypeNameMap = map[AttribType]string{
 var attribTypeSizeMap = map[AttribType]int32{
 	VertexPosition:  3,
 	VertexNormal:    3,
-	VertexTangent:   3, // TODO
+	VertexTangent:   4, // Tangent direction and bitangent handedness
 	VertexColor:     3,
 	VertexTexcoord:  2,
 	VertexTexcoord2: 2,
@@ -200,6 +205,7 @@ func (vbo *VBO) Dispose() {
 		vbo.gs.DeleteBuffers(vbo.handle)
 	}
 	vbo.gs = nil
+	vbo.size = 0
 }
 
 // SetBuffer sets the VBO buffer.
@@ -229,6 +235,34 @@ func (vbo *VBO) Update() {
 	vbo.update = true
 }
 
+// UpdateRange sets the specified range of elements of the buffer to be transferred to OpenGL,
+// without transferring the whole buffer if its size did not change. Overlapping and adjacent
+// ranges are merged, and too many ranges update the whole buffer.
+func (vbo *VBO) UpdateRange(start, count int) {
+
+	if vbo.update || count <= 0 {
+		return
+	}
+	r := [2]int{start, start + count}
+	ranges := vbo.ranges[:0]
+	for _, other := range vbo.ranges {
+		if other[1] < r[0] || other[0] > r[1] {
+			ranges = append(ranges, other)
+			continue
+		}
+		if other[0] < r[0] {
+			r[0] = other[0]
+		}
+		if other[1] > r[1] {
+			r[1] = other[1]
+		}
+	}
+	vbo.ranges = append(ranges, r)
+	if len(vbo.ranges) > maxUpdateRanges {
+		vbo.Update()
+	}
+}
+
 // AttribOffset returns the total number of elements from
 // all attributes preceding the attribute specified by type.
 func (vbo *VBO) AttribOffset(attribType AttribType) int {
@@ -314,14 +348,30 @@ func (vbo *VBO) Transfer(gs *GLS) {
 	}
 
 	// If nothing has changed, no need to transfer data to OpenGL
-	if !vbo.update {
+	if !vbo.update && len(vbo.ranges) == 0 {
 		return
 	}
 
-	// Transfer the VBO data to OpenGL
+	// Transfer the VBO data to OpenGL, only the updated ranges if the size did not change
 	gs.BindBuffer(ARRAY_BUFFER, vbo.handle)
-	gs.BufferData(ARRAY_BUFFER, vbo.buffer.Bytes(), vbo.buffer.ToFloat32(), vbo.usage)
+	if vbo.update || vbo.size != vbo.buffer.Bytes() {
+		gs.BufferData(ARRAY_BUFFER, vbo.buffer.Bytes(), vbo.buffer.ToFloat32(), vbo.usage)
+		vbo.size = vbo.buffer.Bytes()
+	} else {
+		for _, r := range vbo.ranges {
+			if r[1] > vbo.buffer.Size() {
+				r[1] = vbo.buffer.Size()
+			}
+			if r[0] >= r[1] {
+				continue
+			}
+			before := vbo.buffer[:r[0]]
+			data := vbo.buffer[r[0]:r[1]]
+			gs.BufferSubData(ARRAY_BUFFER, before.Bytes(), data.Bytes(), data.ToFloat32())
+		}
+	}
 	vbo.update = false
+	vbo.ranges = vbo.ranges[:0]
 }
 
 // OperateOnVectors3 iterates over all 3-float32 items for the specified attribute
@@ -0,0 +1,224 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// BillboardMode specifies how a Billboard turns to face the camera.
+type BillboardMode int
+
+// The possible billboard modes.
+const (
+	BillboardSpherical   = BillboardMode(iota) // Turns around all the axes, parallel to the screen
+	BillboardCylindrical                       // Turns only around the Y axis of its node, such as trees
+)
+
+// Billboard is a quad in the XY plane of its node which faces the camera, turning around
+// all the axes or only around the Y axis of its node. Its node origin is at an anchor point of
+// the quad, and its size is either in world units scaled by the node or in pixels on screen.
+// Unlike Sprite it sends the matrices used by the lights and fog of the standard materials.
+type Billboard struct {
+	Graphic                   // Embedded graphic
+	mode       BillboardMode  // How the billboard faces the camera
+	width      float32        // Width of the quad
+	height     float32        // Height of the quad
+	anchor     math32.Vector2 // Position of the node origin in the quad from its bottom left corner (0 to 1)
+	screenSize bool           // Whether the size is in pixels
+	mvm        math32.Matrix4 // Model view matrix of the last rendering
+	uniMm      gls.Uniform    // Model matrix uniform location cache
+	uniMVm     gls.Uniform    // Model view matrix uniform location cache
+	uniMVPm    gls.Uniform    // Model view projection matrix uniform location cache
+	uniNm      gls.Uniform    // Normal matrix uniform location cache
+}
+
+// NewBillboard creates and returns a pointer to a new spherical billboard with the
+// specified size and material, centered on the origin of its node.
+func NewBillboard(width, height float32, imat material.IMaterial) *Billboard {
+
+	b := new(Billboard)
+	b.init(b, width, height, imat)
+	return b
+}
+
+// init initializes the billboard of the specified graphic.
+func (b *Billboard) init(igr IGraphic, width, height float32, imat material.IMaterial) {
+
+	b.width = width
+	b.height = height
+	b.anchor = math32.Vector2{0.5, 0.5}
+	geom := geometry.NewGeometry()
+	geom.AddVBO(gls.NewVBO(math32.NewArrayF32(0, 0)).
+		AddAttrib(gls.VertexPosition).
+		AddAttrib(gls.VertexNormal).
+		AddAttrib(gls.VertexTexcoord),
+	)
+	indices := math32.NewArrayU32(0, 6)
+	indices.Append(0, 1, 2, 0, 2, 3)
+	geom.SetIndices(indices)
+	b.Graphic.Init(igr, geom, gls.TRIANGLES)
+	b.AddMaterial(igr, imat, 0, 0)
+	b.uniMm.Init("ModelMatrix")
+	b.uniMVm.Init("ModelViewMatrix")
+	b.uniMVPm.Init("MVP")
+	b.uniNm.Init("NormalMatrix")
+	b.update()
+}
+
+// SetMode sets how the billboard faces the camera.
+func (b *Billboard) SetMode(mode BillboardMode) {
+
+	b.mode = mode
+	b.update()
+}
+
+// Mode returns how the billboard faces the camera.
+func (b *Billboard) Mode() BillboardMode {
+
+	return b.mode
+}
+
+// SetSize sets the size of the billboard, in world units or in pixels if ScreenSize is set.
+func (b *Billboard) SetSize(width, height float32) {
+
+	b.width = width
+	b.height = height
+	b.update()
+}
+
+// Size returns the size of the billboard.
+func (b *Billboard) Size() (float32, float32) {
+
+	return b.width, b.height
+}
+
+// SetAnchor sets the position of the node origin in the quad, from 0 to 1 from its bottom
+// left corner. The default is the center and trees are usually anchored at (0.5, 0).
+func (b *Billboard) SetAnchor(x, y float32) {
+
+	b.anchor = math32.Vector2{x, y}
+	b.update()
+}
+
+// Anchor returns the position of the node origin in the quad from its bottom left corner.
+func (b *Billboard) Anchor() math32.Vector2 {
+
+	return b.anchor
+}
+
+// SetScreenSize sets whether the size of the billboard is in pixels, keeping it constant
+// on screen at any distance, such as for markers. These billboards are not culled.
+func (b *Billboard) SetScreenSize(state bool) {
+
+	b.screenSize = state
+	b.SetCullable(!state)
+}
+
+// ScreenSize returns whether the size of the billboard is in pixels.
+func (b *Billboard) ScreenSize() bool {
+
+	return b.screenSize
+}
+
+// update rebuilds the quad from the size and anchor. Two vertices outside of the
+// triangles extend the bounding box over all the orientations of the quad for culling.
+func (b *Billboard) update() {
+
+	x0, y0 := -b.anchor.X*b.width, -b.anchor.Y*b.height
+	x1, y1 := x0+b.width, y0+b.height
+	buf := math32.NewArrayF32(0, 48)
+	buf.Append(
+		x0, y0, 0, 0, 0, 1, 0, 0,
+		x1, y0, 0, 0, 0, 1, 1, 0,
+		x1, y1, 0, 0, 0, 1, 1, 1,
+		x0, y1, 0, 0, 0, 1, 0, 1,
+	)
+	r := math32.Max(math32.Abs(x0), math32.Abs(x1))
+	if b.mode == BillboardSpherical {
+		r = math32.Sqrt(r*r + math32.Max(y0*y0, y1*y1))
+		buf.Append(-r, -r, -r, 0, 0, 1, 0, 0, r, r, r, 0, 0, 1, 0, 0)
+	} else {
+		buf.Append(-r, y0, -r, 0, 0, 1, 0, 0, r, y1, r, 0, 0, 1, 0, 0)
+	}
+	b.GetGeometry().VBO(gls.VertexPosition).SetBuffer(buf)
+	b.GetGeometry().SetIndices(b.GetGeometry().Indices())
+}
+
+// modelView calculates the model view matrix of the billboard facing the camera.
+func (b *Billboard) modelView(gs *gls.GLS, rinfo *core.RenderInfo) *math32.Matrix4 {
+
+	mw := b.MatrixWorld()
+	var pos, scale math32.Vector3
+	var quat math32.Quaternion
+	mw.Decompose(&pos, &quat, &scale)
+	pos.ApplyMatrix4(&rinfo.ViewMatrix)
+	view := &rinfo.ViewMatrix
+	proj := &rinfo.ProjMatrix
+
+	// Axes of the billboard in camera coordinates
+	var x, y, z math32.Vector3
+	if b.mode == BillboardCylindrical {
+		y.Set(mw[4], mw[5], mw[6])
+		y.Set(view[0]*y.X+view[4]*y.Y+view[8]*y.Z, view[1]*y.X+view[5]*y.Y+view[9]*y.Z, view[2]*y.X+view[6]*y.Y+view[10]*y.Z).Normalize()
+		// Toward the camera, or along the view axis with an orthographic projection
+		z.Set(0, 0, 1)
+		if proj[11] != 0 {
+			z.Copy(&pos).Negate()
+		}
+		var along math32.Vector3
+		z.Sub(along.Copy(&y).MultiplyScalar(z.Dot(&y)))
+		if z.LengthSq() < 1e-12 {
+			// Seen along the Y axis, faces the top of the screen
+			z.Set(0, 1, 0).Sub(along.Copy(&y).MultiplyScalar(y.Y))
+		}
+		z.Normalize()
+		x.CrossVectors(&y, &z)
+	} else {
+		// Keeps the rotation of the node around the view axis like Sprite
+		rot := b.Rotation().Z
+		x.Set(math32.Cos(rot), math32.Sin(rot), 0)
+		y.Set(-x.Y, x.X, 0)
+		z.Set(0, 0, 1)
+	}
+
+	// Size of a pixel at the distance of the billboard from the projection and the viewport
+	if b.screenSize {
+		_, _, _, height := gs.GetViewport()
+		w := proj[3]*pos.X + proj[7]*pos.Y + proj[11]*pos.Z + proj[15]
+		s := 2 * w / (proj[5] * float32(height))
+		scale.Set(s, s, s)
+	}
+	x.MultiplyScalar(scale.X)
+	y.MultiplyScalar(scale.Y)
+	z.MultiplyScalar(scale.Z)
+	b.mvm.Set(
+		x.X, y.X, z.X, pos.X,
+		x.Y, y.Y, z.Y, pos.Y,
+		x.Z, y.Z, z.Z, pos.Z,
+		0, 0, 0, 1,
+	)
+	return &b.mvm
+}
+
+// RenderSetup sets up the rendering of the billboard.
+func (b *Billboard) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+
+	mvm := b.modelView(gs, rinfo)
+	var mm, invView, mvpm math32.Matrix4
+	invView.GetInverse(&rinfo.ViewMatrix)
+	mm.MultiplyMatrices(&invView, mvm)
+	mvpm.MultiplyMatrices(&rinfo.ProjMatrix, mvm)
+	var nm math32.Matrix3
+	nm.GetNormalMatrix(mvm)
+	gs.UniformMatrix4fv(b.uniMm.Location(gs), 1, false, &mm[0])
+	gs.UniformMatrix4fv(b.uniMVm.Location(gs), 1, false, &mvm[0])
+	gs.UniformMatrix4fv(b.uniMVPm.Location(gs), 1, false, &mvpm[0])
+	gs.UniformMatrix3fv(b.uniNm.Location(gs), 1, false, &nm[0])
+}
@@ -0,0 +1,191 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// Decal is a mesh with the triangles of a target mesh inside an oriented box, clipped to it,
+// with texture coordinates projecting the material textures onto them along the box -Z axis.
+// Its geometry is in the model coordinates of the target, so it should be added as a child of
+// the target to follow it. The polygon offset of the material is set to draw the decal in front
+// of the target surfaces. For an alternative without geometry see ScreenDecal.
+type Decal struct {
+	Mesh                          // Embedded mesh
+	target      *Mesh             // Mesh onto which the decal is projected
+	position    math32.Vector3    // Center of the box in world coordinates
+	orientation math32.Quaternion // Orientation of the box in world coordinates
+	size        math32.Vector3    // Size of the box
+	maxAngle    float32           // Maximum angle between the surfaces and the projection direction
+}
+
+// decalVertex is a vertex of a triangle clipped by a Decal.
+type decalVertex struct {
+	box    math32.Vector3 // Position in box coordinates
+	pos    math32.Vector3 // Position in target model coordinates
+	normal math32.Vector3 // Normal in target model coordinates
+}
+
+// NewDecal creates and returns a pointer to a new decal projecting the textures of the specified
+// material onto the target mesh inside the box with the specified center, orientation and size
+// in world coordinates. The texture U and V axes are the box X and Y axes.
+func NewDecal(target *Mesh, position *math32.Vector3, orientation *math32.Quaternion, size *math32.Vector3, imat material.IMaterial) *Decal {
+
+	d := new(Decal)
+	d.target = target
+	d.maxAngle = math32.Pi / 2
+	d.Mesh.Init(geometry.NewGeometry(), imat)
+	d.SetIGraphic(d)
+	imat.GetMaterial().SetPolygonOffset(-1, -4)
+	d.Project(position, orientation, size)
+	return d
+}
+
+// Target returns the mesh onto which the decal is projected.
+func (d *Decal) Target() *Mesh {
+
+	return d.target
+}
+
+// SetMaxAngle sets the maximum angle in radians between the normal of the target triangles
+// and the direction opposite to the projection for them to receive the decal, which avoids
+// stretching the textures on steep surfaces. The default is Pi/2, which excludes back faces.
+func (d *Decal) SetMaxAngle(angle float32) {
+
+	d.maxAngle = angle
+	d.Project(&d.position, &d.orientation, &d.size)
+}
+
+// MaxAngle returns the maximum angle between the normal of the target triangles and the
+// direction opposite to the projection.
+func (d *Decal) MaxAngle() float32 {
+
+	return d.maxAngle
+}
+
+// Project regenerates the geometry of the decal for the box with the specified center,
+// orientation and size in world coordinates, using the current world transform of the target.
+func (d *Decal) Project(position *math32.Vector3, orientation *math32.Quaternion, size *math32.Vector3) {
+
+	d.position = *position
+	d.orientation = *orientation
+	d.size = *size
+
+	// Transform from the target model coordinates to the unit box coordinates
+	var box, toBox math32.Matrix4
+	box.Compose(position, orientation, size)
+	if err := toBox.GetInverse(&box); err != nil {
+		panic("Invalid argument(s). The decal size must not be zero")
+	}
+	world := d.target.MatrixWorld()
+	toBox.Multiply(&world)
+	var normalMatrix math32.Matrix3
+	normalMatrix.GetNormalMatrix(&world)
+	axis := math32.Vector3{0, 0, 1}
+	axis.ApplyQuaternion(orientation)
+	minCos := math32.Cos(d.maxAngle)
+	if math32.Abs(minCos) < 1e-6 {
+		minCos = 0 // Excludes the faces parallel to the projection with the default angle
+	}
+
+	geom := d.target.GetGeometry()
+	positions, _ := geom.AttribData(gls.VertexPosition)
+	normals, _ := geom.AttribData(gls.VertexNormal)
+	indices := geom.Indices()
+	if !geom.Indexed() {
+		indices = math32.NewArrayU32(0, positions.Size()/3)
+		for i := 0; i < positions.Size()/3; i++ {
+			indices.Append(uint32(i))
+		}
+	}
+
+	outPositions := math32.NewArrayF32(0, 0)
+	outNormals := math32.NewArrayF32(0, 0)
+	outUvs := math32.NewArrayF32(0, 0)
+	var poly, tmp []decalVertex
+	for f := 0; f+2 < indices.Size(); f += 3 {
+		// Skips the triangles facing away from the projection using their world normal
+		poly = poly[:0]
+		for c := 0; c < 3; c++ {
+			var v decalVertex
+			positions.GetVector3(3*int(indices[f+c]), &v.pos)
+			poly = append(poly, v)
+		}
+		var e1, e2, faceNormal math32.Vector3
+		e1.SubVectors(&poly[1].pos, &poly[0].pos)
+		e2.SubVectors(&poly[2].pos, &poly[0].pos)
+		faceNormal.CrossVectors(&e1, &e2).ApplyMatrix3(&normalMatrix).Normalize()
+		if faceNormal.Dot(&axis) <= minCos {
+			continue
+		}
+		for c := range poly {
+			v := &poly[c]
+			v.box = v.pos
+			v.box.ApplyMatrix4(&toBox)
+			if normals != nil {
+				normals.GetVector3(3*int(indices[f+c]), &v.normal)
+			} else {
+				v.normal.CrossVectors(&e1, &e2).Normalize()
+			}
+		}
+
+		// Clips the triangle against the six planes of the box
+		for a := 0; a < 3 && len(poly) > 0; a++ {
+			for _, sign := range []float32{1, -1} {
+				tmp = clipDecalPolygon(poly, tmp[:0], a, sign)
+				poly, tmp = tmp, poly
+			}
+		}
+		if len(poly) < 3 {
+			continue
+		}
+
+		// Triangulates the clipped polygon as a fan
+		for i := 2; i < len(poly); i++ {
+			for _, v := range []*decalVertex{&poly[0], &poly[i-1], &poly[i]} {
+				outPositions.AppendVector3(&v.pos)
+				outNormals.AppendVector3(&v.normal)
+				outUvs.Append(v.box.X+0.5, v.box.Y+0.5)
+			}
+		}
+	}
+
+	out := d.GetGeometry()
+	out.SetAttribData(gls.VertexPosition, outPositions, 3)
+	out.SetAttribData(gls.VertexNormal, outNormals, 3)
+	out.SetAttribData(gls.VertexTexcoord, outUvs, 2)
+	d.SetRenderable(outPositions.Size() > 0)
+}
+
+// clipDecalPolygon appends to out the part of the polygon on the inner side of the box plane
+// perpendicular to the specified axis on the side of the specified sign, and returns it.
+func clipDecalPolygon(poly, out []decalVertex, axis int, sign float32) []decalVertex {
+
+	for i := range poly {
+		a := &poly[i]
+		b := &poly[(i+1)%len(poly)]
+		da := 0.5 - sign*a.box.Component(axis)
+		db := 0.5 - sign*b.box.Component(axis)
+		if da >= 0 {
+			out = append(out, *a)
+		}
+		if (da >= 0) != (db >= 0) {
+			t := da / (da - db)
+			var v decalVertex
+			v.box = a.box
+			v.box.Lerp(&b.box, t)
+			v.pos = a.pos
+			v.pos.Lerp(&b.pos, t)
+			v.normal = a.normal
+			v.normal.Lerp(&b.normal, t).Normalize()
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// ImpostorAtlas is a texture atlas of views of an object rendered by the renderer from
+// regularly spaced angles around its vertical axis, used by impostors to display the
+// object at a distance. View i is seen from the direction (sin(a), 0, cos(a)) with
+// a = 2*Pi*i/Views, and is in column i%Columns and row i/Columns from the bottom left.
+type ImpostorAtlas struct {
+	Texture *texture.Texture2D // Atlas texture
+	Views   int                // Number of views
+	Columns int                // Number of columns of views
+	Rows    int                // Number of rows of views
+	Radius  float32            // Horizontal radius of the object around its origin
+	Bottom  float32            // Height of the bottom of the object relative to its origin
+	Top     float32            // Height of the top of the object relative to its origin
+}
+
+// Impostor is a cylindrical billboard which displays an object from an ImpostorAtlas,
+// cross fading the views baked from the two angles nearest to the direction of the camera
+// around its Y axis. The impostor has the same origin and size as the baked object.
+type Impostor struct {
+	Billboard                    // Embedded billboard
+	mat       *material.Material // Impostor material
+	atlas     *ImpostorAtlas     // Atlas of views
+	udata     [8]float32         // ImpostorParams uniform data (2 vec4)
+	uniParams gls.Uniform        // ImpostorParams uniform location cache
+}
+
+// NewImpostor creates and returns a pointer to a new impostor displaying the views of the specified atlas.
+func NewImpostor(atlas *ImpostorAtlas) *Impostor {
+
+	if atlas.Views < 1 || atlas.Columns*atlas.Rows < atlas.Views || atlas.Top <= atlas.Bottom {
+		panic("Invalid argument(s). The atlas must have at least one view and a height")
+	}
+	im := new(Impostor)
+	im.atlas = atlas
+	im.mat = material.NewMaterial()
+	im.mat.SetShader("impostor")
+	im.mat.SetUseLights(material.UseLightNone)
+	atlas.Texture.SetUniformNames("uImpostorSampler", "uImpostorTexParams")
+	im.mat.AddTexture(atlas.Texture.Incref())
+	im.Billboard.init(im, 2*atlas.Radius, atlas.Top-atlas.Bottom, im.mat)
+	im.mode = BillboardCylindrical
+	im.SetAnchor(0.5, -atlas.Bottom/(atlas.Top-atlas.Bottom))
+	im.udata[4] = 1 / float32(atlas.Columns)
+	im.udata[5] = 1 / float32(atlas.Rows)
+	im.SetAlphaCutoff(0.5)
+	im.uniParams.Init("ImpostorParams")
+	return im
+}
+
+// Material returns the material of the impostor.
+func (im *Impostor) Material() *material.Material {
+
+	return im.mat
+}
+
+// Atlas returns the atlas of views of the impostor.
+func (im *Impostor) Atlas() *ImpostorAtlas {
+
+	return im.atlas
+}
+
+// SetAlphaCutoff sets the opacity of the atlas below which the fragments are discarded.
+func (im *Impostor) SetAlphaCutoff(cutoff float32) {
+
+	im.udata[7] = cutoff
+}
+
+// AlphaCutoff returns the opacity of the atlas below which the fragments are discarded.
+func (im *Impostor) AlphaCutoff() float32 {
+
+	return im.udata[7]
+}
+
+// RenderSetup sets up the rendering of the impostor.
+func (im *Impostor) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+
+	im.Billboard.RenderSetup(gs, rinfo)
+
+	// Direction to the camera in local coordinates, or opposite to the view direction with an orthographic projection
+	var camWorld, invWorld math32.Matrix4
+	camWorld.GetInverse(&rinfo.ViewMatrix)
+	mw := im.MatrixWorld()
+	invWorld.GetInverse(&mw)
+	var dir math32.Vector3
+	dir.SetFromMatrixPosition(&camWorld)
+	if rinfo.ProjMatrix[11] == 0 {
+		var pos math32.Vector3
+		pos.SetFromMatrixPosition(&mw)
+		dir.Set(camWorld[8], camWorld[9], camWorld[10]).Add(&pos)
+	}
+	dir.ApplyMatrix4(&invWorld)
+
+	// Nearest views and blend factor from the azimuth of the camera
+	views := im.atlas.Views
+	f := math32.Atan2(dir.X, dir.Z) / (2 * math32.Pi) * float32(views)
+	if f < 0 {
+		f += float32(views)
+	}
+	v0 := int(f) % views
+	v1 := (v0 + 1) % views
+	im.udata[0] = float32(v0%im.atlas.Columns) * im.udata[4]
+	im.udata[1] = float32(v0/im.atlas.Columns) * im.udata[5]
+	im.udata[2] = float32(v1%im.atlas.Columns) * im.udata[4]
+	im.udata[3] = float32(v1/im.atlas.Columns) * im.udata[5]
+	im.udata[6] = f - math32.Floor(f)
+	gs.Uniform4fv(im.uniParams.Location(gs), 2, &im.udata[0])
+}
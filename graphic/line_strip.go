@@ -15,6 +15,7 @@ import (
 type LineStrip struct {
 	Graphic             // Embedded graphic object
 	uniMVPm gls.Uniform // Model view projection matrix uniform location cache
+	uniMVm  gls.Uniform // Model view matrix uniform location cache
 }
 
 // NewLineStrip creates and returns a pointer to a new LineStrip graphic
@@ -25,6 +26,7 @@ func NewLineStrip(igeom geometry.IGeometry, imat material.IMaterial) *LineStrip
 	l.Graphic.Init(l, igeom, gls.LINE_STRIP)
 	l.AddMaterial(l, imat, 0, 0)
 	l.uniMVPm.Init("MVP")
+	l.uniMVm.Init("ModelViewMatrix")
 	return l
 }
 
@@ -35,4 +37,10 @@ func (l *LineStrip) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
 	mvpm := l.ModelViewProjectionMatrix()
 	location := l.uniMVPm.Location(gs)
 	gs.UniformMatrix4fv(location, 1, false, &mvpm[0])
+
+	// Transfer model view matrix uniform used by the fog
+	if rinfo.Fog != nil {
+		mvm := l.ModelViewMatrix()
+		gs.UniformMatrix4fv(l.uniMVm.Location(gs), 1, false, &mvm[0])
+	}
 }
@@ -15,6 +15,7 @@ import (
 type Lines struct {
 	Graphic             // Embedded graphic object
 	uniMVPm gls.Uniform // Model view projection matrix uniform location cache
+	uniMVm  gls.Uniform // Model view matrix uniform location cache
 }
 
 // NewLines returns a pointer to a new Lines object.
@@ -31,6 +32,7 @@ func (l *Lines) Init(igeom geometry.IGeometry, imat material.IMaterial) {
 	l.Graphic.Init(l, igeom, gls.LINES)
 	l.AddMaterial(l, imat, 0, 0)
 	l.uniMVPm.Init("MVP")
+	l.uniMVm.Init("ModelViewMatrix")
 }
 
 // RenderSetup is called by the engine before drawing this geometry.
@@ -40,4 +42,10 @@ func (l *Lines) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
 	mvpm := l.ModelViewProjectionMatrix()
 	location := l.uniMVPm.Location(gs)
 	gs.UniformMatrix4fv(location, 1, false, &mvpm[0])
+
+	// Transfer model view matrix uniform used by the fog
+	if rinfo.Fog != nil {
+		mvm := l.ModelViewMatrix()
+		gs.UniformMatrix4fv(l.uniMVm.Location(gs), 1, false, &mvm[0])
+	}
 }
@@ -0,0 +1,109 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"sort"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+// LOD is a node which shows only one of its levels of detail, selected by the renderer
+// from the distance of the camera to its origin, such as a mesh near the camera and an
+// Impostor of it farther away. The levels beyond the distance of the last level are hidden.
+type LOD struct {
+	core.Node            // Embedded node
+	levels    []lodLevel // Levels of detail sorted by distance
+	maxDist   float32    // Distance beyond which no level is shown (0 for none)
+	current   int        // Index of the current level or -1 if none
+}
+
+// lodLevel is a level of detail of a LOD.
+type lodLevel struct {
+	inode    core.INode // Node of the level
+	distance float32    // Distance from which the level is shown
+}
+
+// NewLOD creates and returns a pointer to a new LOD without levels.
+func NewLOD() *LOD {
+
+	lod := new(LOD)
+	lod.Node.Init(lod)
+	lod.current = -1
+	return lod
+}
+
+// AddLevel adds the specified node as a child of the LOD shown from the specified distance
+// until the distance of the next level. The first level should usually be at distance 0.
+func (lod *LOD) AddLevel(inode core.INode, distance float32) {
+
+	lod.Add(inode)
+	lod.levels = append(lod.levels, lodLevel{inode, distance})
+	sort.SliceStable(lod.levels, func(i, j int) bool { return lod.levels[i].distance < lod.levels[j].distance })
+	lod.current = -1
+}
+
+// RemoveLevel removes the specified level node from the LOD and its children.
+func (lod *LOD) RemoveLevel(inode core.INode) {
+
+	for i := range lod.levels {
+		if lod.levels[i].inode == inode {
+			lod.levels = append(lod.levels[:i], lod.levels[i+1:]...)
+			lod.Remove(inode)
+			lod.current = -1
+			return
+		}
+	}
+}
+
+// Levels returns the number of levels of detail.
+func (lod *LOD) Levels() int {
+
+	return len(lod.levels)
+}
+
+// SetMaxDistance sets the distance beyond which no level is shown. The default 0 shows the
+// last level at any distance.
+func (lod *LOD) SetMaxDistance(distance float32) {
+
+	lod.maxDist = distance
+}
+
+// MaxDistance returns the distance beyond which no level is shown.
+func (lod *LOD) MaxDistance() float32 {
+
+	return lod.maxDist
+}
+
+// Select shows the level of detail for the specified camera position in world coordinates
+// and hides the others. It is called by the renderer before culling the levels.
+func (lod *LOD) Select(camPos *math32.Vector3) {
+
+	var pos math32.Vector3
+	mw := lod.MatrixWorld()
+	pos.SetFromMatrixPosition(&mw)
+	dist := pos.DistanceTo(camPos)
+	lod.current = -1
+	if lod.maxDist <= 0 || dist < lod.maxDist {
+		for i := range lod.levels {
+			if dist >= lod.levels[i].distance {
+				lod.current = i
+			}
+		}
+	}
+	for i := range lod.levels {
+		lod.levels[i].inode.SetVisible(i == lod.current)
+	}
+}
+
+// Current returns the node of the level of detail selected by the last rendering or nil if none.
+func (lod *LOD) Current() core.INode {
+
+	if lod.current < 0 {
+		return nil
+	}
+	return lod.levels[lod.current].inode
+}
@@ -0,0 +1,74 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// Mirror is a rectangular planar mirror in the XY plane of its node, facing +Z,
+// showing the reflection of the scene rendered by its Reflector tinted with its color.
+type Mirror struct {
+	Graphic                      // Embedded graphic
+	Reflector                    // Embedded reflector
+	mat       *material.Material // Mirror material
+	udata     [28]float32        // ReflectorParams uniform data (7 vec4)
+	uniParams gls.Uniform        // ReflectorParams uniform location cache
+}
+
+// NewMirror creates and returns a pointer to a new white mirror with the specified size.
+func NewMirror(width, height float32) *Mirror {
+
+	m := new(Mirror)
+	m.Graphic.Init(m, geometry.NewPlane(width, height), gls.TRIANGLES)
+	m.mat = material.NewMaterial()
+	m.mat.SetShader("reflector")
+	m.mat.SetUseLights(material.UseLightNone)
+	m.Reflector.init(m, m.mat, false)
+	m.AddMaterial(m, m.mat, 0, 0)
+	m.SetColor(&math32.Color{1, 1, 1})
+	m.uniParams.Init("ReflectorParams")
+	return m
+}
+
+// Material returns the material of the mirror.
+func (m *Mirror) Material() *material.Material {
+
+	return m.mat
+}
+
+// SetColor sets the color of the mirror, which multiplies the reflection.
+func (m *Mirror) SetColor(color *math32.Color) {
+
+	m.udata[0], m.udata[1], m.udata[2], m.udata[3] = color.R, color.G, color.B, 1
+}
+
+// Color returns the color of the mirror.
+func (m *Mirror) Color() math32.Color {
+
+	return math32.Color{m.udata[0], m.udata[1], m.udata[2]}
+}
+
+// RenderSetup sets up the rendering of the mirror.
+func (m *Mirror) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+
+	m.Reflector.renderSetup(gs, rinfo)
+	m.udata[26] = 0
+	if m.reflecting {
+		m.udata[26] = 1
+	}
+	gs.Uniform4fv(m.uniParams.Location(gs), 7, &m.udata[0])
+}
+
+// Dispose releases the render target and the resources of the graphic.
+func (m *Mirror) Dispose() {
+
+	m.Reflector.dispose()
+	m.Graphic.Dispose()
+}
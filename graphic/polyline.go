@@ -0,0 +1,367 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// LineJoin specifies how the segments of a Polyline are joined.
+type LineJoin int
+
+// The joins of the segments of a Polyline.
+const (
+	LineJoinMiter = LineJoin(iota) // Sharp corners, beveled beyond the miter limit
+	LineJoinRound                  // Rounded corners
+	LineJoinBevel                  // Corners cut at half the width
+)
+
+// LineCap specifies how the ends of the strips of a Polyline are drawn.
+type LineCap int
+
+// The caps of the strips of a Polyline.
+const (
+	LineCapButt   = LineCap(iota) // Ends at the end points
+	LineCapRound                  // Half circles around the end points
+	LineCapSquare                 // Extended by half the width beyond the end points
+)
+
+// Polyline is a set of strips of connected line segments with any width, expanded into
+// antialiased quads in screen space by the vertex shader, with joins, caps, dash patterns and
+// per vertex colors. Unlike Lines and LineStrip it does not depend on the OpenGL line width.
+type Polyline struct {
+	Graphic                       // Embedded graphic
+	mat        *material.Material // Polyline material
+	vbo        *gls.VBO           // Vertex buffer of the quads
+	strips     []polylineStrip    // Strips of the polyline
+	width      float32            // Width of the lines in pixels or world units
+	worldUnits bool               // Width in world units instead of pixels
+	join       LineJoin           // Joins of the segments
+	lcap       LineCap            // Caps of the strips
+	udata      [24]float32        // LineStyle uniform data (6 vec4)
+	proj       math32.Matrix4     // Projection matrix of the last rendering
+	viewport   float32            // Height of the viewport in pixels of the last rendering
+	uniMVPM    gls.Uniform        // Model view projection matrix uniform location cache
+	uniStyle   gls.Uniform        // LineStyle uniform location cache
+}
+
+// polylineStrip is a strip of connected points of a Polyline.
+type polylineStrip struct {
+	points []math32.Vector3 // Points of the strip
+	colors []math32.Color4  // Colors of the points
+	closed bool             // Whether the last point is connected to the first one
+}
+
+// Kinds of the quads of a Polyline
+const (
+	polylineSegment = iota
+	polylineJoin
+	polylineCap
+)
+
+// NewPolyline creates and returns a pointer to a new empty white polyline
+// with the specified width in pixels, miter joins and butt caps.
+func NewPolyline(width float32) *Polyline {
+
+	l := new(Polyline)
+	l.width = width
+	l.udata[0], l.udata[1], l.udata[2], l.udata[3] = 1, 1, 1, 1
+	l.SetMiterLimit(4)
+
+	// Attributes have fixed locations in the shader and the center point of each quad is
+	// the vertex position so that the bounds of the geometry are the bounds of the points
+	geom := geometry.NewGeometry()
+	l.vbo = gls.NewVBO(math32.NewArrayF32(0, 0)).
+		AddAttrib(gls.VertexPosition).
+		AddCustomAttrib("LinePoint0", 3).
+		AddCustomAttrib("LinePoint2", 3).
+		AddCustomAttrib("LineParams", 4).
+		AddCustomAttrib("LineColor", 4)
+	geom.AddVBO(l.vbo)
+	geom.SetIndices(math32.NewArrayU32(0, 0))
+	l.Graphic.Init(l, geom, gls.TRIANGLES)
+
+	l.mat = material.NewMaterial()
+	l.mat.SetShader("polyline")
+	l.mat.SetUseLights(material.UseLightNone)
+	l.mat.SetSide(material.SideDouble)
+	l.mat.SetTransparent(true)
+	l.AddMaterial(l, l.mat, 0, 0)
+
+	l.uniMVPM.Init("MVP")
+	l.uniStyle.Init("LineStyle")
+	l.SetRenderable(false)
+	return l
+}
+
+// AddStrip adds a strip of line segments connecting the specified points, with optional
+// colors for each point, which are multiplied by the color of the polyline. If closed is true
+// the last point is also connected to the first one. Strips with less than two points are ignored.
+func (l *Polyline) AddStrip(points []math32.Vector3, colors []math32.Color4, closed bool) {
+
+	if colors != nil && len(colors) != len(points) {
+		panic("Invalid argument(s). The number of colors must be the number of points")
+	}
+	if len(points) < 2 {
+		return
+	}
+	strip := polylineStrip{append([]math32.Vector3(nil), points...), nil, closed}
+	if colors != nil {
+		strip.colors = append([]math32.Color4(nil), colors...)
+	}
+	l.strips = append(l.strips, strip)
+	l.update()
+}
+
+// Clear removes all the strips of the polyline.
+func (l *Polyline) Clear() {
+
+	l.strips = l.strips[:0]
+	l.update()
+}
+
+// Strips returns the number of strips of the polyline.
+func (l *Polyline) Strips() int {
+
+	return len(l.strips)
+}
+
+// Material returns the material of the polyline.
+func (l *Polyline) Material() *material.Material {
+
+	return l.mat
+}
+
+// SetWidth sets the width of the lines in pixels or, if worldUnits is true, in world units.
+// Lines with pixel widths have the same width at any distance.
+func (l *Polyline) SetWidth(width float32, worldUnits bool) {
+
+	l.width = width
+	l.worldUnits = worldUnits
+}
+
+// Width returns the width of the lines and whether it is in world units.
+func (l *Polyline) Width() (float32, bool) {
+
+	return l.width, l.worldUnits
+}
+
+// SetColor sets the color of the polyline, which multiplies the colors of the points.
+func (l *Polyline) SetColor(color *math32.Color4) {
+
+	l.udata[0], l.udata[1], l.udata[2], l.udata[3] = color.R, color.G, color.B, color.A
+}
+
+// Color returns the color of the polyline.
+func (l *Polyline) Color() math32.Color4 {
+
+	return math32.Color4{l.udata[0], l.udata[1], l.udata[2], l.udata[3]}
+}
+
+// SetJoin sets how the segments of the strips are joined.
+func (l *Polyline) SetJoin(join LineJoin) {
+
+	l.join = join
+}
+
+// Join returns how the segments of the strips are joined.
+func (l *Polyline) Join() LineJoin {
+
+	return l.join
+}
+
+// SetCap sets how the ends of the strips which are not closed are drawn.
+func (l *Polyline) SetCap(lcap LineCap) {
+
+	l.lcap = lcap
+}
+
+// Cap returns how the ends of the strips which are not closed are drawn.
+func (l *Polyline) Cap() LineCap {
+
+	return l.lcap
+}
+
+// SetMiterLimit sets the maximum ratio between the length of a miter join and half the
+// width of the lines, beyond which the join is beveled. The default is 4.
+func (l *Polyline) SetMiterLimit(limit float32) {
+
+	l.udata[8] = limit
+}
+
+// MiterLimit returns the maximum ratio between the length of a miter join and half the width of the lines.
+func (l *Polyline) MiterLimit() float32 {
+
+	return l.udata[8]
+}
+
+// SetDash sets the dash pattern of the lines as up to 8 alternating lengths of dashes and gaps
+// in model units along the strips, starting with a dash, and the offset of the pattern from the
+// start of the strips. An empty pattern draws solid lines.
+func (l *Polyline) SetDash(pattern []float32, offset float32) {
+
+	if len(pattern) > 8 {
+		panic("Invalid argument(s). The dash pattern can have at most 8 lengths")
+	}
+	var total float32
+	for i := 0; i < 8; i++ {
+		var length float32
+		if i < len(pattern) {
+			length = pattern[i]
+		}
+		l.udata[12+i] = length
+		total += length
+	}
+	l.udata[9] = total
+	l.udata[10] = offset
+}
+
+// Dash returns the dash pattern of the lines and its offset.
+func (l *Polyline) Dash() ([]float32, float32) {
+
+	var pattern []float32
+	for i := 0; i < 8 && l.udata[12+i] > 0; i++ {
+		pattern = append(pattern, l.udata[12+i])
+	}
+	return pattern, l.udata[10]
+}
+
+// WidthAt returns the width in world units of the lines at the specified point in world
+// coordinates, for the specified view matrix and the projection and viewport of the last
+// rendering of the polyline. It returns zero for pixel widths if it has not been rendered.
+func (l *Polyline) WidthAt(point *math32.Vector3, view *math32.Matrix4) float32 {
+
+	if l.worldUnits {
+		return l.width
+	}
+	if l.viewport == 0 || l.proj[5] == 0 {
+		return 0
+	}
+	p := *point
+	p.ApplyMatrix4(view)
+	w := l.proj[3]*p.X + l.proj[7]*p.Y + l.proj[11]*p.Z + l.proj[15]
+	return l.width * w / (l.proj[5] * l.viewport / 2)
+}
+
+// VisitSegments calls the specified function with the end points in model coordinates
+// of each segment of the strips, in order, with the index of its first point counting
+// the points of all the strips, until the function returns true.
+func (l *Polyline) VisitSegments(cb func(index int, a, b *math32.Vector3) bool) {
+
+	base := 0
+	for _, s := range l.strips {
+		n := len(s.points)
+		last := n - 1
+		if s.closed {
+			last = n
+		}
+		for i := 0; i < last; i++ {
+			if cb(base+i, &s.points[i], &s.points[(i+1)%n]) {
+				return
+			}
+		}
+		base += n
+	}
+}
+
+// update rebuilds the quads of the segments, joins and caps of the strips.
+func (l *Polyline) update() {
+
+	buf := (*l.vbo.Buffer())[:0]
+	indices := l.GetGeometry().Indices()[:0]
+	white := math32.Color4{1, 1, 1, 1}
+	nquads := 0
+	quad := func(kind int, p0, p1, p2 *math32.Vector3, color0, color1 *math32.Color4, dist0, dist1 float32) {
+		for c := 0; c < 4; c++ {
+			// Segments have their start point in corners 0 and 3 on sides -1 and +1
+			corner, side, color, dist := float32(c), float32(0), color1, dist1
+			if kind == polylineSegment {
+				corner, side = 1, 1
+				if c == 0 || c == 3 {
+					corner, color, dist = 0, color0, dist0
+				}
+				if c < 2 {
+					side = -1
+				}
+			}
+			buf.Append(
+				p1.X, p1.Y, p1.Z,
+				p0.X, p0.Y, p0.Z,
+				p2.X, p2.Y, p2.Z,
+				float32(kind), corner, side, dist,
+				color.R, color.G, color.B, color.A,
+			)
+		}
+		v := uint32(4 * nquads)
+		indices = append(indices, v, v+1, v+2, v, v+2, v+3)
+		nquads++
+	}
+
+	for _, s := range l.strips {
+		n := len(s.points)
+		color := func(i int) *math32.Color4 {
+			if s.colors == nil {
+				return &white
+			}
+			return &s.colors[i%n]
+		}
+
+		// Segments with their distances along the strip
+		nseg := n - 1
+		if s.closed {
+			nseg = n
+		}
+		dists := make([]float32, nseg+1)
+		for i := 0; i < nseg; i++ {
+			a, b := &s.points[i], &s.points[(i+1)%n]
+			dists[i+1] = dists[i] + a.DistanceTo(b)
+			quad(polylineSegment, a, b, b, color(i), color(i+1), dists[i], dists[i+1])
+		}
+
+		// Joins at the inner points and at the first point of closed strips
+		for i := 1; i < nseg; i++ {
+			quad(polylineJoin, &s.points[i-1], &s.points[i], &s.points[(i+1)%n], nil, color(i), 0, dists[i])
+		}
+		if s.closed {
+			quad(polylineJoin, &s.points[n-1], &s.points[0], &s.points[1], nil, color(0), 0, 0)
+			continue
+		}
+
+		// Caps at both ends
+		quad(polylineCap, &s.points[1], &s.points[0], &s.points[0], nil, color(0), 0, 0)
+		quad(polylineCap, &s.points[n-2], &s.points[n-1], &s.points[n-1], nil, color(n-1), 0, dists[nseg])
+	}
+	l.vbo.SetBuffer(buf)
+	l.GetGeometry().SetIndices(indices)
+	l.SetRenderable(nquads > 0)
+}
+
+// RenderSetup sets up the rendering of the polyline.
+func (l *Polyline) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+
+	mw := l.MatrixWorld()
+	var mvpm math32.Matrix4
+	mvpm.MultiplyMatrices(&rinfo.ViewMatrix, &mw)
+	mvpm.MultiplyMatrices(&rinfo.ProjMatrix, &mvpm)
+	gs.UniformMatrix4fv(l.uniMVPM.Location(gs), 1, false, &mvpm[0])
+
+	// World unit widths are converted to pixels in the shader dividing by the clip W
+	_, _, width, height := gs.GetViewport()
+	l.proj = rinfo.ProjMatrix
+	l.viewport = float32(height)
+	l.udata[4] = l.width
+	l.udata[5] = 0
+	if l.worldUnits {
+		l.udata[5] = l.proj[5] * l.viewport / 2
+	}
+	l.udata[6] = float32(l.join)
+	l.udata[7] = float32(l.lcap)
+	l.udata[20], l.udata[21] = float32(width), float32(height)
+	gs.Uniform4fv(l.uniStyle.Location(gs), 6, &l.udata[0])
+}
@@ -0,0 +1,233 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// IReflector is the interface for the graphics with a planar Reflector,
+// whose passes are rendered by the renderer before the scene.
+type IReflector interface {
+	IGraphic
+	GetReflector() *Reflector
+}
+
+// Reflector renders the reflection of the scene on a plane, and optionally the scene
+// seen through it, into render targets used by the material of a graphic such as
+// Water and Mirror. The plane is the XY plane of the graphic with its normal along +Z,
+// and only reflects when the camera is on the side of the normal.
+type Reflector struct {
+	igraphic   IGraphic              // Graphic of the reflecting surface
+	reflection *texture.RenderTarget // Reflection of the scene
+	refraction *texture.RenderTarget // Optional scene seen through the plane, with depth
+	resolution float32               // Size of the render targets relative to the viewport
+	clipBias   float32               // Offset of the clipping plane of the reflection
+	reflecting bool                  // Whether the reflection was rendered in the last passes
+	texMatrix  math32.Matrix4        // World to reflection texture coordinates transform
+	projMatrix math32.Matrix4        // Projection matrix of the refraction
+	passes     []ReflectorPass       // Passes of the last frame
+	uniMVPM    gls.Uniform           // Model view projection matrix uniform location cache
+	uniMM      gls.Uniform           // Model matrix uniform location cache
+	uniTexM    gls.Uniform           // Reflection texture matrix uniform location cache
+}
+
+// ReflectorPass is a rendering of the scene into a render target of a Reflector
+// with the specified view and projection matrices.
+type ReflectorPass struct {
+	Target     *texture.RenderTarget // Render target
+	ViewMatrix math32.Matrix4        // View matrix
+	ProjMatrix math32.Matrix4        // Projection matrix
+}
+
+// init initializes the reflector of the specified graphic, adding its textures
+// to the specified material, with a refraction target if refraction is true.
+func (r *Reflector) init(igr IGraphic, mat *material.Material, refraction bool) {
+
+	r.igraphic = igr
+	r.resolution = 0.5
+	r.reflection = texture.NewRenderTarget(1, 1, false)
+	r.reflection.Texture().SetUniformNames("uReflectionSampler", "uReflectionTexParams")
+	mat.AddTexture(r.reflection.Texture().Incref())
+	if refraction {
+		r.refraction = texture.NewRenderTarget(1, 1, true)
+		r.refraction.Texture().SetUniformNames("uRefractionSampler", "uRefractionTexParams")
+		r.refraction.DepthTexture().SetUniformNames("uRefractionDepthSampler", "uRefractionDepthTexParams")
+		mat.AddTexture(r.refraction.Texture().Incref())
+		mat.AddTexture(r.refraction.DepthTexture().Incref())
+	}
+	r.uniMVPM.Init("MVP")
+	r.uniMM.Init("ModelMatrix")
+	r.uniTexM.Init("ReflTexMatrix")
+}
+
+// GetReflector returns a pointer to this reflector.
+// It is used to satisfy the IReflector interface.
+func (r *Reflector) GetReflector() *Reflector {
+
+	return r
+}
+
+// ReflectionTarget returns the render target of the reflection.
+func (r *Reflector) ReflectionTarget() *texture.RenderTarget {
+
+	return r.reflection
+}
+
+// RefractionTarget returns the render target of the scene seen through the plane or nil if none.
+func (r *Reflector) RefractionTarget() *texture.RenderTarget {
+
+	return r.refraction
+}
+
+// SetResolution sets the size of the render targets relative to the viewport.
+// The default is 0.5.
+func (r *Reflector) SetResolution(scale float32) {
+
+	r.resolution = scale
+}
+
+// Resolution returns the size of the render targets relative to the viewport.
+func (r *Reflector) Resolution() float32 {
+
+	return r.resolution
+}
+
+// SetClipBias sets the offset of the plane clipping the reflected scene,
+// which can hide artifacts of the surfaces crossing the plane.
+func (r *Reflector) SetClipBias(bias float32) {
+
+	r.clipBias = bias
+}
+
+// ClipBias returns the offset of the plane clipping the reflected scene.
+func (r *Reflector) ClipBias() float32 {
+
+	return r.clipBias
+}
+
+// Passes returns the passes needed for the camera with the specified view and projection
+// matrices and the viewport with the specified size in pixels, resizing the render targets.
+// The reflection pass mirrors the camera on the plane and clips the scene behind it.
+func (r *Reflector) Passes(view, proj *math32.Matrix4, width, height int) []ReflectorPass {
+
+	r.passes = r.passes[:0]
+	r.reflecting = false
+	r.projMatrix = *proj
+	w := int(math32.Max(float32(width)*r.resolution, 1))
+	h := int(math32.Max(float32(height)*r.resolution, 1))
+
+	if r.refraction != nil {
+		r.refraction.SetSize(w, h)
+		r.passes = append(r.passes, ReflectorPass{r.refraction, *view, *proj})
+	}
+
+	// Plane and camera in world coordinates
+	mw := r.igraphic.GetGraphic().MatrixWorld()
+	var rot, camWorld math32.Matrix4
+	var pos, normal, camPos math32.Vector3
+	pos.SetFromMatrixPosition(&mw)
+	rot.ExtractRotation(&mw)
+	normal.Set(0, 0, 1).ApplyMatrix4(&rot).Normalize()
+	camWorld.GetInverse(view)
+	camPos.SetFromMatrixPosition(&camWorld)
+	var toCam math32.Vector3
+	if toCam.SubVectors(&camPos, &pos).Dot(&normal) <= 0 {
+		return r.passes
+	}
+
+	// Mirrored camera looking at the reflection of the camera target, with the reflected up
+	// direction, which keeps it a rotation so that the faces keep their winding
+	reflect := func(p *math32.Vector3) {
+		var d math32.Vector3
+		d.SubVectors(p, &pos)
+		p.Sub(normal.Clone().MultiplyScalar(2 * d.Dot(&normal)))
+	}
+	var target, up math32.Vector3
+	mirrorPos := camPos
+	reflect(&mirrorPos)
+	target.SetFromMatrixColumn(2, &camWorld).Negate().Add(&camPos)
+	reflect(&target)
+	up.SetFromMatrixColumn(1, &camWorld).Reflect(&normal)
+	var mirrorWorld math32.Matrix4
+	mirrorWorld.Identity()
+	mirrorWorld.LookAt(&mirrorPos, &target, &up)
+	mirrorWorld.SetPosition(&mirrorPos)
+	pass := ReflectorPass{Target: r.reflection, ProjMatrix: *proj}
+	pass.ViewMatrix.GetInverse(&mirrorWorld)
+
+	// Texture coordinates from the clip coordinates of the mirrored camera
+	r.texMatrix.Set(
+		0.5, 0, 0, 0.5,
+		0, 0.5, 0, 0.5,
+		0, 0, 0.5, 0.5,
+		0, 0, 0, 1,
+	)
+	r.texMatrix.Multiply(&pass.ProjMatrix).Multiply(&pass.ViewMatrix)
+
+	// Oblique near plane on the reflecting plane (Lengyel), changing only the depth
+	var nv, pv math32.Vector3
+	var viewRot math32.Matrix4
+	viewRot.ExtractRotation(&pass.ViewMatrix)
+	nv.Copy(&normal).ApplyMatrix4(&viewRot)
+	pv.Copy(&pos).ApplyMatrix4(&pass.ViewMatrix)
+	clip := math32.Vector4{nv.X, nv.Y, nv.Z, -nv.Dot(&pv)}
+	p := &pass.ProjMatrix
+	q := math32.Vector4{(sign(clip.X) + p[8]) / p[0], (sign(clip.Y) + p[9]) / p[5], -1, (1 + p[10]) / p[14]}
+	clip.MultiplyScalar(2 / clip.Dot(&q))
+	p[2] = clip.X
+	p[6] = clip.Y
+	p[10] = clip.Z + 1 - r.clipBias
+	p[14] = clip.W
+
+	r.reflection.SetSize(w, h)
+	r.passes = append(r.passes, pass)
+	r.reflecting = true
+	return r.passes
+}
+
+// Reflecting returns whether the reflection was rendered in the last passes,
+// which is not the case when the camera is behind the plane.
+func (r *Reflector) Reflecting() bool {
+
+	return r.reflecting
+}
+
+// renderSetup sends the transform uniforms of the reflector's graphic.
+func (r *Reflector) renderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+
+	gr := r.igraphic.GetGraphic()
+	mw := gr.MatrixWorld()
+	var texm math32.Matrix4
+	texm.MultiplyMatrices(&r.texMatrix, &mw)
+	gs.UniformMatrix4fv(r.uniMVPM.Location(gs), 1, false, &gr.ModelViewProjectionMatrix()[0])
+	gs.UniformMatrix4fv(r.uniMM.Location(gs), 1, false, &mw[0])
+	gs.UniformMatrix4fv(r.uniTexM.Location(gs), 1, false, &texm[0])
+}
+
+// dispose releases the render targets of the reflector.
+func (r *Reflector) dispose() {
+
+	r.reflection.Dispose()
+	if r.refraction != nil {
+		r.refraction.Dispose()
+	}
+}
+
+// sign returns -1, 0 or 1 according to the sign of x.
+func sign(x float32) float32 {
+
+	if x > 0 {
+		return 1
+	}
+	if x < 0 {
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,160 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// ScreenDecal is a texture projected along the -Z axis onto the surfaces inside the unit
+// box of its node, centered at the node origin and sized with the node scale, which are
+// found in screen space from the depth buffer of the scene rendered before it.
+// Unlike Decal it needs no target geometry and follows the surfaces as they change,
+// but it copies the depth buffer on each rendering, which needs a perspective or
+// orthographic camera and is not supported by WebGL or multisampled framebuffers.
+type ScreenDecal struct {
+	Graphic                        // Embedded graphic
+	mat         *material.Material // Decal material
+	maxAngle    float32            // Maximum angle between the surfaces and the projection direction
+	udata       [12]float32        // DecalParams uniform data (3 vec4)
+	uniMVPM     gls.Uniform        // Model view projection matrix uniform location cache
+	uniInvProj  gls.Uniform        // Inverse projection matrix uniform location cache
+	uniInvMV    gls.Uniform        // Inverse model view matrix uniform location cache
+	uniParams   gls.Uniform        // DecalParams uniform location cache
+	uniDepth    gls.Uniform        // uDepthSampler uniform location cache
+	gs          *gls.GLS           // OpenGL state of the depth texture
+	depthTex    uint32             // Copy of the depth buffer
+	depthWidth  int32              // Width of the depth texture
+	depthHeight int32              // Height of the depth texture
+}
+
+// NewScreenDecal creates and returns a pointer to a new screen space decal projecting
+// the specified texture onto the surfaces inside the unit box of its node.
+func NewScreenDecal(tex *texture.Texture2D) *ScreenDecal {
+
+	d := new(ScreenDecal)
+	d.maxAngle = math32.Pi / 2
+	d.udata[0], d.udata[1], d.udata[2], d.udata[3] = 1, 1, 1, 1
+	d.Graphic.Init(d, geometry.NewCube(1), gls.TRIANGLES)
+
+	// Draws the back faces of the box without depth test so the decal
+	// is drawn once also when the camera is inside it
+	tex.SetUniformNames("uDecalSampler", "uDecalTexParams")
+	d.mat = material.NewMaterial()
+	d.mat.SetShader("decal")
+	d.mat.SetUseLights(material.UseLightNone)
+	d.mat.SetSide(material.SideBack)
+	d.mat.SetTransparent(true)
+	d.mat.SetDepthTest(false)
+	d.mat.SetDepthMask(false)
+	d.mat.AddTexture(tex)
+	d.AddMaterial(d, d.mat, 0, 0)
+
+	d.uniMVPM.Init("MVP")
+	d.uniInvProj.Init("DecalInvProj")
+	d.uniInvMV.Init("DecalInvModelView")
+	d.uniParams.Init("DecalParams")
+	d.uniDepth.Init("uDepthSampler")
+	return d
+}
+
+// Material returns the material of the decal.
+func (d *ScreenDecal) Material() *material.Material {
+
+	return d.mat
+}
+
+// SetColor sets the color of the decal, which multiplies the texture.
+func (d *ScreenDecal) SetColor(color *math32.Color4) {
+
+	d.udata[0], d.udata[1], d.udata[2], d.udata[3] = color.R, color.G, color.B, color.A
+}
+
+// Color returns the color of the decal.
+func (d *ScreenDecal) Color() math32.Color4 {
+
+	return math32.Color4{d.udata[0], d.udata[1], d.udata[2], d.udata[3]}
+}
+
+// SetMaxAngle sets the maximum angle in radians between the normal of the surfaces
+// and the direction opposite to the projection for them to receive the decal.
+// The default is Pi/2, which excludes the surfaces facing away from the projection.
+func (d *ScreenDecal) SetMaxAngle(angle float32) {
+
+	d.maxAngle = angle
+}
+
+// MaxAngle returns the maximum angle between the normal of the surfaces and the
+// direction opposite to the projection.
+func (d *ScreenDecal) MaxAngle() float32 {
+
+	return d.maxAngle
+}
+
+// RenderSetup sets up the rendering of the decal.
+func (d *ScreenDecal) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+
+	mw := d.MatrixWorld()
+	var mvm, mvpm, invProj, invMV math32.Matrix4
+	mvm.MultiplyMatrices(&rinfo.ViewMatrix, &mw)
+	mvpm.MultiplyMatrices(&rinfo.ProjMatrix, &mvm)
+	invProj.GetInverse(&rinfo.ProjMatrix)
+	invMV.GetInverse(&mvm)
+	gs.UniformMatrix4fv(d.uniMVPM.Location(gs), 1, false, &mvpm[0])
+	gs.UniformMatrix4fv(d.uniInvProj.Location(gs), 1, false, &invProj[0])
+	gs.UniformMatrix4fv(d.uniInvMV.Location(gs), 1, false, &invMV[0])
+
+	// Projection axis in view coordinates
+	axis := math32.Vector3{mvm[8], mvm[9], mvm[10]}
+	axis.Normalize()
+	d.udata[8], d.udata[9], d.udata[10] = axis.X, axis.Y, axis.Z
+	d.udata[11] = math32.Cos(d.maxAngle)
+	if math32.Abs(d.udata[11]) < 1e-6 {
+		d.udata[11] = 0
+	}
+	d.captureDepth(gs)
+}
+
+// captureDepth copies the depth buffer of the viewport to the depth texture
+// in the texture unit following the ones of the material and sends the uniforms.
+func (d *ScreenDecal) captureDepth(gs *gls.GLS) {
+
+	unit := d.mat.TextureCount()
+	gs.ActiveTexture(gls.TEXTURE0 + uint32(unit))
+	if d.gs == nil {
+		d.gs = gs
+		d.depthTex = gs.GenTexture()
+	}
+	gs.BindTexture(gls.TEXTURE_2D, d.depthTex)
+	x, y, width, height := gs.GetViewport()
+	if width != d.depthWidth || height != d.depthHeight {
+		gs.TexImage2D(gls.TEXTURE_2D, 0, gls.DEPTH_COMPONENT24, width, height, gls.DEPTH_COMPONENT, gls.UNSIGNED_INT, nil)
+		gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_MIN_FILTER, gls.NEAREST)
+		gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_MAG_FILTER, gls.NEAREST)
+		gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_WRAP_S, gls.CLAMP_TO_EDGE)
+		gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_WRAP_T, gls.CLAMP_TO_EDGE)
+		d.depthWidth, d.depthHeight = width, height
+	}
+	gs.CopyTexSubImage2D(gls.TEXTURE_2D, 0, 0, 0, x, y, width, height)
+	gs.Uniform1i(d.uniDepth.Location(gs), int32(unit))
+	d.udata[4], d.udata[5] = float32(x), float32(y)
+	d.udata[6], d.udata[7] = float32(width), float32(height)
+	gs.Uniform4fv(d.uniParams.Location(gs), 3, &d.udata[0])
+}
+
+// Dispose releases the depth texture and the resources of the graphic.
+func (d *ScreenDecal) Dispose() {
+
+	if d.gs != nil {
+		d.gs.DeleteTextures(d.depthTex)
+		d.gs = nil
+	}
+	d.Graphic.Dispose()
+}
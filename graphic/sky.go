@@ -0,0 +1,440 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// Sky is a procedural sky using the Preetham atmospheric scattering model, with the sun
+// positioned from the time of day, the day of the year and the latitude, or set directly.
+// It can drive the direction, color and intensity of a directional light, blend its horizon
+// with a fog color and show stars at night and a layer of moving clouds.
+// The world Y axis points up, the X axis east and the -Z axis north.
+// The sky is drawn behind everything else around the camera, independently of the transform of its node.
+type Sky struct {
+	Graphic                           // Embedded graphic
+	mat            *material.Material // Sky material
+	sunDir         math32.Vector3     // Direction to the sun
+	latitude       float32            // Latitude in degrees
+	day            int                // Day of the year
+	hours          float32            // Local solar time in hours
+	timeSpeed      float32            // Hours advanced per second by Update
+	turbidity      float32            // Atmospheric turbidity
+	rayleigh       float32            // Rayleigh scattering coefficient
+	mieCoefficient float32            // Mie scattering coefficient
+	mieG           float32            // Mie scattering directionality
+	luminance      float32            // Luminance of the tone mapping
+	sunE           float32            // Sun intensity from its zenith angle
+	sunFade        float32            // Fade of the sun below the horizon
+	betaR          [3]float32         // Rayleigh scattering
+	betaM          [3]float32         // Mie scattering
+	wind           math32.Vector2     // Velocity of the clouds in cloud layer units per second
+	sun            *light.Directional // Optional light driven by the sun
+	sunIntensity   float32            // Intensity of the light with the sun at the zenith
+	udata          [28]float32        // SkyParams uniform data (7 vec4)
+	uniMVPm        gls.Uniform        // Model view projection matrix uniform location cache
+	uniParams      gls.Uniform        // SkyParams uniform location cache
+}
+
+// Constants of the Preetham sky model
+const (
+	skySunE           = 1000
+	skyCutoffAngle    = 1.6110731556870734
+	skySteepness      = 1.5
+	skyRayleighZenith = 8.4e3
+	skyMieZenith      = 1.25e3
+	skySunDiskCos     = 0.99995667694644844
+)
+
+var skyTotalRayleigh = [3]float32{5.804542996261093e-6, 1.3562911419845635e-5, 3.0265902468824876e-5}
+var skyMieConst = [3]float32{1.8399918514433978e14, 2.7798023919660528e14, 4.0790479543861094e14}
+
+// NewSky creates and returns a pointer to a new sky at noon on the spring equinox
+// at a latitude of 45 degrees, without stars, clouds or fog.
+func NewSky() *Sky {
+
+	s := new(Sky)
+	s.Graphic.Init(s, geometry.NewCube(1), gls.TRIANGLES)
+	s.SetCullable(false)
+
+	// Drawn on the far plane without writing the depth so that it stays behind
+	// every opaque object whatever the order in which they are drawn
+	s.mat = material.NewMaterial()
+	s.mat.SetShader("sky")
+	s.mat.SetUseLights(material.UseLightNone)
+	s.mat.SetSide(material.SideDouble)
+	s.mat.SetDepthFunc(gls.LEQUAL)
+	s.mat.SetDepthMask(false)
+	s.AddMaterial(s, s.mat, 0, 0)
+	s.SetRenderOrder(100)
+
+	s.turbidity = 10
+	s.rayleigh = 2
+	s.mieCoefficient = 0.005
+	s.mieG = 0.8
+	s.luminance = 1
+	s.latitude = 45
+	s.day = 80
+	s.hours = 12
+	s.udata[18] = 0.5
+	s.uniMVPm.Init("MVP")
+	s.uniParams.Init("SkyParams")
+	s.updateSun()
+	return s
+}
+
+// Material returns the material of the sky.
+func (s *Sky) Material() *material.Material {
+
+	return s.mat
+}
+
+// SetTimeOfDay sets the local solar time in hours, from 0 to 24, which positions the sun.
+func (s *Sky) SetTimeOfDay(hours float32) {
+
+	s.hours = math32.Mod(hours, 24)
+	if s.hours < 0 {
+		s.hours += 24
+	}
+	s.updateSun()
+}
+
+// TimeOfDay returns the local solar time in hours.
+func (s *Sky) TimeOfDay() float32 {
+
+	return s.hours
+}
+
+// SetDayOfYear sets the day of the year, from 1 to 365, which changes the declination of the sun.
+func (s *Sky) SetDayOfYear(day int) {
+
+	s.day = day
+	s.updateSun()
+}
+
+// DayOfYear returns the day of the year.
+func (s *Sky) DayOfYear() int {
+
+	return s.day
+}
+
+// SetLatitude sets the latitude in degrees, positive in the northern hemisphere.
+func (s *Sky) SetLatitude(degrees float32) {
+
+	s.latitude = degrees
+	s.updateSun()
+}
+
+// Latitude returns the latitude in degrees.
+func (s *Sky) Latitude() float32 {
+
+	return s.latitude
+}
+
+// SetTimeSpeed sets the hours advanced per second of the time of day by Update.
+// The default is 0, which keeps the sun still.
+func (s *Sky) SetTimeSpeed(hoursPerSecond float32) {
+
+	s.timeSpeed = hoursPerSecond
+}
+
+// TimeSpeed returns the hours advanced per second of the time of day by Update.
+func (s *Sky) TimeSpeed() float32 {
+
+	return s.timeSpeed
+}
+
+// SetSunDirection sets the direction to the sun in world coordinates, overriding the
+// position from the time of day until it is changed again.
+func (s *Sky) SetSunDirection(dir *math32.Vector3) {
+
+	s.sunDir = *dir
+	s.sunDir.Normalize()
+	s.updateScattering()
+}
+
+// SunDirection returns the direction to the sun in world coordinates.
+func (s *Sky) SunDirection() math32.Vector3 {
+
+	return s.sunDir
+}
+
+// SetTurbidity sets the turbidity of the atmosphere, from 1 for a clear sky to about 20 for haze.
+func (s *Sky) SetTurbidity(turbidity float32) {
+
+	s.turbidity = turbidity
+	s.updateScattering()
+}
+
+// Turbidity returns the turbidity of the atmosphere.
+func (s *Sky) Turbidity() float32 {
+
+	return s.turbidity
+}
+
+// SetRayleigh sets the Rayleigh scattering coefficient, which makes the sky bluer and redder at sunset.
+func (s *Sky) SetRayleigh(rayleigh float32) {
+
+	s.rayleigh = rayleigh
+	s.updateScattering()
+}
+
+// Rayleigh returns the Rayleigh scattering coefficient.
+func (s *Sky) Rayleigh() float32 {
+
+	return s.rayleigh
+}
+
+// SetMie sets the Mie scattering coefficient and its directionality, from 0 to 1,
+// which control the glow around the sun.
+func (s *Sky) SetMie(coefficient, g float32) {
+
+	s.mieCoefficient = coefficient
+	s.mieG = g
+	s.updateScattering()
+}
+
+// Mie returns the Mie scattering coefficient and its directionality.
+func (s *Sky) Mie() (float32, float32) {
+
+	return s.mieCoefficient, s.mieG
+}
+
+// SetLuminance sets the luminance of the tone mapping, where lower values brighten the sky.
+func (s *Sky) SetLuminance(luminance float32) {
+
+	s.luminance = luminance
+	s.updateScattering()
+}
+
+// Luminance returns the luminance of the tone mapping.
+func (s *Sky) Luminance() float32 {
+
+	return s.luminance
+}
+
+// SetLight sets the directional light driven by the sun, or none if nil. Its direction
+// follows the sun and its color and intensity the sunlight through the atmosphere,
+// with the specified intensity when the sun is at the zenith.
+func (s *Sky) SetLight(l *light.Directional, intensity float32) {
+
+	s.sun = l
+	s.sunIntensity = intensity
+	s.updateLight()
+}
+
+// Light returns the directional light driven by the sun or nil if none.
+func (s *Sky) Light() *light.Directional {
+
+	return s.sun
+}
+
+// SetStars sets the brightness of the stars shown when the sun is below the horizon.
+// The default is 0, which disables them.
+func (s *Sky) SetStars(brightness float32) {
+
+	s.udata[16] = brightness
+}
+
+// Stars returns the brightness of the stars.
+func (s *Sky) Stars() float32 {
+
+	return s.udata[16]
+}
+
+// SetClouds sets the fraction of the sky covered by clouds, from 0 to 1, the size of the
+// cloud pattern and the velocity of the clouds moved by Update. A coverage of 0 disables them.
+func (s *Sky) SetClouds(coverage, size float32, wind *math32.Vector2) {
+
+	s.udata[17] = coverage
+	s.udata[18] = 1 / size
+	s.wind = *wind
+}
+
+// Clouds returns the coverage, size and velocity of the clouds.
+func (s *Sky) Clouds() (float32, float32, math32.Vector2) {
+
+	return s.udata[17], 1 / s.udata[18], s.wind
+}
+
+// SetFog sets the color toward which the sky is blended from the horizon up to the
+// specified elevation in radians when the scene has no fog, in which case the sky is
+// blended with the scene fog. An elevation of 0 disables the blending.
+// HorizonColor returns a color of the sky which can be used for the scene fog.
+func (s *Sky) SetFog(color *math32.Color, elevation float32) {
+
+	s.udata[12], s.udata[13], s.udata[14] = color.R, color.G, color.B
+	s.udata[15] = math32.Sin(elevation)
+}
+
+// Fog returns the color toward which the sky is blended and the elevation of the blending.
+func (s *Sky) Fog() (math32.Color, float32) {
+
+	return math32.Color{s.udata[12], s.udata[13], s.udata[14]}, math32.Asin(s.udata[15])
+}
+
+// Update advances the time of day and moves the clouds by the specified interval in seconds.
+func (s *Sky) Update(dt float32) {
+
+	if s.timeSpeed != 0 {
+		s.SetTimeOfDay(s.hours + s.timeSpeed*dt)
+	}
+	s.udata[24] += s.wind.X * dt
+	s.udata[25] += s.wind.Y * dt
+}
+
+// updateSun positions the sun from the time of day, the day of the year and the latitude.
+func (s *Sky) updateSun() {
+
+	lat := math32.DegToRad(s.latitude)
+	decl := math32.DegToRad(-23.44) * math32.Cos(2*math32.Pi*float32(s.day+10)/365)
+	hour := math32.DegToRad(15 * (s.hours - 12))
+	east := -math32.Cos(decl) * math32.Sin(hour)
+	north := math32.Cos(lat)*math32.Sin(decl) - math32.Sin(lat)*math32.Cos(decl)*math32.Cos(hour)
+	up := math32.Sin(lat)*math32.Sin(decl) + math32.Cos(lat)*math32.Cos(decl)*math32.Cos(hour)
+	s.sunDir.Set(east, up, -north).Normalize()
+	s.updateScattering()
+}
+
+// updateScattering updates the scattering coefficients from the sun direction and the
+// atmosphere parameters, the uniform data and the driven light.
+func (s *Sky) updateScattering() {
+
+	s.sunE = skySunIntensity(s.sunDir.Y)
+	s.sunFade = 1 - math32.Clamp(1-math32.Exp(s.sunDir.Y), 0, 1)
+	rayleigh := s.rayleigh - (1 - s.sunFade)
+	mie := 0.434 * 0.2 * s.turbidity * 10e-18 * s.mieCoefficient
+	for c := 0; c < 3; c++ {
+		s.betaR[c] = skyTotalRayleigh[c] * rayleigh
+		s.betaM[c] = skyMieConst[c] * mie
+	}
+	s.udata[0], s.udata[1], s.udata[2], s.udata[3] = s.sunDir.X, s.sunDir.Y, s.sunDir.Z, s.sunE
+	s.udata[4], s.udata[5], s.udata[6], s.udata[7] = s.betaR[0], s.betaR[1], s.betaR[2], s.sunFade
+	s.udata[8], s.udata[9], s.udata[10], s.udata[11] = s.betaM[0], s.betaM[1], s.betaM[2], s.mieG
+	s.udata[19] = s.luminance
+	color, intensity := s.Sunlight()
+	s.udata[20], s.udata[21], s.udata[22] = color.R*intensity, color.G*intensity, color.B*intensity
+	s.updateLight()
+}
+
+// updateLight updates the direction, color and intensity of the driven light.
+func (s *Sky) updateLight() {
+
+	if s.sun == nil {
+		return
+	}
+	color, intensity := s.Sunlight()
+	s.sun.SetPositionVec(&s.sunDir)
+	s.sun.SetColor(&color)
+	s.sun.SetIntensity(intensity * s.sunIntensity)
+}
+
+// Sunlight returns the color of the sunlight through the atmosphere, normalized to a
+// maximum component of 1, and its intensity relative to the sun at the zenith.
+func (s *Sky) Sunlight() (math32.Color, float32) {
+
+	var fex [3]float32
+	s.extinction(math32.Max(s.sunDir.Y, 0), &fex)
+	max := math32.Max(fex[0], math32.Max(fex[1], fex[2]))
+	if max <= 0 {
+		return math32.Color{1, 1, 1}, 0
+	}
+	return math32.Color{fex[0] / max, fex[1] / max, fex[2] / max}, s.sunE / skySunIntensity(1)
+}
+
+// skySunIntensity returns the intensity of the sun with the specified cosine of its zenith angle.
+func skySunIntensity(cosZenith float32) float32 {
+
+	return skySunE * math32.Max(0, 1-math32.Exp(-(skyCutoffAngle-math32.Acos(math32.Clamp(cosZenith, -1, 1)))/skySteepness))
+}
+
+// extinction sets fex to the extinction of the light through the atmosphere in the
+// direction with the specified cosine of the zenith angle.
+func (s *Sky) extinction(cosZenith float32, fex *[3]float32) {
+
+	zenith := math32.Acos(math32.Max(0, cosZenith))
+	inv := 1 / (math32.Cos(zenith) + 0.15*math32.Pow(93.885-zenith*180/math32.Pi, -1.253))
+	for c := 0; c < 3; c++ {
+		fex[c] = math32.Exp(-(s.betaR[c]*skyRayleighZenith*inv + s.betaM[c]*skyMieZenith*inv))
+	}
+}
+
+// Color returns the color of the sky in the specified world direction,
+// without the stars, clouds and fog. It matches the color computed by the shader.
+func (s *Sky) Color(dir *math32.Vector3) math32.Color {
+
+	d := *dir
+	d.Normalize()
+	var fex [3]float32
+	s.extinction(d.Y, &fex)
+	cosTheta := d.Dot(&s.sunDir)
+	rPhase := 3 / (16 * math32.Pi) * (1 + math32.Pow(cosTheta*0.5+0.5, 2))
+	g2 := s.mieG * s.mieG
+	mPhase := 1 / (4 * math32.Pi) * (1 - g2) / math32.Pow(1-2*s.mieG*cosTheta+g2, 1.5)
+	sunset := math32.Clamp(math32.Pow(1-s.sunDir.Y, 5), 0, 1)
+	disk := skySmoothstep(skySunDiskCos, skySunDiskCos+0.00002, cosTheta)
+	exposure := math32.Log2(2 / math32.Pow(s.luminance, 4))
+	whiteScale := 1 / skyTonemap(1000)
+	gamma := 1 / (1.2 + 1.2*s.sunFade)
+	ambient := [3]float32{0, 0.0003, 0.00075}
+	var rgb [3]float32
+	for c := 0; c < 3; c++ {
+		ratio := (s.betaR[c]*rPhase + s.betaM[c]*mPhase) / (s.betaR[c] + s.betaM[c])
+		lin := math32.Pow(s.sunE*ratio*(1-fex[c]), 1.5)
+		lin *= 1 + (math32.Pow(s.sunE*ratio*fex[c], 0.5)-1)*sunset
+		l0 := 0.1*fex[c] + s.sunE*19000*fex[c]*disk
+		v := (lin+l0)*0.04 + ambient[c]
+		rgb[c] = math32.Pow(skyTonemap(exposure*v)*whiteScale, gamma)
+	}
+	return math32.Color{rgb[0], rgb[1], rgb[2]}
+}
+
+// HorizonColor returns the average color of the sky around the horizon,
+// which can be used as the color of the fog of the scene.
+func (s *Sky) HorizonColor() math32.Color {
+
+	var sum math32.Color
+	const n = 16
+	for i := 0; i < n; i++ {
+		a := 2 * math32.Pi * float32(i) / n
+		c := s.Color(&math32.Vector3{math32.Cos(a), 0.02, math32.Sin(a)})
+		sum.R += c.R / n
+		sum.G += c.G / n
+		sum.B += c.B / n
+	}
+	return sum
+}
+
+// skyTonemap is the Uncharted 2 filmic tone mapping curve.
+func skyTonemap(x float32) float32 {
+
+	const a, b, c, d, e, f = 0.15, 0.50, 0.10, 0.20, 0.02, 0.30
+	return ((x*(a*x+c*b) + d*e) / (x*(a*x+b) + d*f)) - e/f
+}
+
+// skySmoothstep returns the smooth Hermite interpolation of x between edge0 and edge1.
+func skySmoothstep(edge0, edge1, x float32) float32 {
+
+	t := math32.Clamp((x-edge0)/(edge1-edge0), 0, 1)
+	return t * t * (3 - 2*t)
+}
+
+// RenderSetup sets up the rendering of the sky.
+func (s *Sky) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+
+	// Rotation of the view only, so the sky stays around the camera
+	vm := rinfo.ViewMatrix
+	vm[12], vm[13], vm[14] = 0, 0, 0
+	var mvpm math32.Matrix4
+	mvpm.MultiplyMatrices(&rinfo.ProjMatrix, &vm)
+	gs.UniformMatrix4fv(s.uniMVPm.Location(gs), 1, false, &mvpm[0])
+	gs.Uniform4fv(s.uniParams.Location(gs), 7, &s.udata[0])
+}
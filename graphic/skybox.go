@@ -63,6 +63,9 @@ func NewSkybox(data SkyboxData) (*Skybox, error) {
 	// The skybox should always be rendered last among the opaque objects
 	skybox.SetRenderOrder(100)
 
+	// The scene fog is blended toward the horizon instead of with the distance
+	skybox.ShaderDefines.Set("FOG_SKY", "")
+
 	return skybox, nil
 }
 
@@ -10,6 +10,7 @@ import (
 	"github.com/g3n/engine/gls"
 	"github.com/g3n/engine/material"
 	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
 )
 
 // Sprite is a potentially animated image positioned in space that always faces the camera.
@@ -21,6 +22,23 @@ type Sprite struct {
 // NewSprite creates and returns a pointer to a sprite with the specified dimensions and material
 func NewSprite(width, height float32, imat material.IMaterial) *Sprite {
 
+	return newSprite(width, height, imat, 0, 0, 1, 1)
+}
+
+// NewSpriteFromRegion creates and returns a pointer to a sprite with the specified dimensions
+// and material which displays the specified region of an atlas.
+// The material should use the atlas texture, which can be shared by many sprites
+// as the region is set in the sprite's texture coordinates.
+func NewSpriteFromRegion(width, height float32, imat material.IMaterial, region *texture.AtlasRegion) *Sprite {
+
+	// Texture coordinates are flipped vertically by the shader
+	return newSprite(width, height, imat, region.U0, 1-region.V1, region.U1, 1-region.V0)
+}
+
+// newSprite creates and returns a pointer to a sprite with the specified dimensions,
+// material and texture coordinates of its bottom left and top right corners.
+func newSprite(width, height float32, imat material.IMaterial, u0, v0, u1, v1 float32) *Sprite {
+
 	s := new(Sprite)
 
 	// Creates geometry
@@ -29,12 +47,12 @@ func NewSprite(width, height float32, imat material.IMaterial) *Sprite {
 	h := height / 2
 
 	// Builds array with vertex positions and texture coordinates
-	positions := math32.NewArrayF32(0, 12)
+	positions := math32.NewArrayF32(0, 20)
 	positions.Append(
-		-w, -h, 0, 0, 0,
-		w, -h, 0, 1, 0,
-		w, h, 0, 1, 1,
-		-w, h, 0, 0, 1,
+		-w, -h, 0, u0, v0,
+		w, -h, 0, u1, v0,
+		w, h, 0, u1, v1,
+		-w, h, 0, u0, v1,
 	)
 	// Builds array of indices
 	indices := math32.NewArrayU32(0, 6)
@@ -0,0 +1,291 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// StaticBatch is a node containing meshes which merge the triangles of the static meshes
+// of a scene subtree sharing the same material, with their world transforms baked in
+// their vertices, so they can be drawn with one draw call per material.
+// As the batch geometries are in world coordinates, the batch node should be added
+// to the scene without any transform.
+// The batch keeps the mapping from its triangles to the original meshes,
+// which can be used to identify the node hit by a raycast and to hide individual nodes.
+type StaticBatch struct {
+	core.Node                         // Embedded node
+	batches   []*batchMesh            // Batch meshes
+	entries   []BatchEntry            // Ranges of triangles of the original meshes
+	nodes     map[core.INode][]int    // Indices of the entries of each original mesh
+	hidden    map[core.INode]struct{} // Original meshes hidden in the batch
+}
+
+// BatchEntry describes the range of the indices of a batch mesh
+// containing the triangles of an original mesh with one of its materials.
+type BatchEntry struct {
+	Node  *Mesh // Original mesh
+	Batch *Mesh // Batch mesh containing its triangles
+	Start int   // Index of the first element in the batch geometry indices
+	Count int   // Number of elements
+}
+
+// batchMesh is a batch mesh with its original indices.
+type batchMesh struct {
+	mesh    *Mesh           // Batch mesh
+	indices math32.ArrayU32 // Original indices of the batch geometry
+	entries []int           // Indices of the entries of the batch mesh
+}
+
+// batchPart is a subset of the triangles of an original mesh using one material.
+type batchPart struct {
+	node *Mesh              // Original mesh
+	geom *geometry.Geometry // Geometry with the subset of triangles
+}
+
+// NewStaticBatch creates and returns a pointer to a static batch of all the meshes in the
+// subtree of the specified root node, including it, grouped by material.
+// The world transforms of the subtree are updated before being baked into the batch.
+// The original meshes are removed from their parents, which receive their children with
+// the transforms of the meshes baked in, so they cost nothing per frame and are not hit by
+// raycasts. They are kept in the batch entries. A root mesh without parent is only made not
+// renderable. Meshes in invisible subtrees are batched hidden and can be shown using SetNodeVisible.
+// Only the vertex attributes present in all the meshes sharing a material are kept.
+func NewStaticBatch(root core.INode) *StaticBatch {
+
+	b := new(StaticBatch)
+	b.Node.Init(b)
+	b.nodes = make(map[core.INode][]int)
+	b.hidden = make(map[core.INode]struct{})
+	root.UpdateMatrixWorld()
+
+	// Collects the parts of the meshes of the subtree by material in the order they are found
+	var materials []material.IMaterial
+	var meshes []*Mesh
+	parts := make(map[material.IMaterial][]batchPart)
+	var collect func(inode core.INode, visible bool)
+	collect = func(inode core.INode, visible bool) {
+		visible = visible && inode.Visible()
+		if m, ok := inode.(*Mesh); ok && m.Renderable() {
+			for _, grmat := range m.Materials() {
+				geom := batchGeometry(m.GetGeometry(), grmat.start, grmat.count)
+				if geom == nil {
+					continue
+				}
+				if _, ok := parts[grmat.imat]; !ok {
+					materials = append(materials, grmat.imat)
+				}
+				parts[grmat.imat] = append(parts[grmat.imat], batchPart{m, geom})
+			}
+			if len(m.Materials()) > 0 {
+				m.SetRenderable(false)
+				meshes = append(meshes, m)
+				if !visible {
+					b.hidden[m] = struct{}{}
+				}
+			}
+		}
+		for _, child := range inode.Children() {
+			collect(child, visible)
+		}
+	}
+	collect(root, true)
+
+	// Removes the original meshes from the scene, parents before children
+	for _, m := range meshes {
+		detachMesh(m)
+	}
+
+	// Merges the parts of each material, each one becoming a group of the merged geometry
+	for _, imat := range materials {
+		geoms := make([]*geometry.Geometry, len(parts[imat]))
+		matrices := make([]*math32.Matrix4, len(parts[imat]))
+		for i, part := range parts[imat] {
+			mw := part.node.MatrixWorld()
+			geoms[i] = part.geom
+			matrices[i] = &mw
+		}
+		merged := geometry.Merge(geoms, matrices)
+		merged.OptimizeVertexFetch()
+
+		imat.GetMaterial().Incref()
+		bm := &batchMesh{mesh: NewMesh(merged, imat)}
+		bm.indices = append(math32.ArrayU32(nil), merged.Indices()...)
+		for i, part := range parts[imat] {
+			group := merged.GroupAt(i)
+			b.nodes[part.node] = append(b.nodes[part.node], len(b.entries))
+			bm.entries = append(bm.entries, len(b.entries))
+			b.entries = append(b.entries, BatchEntry{part.node, bm.mesh, group.Start, group.Count})
+		}
+		b.batches = append(b.batches, bm)
+		b.Add(bm.mesh)
+	}
+
+	// Hides the meshes which were not visible
+	for inode := range b.hidden {
+		b.updateNode(inode)
+	}
+	return b
+}
+
+// detachMesh removes the specified mesh from its parent and moves its children to the
+// parent, with the local transform and the visibility of the mesh baked in their own.
+func detachMesh(m *Mesh) {
+
+	iparent := m.Parent()
+	if iparent == nil {
+		return
+	}
+	parent := iparent.GetNode()
+	mm := m.Matrix()
+	for _, ichild := range append([]core.INode(nil), m.Children()...) {
+		child := ichild.GetNode()
+		cm := child.Matrix()
+		var local math32.Matrix4
+		local.MultiplyMatrices(&mm, &cm)
+		m.Remove(ichild)
+		child.SetMatrix(&local)
+		child.SetVisible(child.Visible() && m.Visible())
+		parent.Add(ichild)
+	}
+	parent.Remove(m)
+}
+
+// batchGeometry returns a geometry sharing the vertex buffers of the specified
+// geometry with the triangles of the specified range of elements,
+// or nil if the range has no triangles.
+func batchGeometry(geom *geometry.Geometry, start, count int) *geometry.Geometry {
+
+	if geom.VBO(gls.VertexPosition) == nil {
+		return nil
+	}
+	var indices math32.ArrayU32
+	if geom.Indexed() {
+		all := geom.Indices()
+		if count == 0 {
+			start, count = 0, all.Size()
+		}
+		indices = append(indices, all[start:start+count]...)
+	} else {
+		if count == 0 {
+			start, count = 0, geom.Items()
+		}
+		indices = math32.NewArrayU32(0, count)
+		for i := start; i < start+count; i++ {
+			indices.Append(uint32(i))
+		}
+	}
+	if indices.Size() < 3 {
+		return nil
+	}
+	part := geometry.NewGeometry()
+	for _, vbo := range geom.VBOs() {
+		part.AddVBO(vbo)
+	}
+	part.SetIndices(indices)
+	part.ShaderDefines = geom.ShaderDefines
+	return part
+}
+
+// Meshes returns the batch meshes, one for each material.
+func (b *StaticBatch) Meshes() []*Mesh {
+
+	meshes := make([]*Mesh, len(b.batches))
+	for i, bm := range b.batches {
+		meshes[i] = bm.mesh
+	}
+	return meshes
+}
+
+// Entries returns the ranges of the batch meshes containing the triangles of the original meshes.
+func (b *StaticBatch) Entries() []BatchEntry {
+
+	return b.entries
+}
+
+// NodeAt returns the original mesh whose triangles include the element at the specified
+// index of the specified batch mesh indices, such as the index of a raycast intersection,
+// or nil if not found.
+func (b *StaticBatch) NodeAt(batch *Mesh, index int) *Mesh {
+
+	for _, bm := range b.batches {
+		if bm.mesh != batch {
+			continue
+		}
+		for _, ei := range bm.entries {
+			entry := &b.entries[ei]
+			if index >= entry.Start && index < entry.Start+entry.Count {
+				return entry.Node
+			}
+		}
+	}
+	return nil
+}
+
+// SetNodeVisible sets the visibility in the batch of the triangles of the specified original mesh.
+// The triangles of hidden meshes are collapsed, so they are neither drawn nor hit by raycasts.
+func (b *StaticBatch) SetNodeVisible(inode core.INode, state bool) {
+
+	if _, ok := b.nodes[inode]; !ok {
+		return
+	}
+	if _, hidden := b.hidden[inode]; hidden != state {
+		return
+	}
+	if state {
+		delete(b.hidden, inode)
+	} else {
+		b.hidden[inode] = struct{}{}
+	}
+	b.updateNode(inode)
+}
+
+// NodeVisible returns the visibility in the batch of the triangles of the specified original mesh.
+func (b *StaticBatch) NodeVisible(inode core.INode) bool {
+
+	_, hidden := b.hidden[inode]
+	return !hidden
+}
+
+// updateNode updates the indices of the batch meshes containing the
+// triangles of the specified original mesh with its visibility.
+func (b *StaticBatch) updateNode(inode core.INode) {
+
+	_, hidden := b.hidden[inode]
+	for _, ei := range b.nodes[inode] {
+		entry := &b.entries[ei]
+		var bm *batchMesh
+		for _, bm = range b.batches {
+			if bm.mesh == entry.Batch {
+				break
+			}
+		}
+		geom := bm.mesh.GetGeometry()
+		indices := geom.Indices()
+		end := entry.Start + entry.Count
+		if hidden {
+			for i := entry.Start; i < end; i++ {
+				indices[i] = bm.indices[entry.Start]
+			}
+		} else {
+			copy(indices[entry.Start:end], bm.indices[entry.Start:end])
+		}
+		geom.SetIndices(indices)
+
+		// Hides the batch mesh if all its entries are hidden
+		visible := false
+		for _, bei := range bm.entries {
+			if _, h := b.hidden[b.entries[bei].Node]; !h {
+				visible = true
+				break
+			}
+		}
+		bm.mesh.SetVisible(visible)
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/text"
+)
+
+// TextMode specifies the orientation of a Text.
+type TextMode int
+
+// The orientations of a Text.
+const (
+	TextPlanar    = TextMode(iota) // Text in the XY plane of its node facing +Z
+	TextBillboard                  // Text always facing the camera
+)
+
+// Text is a string drawn in space with the glyphs of a glyph atlas,
+// which can be shared by many texts, with optional outline, drop shadow and glow.
+// Texts drawn with distance field atlases stay sharp at any size.
+type Text struct {
+	Graphic                     // Embedded graphic
+	mat      *material.Material // Text material
+	atlas    *text.GlyphAtlas   // Glyph atlas of the font
+	text     string             // Text string
+	mode     TextMode           // Orientation of the text
+	size     float32            // Height of a line in world units
+	maxWidth float32            // Width at which lines are wrapped in world units
+	align    text.Align         // Alignment of the lines
+	anchor   math32.Vector2     // Point of the text at the node origin
+	width    float32            // Width of the text in world units
+	height   float32            // Height of the text in world units
+	udata    [24]float32        // TextParams uniform data (6 vec4)
+	uniMVPM  gls.Uniform        // Model view projection matrix uniform location cache
+	uniText  gls.Uniform        // TextParams uniform location cache
+	vbo      *gls.VBO           // Vertex buffer of the glyph quads
+}
+
+// NewText creates and returns a pointer to a new planar text with the specified string
+// drawn with the glyphs of the specified atlas. The text is black, has a line height of
+// one world unit and is centered at the node origin.
+func NewText(atlas *text.GlyphAtlas, str string) *Text {
+
+	t := new(Text)
+	t.atlas = atlas
+	t.size = 1
+	t.anchor = math32.Vector2{0.5, 0.5}
+	t.udata[3] = 1
+	t.udata[20] = float32(atlas.Padding())
+
+	geom := geometry.NewGeometry()
+	t.vbo = gls.NewVBO(math32.NewArrayF32(0, 0)).
+		AddAttrib(gls.VertexPosition).
+		AddAttrib(gls.VertexTexcoord)
+	geom.AddVBO(t.vbo)
+	geom.SetIndices(math32.NewArrayU32(0, 0))
+	t.Graphic.Init(t, geom, gls.TRIANGLES)
+
+	// The atlas texture is shared by all the texts using the atlas
+	tex := atlas.Texture()
+	tex.SetUniformNames("uTextSampler", "uTextTexParams")
+	t.mat = material.NewMaterial()
+	t.mat.SetShader("text")
+	t.mat.SetUseLights(material.UseLightNone)
+	t.mat.SetSide(material.SideDouble)
+	t.mat.SetTransparent(true)
+	t.mat.AddTexture(tex.Incref())
+	switch atlas.Kind() {
+	case text.AtlasSDF:
+		t.mat.ShaderDefines.Set("SDF", "")
+	case text.AtlasMSDF:
+		t.mat.ShaderDefines.Set("MSDF", "")
+	}
+	t.AddMaterial(t, t.mat, 0, 0)
+
+	t.uniMVPM.Init("MVP")
+	t.uniText.Init("TextParams")
+	t.SetText(str)
+	return t
+}
+
+// SetText sets the string of the text, which can contain line breaks (\n).
+func (t *Text) SetText(str string) {
+
+	t.text = str
+	t.update()
+}
+
+// Text returns the string of the text.
+func (t *Text) Text() string {
+
+	return t.text
+}
+
+// Atlas returns the glyph atlas of the text.
+func (t *Text) Atlas() *text.GlyphAtlas {
+
+	return t.atlas
+}
+
+// SetMode sets the orientation of the text.
+// Billboard texts are not culled as their bounds change with the camera.
+func (t *Text) SetMode(mode TextMode) {
+
+	t.mode = mode
+	t.SetCullable(mode != TextBillboard)
+}
+
+// Mode returns the orientation of the text.
+func (t *Text) Mode() TextMode {
+
+	return t.mode
+}
+
+// SetSize sets the height of a line of text in world units.
+func (t *Text) SetSize(size float32) {
+
+	t.size = size
+	t.update()
+}
+
+// Size returns the height of a line of text in world units.
+func (t *Text) Size() float32 {
+
+	return t.size
+}
+
+// SetMaxWidth sets the width in world units at which the lines are wrapped.
+// Zero disables wrapping.
+func (t *Text) SetMaxWidth(width float32) {
+
+	t.maxWidth = width
+	t.update()
+}
+
+// MaxWidth returns the width in world units at which the lines are wrapped.
+func (t *Text) MaxWidth() float32 {
+
+	return t.maxWidth
+}
+
+// SetAlign sets the horizontal alignment of the lines of the text.
+func (t *Text) SetAlign(align text.Align) {
+
+	t.align = align
+	t.update()
+}
+
+// Align returns the horizontal alignment of the lines of the text.
+func (t *Text) Align() text.Align {
+
+	return t.align
+}
+
+// SetAnchor sets the point of the text placed at the node origin, as fractions
+// of its width and height from its bottom left corner. The default is the center (0.5, 0.5).
+func (t *Text) SetAnchor(x, y float32) {
+
+	t.anchor = math32.Vector2{x, y}
+	t.update()
+}
+
+// Anchor returns the point of the text placed at the node origin.
+func (t *Text) Anchor() math32.Vector2 {
+
+	return t.anchor
+}
+
+// SetColor sets the color of the text.
+func (t *Text) SetColor(color *math32.Color4) {
+
+	t.udata[0], t.udata[1], t.udata[2], t.udata[3] = color.R, color.G, color.B, color.A
+}
+
+// Color returns the color of the text.
+func (t *Text) Color() math32.Color4 {
+
+	return math32.Color4{t.udata[0], t.udata[1], t.udata[2], t.udata[3]}
+}
+
+// SetOutline sets the width in atlas pixels and the color of the outline of the glyphs.
+// The width is limited by the padding of the atlas. A zero width disables the outline.
+func (t *Text) SetOutline(width float32, color *math32.Color4) {
+
+	t.udata[4], t.udata[5], t.udata[6], t.udata[7] = color.R, color.G, color.B, color.A
+	t.udata[16] = math32.Clamp(width, 0, float32(t.atlas.Padding()))
+}
+
+// SetShadow sets the offset in atlas pixels, with Y up, and the color of the drop shadow
+// of the glyphs, which includes their outline. The offset plus the outline width is limited
+// by the padding of the atlas. A transparent color disables the shadow.
+func (t *Text) SetShadow(dx, dy float32, color *math32.Color4) {
+
+	t.udata[8], t.udata[9], t.udata[10], t.udata[11] = color.R, color.G, color.B, color.A
+	t.udata[17], t.udata[18] = dx, dy
+}
+
+// SetGlow sets the width in atlas pixels and the color of the glow fading out around the
+// outlined glyphs. It is only drawn with distance field atlases and the width plus the outline
+// width is limited by their spread. A zero width disables the glow.
+func (t *Text) SetGlow(width float32, color *math32.Color4) {
+
+	t.udata[12], t.udata[13], t.udata[14], t.udata[15] = color.R, color.G, color.B, color.A
+	t.udata[19] = math32.Clamp(width, 0, float32(t.atlas.Padding()))
+}
+
+// Width returns the width of the text in world units.
+func (t *Text) Width() float32 {
+
+	return t.width
+}
+
+// Height returns the height of the text in world units.
+func (t *Text) Height() float32 {
+
+	return t.height
+}
+
+// update rebuilds the glyph quads of the text.
+func (t *Text) update() {
+
+	// Lays out the text in atlas pixels
+	scale := t.size / t.atlas.LineHeight()
+	quads, width, height := t.atlas.Layout(t.text, t.maxWidth/scale, t.align, 1)
+	t.width = width * scale
+	t.height = height * scale
+
+	// Converts the quads to world units with Y up and the anchor at the origin
+	// with texture coordinates in atlas pixels
+	ox := t.anchor.X * width
+	oy := (1 - t.anchor.Y) * height
+	positions := (*t.vbo.Buffer())[:0]
+	indices := t.GetGeometry().Indices()[:0]
+	for i, q := range quads {
+		g := q.Glyph
+		x0, x1 := (q.X0-ox)*scale, (q.X1-ox)*scale
+		y0, y1 := (oy-q.Y1)*scale, (oy-q.Y0)*scale
+		u0, v0 := float32(g.X), float32(g.Y+g.Height)
+		u1, v1 := float32(g.X+g.Width), float32(g.Y)
+		positions.Append(
+			x0, y0, 0, u0, v0,
+			x1, y0, 0, u1, v0,
+			x1, y1, 0, u1, v1,
+			x0, y1, 0, u0, v1,
+		)
+		v := uint32(4 * i)
+		indices = append(indices, v, v+1, v+2, v, v+2, v+3)
+	}
+	t.vbo.SetBuffer(positions)
+	t.GetGeometry().SetIndices(indices)
+	t.SetRenderable(len(quads) > 0)
+}
+
+// RenderSetup sets up the rendering of the text.
+func (t *Text) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+
+	mw := t.MatrixWorld()
+	var mvm math32.Matrix4
+	mvm.MultiplyMatrices(&rinfo.ViewMatrix, &mw)
+
+	// Billboards keep only the position and scale of the model view matrix
+	if t.mode == TextBillboard {
+		var position, scale math32.Vector3
+		var quaternion math32.Quaternion
+		mvm.Decompose(&position, &quaternion, &scale)
+		mvm.Compose(&position, &math32.Quaternion{0, 0, 0, 1}, &scale)
+	}
+	var mvpm math32.Matrix4
+	mvpm.MultiplyMatrices(&rinfo.ProjMatrix, &mvm)
+	gs.UniformMatrix4fv(t.uniMVPM.Location(gs), 1, false, &mvpm[0])
+	gs.Uniform4fv(t.uniText.Location(gs), 6, &t.udata[0])
+}
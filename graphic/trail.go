@@ -0,0 +1,269 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// Trail is a ribbon following the past positions of a node, which always faces the camera.
+// Its width and color are interpolated over the age of its points, which are removed when
+// older than its lifetime. The points are in world coordinates, so the trail should not be
+// added as a child of the followed node but to a node without transform such as the scene.
+type Trail struct {
+	Graphic                        // Embedded graphic
+	mat         *material.Material // Trail material
+	tex         *texture.Texture2D // Optional texture of the ribbon
+	target      core.INode         // Followed node
+	points      []trailPoint       // Past positions of the node from the oldest
+	time        float32            // Current time
+	lifetime    float32            // Age at which the points are removed
+	minDistance float32            // Minimum distance between consecutive points
+	emitting    bool               // Whether new points are added
+	width       [2]float32         // Width at the head and at the end of the lifetime
+	color       [2]math32.Color4   // Color at the head and at the end of the lifetime
+	vbo         *gls.VBO           // Vertex buffer of the ribbon
+	uniMVm      gls.Uniform        // Model view matrix uniform location cache
+	uniPm       gls.Uniform        // Projection matrix uniform location cache
+}
+
+// trailPoint is a past position of the node followed by a Trail.
+type trailPoint struct {
+	pos  math32.Vector3 // Position in world coordinates
+	time float32        // Time at which the position was added
+}
+
+// NewTrail creates and returns a pointer to a new white trail following the specified node,
+// with points removed after the specified lifetime in seconds, a width of 1 and points
+// added when the node moves at least 0.1 from the last one.
+func NewTrail(target core.INode, lifetime float32) *Trail {
+
+	t := new(Trail)
+	t.target = target
+	t.lifetime = lifetime
+	t.minDistance = 0.1
+	t.emitting = true
+	t.width = [2]float32{1, 1}
+	t.color = [2]math32.Color4{{1, 1, 1, 1}, {1, 1, 1, 1}}
+
+	// Attributes have fixed locations in the shader
+	geom := geometry.NewGeometry()
+	t.vbo = gls.NewVBO(math32.NewArrayF32(0, 0)).
+		AddAttrib(gls.VertexPosition).
+		AddCustomAttrib("TrailTangent", 3).
+		AddCustomAttrib("TrailParams", 4).
+		AddCustomAttrib("TrailColor", 4)
+	t.vbo.SetUsage(gls.DYNAMIC_DRAW)
+	geom.AddVBO(t.vbo)
+	geom.SetIndices(math32.NewArrayU32(0, 0))
+	t.Graphic.Init(t, geom, gls.TRIANGLES)
+
+	t.mat = material.NewMaterial()
+	t.mat.SetShader("trail")
+	t.mat.SetUseLights(material.UseLightNone)
+	t.mat.SetSide(material.SideDouble)
+	t.mat.SetTransparent(true)
+	t.mat.SetDepthMask(false)
+	t.AddMaterial(t, t.mat, 0, 0)
+
+	// The bounds change every frame and do not include the width of the ribbon
+	t.SetCullable(false)
+	t.SetRenderable(false)
+	t.uniMVm.Init("ModelViewMatrix")
+	t.uniPm.Init("ProjMatrix")
+	return t
+}
+
+// Target returns the node followed by the trail.
+func (t *Trail) Target() core.INode {
+
+	return t.target
+}
+
+// Material returns the material of the trail, which can be used to change its blending.
+func (t *Trail) Material() *material.Material {
+
+	return t.mat
+}
+
+// SetTexture sets the texture of the trail, with its U coordinate along the lifetime
+// from the head and its V coordinate across the ribbon, or removes it if nil.
+func (t *Trail) SetTexture(tex *texture.Texture2D) {
+
+	if t.tex != nil {
+		t.mat.RemoveTexture(t.tex)
+	}
+	t.tex = tex
+	if tex == nil {
+		t.mat.ShaderDefines.Unset("HAS_TRAILMAP")
+		return
+	}
+	tex.SetUniformNames("uTrailSampler", "uTrailTexParams")
+	t.mat.AddTexture(tex)
+	t.mat.ShaderDefines.Set("HAS_TRAILMAP", "")
+}
+
+// SetLifetime sets the age in seconds at which the points of the trail are removed.
+func (t *Trail) SetLifetime(lifetime float32) {
+
+	t.lifetime = lifetime
+}
+
+// Lifetime returns the age in seconds at which the points of the trail are removed.
+func (t *Trail) Lifetime() float32 {
+
+	return t.lifetime
+}
+
+// SetMinDistance sets the minimum distance the followed node must move from the
+// last point of the trail for a new point to be added.
+func (t *Trail) SetMinDistance(distance float32) {
+
+	t.minDistance = distance
+}
+
+// MinDistance returns the minimum distance between consecutive points of the trail.
+func (t *Trail) MinDistance() float32 {
+
+	return t.minDistance
+}
+
+// SetWidth sets the width of the trail at its head and at the end of the lifetime,
+// linearly interpolated over the age of the points.
+func (t *Trail) SetWidth(head, end float32) {
+
+	t.width = [2]float32{head, end}
+}
+
+// Width returns the width of the trail at its head and at the end of the lifetime.
+func (t *Trail) Width() (float32, float32) {
+
+	return t.width[0], t.width[1]
+}
+
+// SetColor sets the color and opacity of the trail at its head and at the end of the
+// lifetime, linearly interpolated over the age of the points. A transparent end color
+// fades the trail out over time.
+func (t *Trail) SetColor(head, end *math32.Color4) {
+
+	t.color = [2]math32.Color4{*head, *end}
+}
+
+// Color returns the color of the trail at its head and at the end of the lifetime.
+func (t *Trail) Color() (math32.Color4, math32.Color4) {
+
+	return t.color[0], t.color[1]
+}
+
+// SetEmitting sets whether new points are added as the followed node moves.
+// Without new points the trail fades out as its points age.
+func (t *Trail) SetEmitting(state bool) {
+
+	t.emitting = state
+}
+
+// Emitting returns whether new points are added as the followed node moves.
+func (t *Trail) Emitting() bool {
+
+	return t.emitting
+}
+
+// Clear removes all the points of the trail.
+func (t *Trail) Clear() {
+
+	t.points = t.points[:0]
+	t.update()
+}
+
+// Update advances the time of the trail by the specified interval in seconds,
+// removing the expired points and adding the current position of the followed node.
+// It should be called once per frame after the node is moved.
+func (t *Trail) Update(dt float32) {
+
+	t.time += dt
+	expired := 0
+	for expired < len(t.points) && t.time-t.points[expired].time >= t.lifetime {
+		expired++
+	}
+	t.points = append(t.points[:0], t.points[expired:]...)
+
+	if t.emitting {
+		var pos math32.Vector3
+		t.target.GetNode().WorldPosition(&pos)
+		if len(t.points) == 0 || t.points[len(t.points)-1].pos.DistanceTo(&pos) >= t.minDistance {
+			t.points = append(t.points, trailPoint{pos, t.time})
+		}
+	}
+	t.update()
+}
+
+// update rebuilds the ribbon with the points of the trail and the current position
+// of the followed node as its head.
+func (t *Trail) update() {
+
+	points := t.points
+	if t.emitting && len(points) > 0 {
+		var head math32.Vector3
+		t.target.GetNode().WorldPosition(&head)
+		if !head.Equals(&points[len(points)-1].pos) {
+			points = append(points, trailPoint{head, t.time})
+		}
+	}
+
+	buf := (*t.vbo.Buffer())[:0]
+	indices := t.GetGeometry().Indices()[:0]
+	for i := range points {
+		// Tangent from the neighbour points
+		prev, next := &points[i].pos, &points[i].pos
+		if i > 0 {
+			prev = &points[i-1].pos
+		}
+		if i < len(points)-1 {
+			next = &points[i+1].pos
+		}
+		var tangent math32.Vector3
+		tangent.SubVectors(next, prev)
+
+		// Width, color and texture coordinate from the normalized age
+		age := math32.Clamp((t.time-points[i].time)/t.lifetime, 0, 1)
+		width := t.width[0] + (t.width[1]-t.width[0])*age
+		color := t.color[0]
+		color.R += (t.color[1].R - color.R) * age
+		color.G += (t.color[1].G - color.G) * age
+		color.B += (t.color[1].B - color.B) * age
+		color.A += (t.color[1].A - color.A) * age
+		p := &points[i].pos
+		for _, side := range []float32{-1, 1} {
+			buf.Append(
+				p.X, p.Y, p.Z,
+				tangent.X, tangent.Y, tangent.Z,
+				side, width/2, age, 0,
+				color.R, color.G, color.B, color.A,
+			)
+		}
+		if i > 0 {
+			v := uint32(2 * (i - 1))
+			indices = append(indices, v, v+1, v+3, v, v+3, v+2)
+		}
+	}
+	t.vbo.SetBuffer(buf)
+	t.GetGeometry().SetIndices(indices)
+	t.SetRenderable(len(indices) > 0)
+}
+
+// RenderSetup sets up the rendering of the trail.
+func (t *Trail) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+
+	mw := t.MatrixWorld()
+	var mvm math32.Matrix4
+	mvm.MultiplyMatrices(&rinfo.ViewMatrix, &mw)
+	gs.UniformMatrix4fv(t.uniMVm.Location(gs), 1, false, &mvm[0])
+	gs.UniformMatrix4fv(t.uniPm.Location(gs), 1, false, &rinfo.ProjMatrix[0])
+}
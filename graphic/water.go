@@ -0,0 +1,200 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+	"github.com/g3n/engine/texture/procedural"
+)
+
+// Water is a rectangular water surface in the XY plane of its node, facing +Z, which blends
+// the reflection and the refraction of the scene rendered by its Reflector with a fresnel
+// factor. The surface is perturbed by two layers of a scrolling normal map, and the scene
+// seen through it is tinted with the water color according to the water thickness,
+// which needs a perspective camera.
+type Water struct {
+	Graphic                      // Embedded graphic
+	Reflector                    // Embedded reflector
+	mat       *material.Material // Water material
+	normalMap *texture.Texture2D // Normal map of the waves
+	velocity  math32.Vector2     // Velocity of the first normal map layer in texture coordinates per second
+	time      float32            // Current time
+	udata     [28]float32        // ReflectorParams uniform data (7 vec4)
+	uniParams gls.Uniform        // ReflectorParams uniform location cache
+}
+
+// NewWater creates and returns a pointer to a new water surface with the specified size
+// and normal map of the waves, which should be tileable. If the normal map is nil a
+// procedural one is generated.
+func NewWater(width, height float32, normalMap *texture.Texture2D) *Water {
+
+	w := new(Water)
+	w.Graphic.Init(w, geometry.NewPlane(width, height), gls.TRIANGLES)
+	if normalMap == nil {
+		normalMap = newWaterNormalMap()
+	}
+	normalMap.SetWrapS(gls.REPEAT)
+	normalMap.SetWrapT(gls.REPEAT)
+	normalMap.SetUniformNames("uNormalSampler", "uNormalTexParams")
+	w.normalMap = normalMap
+
+	w.mat = material.NewMaterial()
+	w.mat.SetShader("reflector")
+	w.mat.SetUseLights(material.UseLightNone)
+	w.mat.ShaderDefines.Set("WATER", "")
+	w.mat.ShaderDefines.Set("HAS_REFRACTION", "")
+	w.mat.AddTexture(normalMap)
+	w.Reflector.init(w, w.mat, true)
+	w.AddMaterial(w, w.mat, 0, 0)
+
+	w.SetColor(&math32.Color{0, 0.2, 0.3})
+	w.SetDepthFade(2)
+	w.SetWaves(4, &math32.Vector2{0.03, 0.02})
+	w.SetDistortion(0.02)
+	w.SetFresnel(5, 0.02)
+	w.SetSun(&math32.Vector3{0, 0, 1}, &math32.Color{0, 0, 0}, 200)
+	w.uniParams.Init("ReflectorParams")
+	return w
+}
+
+// newWaterNormalMap returns a tileable normal map of waves from a sum of sines
+// with integer frequencies.
+func newWaterNormalMap() *texture.Texture2D {
+
+	waves := [][4]float32{{3, 1, 0, 1}, {-2, 4, 1.3, 0.7}, {5, -3, 2.1, 0.5}, {1, 7, 0.4, 0.35}, {-9, -4, 3.3, 0.25}}
+	heights := procedural.NewFieldFunc(128, 128, func(u, v float32) float32 {
+		var h float32
+		for _, w := range waves {
+			h += w[3] * math32.Sin(2*math32.Pi*(w[0]*u+w[1]*v)+w[2])
+		}
+		return h
+	}).Normalize()
+	return texture.NewTexture2DFromRGBA(procedural.NormalMap(heights, 4))
+}
+
+// Material returns the material of the water.
+func (w *Water) Material() *material.Material {
+
+	return w.mat
+}
+
+// NormalMap returns the normal map of the waves.
+func (w *Water) NormalMap() *texture.Texture2D {
+
+	return w.normalMap
+}
+
+// SetColor sets the color of the water, which tints the scene seen through it.
+func (w *Water) SetColor(color *math32.Color) {
+
+	w.udata[0], w.udata[1], w.udata[2], w.udata[3] = color.R, color.G, color.B, 1
+}
+
+// Color returns the color of the water.
+func (w *Water) Color() math32.Color {
+
+	return math32.Color{w.udata[0], w.udata[1], w.udata[2]}
+}
+
+// SetDepthFade sets the thickness of water through which the scene is mostly hidden
+// by the water color, which falls off exponentially with the thickness.
+func (w *Water) SetDepthFade(distance float32) {
+
+	w.udata[19] = distance
+}
+
+// DepthFade returns the thickness of water through which the scene is mostly hidden.
+func (w *Water) DepthFade() float32 {
+
+	return w.udata[19]
+}
+
+// SetWaves sets the number of repetitions of the normal map over the surface and the
+// velocity of its first layer in texture coordinates per second. The second layer is
+// smaller and moves across the first one.
+func (w *Water) SetWaves(scale float32, velocity *math32.Vector2) {
+
+	w.udata[4] = scale
+	w.velocity = *velocity
+}
+
+// Waves returns the number of repetitions of the normal map and the velocity of its first layer.
+func (w *Water) Waves() (float32, math32.Vector2) {
+
+	return w.udata[4], w.velocity
+}
+
+// SetDistortion sets the displacement in texture coordinates of the reflection
+// and refraction by the waves.
+func (w *Water) SetDistortion(distortion float32) {
+
+	w.udata[5] = distortion
+}
+
+// Distortion returns the displacement of the reflection and refraction by the waves.
+func (w *Water) Distortion() float32 {
+
+	return w.udata[5]
+}
+
+// SetFresnel sets the exponent and the minimum of the fresnel factor which blends
+// the refraction seen from above with the reflection seen at grazing angles.
+func (w *Water) SetFresnel(power, bias float32) {
+
+	w.udata[6], w.udata[7] = power, bias
+}
+
+// Fresnel returns the exponent and the minimum of the fresnel factor.
+func (w *Water) Fresnel() (float32, float32) {
+
+	return w.udata[6], w.udata[7]
+}
+
+// SetSun sets the direction to the sun in world coordinates, the color of its highlight on
+// the waves and their shininess. The default color is black, which disables the highlight.
+func (w *Water) SetSun(direction *math32.Vector3, color *math32.Color, shininess float32) {
+
+	dir := *direction
+	dir.Normalize()
+	w.udata[12], w.udata[13], w.udata[14], w.udata[15] = dir.X, dir.Y, dir.Z, shininess
+	w.udata[16], w.udata[17], w.udata[18] = color.R, color.G, color.B
+}
+
+// Update advances the time of the water by the specified interval in seconds, scrolling the waves.
+func (w *Water) Update(dt float32) {
+
+	w.time += dt
+	u1, v1 := w.velocity.X*w.time, w.velocity.Y*w.time
+	u2, v2 := -w.velocity.Y*w.time*0.8, w.velocity.X*w.time*0.8
+	w.udata[8], w.udata[9] = u1-math32.Floor(u1), v1-math32.Floor(v1)
+	w.udata[10], w.udata[11] = u2-math32.Floor(u2), v2-math32.Floor(v2)
+}
+
+// RenderSetup sets up the rendering of the water.
+func (w *Water) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+
+	w.Reflector.renderSetup(gs, rinfo)
+	var camWorld math32.Matrix4
+	camWorld.GetInverse(&rinfo.ViewMatrix)
+	w.udata[20], w.udata[21], w.udata[22] = camWorld[12], camWorld[13], camWorld[14]
+	w.udata[24], w.udata[25] = w.projMatrix[10], w.projMatrix[14]
+	w.udata[26] = 0
+	if w.reflecting {
+		w.udata[26] = 1
+	}
+	gs.Uniform4fv(w.uniParams.Location(gs), 7, &w.udata[0])
+}
+
+// Dispose releases the render targets and the resources of the graphic.
+func (w *Water) Dispose() {
+
+	w.Reflector.dispose()
+	w.Graphic.Dispose()
+}
@@ -5,6 +5,7 @@
 package gui
 
 import (
+	"github.com/g3n/engine/math32"
 	"github.com/g3n/engine/texture"
 	"image"
 )
@@ -57,6 +58,30 @@ func NewImageFromTex(tex *texture.Texture2D) *Image {
 	return i
 }
 
+// NewImageFromRegion creates and returns an image panel which displays the specified
+// region of an atlas texture. The texture can be shared by many image panels.
+// Initially the size of the panel content area is the exact size of the region.
+func NewImageFromRegion(tex *texture.Texture2D, region *texture.AtlasRegion) *Image {
+
+	i := NewImageFromTex(tex.Incref())
+	i.SetRegion(region)
+	return i
+}
+
+// SetRegion sets the region of the image texture displayed by the panel
+// and sets the size of the panel content area to the size of the region.
+// A nil region displays the whole texture.
+func (i *Image) SetRegion(region *texture.AtlasRegion) {
+
+	if region == nil {
+		i.udata.texRegion = math32.Vector4{0, 0, 1, 1}
+		i.Panel.SetContentSize(float32(i.tex.Width()), float32(i.tex.Height()))
+		return
+	}
+	i.udata.texRegion = math32.Vector4{region.U0, region.V0, region.U1 - region.U0, region.V1 - region.V0}
+	i.Panel.SetContentSize(float32(region.Width), float32(region.Height))
+}
+
 // SetTexture changes the image texture to the specified texture2D.
 // It returns a pointer to the previous texture.
 func (i *Image) SetTexture(tex *texture.Texture2D) *texture.Texture2D {
@@ -64,6 +89,7 @@ func (i *Image) SetTexture(tex *texture.Texture2D) *texture.Texture2D {
 	prevtex := i.tex
 	i.Material().RemoveTexture(prevtex)
 	i.tex = tex
+	i.udata.texRegion = math32.Vector4{0, 0, 1, 1}
 	i.Panel.SetContentSize(float32(i.tex.Width()), float32(i.tex.Height()))
 	i.Material().AddTexture(i.tex)
 	return prevtex
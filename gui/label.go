@@ -5,7 +5,11 @@
 package gui
 
 import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
 	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
 	"github.com/g3n/engine/math32"
 	"github.com/g3n/engine/text"
 	"github.com/g3n/engine/texture"
@@ -13,12 +17,15 @@ import (
 
 // Label is a panel which contains a texture with text.
 // The content size of the label panel is the exact size of the texture.
+// Optionally the text is drawn with the glyphs of the shared distance field atlas of the font.
 type Label struct {
-	Panel                    // Embedded Panel
-	font  *text.Font         // TrueType font face
-	tex   *texture.Texture2D // Texture with text
-	style *LabelStyle        // The style of the panel and font attributes
-	text  string             // Text being displayed
+	Panel                     // Embedded Panel
+	font   *text.Font         // TrueType font face
+	tex    *texture.Texture2D // Texture with text
+	style  *LabelStyle        // The style of the panel and font attributes
+	text   string             // Text being displayed
+	sdf    bool               // Whether the text is drawn with the distance field atlas
+	glyphs *labelGlyphs       // Panel with the glyphs of the text created on demand
 }
 
 // LabelStyle contains all the styling attributes of a Label.
@@ -73,6 +80,14 @@ func (l *Label) initialize(msg string, font *text.Font) {
 // SetText sets and draws the label text using the font.
 func (l *Label) SetText(text string) {
 
+	if l.sdf {
+		l.text = text
+		width, height := l.glyphs.setText(text, l.font.SDFAtlas(), &l.style.FontAttributes, &l.style.FgColor)
+		l.Panel.SetColor4(&l.style.BgColor)
+		l.Panel.SetContentSize(width, height)
+		return
+	}
+
 	// Need at least a character to get dimensions
 	l.text = text
 	if text == "" {
@@ -101,6 +116,62 @@ func (l *Label) SetText(text string) {
 	l.Panel.SetContentSize(float32(textImage.Rect.Dx()), float32(textImage.Rect.Dy()))
 }
 
+// SetSDF sets whether the text is drawn with the glyphs of the multi-channel signed distance field
+// atlas of the font, which stays sharp at any size and is shared by all the labels using the font,
+// instead of a texture of its own.
+func (l *Label) SetSDF(enabled bool) *Label {
+
+	if enabled == l.sdf {
+		return l
+	}
+	l.sdf = enabled
+	if enabled {
+		if l.tex != nil {
+			l.Panel.Material().RemoveTexture(l.tex)
+			l.tex.Dispose()
+			l.tex = nil
+		}
+		if l.glyphs == nil {
+			l.glyphs = newLabelGlyphs()
+		}
+		l.Panel.Add(l.glyphs)
+	} else {
+		l.Panel.Remove(l.glyphs)
+	}
+	l.SetText(l.text)
+	return l
+}
+
+// SDF returns whether the text is drawn with the glyphs of the distance field atlas of the font.
+func (l *Label) SDF() bool {
+
+	return l.sdf
+}
+
+// SetOutline sets the width in pixels and the color of the outline of the text
+// drawn with the distance field atlas. A zero width disables the outline.
+func (l *Label) SetOutline(width float32, color *math32.Color4) *Label {
+
+	if l.glyphs == nil {
+		l.glyphs = newLabelGlyphs()
+	}
+	l.glyphs.outline, l.glyphs.outlineColor = width, *color
+	l.SetText(l.text)
+	return l
+}
+
+// SetGlow sets the width in pixels and the color of the glow around the text
+// drawn with the distance field atlas. A zero width disables the glow.
+func (l *Label) SetGlow(width float32, color *math32.Color4) *Label {
+
+	if l.glyphs == nil {
+		l.glyphs = newLabelGlyphs()
+	}
+	l.glyphs.glow, l.glyphs.glowColor = width, *color
+	l.SetText(l.text)
+	return l
+}
+
 // Text returns the label text.
 func (l *Label) Text() string {
 
@@ -240,3 +311,134 @@ func (l *Label) setTextCaret(msg string, mx, width, line, col int) {
 	l.Panel.SetContentSize(float32(width), float32(height))
 	l.text = msg
 }
+
+// labelGlyphs is a panel which draws the text of a label with the glyphs
+// of the shared distance field atlas of its font.
+type labelGlyphs struct {
+	Panel                          // Embedded panel
+	mat          material.Material // Text material
+	atlas        *text.GlyphAtlas  // Atlas of the glyphs
+	outline      float32           // Outline width in pixels
+	outlineColor math32.Color4     // Outline color
+	glow         float32           // Glow width in pixels
+	glowColor    math32.Color4     // Glow color
+	udata        [24]float32       // TextParams uniform data (6 vec4)
+	uniMVPM      gls.Uniform       // Model matrix uniform location cache
+	uniText      gls.Uniform       // TextParams uniform location cache
+	uniClip      gls.Uniform       // TextClip uniform location cache
+	vbo          *gls.VBO          // Vertex buffer of the glyph quads
+}
+
+// newLabelGlyphs creates and returns a pointer to a new empty label glyphs panel.
+func newLabelGlyphs() *labelGlyphs {
+
+	g := new(labelGlyphs)
+	geom := geometry.NewGeometry()
+	g.vbo = gls.NewVBO(math32.NewArrayF32(0, 0)).
+		AddAttrib(gls.VertexPosition).
+		AddAttrib(gls.VertexTexcoord)
+	geom.AddVBO(g.vbo)
+	geom.SetIndices(math32.NewArrayU32(0, 0))
+	gr := graphic.NewGraphic(g, geom, gls.TRIANGLES)
+
+	g.mat.Init()
+	g.mat.SetShader("text")
+	g.mat.SetUseLights(material.UseLightNone)
+	g.mat.SetTransparent(true)
+	g.mat.ShaderDefines.Set("TEXT_CLIP", "")
+	gr.AddMaterial(g, &g.mat, 0, 0)
+	g.Panel.InitializeGraphic(0, 0, gr)
+
+	// The label receives the events over its text
+	g.SetEnabled(false)
+	g.uniMVPM.Init("MVP")
+	g.uniText.Init("TextParams")
+	g.uniClip.Init("TextClip")
+	return g
+}
+
+// setText builds the glyph quads of the specified text with the glyphs of the specified atlas,
+// font attributes and color and returns the size of the text in pixels.
+func (g *labelGlyphs) setText(str string, atlas *text.GlyphAtlas, attrib *text.FontAttributes, color *math32.Color4) (float32, float32) {
+
+	// Uses the texture of the atlas
+	if atlas != g.atlas {
+		if g.atlas != nil {
+			tex := g.atlas.Texture()
+			g.mat.RemoveTexture(tex)
+			tex.Dispose()
+		}
+		g.atlas = atlas
+		tex := atlas.Texture()
+		tex.SetUniformNames("uTextSampler", "uTextTexParams")
+		g.mat.AddTexture(tex.Incref())
+		g.mat.ShaderDefines.Unset("SDF")
+		g.mat.ShaderDefines.Unset("MSDF")
+		switch atlas.Kind() {
+		case text.AtlasSDF:
+			g.mat.ShaderDefines.Set("SDF", "")
+		case text.AtlasMSDF:
+			g.mat.ShaderDefines.Set("MSDF", "")
+		}
+	}
+
+	// Lays out the text in atlas pixels and scales it to the font size
+	scale := float32(attrib.PointSize*attrib.DPI/72) / atlas.EmSize()
+	quads, width, height := atlas.Layout(str, 0, text.AlignLeft, float32(attrib.LineSpacing))
+	width = math32.Ceil(width * scale)
+	height = math32.Ceil(height * scale)
+	g.SetSize(width, height)
+
+	// Positions in the unit quad of the panel with Y down and texture coordinates in atlas pixels
+	positions := (*g.vbo.Buffer())[:0]
+	indices := g.GetGeometry().Indices()[:0]
+	if width > 0 && height > 0 {
+		for i, q := range quads {
+			gl := q.Glyph
+			x0, x1 := q.X0*scale/width, q.X1*scale/width
+			y0, y1 := -q.Y1*scale/height, -q.Y0*scale/height
+			u0, v0 := float32(gl.X), float32(gl.Y+gl.Height)
+			u1, v1 := float32(gl.X+gl.Width), float32(gl.Y)
+			positions.Append(
+				x0, y0, 0, u0, v0,
+				x1, y0, 0, u1, v0,
+				x1, y1, 0, u1, v1,
+				x0, y1, 0, u0, v1,
+			)
+			v := uint32(4 * i)
+			indices = append(indices, v, v+1, v+2, v, v+2, v+3)
+		}
+	}
+	g.vbo.SetBuffer(positions)
+	g.GetGeometry().SetIndices(indices)
+	g.SetRenderable(len(indices) > 0)
+
+	// Uniform data with the widths converted to atlas pixels
+	g.udata[0], g.udata[1], g.udata[2], g.udata[3] = color.R, color.G, color.B, color.A
+	g.udata[4], g.udata[5], g.udata[6], g.udata[7] = g.outlineColor.R, g.outlineColor.G, g.outlineColor.B, g.outlineColor.A
+	g.udata[12], g.udata[13], g.udata[14], g.udata[15] = g.glowColor.R, g.glowColor.G, g.glowColor.B, g.glowColor.A
+	spread := float32(atlas.Padding())
+	g.udata[16] = math32.Min(g.outline/scale, spread)
+	g.udata[19] = math32.Min(g.glow/scale, spread)
+	g.udata[20] = spread
+	return width, height
+}
+
+// RenderSetup is called by the renderer before drawing this graphic
+// It overrides the original panel RenderSetup
+// Calculates the model matrix and transfer to OpenGL.
+func (g *labelGlyphs) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+
+	// Sets model matrix
+	var mm math32.Matrix4
+	g.SetModelMatrix(gs, &mm)
+	gs.UniformMatrix4fv(g.uniMVPM.Location(gs), 1, false, &mm[0])
+	gs.Uniform4fv(g.uniText.Location(gs), 6, &g.udata[0])
+
+	// Clips to the bounds of the panel in OpenGL window coordinates
+	sX, sY := Manager().win.GetScale()
+	_, _, _, height := gs.GetViewport()
+	gs.Uniform4f(g.uniClip.Location(gs),
+		g.xmin*float32(sX), float32(height)-g.ymax*float32(sY),
+		g.xmax*float32(sX), float32(height)-g.ymin*float32(sY))
+}
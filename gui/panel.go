@@ -89,7 +89,7 @@ type Panel struct {
 	// Uniforms sent to shader
 	uniMatrix gls.Uniform // model matrix uniform location cache
 	uniPanel  gls.Uniform // panel parameters uniform location cache
-	udata     struct {    // Combined uniform data 8 * vec4
+	udata     struct {    // Combined uniform data 9 * vec4
 		bounds        math32.Vector4 // panel bounds in texture coordinates
 		borders       math32.Vector4 // panel borders in texture coordinates
 		paddings      math32.Vector4 // panel paddings in texture coordinates
@@ -99,6 +99,7 @@ type Panel struct {
 		contentColor  math32.Color4  // panel content color
 		textureValid  float32        // texture valid flag (bool)
 		dummy         [3]float32     // complete 8 * vec4
+		texRegion     math32.Vector4 // region of the texture displayed in the content area (x, y, width, height)
 	}
 }
 
@@ -182,6 +183,7 @@ func (p *Panel) Initialize(ipan IPanel, width, height float32) { // TODO rename
 
 	// Set defaults
 	p.udata.bordersColor = math32.Color4{0, 0, 0, 1}
+	p.udata.texRegion = math32.Vector4{0, 0, 1, 1}
 	p.bounded = true
 	p.enabled = true
 	p.resize(width, height, true)
@@ -861,7 +863,7 @@ func (p *Panel) RenderSetup(gl *gls.GLS, rinfo *core.RenderInfo) {
 
 	// Transfer panel parameters combined uniform
 	location = p.uniPanel.Location(gl)
-	const vec4count = 8
+	const vec4count = 9
 	gl.Uniform4fv(location, vec4count, &p.udata.bounds.X)
 }
 
@@ -0,0 +1,115 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package isosurface
+
+import (
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/math32"
+)
+
+// qefBias is the weight which pulls the vertex of a cell towards the mass point
+// of its edge crossings, keeping it stable where the surface is flat.
+const qefBias = 0.01
+
+// DualContouring extracts the isosurface of the specified grid at the specified iso value
+// using dual contouring, returning an indexed geometry with one vertex in each cell crossed by
+// the surface and a quad around each crossed edge of the grid. Unlike marching cubes it keeps
+// the sharp edges and corners of the surface, though where the surface is thinner than a cell
+// it may have non-manifold edges. The values below the iso value are inside the surface
+// and the normals point outside. Chunks of the grid are extracted concurrently.
+func DualContouring(g *Grid, iso float32) *geometry.Geometry {
+
+	inside := func(i, j, k int) bool { return g.At(i, j, k) < iso }
+
+	// cellVertex returns the vertex minimizing the squared distances to the tangent planes
+	// at the edge crossings of the specified cell, clamped to the cell.
+	cellVertex := func(i, j, k int) (math32.Vector3, math32.Vector3) {
+
+		var mass, normal, atb math32.Vector3
+		var ata [6]float32 // Symmetric xx, xy, xz, yy, yz, zz
+		count := 0
+		for _, e := range cubeEdges {
+			a, b := e[0], e[1]
+			ai, aj, ak := i+a&1, j+a>>1&1, k+a>>2&1
+			bi, bj, bk := i+b&1, j+b>>1&1, k+b>>2&1
+			va, vb := g.At(ai, aj, ak), g.At(bi, bj, bk)
+			if (va < iso) == (vb < iso) {
+				continue
+			}
+			t := (iso - va) / (vb - va)
+			p, pb := g.Position(ai, aj, ak), g.Position(bi, bj, bk)
+			n, nb := g.Gradient(ai, aj, ak), g.Gradient(bi, bj, bk)
+			p.Lerp(&pb, t)
+			n.Lerp(&nb, t)
+			n.Normalize()
+			d := n.Dot(&p)
+			ata[0] += n.X * n.X
+			ata[1] += n.X * n.Y
+			ata[2] += n.X * n.Z
+			ata[3] += n.Y * n.Y
+			ata[4] += n.Y * n.Z
+			ata[5] += n.Z * n.Z
+			atb.X += n.X * d
+			atb.Y += n.Y * d
+			atb.Z += n.Z * d
+			mass.Add(&p)
+			normal.Add(&n)
+			count++
+		}
+		mass.DivideScalar(float32(count))
+		normal.Normalize()
+
+		// Solves (AtA + bias*I) x = Atb + bias*mass
+		var m, inv math32.Matrix3
+		m.Set(
+			ata[0]+qefBias, ata[1], ata[2],
+			ata[1], ata[3]+qefBias, ata[4],
+			ata[2], ata[4], ata[5]+qefBias,
+		)
+		if err := inv.GetInverse(&m); err != nil {
+			return mass, normal
+		}
+		var bias math32.Vector3
+		bias.Copy(&mass).MultiplyScalar(qefBias)
+		x := atb
+		x.Add(&bias).ApplyMatrix3(&inv)
+		min, max := g.Position(i, j, k), g.Position(i+1, j+1, k+1)
+		x.Clamp(&min, &max)
+		return x, normal
+	}
+
+	return extract(g, func(c *chunkMesh, i, j, k int) {
+
+		// Each crossed edge starting at the first corner of the cell, with the four cells
+		// around it inside the grid, becomes a quad facing towards its outside end.
+		p := [3]int{i, j, k}
+		for axis := 0; axis < 3; axis++ {
+			b, d := (axis+1)%3, (axis+2)%3
+			if p[b] < 1 || p[d] < 1 {
+				continue
+			}
+			q := p
+			q[axis]++
+			in := inside(p[0], p[1], p[2])
+			if in == inside(q[0], q[1], q[2]) {
+				continue
+			}
+			var quad [4]uint32
+			offsets := [4][2]int{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+			for n, off := range offsets {
+				cell := p
+				cell[b] -= off[0]
+				cell[d] -= off[1]
+				quad[n] = c.vertex(g.index(cell[0], cell[1], cell[2]), func() (math32.Vector3, math32.Vector3) {
+					return cellVertex(cell[0], cell[1], cell[2])
+				})
+			}
+			if !in {
+				quad[1], quad[3] = quad[3], quad[1]
+			}
+			c.indices = append(c.indices, quad[0], quad[1], quad[2], quad[0], quad[2], quad[3])
+		}
+	})
+}
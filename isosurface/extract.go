@@ -0,0 +1,119 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package isosurface
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// ChunkSize is the number of cells along each axis of the chunks of the grid
+// which are extracted concurrently.
+var ChunkSize = 32
+
+// chunkMesh is the part of the mesh extracted from a chunk of the grid.
+// Its vertices have global keys so that vertices shared with other chunks are merged.
+type chunkMesh struct {
+	keys      []int           // Global key of each vertex
+	positions math32.ArrayF32 // Vertex positions
+	normals   math32.ArrayF32 // Vertex normals
+	indices   []uint32        // Triangle indices of the local vertices
+	lookup    map[int]uint32  // Local index of each key
+}
+
+// vertex returns the local index of the vertex with the specified key,
+// calling the specified function to build it the first time.
+func (c *chunkMesh) vertex(key int, build func() (math32.Vector3, math32.Vector3)) uint32 {
+
+	if idx, ok := c.lookup[key]; ok {
+		return idx
+	}
+	position, normal := build()
+	idx := uint32(len(c.keys))
+	c.keys = append(c.keys, key)
+	c.positions.AppendVector3(&position)
+	c.normals.AppendVector3(&normal)
+	c.lookup[key] = idx
+	return idx
+}
+
+// extract calls the specified function for each cell of the grid, in chunks processed
+// concurrently, and returns the geometry of the merged chunk meshes.
+func extract(g *Grid, cell func(c *chunkMesh, i, j, k int)) *geometry.Geometry {
+
+	size := ChunkSize
+	if size < 1 {
+		size = 1
+	}
+	type chunk struct{ i, j, k int }
+	var chunks []chunk
+	for k := 0; k < g.Nz-1; k += size {
+		for j := 0; j < g.Ny-1; j += size {
+			for i := 0; i < g.Nx-1; i += size {
+				chunks = append(chunks, chunk{i, j, k})
+			}
+		}
+	}
+
+	// Extracts the chunks concurrently keeping their meshes in order
+	meshes := make([]*chunkMesh, len(chunks))
+	queue := make(chan int, len(chunks))
+	for n := range chunks {
+		queue <- n
+	}
+	close(queue)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range queue {
+				ch := chunks[n]
+				c := &chunkMesh{lookup: make(map[int]uint32)}
+				for k := ch.k; k < ch.k+size && k < g.Nz-1; k++ {
+					for j := ch.j; j < ch.j+size && j < g.Ny-1; j++ {
+						for i := ch.i; i < ch.i+size && i < g.Nx-1; i++ {
+							cell(c, i, j, k)
+						}
+					}
+				}
+				meshes[n] = c
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Merges the chunk meshes, removing the vertices duplicated on their borders
+	positions := math32.NewArrayF32(0, 0)
+	normals := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, 0)
+	merged := make(map[int]uint32)
+	for _, c := range meshes {
+		remap := make([]uint32, len(c.keys))
+		for v, key := range c.keys {
+			idx, ok := merged[key]
+			if !ok {
+				idx = uint32(positions.Size() / 3)
+				merged[key] = idx
+				positions.Append(c.positions[v*3 : v*3+3]...)
+				normals.Append(c.normals[v*3 : v*3+3]...)
+			}
+			remap[v] = idx
+		}
+		for _, idx := range c.indices {
+			indices.Append(remap[idx])
+		}
+	}
+
+	geom := geometry.NewGeometry()
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	return geom
+}
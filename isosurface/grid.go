@@ -0,0 +1,124 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package isosurface extracts isosurfaces of scalar fields, such as volume data,
+// voxels or signed distance functions, as indexed geometries with normals
+// using marching cubes or dual contouring.
+package isosurface
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Field is a scalar field defined at any point, such as a signed distance function.
+// Points with values below the iso value of the extraction are inside the surface.
+type Field func(x, y, z float32) float32
+
+// Grid is a regular 3D grid of scalar values sampled at its corners.
+type Grid struct {
+	Nx, Ny, Nz int            // Number of samples along each axis
+	Min        math32.Vector3 // Position of the first sample
+	Spacing    math32.Vector3 // Distance between samples along each axis
+	Values     []float32      // Values in order of X, then Y, then Z
+}
+
+// NewGrid creates and returns a pointer to a new grid with the specified number of samples
+// along each axis, spread evenly over the box with the specified minimum and maximum corners.
+// All the values are initialized to zero.
+func NewGrid(nx, ny, nz int, min, max *math32.Vector3) *Grid {
+
+	if nx < 2 || ny < 2 || nz < 2 {
+		panic("Invalid argument(s). Grid needs at least 2 samples along each axis.")
+	}
+	g := new(Grid)
+	g.Nx, g.Ny, g.Nz = nx, ny, nz
+	g.Min = *min
+	g.Spacing.Set((max.X-min.X)/float32(nx-1), (max.Y-min.Y)/float32(ny-1), (max.Z-min.Z)/float32(nz-1))
+	g.Values = make([]float32, nx*ny*nz)
+	return g
+}
+
+// Sample creates and returns a pointer to a new grid with the specified number of samples along each axis
+// over the box with the specified minimum and maximum corners, with the values of the specified field.
+// The field is sampled concurrently and must be safe to call from several goroutines.
+func Sample(f Field, min, max *math32.Vector3, nx, ny, nz int) *Grid {
+
+	g := NewGrid(nx, ny, nz, min, max)
+	g.Fill(f)
+	return g
+}
+
+// Fill sets all the values of the grid from the specified field, sampling it concurrently.
+func (g *Grid) Fill(f Field) {
+
+	var wg sync.WaitGroup
+	slices := make(chan int, g.Nz)
+	for k := 0; k < g.Nz; k++ {
+		slices <- k
+	}
+	close(slices)
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range slices {
+				for j := 0; j < g.Ny; j++ {
+					for i := 0; i < g.Nx; i++ {
+						p := g.Position(i, j, k)
+						g.Values[g.index(i, j, k)] = f(p.X, p.Y, p.Z)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// index returns the index of the value of the specified sample.
+func (g *Grid) index(i, j, k int) int {
+
+	return (k*g.Ny+j)*g.Nx + i
+}
+
+// At returns the value of the specified sample.
+func (g *Grid) At(i, j, k int) float32 {
+
+	return g.Values[g.index(i, j, k)]
+}
+
+// Set sets the value of the specified sample.
+func (g *Grid) Set(i, j, k int, value float32) {
+
+	g.Values[g.index(i, j, k)] = value
+}
+
+// Position returns the position of the specified sample.
+func (g *Grid) Position(i, j, k int) math32.Vector3 {
+
+	return math32.Vector3{
+		g.Min.X + float32(i)*g.Spacing.X,
+		g.Min.Y + float32(j)*g.Spacing.Y,
+		g.Min.Z + float32(k)*g.Spacing.Z,
+	}
+}
+
+// Gradient returns the gradient of the values at the specified sample
+// from central differences, or one-sided differences at the borders of the grid.
+func (g *Grid) Gradient(i, j, k int) math32.Vector3 {
+
+	diff := func(i0, j0, k0, i1, j1, k1 int, spacing float32) float32 {
+		return (g.At(i1, j1, k1) - g.At(i0, j0, k0)) / (spacing * float32(i1-i0+j1-j0+k1-k0))
+	}
+	clamp := func(v, n int) int {
+		return math32.ClampInt(v, 0, n-1)
+	}
+	return math32.Vector3{
+		diff(clamp(i-1, g.Nx), j, k, clamp(i+1, g.Nx), j, k, g.Spacing.X),
+		diff(i, clamp(j-1, g.Ny), k, i, clamp(j+1, g.Ny), k, g.Spacing.Y),
+		diff(i, j, clamp(k-1, g.Nz), i, j, clamp(k+1, g.Nz), g.Spacing.Z),
+	}
+}
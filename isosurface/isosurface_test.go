@@ -0,0 +1,75 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package isosurface
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// sphereGrid returns a grid of the signed distance to a sphere of radius 1 at the origin.
+func sphereGrid() *Grid {
+
+	return Sample(func(x, y, z float32) float32 {
+		return math32.Sqrt(x*x+y*y+z*z) - 1
+	}, &math32.Vector3{-1.5, -1.5, -1.5}, &math32.Vector3{1.5, 1.5, 1.5}, 16, 16, 16)
+}
+
+// checkSphere checks that the vertices of the specified geometry are near the unit sphere
+// with normals pointing outside.
+func checkSphere(t *testing.T, name string, geom *geometry.Geometry, tolerance float32) {
+
+	positions, _ := geom.AttribData(gls.VertexPosition)
+	normals, _ := geom.AttribData(gls.VertexNormal)
+	if positions.Size() == 0 || len(geom.Indices()) == 0 {
+		t.Fatalf("%s: no triangles", name)
+	}
+	var p, n math32.Vector3
+	for i := 0; i < positions.Size(); i += 3 {
+		positions.GetVector3(i, &p)
+		normals.GetVector3(i, &n)
+		if d := math32.Abs(p.Length() - 1); d > tolerance {
+			t.Fatalf("%s: vertex %v at distance %v from the surface", name, p, d)
+		}
+		if p.Dot(&n) <= 0 {
+			t.Fatalf("%s: normal %v of vertex %v points inside", name, n, p)
+		}
+	}
+	for _, idx := range geom.Indices() {
+		if int(idx) >= positions.Size()/3 {
+			t.Fatalf("%s: index %d out of range", name, idx)
+		}
+	}
+}
+
+// Test marching cubes extraction of a sphere
+func TestMarchingCubes(t *testing.T) {
+
+	g := sphereGrid()
+	checkSphere(t, "MarchingCubes", MarchingCubes(g, 0), g.Spacing.X)
+}
+
+// Test dual contouring extraction of a sphere
+func TestDualContouring(t *testing.T) {
+
+	g := sphereGrid()
+	checkSphere(t, "DualContouring", DualContouring(g, 0), g.Spacing.X)
+}
+
+// Test that a field without crossings generates no triangles
+func TestEmpty(t *testing.T) {
+
+	g := NewGrid(4, 4, 4, &math32.Vector3{0, 0, 0}, &math32.Vector3{1, 1, 1})
+	g.Fill(func(x, y, z float32) float32 { return 1 })
+	if n := len(MarchingCubes(g, 0).Indices()); n != 0 {
+		t.Errorf("MarchingCubes of an empty field has %d indices", n)
+	}
+	if n := len(DualContouring(g, 0).Indices()); n != 0 {
+		t.Errorf("DualContouring of an empty field has %d indices", n)
+	}
+}
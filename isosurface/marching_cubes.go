@@ -0,0 +1,152 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package isosurface
+
+import (
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/math32"
+)
+
+// The corners of a cell are numbered by the bits of their offsets: 1 for X, 2 for Y and 4 for Z.
+// cubeEdges are the corners of each of the 12 edges of a cell, the first one with the lower offset.
+var cubeEdges [12][2]int
+
+// cubeTriangles are the edges of the vertices of the triangles of each of the 256 cases
+// of the corners of a cell inside the surface.
+var cubeTriangles [256][][3]int
+
+// init generates the marching cubes tables. On each face of the cell the segments of the
+// surface separate the corners inside from the others, so the faces shared by neighbour
+// cells have the same segments and the surface has no holes. The segments of all the faces
+// are chained into loops, which are triangulated.
+func init() {
+
+	edgeIndex := make(map[[2]int]int)
+	for a := 0; a < 8; a++ {
+		for bit := 1; bit < 8; bit <<= 1 {
+			if a&bit == 0 {
+				e := len(edgeIndex) / 2
+				edgeIndex[[2]int{a, a | bit}] = e
+				edgeIndex[[2]int{a | bit, a}] = e
+				cubeEdges[e] = [2]int{a, a | bit}
+			}
+		}
+	}
+
+	// Corners of each face counterclockwise seen from outside the cell
+	var faces [6][4]int
+	for axis := 0; axis < 3; axis++ {
+		u, v := 1<<uint((axis+1)%3), 1<<uint((axis+2)%3)
+		for side := 0; side < 2; side++ {
+			base := side << uint(axis)
+			face := [4]int{base, base | u, base | u | v, base | v}
+			if side == 0 {
+				face[1], face[3] = face[3], face[1]
+			}
+			faces[axis*2+side] = face
+		}
+	}
+
+	for cube := 0; cube < 256; cube++ {
+		inside := func(c int) bool { return cube&(1<<uint(c)) != 0 }
+
+		// Links the crossing where each run of inside corners starts to the one where it ends
+		next := make(map[int]int)
+		for _, face := range faces {
+			for t := 0; t < 4; t++ {
+				a, b := face[t], face[(t+1)%4]
+				if inside(a) || !inside(b) {
+					continue
+				}
+				for s := 1; s < 4; s++ {
+					c, d := face[(t+s)%4], face[(t+s+1)%4]
+					if inside(c) && !inside(d) {
+						next[edgeIndex[[2]int{a, b}]] = edgeIndex[[2]int{c, d}]
+						break
+					}
+				}
+			}
+		}
+
+		// Triangulates each loop as a fan, from a crossing whose diagonals do not lie on a face
+		// of the cell, as they could also be an edge of the surface in the neighbour cell.
+		for e := 0; e < 12; e++ {
+			if _, ok := next[e]; !ok {
+				continue
+			}
+			loop := []int{e}
+			for n := next[e]; n != e; n = next[n] {
+				loop = append(loop, n)
+			}
+			for _, n := range loop {
+				delete(next, n)
+			}
+			start := 0
+			for s := range loop {
+				diagonal := false
+				for t := 2; t < len(loop)-1; t++ {
+					diagonal = diagonal || sameFace(loop[s], loop[(s+t)%len(loop)])
+				}
+				if !diagonal {
+					start = s
+					break
+				}
+			}
+			for t := 1; t < len(loop)-1; t++ {
+				tri := [3]int{loop[start], loop[(start+t)%len(loop)], loop[(start+t+1)%len(loop)]}
+				cubeTriangles[cube] = append(cubeTriangles[cube], tri)
+			}
+		}
+	}
+}
+
+// sameFace returns if the two specified edges of a cell lie on the same face.
+func sameFace(e1, e2 int) bool {
+
+	and := cubeEdges[e1][0] & cubeEdges[e1][1] & cubeEdges[e2][0] & cubeEdges[e2][1]
+	or := cubeEdges[e1][0] | cubeEdges[e1][1] | cubeEdges[e2][0] | cubeEdges[e2][1]
+	return and != 0 || or != 7
+}
+
+// MarchingCubes extracts the isosurface of the specified grid at the specified iso value
+// using marching cubes, returning an indexed geometry with the vertices on the edges of the cells
+// and normals from the gradient of the values. The values below the iso value are inside the surface
+// and the normals point outside. Chunks of the grid are extracted concurrently.
+func MarchingCubes(g *Grid, iso float32) *geometry.Geometry {
+
+	return extract(g, func(c *chunkMesh, i, j, k int) {
+
+		var values [8]float32
+		cube := 0
+		for corner := range values {
+			values[corner] = g.At(i+corner&1, j+corner>>1&1, k+corner>>2&1)
+			if values[corner] < iso {
+				cube |= 1 << uint(corner)
+			}
+		}
+		for _, tri := range cubeTriangles[cube] {
+			var idx [3]uint32
+			for n, e := range tri {
+				a, b := cubeEdges[e][0], cubeEdges[e][1]
+				ai, aj, ak := i+a&1, j+a>>1&1, k+a>>2&1
+				bi, bj, bk := i+b&1, j+b>>1&1, k+b>>2&1
+				axis := 0
+				for bit := b - a; bit > 1; bit >>= 1 {
+					axis++
+				}
+				idx[n] = c.vertex(g.index(ai, aj, ak)*3+axis, func() (math32.Vector3, math32.Vector3) {
+					t := (iso - values[a]) / (values[b] - values[a])
+					pa, pb := g.Position(ai, aj, ak), g.Position(bi, bj, bk)
+					na, nb := g.Gradient(ai, aj, ak), g.Gradient(bi, bj, bk)
+					pa.Lerp(&pb, t)
+					na.Lerp(&nb, t)
+					na.Normalize()
+					return pa, na
+				})
+			}
+			c.indices = append(c.indices, idx[0], idx[1], idx[2])
+		}
+	})
+}
@@ -69,6 +69,7 @@ type ProfileCOMMON struct {
 		Sid           string
 		Asset         *Asset
 		ShaderElement interface{} // Blinn|Constant|Lambert|Phong
+		Bump          interface{} // Optional bump map Texture from the technique extra
 	}
 }
 
@@ -483,6 +484,33 @@ func (d *Decoder) decProfileCommonTechnique(start xml.StartElement, pc *ProfileC
 			}
 			continue
 		}
+		if child.Name.Local == "extra" {
+			err := d.decProfileCommonTechniqueExtra(child, pc)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+	}
+}
+
+// decProfileCommonTechniqueExtra decodes the extra element of a profile_COMMON
+// technique looking for the bump map which several exporters place there:
+// <extra><technique profile="..."><bump><texture .../></bump></technique></extra>
+func (d *Decoder) decProfileCommonTechniqueExtra(start xml.StartElement, pc *ProfileCOMMON) error {
+
+	for {
+		child, _, err := d.decNextChild(start)
+		if err != nil || child.Name.Local == "" {
+			return err
+		}
+		if child.Name.Local == "bump" {
+			err := d.decColorOrTexture(child, &pc.Technique.Bump)
+			if err != nil {
+				return err
+			}
+			continue
+		}
 	}
 }
 
@@ -546,7 +574,10 @@ func (d *Decoder) decBlinn(start xml.StartElement, pc *ProfileCOMMON) error {
 			continue
 		}
 		if child.Name.Local == "transparent" {
-			// not supported
+			err := d.decColorOrTexture(child, &bl.Transparent)
+			if err != nil {
+				return err
+			}
 			continue
 		}
 		if child.Name.Local == "transparency" {
@@ -626,7 +657,10 @@ func (d *Decoder) decPhong(start xml.StartElement, pc *ProfileCOMMON) error {
 			continue
 		}
 		if child.Name.Local == "transparent" {
-			// not supported
+			err := d.decColorOrTexture(child, &ph.Transparent)
+			if err != nil {
+				return err
+			}
 			continue
 		}
 		if child.Name.Local == "transparency" {
@@ -56,18 +56,33 @@ func (d *Decoder) NewMaterial(id string) (material.IMaterial, error) {
 		return nil, fmt.Errorf("ProfileCOMMON not found")
 	}
 
+	var m *material.Standard
+	var err error
 	switch se := pc.Technique.ShaderElement.(type) {
 	case *Blinn:
-		return d.newBlinnMaterial(se)
+		m, err = d.newBlinnMaterial(se)
 	case *Constant:
 		return d.newConstantMaterial(se)
 	case *Lambert:
 		return d.newLambertMaterial(se)
 	case *Phong:
-		return d.newPhongMaterial(se)
+		m, err = d.newPhongMaterial(se)
 	default:
 		return nil, fmt.Errorf("Invalid shader element")
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional bump map from the technique extra
+	if tex, ok := pc.Technique.Bump.(*Texture); ok {
+		tex2D, err := d.GetTexture2D(tex.Texture)
+		if err != nil {
+			return nil, err
+		}
+		m.SetBumpMap(tex2D)
+	}
+	return m, nil
 }
 
 // GetTexture2D returns a pointer to an instance of the Texture2D
@@ -145,9 +160,9 @@ func (d *Decoder) NewTexture2D(id string) (*texture.Texture2D, error) {
 	return tex, nil
 }
 
-func (d *Decoder) newBlinnMaterial(se *Blinn) (material.IMaterial, error) {
+func (d *Decoder) newBlinnMaterial(se *Blinn) (*material.Standard, error) {
 
-	return nil, fmt.Errorf("Not implemented")
+	return d.newStandardMaterial(se.Emission, se.Diffuse, se.Specular, se.Shininess, se.Transparent)
 }
 
 func (d *Decoder) newConstantMaterial(se *Constant) (material.IMaterial, error) {
@@ -160,19 +175,26 @@ func (d *Decoder) newLambertMaterial(se *Lambert) (material.IMaterial, error) {
 	return nil, fmt.Errorf("Not implemented")
 }
 
-func (d *Decoder) newPhongMaterial(se *Phong) (material.IMaterial, error) {
+func (d *Decoder) newPhongMaterial(se *Phong) (*material.Standard, error) {
+
+	return d.newStandardMaterial(se.Emission, se.Diffuse, se.Specular, se.Shininess, se.Transparent)
+}
+
+// newStandardMaterial creates and returns a pointer to a new standard material from the
+// elements shared by the blinn and phong shader elements, which are either Colors or Textures.
+func (d *Decoder) newStandardMaterial(emission, diffuse, specular, shininess, transparent interface{}) (*material.Standard, error) {
 
 	// Creates material with default color
 	m := material.NewStandard(&math32.Color{0.5, 0.5, 0.5})
 
 	// If "diffuse" is Color set its value in the material
-	_, ok := se.Diffuse.(*Color)
+	_, ok := diffuse.(*Color)
 	if ok {
-		color := getColor(se.Diffuse)
+		color := getColor(diffuse)
 		m.SetColor(&color)
 	} else {
 		// Diffuse must be a Texture
-		tex, ok := se.Diffuse.(*Texture)
+		tex, ok := diffuse.(*Texture)
 		if !ok {
 			return nil, fmt.Errorf("diffuse is not Color nor Texture")
 		}
@@ -181,21 +203,49 @@ func (d *Decoder) newPhongMaterial(se *Phong) (material.IMaterial, error) {
 		if err != nil {
 			return nil, err
 		}
-		// Add texture to this material
-		m.AddTexture(tex2D)
+		// Set texture as this material diffuse map
+		m.SetDiffuseMap(tex2D)
 	}
 
-	emission := getColor(se.Emission)
-	m.SetEmissiveColor(&emission)
+	// Emission and specular may be either a Color or a Texture
+	if tex, ok := emission.(*Texture); ok {
+		tex2D, err := d.GetTexture2D(tex.Texture)
+		if err != nil {
+			return nil, err
+		}
+		m.SetEmissiveColor(&math32.Color{1, 1, 1})
+		m.SetEmissiveMap(tex2D)
+	} else {
+		color := getColor(emission)
+		m.SetEmissiveColor(&color)
+	}
 
 	//ambient := getColor(se.Ambient)
 	//m.SetAmbientColor(&ambient)
 
-	specular := getColor(se.Specular)
-	m.SetSpecularColor(&specular)
+	if tex, ok := specular.(*Texture); ok {
+		tex2D, err := d.GetTexture2D(tex.Texture)
+		if err != nil {
+			return nil, err
+		}
+		m.SetSpecularColor(&math32.Color{1, 1, 1})
+		m.SetSpecularMap(tex2D)
+	} else {
+		color := getColor(specular)
+		m.SetSpecularColor(&color)
+	}
+
+	// Transparent texture is used as the alpha map
+	if tex, ok := transparent.(*Texture); ok {
+		tex2D, err := d.GetTexture2D(tex.Texture)
+		if err != nil {
+			return nil, err
+		}
+		m.SetAlphaMap(tex2D)
+		m.SetTransparent(true)
+	}
 
-	shininess := getFloatOrParam(se.Shininess)
-	m.SetShininess(shininess)
+	m.SetShininess(getFloatOrParam(shininess))
 
 	//m.SetOpacity(opacity float32) {
 	//m.SetWireframe(true)
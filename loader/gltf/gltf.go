@@ -22,6 +22,12 @@ const (
 	KhrMaterialsUnlit                 = "KHR_materials_unlit"
 	KhrMaterialsCommon                = "KHR_materials_common" // TODO this is officially part of glTF 1.0 (remove?)
 	KhrMaterialsPbrSpecularGlossiness = "KHR_materials_pbrSpecularGlossiness"
+	KhrMaterialsClearcoat             = "KHR_materials_clearcoat"
+	KhrMaterialsSheen                 = "KHR_materials_sheen"
+	KhrMaterialsTransmission          = "KHR_materials_transmission"
+	KhrMaterialsIor                   = "KHR_materials_ior"
+	KhrMaterialsEmissiveStrength      = "KHR_materials_emissive_strength"
+	KhrTextureTransform               = "KHR_texture_transform"
 )
 
 // GLTF is the root object for a glTF asset.
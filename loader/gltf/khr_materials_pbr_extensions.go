@@ -0,0 +1,197 @@
+package gltf
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// Clearcoat is the KHR_materials_clearcoat extension object.
+// The specification of this extension is at:
+// https://github.com/KhronosGroup/glTF/tree/master/extensions/2.0/Khronos/KHR_materials_clearcoat
+type Clearcoat struct {
+	ClearcoatFactor           float32            // The clearcoat layer intensity. Not required. Default is 0.
+	ClearcoatTexture          *TextureInfo       // The clearcoat layer intensity texture. Not required.
+	ClearcoatRoughnessFactor  float32            // The clearcoat layer roughness. Not required. Default is 0.
+	ClearcoatRoughnessTexture *TextureInfo       // The clearcoat layer roughness texture. Not required.
+	ClearcoatNormalTexture    *NormalTextureInfo // The clearcoat normal map texture. Not required.
+}
+
+// Sheen is the KHR_materials_sheen extension object.
+// The specification of this extension is at:
+// https://github.com/KhronosGroup/glTF/tree/master/extensions/2.0/Khronos/KHR_materials_sheen
+type Sheen struct {
+	SheenColorFactor      *[3]float32  // The sheen color in linear space. Not required. Default is [0,0,0].
+	SheenColorTexture     *TextureInfo // The sheen color (RGB) texture. Not required.
+	SheenRoughnessFactor  float32      // The sheen roughness. Not required. Default is 0.
+	SheenRoughnessTexture *TextureInfo // The sheen roughness (Alpha) texture. Not required.
+}
+
+// Transmission is the KHR_materials_transmission extension object.
+// The specification of this extension is at:
+// https://github.com/KhronosGroup/glTF/tree/master/extensions/2.0/Khronos/KHR_materials_transmission
+type Transmission struct {
+	TransmissionFactor  float32      // The base percentage of light that is transmitted through the surface. Not required. Default is 0.
+	TransmissionTexture *TextureInfo // The transmission (R) texture. Not required.
+}
+
+// Ior is the KHR_materials_ior extension object.
+// The specification of this extension is at:
+// https://github.com/KhronosGroup/glTF/tree/master/extensions/2.0/Khronos/KHR_materials_ior
+type Ior struct {
+	Ior *float32 // The index of refraction. Not required. Default is 1.5.
+}
+
+// EmissiveStrength is the KHR_materials_emissive_strength extension object.
+// The specification of this extension is at:
+// https://github.com/KhronosGroup/glTF/tree/master/extensions/2.0/Khronos/KHR_materials_emissive_strength
+type EmissiveStrength struct {
+	EmissiveStrength *float32 // The strength adjustment to be multiplied with the emissive factor. Not required. Default is 1.
+}
+
+// isPbrExtension returns whether the specified material extension
+// extends the metallic-roughness model and is supported by the loader.
+func isPbrExtension(ext string) bool {
+
+	switch ext {
+	case KhrMaterialsClearcoat, KhrMaterialsSheen, KhrMaterialsTransmission,
+		KhrMaterialsIor, KhrMaterialsEmissiveStrength:
+		return true
+	}
+	return false
+}
+
+// decodeExtension decodes the generic value of an extension into the specified struct.
+func decodeExtension(ext interface{}, v interface{}) error {
+
+	data, err := json.Marshal(ext)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// applyPbrExtensions decodes the supported metallic-roughness extensions
+// of a material and applies them to the specified physical material.
+func (g *GLTF) applyPbrExtensions(pm *material.Physical, exts map[string]interface{}) error {
+
+	for ext, extData := range exts {
+		var err error
+		switch ext {
+		case KhrMaterialsClearcoat:
+			err = g.applyClearcoat(pm, extData)
+		case KhrMaterialsSheen:
+			err = g.applySheen(pm, extData)
+		case KhrMaterialsTransmission:
+			err = g.applyTransmission(pm, extData)
+		case KhrMaterialsIor:
+			var ior Ior
+			err = decodeExtension(extData, &ior)
+			if err == nil && ior.Ior != nil {
+				pm.SetIOR(*ior.Ior)
+			}
+		case KhrMaterialsEmissiveStrength:
+			var es EmissiveStrength
+			err = decodeExtension(extData, &es)
+			if err == nil && es.EmissiveStrength != nil {
+				pm.SetEmissiveStrength(*es.EmissiveStrength)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s extension:%v", ext, err)
+		}
+	}
+	return nil
+}
+
+// applyClearcoat applies a KHR_materials_clearcoat extension to a physical material.
+func (g *GLTF) applyClearcoat(pm *material.Physical, ext interface{}) error {
+
+	var cc Clearcoat
+	err := decodeExtension(ext, &cc)
+	if err != nil {
+		return err
+	}
+	pm.SetClearcoatFactor(cc.ClearcoatFactor)
+	pm.SetClearcoatRoughnessFactor(cc.ClearcoatRoughnessFactor)
+
+	var tex *texture.Texture2D
+	if cc.ClearcoatTexture != nil {
+		tex, err = g.loadTextureInfo(cc.ClearcoatTexture.Index, cc.ClearcoatTexture.Extensions)
+		if err != nil {
+			return err
+		}
+		pm.SetClearcoatMap(tex)
+	}
+	if cc.ClearcoatRoughnessTexture != nil {
+		tex, err = g.loadTextureInfo(cc.ClearcoatRoughnessTexture.Index, cc.ClearcoatRoughnessTexture.Extensions)
+		if err != nil {
+			return err
+		}
+		pm.SetClearcoatRoughnessMap(tex)
+	}
+	if cc.ClearcoatNormalTexture != nil {
+		tex, err = g.loadTextureInfo(cc.ClearcoatNormalTexture.Index, cc.ClearcoatNormalTexture.Extensions)
+		if err != nil {
+			return err
+		}
+		pm.SetClearcoatNormalMap(tex)
+	}
+	return nil
+}
+
+// applySheen applies a KHR_materials_sheen extension to a physical material.
+func (g *GLTF) applySheen(pm *material.Physical, ext interface{}) error {
+
+	var sh Sheen
+	err := decodeExtension(ext, &sh)
+	if err != nil {
+		return err
+	}
+	if sh.SheenColorFactor != nil {
+		pm.SetSheenColorFactor(&math32.Color{sh.SheenColorFactor[0], sh.SheenColorFactor[1], sh.SheenColorFactor[2]})
+	}
+	pm.SetSheenRoughnessFactor(sh.SheenRoughnessFactor)
+
+	var tex *texture.Texture2D
+	if sh.SheenColorTexture != nil {
+		tex, err = g.loadTextureInfo(sh.SheenColorTexture.Index, sh.SheenColorTexture.Extensions)
+		if err != nil {
+			return err
+		}
+		pm.SetSheenColorMap(tex)
+	}
+	if sh.SheenRoughnessTexture != nil {
+		tex, err = g.loadTextureInfo(sh.SheenRoughnessTexture.Index, sh.SheenRoughnessTexture.Extensions)
+		if err != nil {
+			return err
+		}
+		pm.SetSheenRoughnessMap(tex)
+	}
+	return nil
+}
+
+// applyTransmission applies a KHR_materials_transmission extension to a physical material.
+func (g *GLTF) applyTransmission(pm *material.Physical, ext interface{}) error {
+
+	var tr Transmission
+	err := decodeExtension(ext, &tr)
+	if err != nil {
+		return err
+	}
+	pm.SetTransmissionFactor(tr.TransmissionFactor)
+	if tr.TransmissionFactor > 0 {
+		pm.SetTransparent(true)
+	}
+	if tr.TransmissionTexture != nil {
+		tex, err := g.loadTextureInfo(tr.TransmissionTexture.Index, tr.TransmissionTexture.Extensions)
+		if err != nil {
+			return err
+		}
+		pm.SetTransmissionMap(tex)
+	}
+	return nil
+}
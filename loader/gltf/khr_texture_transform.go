@@ -0,0 +1,48 @@
+package gltf
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/texture"
+)
+
+// TextureTransform is the KHR_texture_transform extension object of a texture info.
+// The specification of this extension is at:
+// https://github.com/KhronosGroup/glTF/tree/master/extensions/2.0/Khronos/KHR_texture_transform
+type TextureTransform struct {
+	Offset   *[2]float32 // The offset of the UV coordinate origin as a factor of the texture dimensions. Not required. Default is [0,0].
+	Rotation float32     // Rotate the UVs by this many radians counter-clockwise around the origin. Not required. Default is 0.
+	Scale    *[2]float32 // The scale factor applied to the components of the UV coordinates. Not required. Default is [1,1].
+	TexCoord *int        // Overrides the textureInfo texCoord value if supplied. Not required.
+}
+
+// loadTextureInfo loads the texture specified by its index and applies
+// the supported extensions of the texture info which references it.
+func (g *GLTF) loadTextureInfo(texIdx int, exts map[string]interface{}) (*texture.Texture2D, error) {
+
+	tex, err := g.LoadTexture(texIdx)
+	if err != nil {
+		return nil, err
+	}
+	extData, ok := exts[KhrTextureTransform]
+	if !ok {
+		return tex, nil
+	}
+
+	var tt TextureTransform
+	err = decodeExtension(extData, &tt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s extension:%v", KhrTextureTransform, err)
+	}
+	if tt.Offset != nil {
+		tex.SetOffset(tt.Offset[0], tt.Offset[1])
+	}
+	if tt.Scale != nil {
+		tex.SetRepeat(tt.Scale[0], tt.Scale[1])
+	}
+	tex.SetRotation(tt.Rotation)
+	if tt.TexCoord != nil && *tt.TexCoord != 0 {
+		log.Warn("%s: texCoord %d not supported", KhrTextureTransform, *tt.TexCoord)
+	}
+	return tex, nil
+}
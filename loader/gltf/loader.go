@@ -488,6 +488,7 @@ func (g *GLTF) LoadMesh(meshIdx int) (core.INode, error) {
 		if err != nil {
 			return nil, err
 		}
+		g.completeAttributes(geom, p)
 
 		// If primitive has targets then the geometry should be a morph geometry
 		if len(p.Targets) > 0 {
@@ -566,7 +567,7 @@ func (g *GLTF) loadAttributes(geom *geometry.Geometry, attributes map[string]int
 			if ok {
 				// Already created VBO for this buffer view
 				// Add attribute with correct byteOffset
-				g.addAttributeToVBO(vbo, name, uint32(*accessor.ByteOffset))
+				g.addAttributeToVBO(vbo, name, uint32(*accessor.ByteOffset), TypeSizes[accessor.Type])
 			} else {
 				// Load data and create vbo
 				buf, err := g.loadBufferView(bvIdx)
@@ -582,7 +583,7 @@ func (g *GLTF) loadAttributes(geom *geometry.Geometry, attributes map[string]int
 					return err
 				}
 				vbo := gls.NewVBO(data)
-				g.addAttributeToVBO(vbo, name, 0)
+				g.addAttributeToVBO(vbo, name, 0, TypeSizes[accessor.Type])
 				// Save reference to VBO keyed by index of the buffer view
 				interleavedVBOs[bvIdx] = vbo
 				// Add VBO to geometry
@@ -598,7 +599,7 @@ func (g *GLTF) loadAttributes(geom *geometry.Geometry, attributes map[string]int
 				return err
 			}
 			vbo := gls.NewVBO(data)
-			g.addAttributeToVBO(vbo, name, 0)
+			g.addAttributeToVBO(vbo, name, 0, TypeSizes[accessor.Type])
 			// Add VBO to geometry
 			geom.AddVBO(vbo)
 		}
@@ -612,6 +613,30 @@ func (g *GLTF) loadAttributes(geom *geometry.Geometry, attributes map[string]int
 	return nil
 }
 
+// completeAttributes generates the vertex attributes which the primitive doesn't provide
+// but which are required to render it: flat normals when normals are missing and
+// tangents when the material has a normal map but tangents are missing.
+// Geometries with morph targets are not modified as their vertices can't be split.
+func (g *GLTF) completeAttributes(geom *geometry.Geometry, p Primitive) {
+
+	_, hasTangents := p.Attributes["TANGENT"]
+	if hasTangents {
+		geom.ShaderDefines.Set("HAS_TANGENTS", "")
+	}
+	if len(p.Targets) > 0 || (p.Mode != nil && *p.Mode != TRIANGLES) {
+		return
+	}
+	if _, ok := p.Attributes["NORMAL"]; !ok {
+		geom.ComputeFlatNormals()
+	}
+	if hasTangents || p.Material == nil || g.Materials[*p.Material].NormalTexture == nil {
+		return
+	}
+	if _, ok := p.Attributes["TEXCOORD_0"]; ok {
+		geom.ComputeTangents()
+	}
+}
+
 // loadIndices loads the indices stored in the specified accessor.
 func (g *GLTF) loadIndices(ai int) (math32.ArrayU32, error) {
 
@@ -619,7 +644,9 @@ func (g *GLTF) loadIndices(ai int) (math32.ArrayU32, error) {
 }
 
 // addAttributeToVBO adds the appropriate attribute to the provided vbo based on the glTF attribute name.
-func (g *GLTF) addAttributeToVBO(vbo *gls.VBO, attribName string, byteOffset uint32) {
+// The number of elements of the attribute is set from the accessor type as it may differ
+// from the default (e.g. morph target tangents have 3 elements instead of 4).
+func (g *GLTF) addAttributeToVBO(vbo *gls.VBO, attribName string, byteOffset uint32, numElements int) {
 
 	aType, ok := AttributeName[attribName]
 	if !ok {
@@ -627,6 +654,7 @@ func (g *GLTF) addAttributeToVBO(vbo *gls.VBO, attribName string, byteOffset uin
 		return
 	}
 	vbo.AddAttribOffset(aType, byteOffset)
+	vbo.Attrib(aType).NumElements = int32(numElements)
 }
 
 // validateAccessorAttribute validates the specified accessor for the given attribute name.
@@ -712,19 +740,21 @@ func (g *GLTF) LoadMaterial(matIdx int) (material.IMaterial, error) {
 	var imat material.IMaterial
 
 	// Check for material extensions
-	if matData.Extensions != nil {
-		for ext, extData := range matData.Extensions {
-			if ext == KhrMaterialsCommon {
-				imat, err = g.loadMaterialCommon(extData)
-			} else if ext == KhrMaterialsUnlit {
-				//imat, err = g.loadMaterialUnlit(matData, extData)
-				//} else if ext == KhrMaterialsPbrSpecularGlossiness {
-			} else {
-				return nil, fmt.Errorf("unsupported extension:%s", ext)
-			}
+	pbr := true
+	for ext, extData := range matData.Extensions {
+		if ext == KhrMaterialsCommon {
+			imat, err = g.loadMaterialCommon(extData)
+			pbr = false
+		} else if ext == KhrMaterialsUnlit {
+			//imat, err = g.loadMaterialUnlit(matData, extData)
+			//} else if ext == KhrMaterialsPbrSpecularGlossiness {
+			pbr = false
+		} else if !isPbrExtension(ext) {
+			return nil, fmt.Errorf("unsupported extension:%s", ext)
 		}
-	} else {
-		// Material is normally PBR
+	}
+	// Material is normally PBR, optionally extended by the PBR extensions
+	if pbr {
 		imat, err = g.loadMaterialPBR(&matData)
 	}
 
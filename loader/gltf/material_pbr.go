@@ -88,7 +88,7 @@ func (g *GLTF) loadMaterialPBR(m *Material) (material.IMaterial, error) {
 
 	// BaseColorTexture
 	if pbr.BaseColorTexture != nil {
-		tex, err := g.LoadTexture(pbr.BaseColorTexture.Index)
+		tex, err := g.loadTextureInfo(pbr.BaseColorTexture.Index, pbr.BaseColorTexture.Extensions)
 		if err != nil {
 			return nil, err
 		}
@@ -97,7 +97,7 @@ func (g *GLTF) loadMaterialPBR(m *Material) (material.IMaterial, error) {
 
 	// MetallicRoughnessTexture
 	if pbr.MetallicRoughnessTexture != nil {
-		tex, err := g.LoadTexture(pbr.MetallicRoughnessTexture.Index)
+		tex, err := g.loadTextureInfo(pbr.MetallicRoughnessTexture.Index, pbr.MetallicRoughnessTexture.Extensions)
 		if err != nil {
 			return nil, err
 		}
@@ -106,7 +106,7 @@ func (g *GLTF) loadMaterialPBR(m *Material) (material.IMaterial, error) {
 
 	// NormalTexture
 	if m.NormalTexture != nil {
-		tex, err := g.LoadTexture(m.NormalTexture.Index)
+		tex, err := g.loadTextureInfo(m.NormalTexture.Index, m.NormalTexture.Extensions)
 		if err != nil {
 			return nil, err
 		}
@@ -115,7 +115,7 @@ func (g *GLTF) loadMaterialPBR(m *Material) (material.IMaterial, error) {
 
 	// OcclusionTexture
 	if m.OcclusionTexture != nil {
-		tex, err := g.LoadTexture(m.OcclusionTexture.Index)
+		tex, err := g.loadTextureInfo(m.OcclusionTexture.Index, m.OcclusionTexture.Extensions)
 		if err != nil {
 			return nil, err
 		}
@@ -124,12 +124,18 @@ func (g *GLTF) loadMaterialPBR(m *Material) (material.IMaterial, error) {
 
 	// EmissiveTexture
 	if m.EmissiveTexture != nil {
-		tex, err := g.LoadTexture(m.EmissiveTexture.Index)
+		tex, err := g.loadTextureInfo(m.EmissiveTexture.Index, m.EmissiveTexture.Extensions)
 		if err != nil {
 			return nil, err
 		}
 		pm.SetEmissiveMap(tex)
 	}
 
+	// Extensions of the metallic-roughness model
+	err := g.applyPbrExtensions(pm, m.Extensions)
+	if err != nil {
+		return nil, err
+	}
+
 	return pm, nil
 }
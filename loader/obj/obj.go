@@ -71,6 +71,13 @@ type Material struct {
 	Specular   math32.Color // Specular color reflectivity
 	Emissive   math32.Color // Emissive color
 	MapKd      string       // Texture file linked to diffuse color
+	MapKs      string       // Texture file linked to specular color
+	MapKe      string       // Texture file linked to emissive color
+	MapD       string       // Texture file linked to the dissolve factor (opacity)
+	MapBump    string       // Texture file with bump heights
+	BumpMult   float32      // Bump heights multiplier
+	MapNorm    string       // Texture file with tangent space normals
+	MapDisp    string       // Texture file with displacement heights (used as parallax map)
 }
 
 // Light gray default material used as when other materials cannot be loaded.
@@ -263,7 +270,7 @@ func (dec *Decoder) NewMesh(obj *Object) (*graphic.Mesh, error) {
 		mat.SetSpecularColor(&matDesc.Specular)
 		mat.SetShininess(matDesc.Shininess)
 		// Loads material textures if specified
-		err = dec.loadTex(&mat.Material, matDesc)
+		err = dec.loadTex(mat, matDesc)
 		if err != nil {
 			return nil, err
 		}
@@ -298,7 +305,7 @@ func (dec *Decoder) NewMesh(obj *Object) (*graphic.Mesh, error) {
 		matGroup.SetSpecularColor(&matDesc.Specular)
 		matGroup.SetShininess(matDesc.Shininess)
 		// Loads material textures if specified
-		err = dec.loadTex(&matGroup.Material, matDesc)
+		err = dec.loadTex(matGroup, matDesc)
 		if err != nil {
 			return nil, err
 		}
@@ -369,30 +376,50 @@ func (dec *Decoder) NewGeometry(obj *Object) (*geometry.Geometry, error) {
 }
 
 // loadTex loads textures described in the material descriptor into the
-// specified material
-func (dec *Decoder) loadTex(mat *material.Material, desc *Material) error {
+// typed texture slots of the specified material
+func (dec *Decoder) loadTex(mat *material.Standard, desc *Material) error {
+
+	maps := []struct {
+		file string
+		set  func(*texture.Texture2D)
+	}{
+		{desc.MapKd, mat.SetDiffuseMap},
+		{desc.MapKs, mat.SetSpecularMap},
+		{desc.MapKe, mat.SetEmissiveMap},
+		{desc.MapD, mat.SetAlphaMap},
+		{desc.MapNorm, mat.SetNormalMap},
+		{desc.MapBump, mat.SetBumpMap},
+		{desc.MapDisp, mat.SetParallaxMap},
+	}
+	for _, m := range maps {
+		// Checks if material descriptor specified texture
+		if m.file == "" {
+			continue
+		}
 
-	// Checks if material descriptor specified texture
-	if desc.MapKd == "" {
-		return nil
-	}
+		// Get texture file path
+		// If texture file path is not absolute assumes it is relative
+		// to the directory of the material file
+		var texPath string
+		if filepath.IsAbs(m.file) {
+			texPath = m.file
+		} else {
+			texPath = filepath.Join(dec.mtlDir, m.file)
+		}
 
-	// Get texture file path
-	// If texture file path is not absolute assumes it is relative
-	// to the directory of the material file
-	var texPath string
-	if filepath.IsAbs(desc.MapKd) {
-		texPath = desc.MapKd
-	} else {
-		texPath = filepath.Join(dec.mtlDir, desc.MapKd)
+		// Try to load texture from image file
+		tex, err := texture.NewTexture2DFromImage(texPath)
+		if err != nil {
+			return err
+		}
+		m.set(tex)
 	}
-
-	// Try to load texture from image file
-	tex, err := texture.NewTexture2DFromImage(texPath)
-	if err != nil {
-		return err
+	if desc.MapD != "" {
+		mat.SetTransparent(true)
+	}
+	if desc.MapBump != "" && desc.BumpMult != 0 {
+		mat.SetBumpScale(desc.BumpMult)
 	}
-	mat.AddTexture(tex)
 	return nil
 }
 
@@ -749,6 +776,18 @@ func (dec *Decoder) parseMtlLine(line string) error {
 		return dec.parseIllum(fields[1:])
 	case "map_Kd":
 		return dec.parseMapKd(fields[1:])
+	case "map_Ks":
+		return dec.parseMap(fields[1:], &dec.matCurrent.MapKs)
+	case "map_Ke":
+		return dec.parseMap(fields[1:], &dec.matCurrent.MapKe)
+	case "map_d":
+		return dec.parseMap(fields[1:], &dec.matCurrent.MapD)
+	case "map_Bump", "map_bump", "bump":
+		return dec.parseMap(fields[1:], &dec.matCurrent.MapBump)
+	case "norm", "map_Kn":
+		return dec.parseMap(fields[1:], &dec.matCurrent.MapNorm)
+	case "disp":
+		return dec.parseMap(fields[1:], &dec.matCurrent.MapDisp)
 	default:
 		dec.appendWarn(mtlType, "field not supported: "+ltype)
 	}
@@ -915,10 +954,52 @@ func (dec *Decoder) parseIllum(fields []string) error {
 // map_Kd [-options] <filename>
 func (dec *Decoder) parseMapKd(fields []string) error {
 
-	if len(fields) < 1 {
+	return dec.parseMap(fields, &dec.matCurrent.MapKd)
+}
+
+// Number of arguments of the texture map options.
+// The -o, -s and -t options have from 1 to 3 arguments.
+var mapOptionArgs = map[string]int{
+	"-blendu": 1, "-blendv": 1, "-bm": 1, "-boost": 1, "-cc": 1, "-clamp": 1,
+	"-imfchan": 1, "-mm": 2, "-o": 3, "-s": 3, "-t": 3, "-texres": 1, "-type": 1,
+}
+
+// Parses a texture map statement skipping its options and saves the file name
+// in the specified destination. The bump multiplier option is kept in the material.
+// map_xx [-options] <filename>
+func (dec *Decoder) parseMap(fields []string, dest *string) error {
+
+	i := 0
+	for i < len(fields) && strings.HasPrefix(fields[i], "-") {
+		opt := fields[i]
+		nargs, ok := mapOptionArgs[opt]
+		if !ok {
+			dec.appendWarn(mtlType, "texture option not supported: "+opt)
+			nargs = 0
+		}
+		i++
+		for n := 0; n < nargs && i < len(fields)-1; n++ {
+			// Optional arguments of -o, -s and -t must be numbers
+			if n > 0 && opt != "-mm" {
+				if _, err := strconv.ParseFloat(fields[i], 32); err != nil {
+					break
+				}
+			}
+			if opt == "-bm" {
+				val, err := strconv.ParseFloat(fields[i], 32)
+				if err != nil {
+					return dec.formatError("'-bm' parse float error")
+				}
+				dec.matCurrent.BumpMult = float32(val)
+			}
+			i++
+		}
+	}
+	if i >= len(fields) {
 		return dec.formatError("No fields")
 	}
-	dec.matCurrent.MapKd = fields[0]
+	// File names may contain spaces
+	*dest = strings.Join(fields[i:], " ")
 	return nil
 }
 
@@ -70,6 +70,7 @@ type Material struct {
 	side        Side                 // Face side(s) visibility
 	blending    Blending             // Blending mode
 	useLights   UseLights            // Which light types to consider
+	useFog      bool                 // Whether the scene fog is applied
 	transparent bool                 // Whether at all transparent
 	wireframe   bool                 // Whether to render only the wireframe
 	lineWidth   float32              // Line width for lines and wireframe
@@ -105,6 +106,7 @@ func (mat *Material) Init() *Material {
 
 	mat.refcount = 1
 	mat.useLights = UseLightAll
+	mat.useFog = true
 	mat.side = SideFront
 	mat.transparent = false
 	mat.wireframe = false
@@ -196,6 +198,19 @@ func (mat *Material) UseLights() UseLights {
 	return mat.useLights
 }
 
+// SetUseFog sets whether the fog of the scene is applied to the material.
+// By default the fog is applied.
+func (mat *Material) SetUseFog(state bool) {
+
+	mat.useFog = state
+}
+
+// UseFog returns whether the fog of the scene is applied to the material.
+func (mat *Material) UseFog() bool {
+
+	return mat.useFog
+}
+
 // SetSide sets the visible side(s) (SideFront | SideBack | SideDouble)
 func (mat *Material) SetSide(side Side) {
 
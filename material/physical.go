@@ -18,17 +18,48 @@ type Physical struct {
 	normalTex            *texture.Texture2D // Optional normal texture
 	occlusionTex         *texture.Texture2D // Optional occlusion texture
 	emissiveTex          *texture.Texture2D // Optional emissive texture
+	clearcoatTex         *texture.Texture2D // Optional clearcoat intensity texture
+	clearcoatRoughTex    *texture.Texture2D // Optional clearcoat roughness texture
+	clearcoatNormalTex   *texture.Texture2D // Optional clearcoat normal texture
+	sheenColorTex        *texture.Texture2D // Optional sheen color texture
+	sheenRoughTex        *texture.Texture2D // Optional sheen roughness texture
+	transmissionTex      *texture.Texture2D // Optional transmission texture
 	uni                  gls.Uniform        // Uniform location cache
 	udata                struct {           // Combined uniform data
-		baseColorFactor math32.Color4
-		emissiveFactor  math32.Color4
-		metallicFactor  float32
-		roughnessFactor float32
+		baseColorFactor          math32.Color4
+		emissiveFactor           math32.Color4
+		metallicFactor           float32
+		roughnessFactor          float32
+		ior                      float32
+		emissiveStrength         float32
+		clearcoatFactor          float32
+		clearcoatRoughnessFactor float32
+		transmissionFactor       float32
+		sheenRoughnessFactor     float32
+		sheenColorFactor         math32.Color
+		_                        float32
+		texRotation              [physicalMapCount + 1]float32
 	}
 }
 
 // Number of glsl shader vec4 elements used by uniform data
-const physicalVec4Count = 3
+const physicalVec4Count = 8
+
+// Indices of the physical material maps in the texture rotation uniform array
+const (
+	physicalBaseColorMap = iota
+	physicalMetallicRoughnessMap
+	physicalNormalMap
+	physicalOcclusionMap
+	physicalEmissiveMap
+	physicalClearcoatMap
+	physicalClearcoatRoughnessMap
+	physicalClearcoatNormalMap
+	physicalSheenColorMap
+	physicalSheenRoughnessMap
+	physicalTransmissionMap
+	physicalMapCount
+)
 
 // NewPhysical creates and returns a pointer to a new Physical material.
 func NewPhysical() *Physical {
@@ -43,6 +74,8 @@ func NewPhysical() *Physical {
 	m.udata.emissiveFactor = math32.Color4{0, 0, 0, 1}
 	m.udata.metallicFactor = 1
 	m.udata.roughnessFactor = 1
+	m.udata.ior = 1.5
+	m.udata.emissiveStrength = 1
 	return m
 }
 
@@ -84,19 +117,82 @@ func (m *Physical) SetEmissiveFactor(c *math32.Color) *Physical {
 	return m
 }
 
+// SetEmissiveStrength sets the multiplier applied to the emissive color,
+// allowing emissive values above 1 (KHR_materials_emissive_strength).
+// Its default value is 1.
+// Returns pointer to this updated material.
+func (m *Physical) SetEmissiveStrength(v float32) *Physical {
+
+	m.udata.emissiveStrength = v
+	return m
+}
+
+// SetIOR sets this material index of refraction, which determines the
+// specular reflectance at normal incidence for dielectrics (KHR_materials_ior).
+// Its default value is 1.5.
+// Returns pointer to this updated material.
+func (m *Physical) SetIOR(v float32) *Physical {
+
+	m.udata.ior = v
+	return m
+}
+
+// SetClearcoatFactor sets the intensity of the clearcoat layer (KHR_materials_clearcoat).
+// Its default value is 0, which disables the clearcoat layer.
+// Returns pointer to this updated material.
+func (m *Physical) SetClearcoatFactor(v float32) *Physical {
+
+	m.udata.clearcoatFactor = v
+	m.updateExtensionDefines()
+	return m
+}
+
+// SetClearcoatRoughnessFactor sets the roughness of the clearcoat layer.
+// Its default value is 0.
+// Returns pointer to this updated material.
+func (m *Physical) SetClearcoatRoughnessFactor(v float32) *Physical {
+
+	m.udata.clearcoatRoughnessFactor = v
+	return m
+}
+
+// SetSheenColorFactor sets the color of the sheen layer (KHR_materials_sheen).
+// Its default value is {0,0,0}, which disables the sheen layer.
+// Returns pointer to this updated material.
+func (m *Physical) SetSheenColorFactor(c *math32.Color) *Physical {
+
+	m.udata.sheenColorFactor = *c
+	m.updateExtensionDefines()
+	return m
+}
+
+// SetSheenRoughnessFactor sets the roughness of the sheen layer.
+// Its default value is 0.
+// Returns pointer to this updated material.
+func (m *Physical) SetSheenRoughnessFactor(v float32) *Physical {
+
+	m.udata.sheenRoughnessFactor = v
+	return m
+}
+
+// SetTransmissionFactor sets the fraction of light transmitted through the
+// surface (KHR_materials_transmission). As the renderer has no access to the
+// scene behind the surface, transmission is approximated by reducing the
+// diffuse contribution and the opacity, so the material should also be transparent.
+// Its default value is 0.
+// Returns pointer to this updated material.
+func (m *Physical) SetTransmissionFactor(v float32) *Physical {
+
+	m.udata.transmissionFactor = v
+	m.updateExtensionDefines()
+	return m
+}
+
 // SetBaseColorMap sets this material optional texture base color.
 // Returns pointer to this updated material.
 func (m *Physical) SetBaseColorMap(tex *texture.Texture2D) *Physical {
 
-	m.baseColorTex = tex
-	if m.baseColorTex != nil {
-		m.baseColorTex.SetUniformNames("uBaseColorSampler", "uBaseColorTexParams")
-		m.ShaderDefines.Set("HAS_BASECOLORMAP", "")
-		m.AddTexture(m.baseColorTex)
-	} else {
-		m.ShaderDefines.Unset("HAS_BASECOLORMAP")
-		m.RemoveTexture(m.baseColorTex)
-	}
+	m.setMap(&m.baseColorTex, tex, "BaseColor", "HAS_BASECOLORMAP")
 	return m
 }
 
@@ -104,15 +200,7 @@ func (m *Physical) SetBaseColorMap(tex *texture.Texture2D) *Physical {
 // Returns pointer to this updated material.
 func (m *Physical) SetMetallicRoughnessMap(tex *texture.Texture2D) *Physical {
 
-	m.metallicRoughnessTex = tex
-	if m.metallicRoughnessTex != nil {
-		m.metallicRoughnessTex.SetUniformNames("uMetallicRoughnessSampler", "uMetallicRoughnessTexParams")
-		m.ShaderDefines.Set("HAS_METALROUGHNESSMAP", "")
-		m.AddTexture(m.metallicRoughnessTex)
-	} else {
-		m.ShaderDefines.Unset("HAS_METALROUGHNESSMAP")
-		m.RemoveTexture(m.metallicRoughnessTex)
-	}
+	m.setMap(&m.metallicRoughnessTex, tex, "MetallicRoughness", "HAS_METALROUGHNESSMAP")
 	return m
 }
 
@@ -121,15 +209,7 @@ func (m *Physical) SetMetallicRoughnessMap(tex *texture.Texture2D) *Physical {
 // TODO add SetNormalMap (and SetSpecularMap) to StandardMaterial.
 func (m *Physical) SetNormalMap(tex *texture.Texture2D) *Physical {
 
-	m.normalTex = tex
-	if m.normalTex != nil {
-		m.normalTex.SetUniformNames("uNormalSampler", "uNormalTexParams")
-		m.ShaderDefines.Set("HAS_NORMALMAP", "")
-		m.AddTexture(m.normalTex)
-	} else {
-		m.ShaderDefines.Unset("HAS_NORMALMAP")
-		m.RemoveTexture(m.normalTex)
-	}
+	m.setMap(&m.normalTex, tex, "Normal", "HAS_NORMALMAP")
 	return m
 }
 
@@ -137,15 +217,7 @@ func (m *Physical) SetNormalMap(tex *texture.Texture2D) *Physical {
 // Returns pointer to this updated material.
 func (m *Physical) SetOcclusionMap(tex *texture.Texture2D) *Physical {
 
-	m.occlusionTex = tex
-	if m.occlusionTex != nil {
-		m.occlusionTex.SetUniformNames("uOcclusionSampler", "uOcclusionTexParams")
-		m.ShaderDefines.Set("HAS_OCCLUSIONMAP", "")
-		m.AddTexture(m.occlusionTex)
-	} else {
-		m.ShaderDefines.Unset("HAS_OCCLUSIONMAP")
-		m.RemoveTexture(m.occlusionTex)
-	}
+	m.setMap(&m.occlusionTex, tex, "Occlusion", "HAS_OCCLUSIONMAP")
 	return m
 }
 
@@ -153,22 +225,126 @@ func (m *Physical) SetOcclusionMap(tex *texture.Texture2D) *Physical {
 // Returns pointer to this updated material.
 func (m *Physical) SetEmissiveMap(tex *texture.Texture2D) *Physical {
 
-	m.emissiveTex = tex
-	if m.emissiveTex != nil {
-		m.emissiveTex.SetUniformNames("uEmissiveSampler", "uEmissiveTexParams")
-		m.ShaderDefines.Set("HAS_EMISSIVEMAP", "")
-		m.AddTexture(m.emissiveTex)
+	m.setMap(&m.emissiveTex, tex, "Emissive", "HAS_EMISSIVEMAP")
+	return m
+}
+
+// SetClearcoatMap sets this material optional clearcoat intensity texture.
+// The intensity is read from the 'r' channel.
+// Returns pointer to this updated material.
+func (m *Physical) SetClearcoatMap(tex *texture.Texture2D) *Physical {
+
+	m.setMap(&m.clearcoatTex, tex, "Clearcoat", "HAS_CLEARCOATMAP")
+	return m
+}
+
+// SetClearcoatRoughnessMap sets this material optional clearcoat roughness texture.
+// The roughness is read from the 'g' channel.
+// Returns pointer to this updated material.
+func (m *Physical) SetClearcoatRoughnessMap(tex *texture.Texture2D) *Physical {
+
+	m.setMap(&m.clearcoatRoughTex, tex, "ClearcoatRoughness", "HAS_CLEARCOATROUGHNESSMAP")
+	return m
+}
+
+// SetClearcoatNormalMap sets this material optional clearcoat normal texture.
+// Returns pointer to this updated material.
+func (m *Physical) SetClearcoatNormalMap(tex *texture.Texture2D) *Physical {
+
+	m.setMap(&m.clearcoatNormalTex, tex, "ClearcoatNormal", "HAS_CLEARCOATNORMALMAP")
+	return m
+}
+
+// SetSheenColorMap sets this material optional sheen color texture.
+// Returns pointer to this updated material.
+func (m *Physical) SetSheenColorMap(tex *texture.Texture2D) *Physical {
+
+	m.setMap(&m.sheenColorTex, tex, "SheenColor", "HAS_SHEENCOLORMAP")
+	return m
+}
+
+// SetSheenRoughnessMap sets this material optional sheen roughness texture.
+// The roughness is read from the 'a' channel.
+// Returns pointer to this updated material.
+func (m *Physical) SetSheenRoughnessMap(tex *texture.Texture2D) *Physical {
+
+	m.setMap(&m.sheenRoughTex, tex, "SheenRoughness", "HAS_SHEENROUGHNESSMAP")
+	return m
+}
+
+// SetTransmissionMap sets this material optional transmission texture.
+// The transmission is read from the 'r' channel.
+// Returns pointer to this updated material.
+func (m *Physical) SetTransmissionMap(tex *texture.Texture2D) *Physical {
+
+	m.setMap(&m.transmissionTex, tex, "Transmission", "HAS_TRANSMISSIONMAP")
+	return m
+}
+
+// updateExtensionDefines sets the shader defines which enable the optional
+// clearcoat, sheen and transmission shading terms only when they are in use.
+func (m *Physical) updateExtensionDefines() {
+
+	if m.udata.clearcoatFactor > 0 {
+		m.ShaderDefines.Set("USE_CLEARCOAT", "")
 	} else {
-		m.ShaderDefines.Unset("HAS_EMISSIVEMAP")
-		m.RemoveTexture(m.emissiveTex)
+		m.ShaderDefines.Unset("USE_CLEARCOAT")
+	}
+	c := m.udata.sheenColorFactor
+	if c.R > 0 || c.G > 0 || c.B > 0 {
+		m.ShaderDefines.Set("USE_SHEEN", "")
+	} else {
+		m.ShaderDefines.Unset("USE_SHEEN")
+	}
+	if m.udata.transmissionFactor > 0 {
+		m.ShaderDefines.Set("USE_TRANSMISSION", "")
+	} else {
+		m.ShaderDefines.Unset("USE_TRANSMISSION")
+	}
+}
+
+// setMap replaces the texture of a map slot, updating the material
+// textures, the uniform names of the new texture and the shader define.
+func (m *Physical) setMap(slot **texture.Texture2D, tex *texture.Texture2D, name, define string) {
+
+	if *slot != nil {
+		m.RemoveTexture(*slot)
+	}
+	*slot = tex
+	if tex != nil {
+		tex.SetUniformNames("u"+name+"Sampler", "u"+name+"TexParams")
+		m.ShaderDefines.Set(define, "")
+		m.AddTexture(tex)
+	} else {
+		m.ShaderDefines.Unset(define)
 	}
-	return m
 }
 
 // RenderSetup transfer this material uniforms and textures to the shader
 func (m *Physical) RenderSetup(gl *gls.GLS) {
 
 	m.Material.RenderSetup(gl)
+
+	// Updates the texture coordinates rotation of each map
+	maps := [physicalMapCount]*texture.Texture2D{
+		physicalBaseColorMap:          m.baseColorTex,
+		physicalMetallicRoughnessMap:  m.metallicRoughnessTex,
+		physicalNormalMap:             m.normalTex,
+		physicalOcclusionMap:          m.occlusionTex,
+		physicalEmissiveMap:           m.emissiveTex,
+		physicalClearcoatMap:          m.clearcoatTex,
+		physicalClearcoatRoughnessMap: m.clearcoatRoughTex,
+		physicalClearcoatNormalMap:    m.clearcoatNormalTex,
+		physicalSheenColorMap:         m.sheenColorTex,
+		physicalSheenRoughnessMap:     m.sheenRoughTex,
+		physicalTransmissionMap:       m.transmissionTex,
+	}
+	for i, tex := range maps {
+		if tex != nil {
+			m.udata.texRotation[i] = tex.Rotation()
+		}
+	}
+
 	location := m.uni.Location(gl)
 	gl.Uniform4fv(location, physicalVec4Count, &m.udata.baseColorFactor.R)
 }
@@ -7,28 +7,40 @@ package material
 import (
 	"github.com/g3n/engine/gls"
 	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
 )
 
 // Standard material supports the classic lighting model with
 // ambient, diffuse, specular and emissive lights.
 // The lighting calculation is implemented in the vertex shader.
 type Standard struct {
-	Material             // Embedded material
-	uni      gls.Uniform // Uniform location cache
-	udata    struct {    // Combined uniform data in 6 vec3:
-		ambient    math32.Color // Ambient color reflectivity
-		diffuse    math32.Color // Diffuse color reflectivity
-		specular   math32.Color // Specular color reflectivity
-		emissive   math32.Color // Emissive color
-		shininess  float32      // Specular shininess factor
-		opacity    float32      // Opacity
-		psize      float32      // Point size
-		protationZ float32      // Point rotation around Z axis
+	Material                       // Embedded material
+	diffuseTex  *texture.Texture2D // Optional diffuse color texture
+	normalTex   *texture.Texture2D // Optional tangent space normal texture
+	bumpTex     *texture.Texture2D // Optional bump (height) texture
+	specularTex *texture.Texture2D // Optional specular color texture
+	emissiveTex *texture.Texture2D // Optional emissive color texture
+	alphaTex    *texture.Texture2D // Optional opacity texture
+	parallaxTex *texture.Texture2D // Optional parallax (height) texture
+	uni         gls.Uniform        // Uniform location cache
+	udata       struct {           // Combined uniform data in 7 vec3:
+		ambient       math32.Color   // Ambient color reflectivity
+		diffuse       math32.Color   // Diffuse color reflectivity
+		specular      math32.Color   // Specular color reflectivity
+		emissive      math32.Color   // Emissive color
+		shininess     float32        // Specular shininess factor
+		opacity       float32        // Opacity
+		psize         float32        // Point size
+		protationZ    float32        // Point rotation around Z axis
+		bumpScale     float32        // Bump map height scale
+		parallaxScale float32        // Parallax map height scale
+		normalScale   math32.Vector2 // Normal map X and Y scale
+		_             float32
 	}
 }
 
 // Number of glsl shader vec3 elements used by uniform data
-const standardVec3Count = 6
+const standardVec3Count = 7
 
 // NewStandard creates and returns a pointer to a new standard material
 func NewStandard(color *math32.Color) *Standard {
@@ -52,6 +64,9 @@ func (ms *Standard) Init(shader string, color *math32.Color) {
 	ms.SetEmissiveColor(&math32.Color{0, 0, 0})
 	ms.SetShininess(30.0)
 	ms.SetOpacity(1.0)
+	ms.SetBumpScale(1.0)
+	ms.SetParallaxScale(0.05)
+	ms.SetNormalScale(1, 1)
 }
 
 // AmbientColor returns the material ambient color reflectivity.
@@ -107,6 +122,139 @@ func (ms *Standard) SetOpacity(opacity float32) {
 	ms.udata.opacity = opacity
 }
 
+// SetBumpScale sets the scale of the heights read from the bump map. Default is 1.0.
+func (ms *Standard) SetBumpScale(scale float32) {
+
+	ms.udata.bumpScale = scale
+}
+
+// SetParallaxScale sets the scale of the heights read from the parallax map. Default is 0.05.
+func (ms *Standard) SetParallaxScale(scale float32) {
+
+	ms.udata.parallaxScale = scale
+}
+
+// SetNormalScale sets the scale of the X and Y components of the normals
+// read from the normal map. Default is {1, 1}.
+func (ms *Standard) SetNormalScale(x, y float32) {
+
+	ms.udata.normalScale.Set(x, y)
+}
+
+// SetDiffuseMap sets the texture which modulates the diffuse and ambient colors.
+// Textures added with AddTexture are still blended over the diffuse map.
+// Passing nil removes the current diffuse map.
+func (ms *Standard) SetDiffuseMap(tex *texture.Texture2D) {
+
+	ms.setMap(&ms.diffuseTex, tex, "Diffuse", "HAS_DIFFUSEMAP")
+}
+
+// DiffuseMap returns the current diffuse map or nil.
+func (ms *Standard) DiffuseMap() *texture.Texture2D {
+
+	return ms.diffuseTex
+}
+
+// SetNormalMap sets the tangent space normal map texture.
+// The geometry tangents are used if it has them (see Geometry.ComputeTangents),
+// otherwise the tangent space is derived from the screen space derivatives.
+// Passing nil removes the current normal map.
+func (ms *Standard) SetNormalMap(tex *texture.Texture2D) {
+
+	ms.setMap(&ms.normalTex, tex, "Normal", "HAS_NORMALMAP")
+}
+
+// NormalMap returns the current normal map or nil.
+func (ms *Standard) NormalMap() *texture.Texture2D {
+
+	return ms.normalTex
+}
+
+// SetBumpMap sets the bump map texture whose red channel contains the heights
+// used to perturb the surface normals. It is ignored if a normal map is also set.
+// Passing nil removes the current bump map.
+func (ms *Standard) SetBumpMap(tex *texture.Texture2D) {
+
+	ms.setMap(&ms.bumpTex, tex, "Bump", "HAS_BUMPMAP")
+}
+
+// BumpMap returns the current bump map or nil.
+func (ms *Standard) BumpMap() *texture.Texture2D {
+
+	return ms.bumpTex
+}
+
+// SetSpecularMap sets the texture which modulates the specular color.
+// Passing nil removes the current specular map.
+func (ms *Standard) SetSpecularMap(tex *texture.Texture2D) {
+
+	ms.setMap(&ms.specularTex, tex, "Specular", "HAS_SPECULARMAP")
+}
+
+// SpecularMap returns the current specular map or nil.
+func (ms *Standard) SpecularMap() *texture.Texture2D {
+
+	return ms.specularTex
+}
+
+// SetEmissiveMap sets the texture which modulates the emissive color.
+// Passing nil removes the current emissive map.
+func (ms *Standard) SetEmissiveMap(tex *texture.Texture2D) {
+
+	ms.setMap(&ms.emissiveTex, tex, "Emissive", "HAS_EMISSIVEMAP")
+}
+
+// EmissiveMap returns the current emissive map or nil.
+func (ms *Standard) EmissiveMap() *texture.Texture2D {
+
+	return ms.emissiveTex
+}
+
+// SetAlphaMap sets the texture whose red channel modulates the opacity.
+// The material should also be set as transparent.
+// Passing nil removes the current alpha map.
+func (ms *Standard) SetAlphaMap(tex *texture.Texture2D) {
+
+	ms.setMap(&ms.alphaTex, tex, "Alpha", "HAS_ALPHAMAP")
+}
+
+// AlphaMap returns the current alpha map or nil.
+func (ms *Standard) AlphaMap() *texture.Texture2D {
+
+	return ms.alphaTex
+}
+
+// SetParallaxMap sets the height map texture whose red channel is used to offset
+// the texture coordinates of all the other maps along the view direction.
+// Passing nil removes the current parallax map.
+func (ms *Standard) SetParallaxMap(tex *texture.Texture2D) {
+
+	ms.setMap(&ms.parallaxTex, tex, "Parallax", "HAS_PARALLAXMAP")
+}
+
+// ParallaxMap returns the current parallax map or nil.
+func (ms *Standard) ParallaxMap() *texture.Texture2D {
+
+	return ms.parallaxTex
+}
+
+// setMap replaces the texture of a typed slot, updating the material
+// textures, the uniform names of the new texture and the shader define.
+func (ms *Standard) setMap(slot **texture.Texture2D, tex *texture.Texture2D, name, define string) {
+
+	if *slot != nil {
+		ms.RemoveTexture(*slot)
+	}
+	*slot = tex
+	if tex != nil {
+		tex.SetUniformNames("u"+name+"Sampler", "u"+name+"TexParams")
+		ms.ShaderDefines.Set(define, "")
+		ms.AddTexture(tex)
+	} else {
+		ms.ShaderDefines.Unset(define)
+	}
+}
+
 // RenderSetup is called by the engine before drawing the object
 // which uses this material
 func (ms *Standard) RenderSetup(gs *gls.GLS) {
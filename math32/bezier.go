@@ -0,0 +1,112 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// QuadraticBezier is a quadratic Bézier curve.
+type QuadraticBezier struct {
+	p0, p1, p2 Vector3   // start, control and end points
+	arc        arcLength // arc length cache
+}
+
+// NewQuadraticBezier creates and returns a pointer to a new quadratic Bézier curve
+// from the start point to the end point with the specified control point.
+func NewQuadraticBezier(start, control, end *Vector3) *QuadraticBezier {
+
+	return &QuadraticBezier{p0: *start, p1: *control, p2: *end}
+}
+
+// PointAt satisfies the Curve interface.
+func (c *QuadraticBezier) PointAt(t float32) Vector3 {
+
+	it := 1 - t
+	k0, k1, k2 := it*it, 2*it*t, t*t
+	return Vector3{
+		k0*c.p0.X + k1*c.p1.X + k2*c.p2.X,
+		k0*c.p0.Y + k1*c.p1.Y + k2*c.p2.Y,
+		k0*c.p0.Z + k1*c.p1.Z + k2*c.p2.Z,
+	}
+}
+
+// TangentAt satisfies the Curve interface.
+func (c *QuadraticBezier) TangentAt(t float32) Vector3 {
+
+	// Derivative: 2(1-t)(p1-p0) + 2t(p2-p1)
+	k0, k1 := 2*(1-t), 2*t
+	d := Vector3{
+		k0*(c.p1.X-c.p0.X) + k1*(c.p2.X-c.p1.X),
+		k0*(c.p1.Y-c.p0.Y) + k1*(c.p2.Y-c.p1.Y),
+		k0*(c.p1.Z-c.p0.Z) + k1*(c.p2.Z-c.p1.Z),
+	}
+	if d.LengthSq() == 0 {
+		return curveTangent(c.PointAt, t)
+	}
+	return *d.Normalize()
+}
+
+// Length satisfies the Curve interface.
+func (c *QuadraticBezier) Length() float32 {
+
+	return c.arc.length(c.PointAt)
+}
+
+// UniformParam satisfies the Curve interface.
+func (c *QuadraticBezier) UniformParam(u float32) float32 {
+
+	return c.arc.param(c.PointAt, u)
+}
+
+// CubicBezier is a cubic Bézier curve.
+type CubicBezier struct {
+	p0, p1, p2, p3 Vector3   // start, first control, second control and end points
+	arc            arcLength // arc length cache
+}
+
+// NewCubicBezier creates and returns a pointer to a new cubic Bézier curve
+// from the start point to the end point with the specified control points.
+func NewCubicBezier(start, control1, control2, end *Vector3) *CubicBezier {
+
+	return &CubicBezier{p0: *start, p1: *control1, p2: *control2, p3: *end}
+}
+
+// PointAt satisfies the Curve interface.
+func (c *CubicBezier) PointAt(t float32) Vector3 {
+
+	it := 1 - t
+	k0, k1, k2, k3 := it*it*it, 3*it*it*t, 3*it*t*t, t*t*t
+	return Vector3{
+		k0*c.p0.X + k1*c.p1.X + k2*c.p2.X + k3*c.p3.X,
+		k0*c.p0.Y + k1*c.p1.Y + k2*c.p2.Y + k3*c.p3.Y,
+		k0*c.p0.Z + k1*c.p1.Z + k2*c.p2.Z + k3*c.p3.Z,
+	}
+}
+
+// TangentAt satisfies the Curve interface.
+func (c *CubicBezier) TangentAt(t float32) Vector3 {
+
+	// Derivative: 3(1-t)^2(p1-p0) + 6(1-t)t(p2-p1) + 3t^2(p3-p2)
+	it := 1 - t
+	k0, k1, k2 := 3*it*it, 6*it*t, 3*t*t
+	d := Vector3{
+		k0*(c.p1.X-c.p0.X) + k1*(c.p2.X-c.p1.X) + k2*(c.p3.X-c.p2.X),
+		k0*(c.p1.Y-c.p0.Y) + k1*(c.p2.Y-c.p1.Y) + k2*(c.p3.Y-c.p2.Y),
+		k0*(c.p1.Z-c.p0.Z) + k1*(c.p2.Z-c.p1.Z) + k2*(c.p3.Z-c.p2.Z),
+	}
+	if d.LengthSq() == 0 {
+		return curveTangent(c.PointAt, t)
+	}
+	return *d.Normalize()
+}
+
+// Length satisfies the Curve interface.
+func (c *CubicBezier) Length() float32 {
+
+	return c.arc.length(c.PointAt)
+}
+
+// UniformParam satisfies the Curve interface.
+func (c *CubicBezier) UniformParam(u float32) float32 {
+
+	return c.arc.param(c.PointAt, u)
+}
@@ -0,0 +1,130 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// Catmull-Rom parameterizations
+const (
+	CatmullRomUniform     = 0   // Uniform parameterization
+	CatmullRomCentripetal = 0.5 // Centripetal parameterization, which avoids cusps and self intersections
+	CatmullRomChordal     = 1   // Chordal parameterization
+)
+
+// CatmullRom is a Catmull-Rom spline curve which passes through all its points.
+type CatmullRom struct {
+	points []Vector3 // points of the curve
+	closed bool      // whether the curve is a loop
+	alpha  float32   // parameterization exponent
+	arc    arcLength // arc length cache
+}
+
+// NewCatmullRom creates and returns a pointer to a new Catmull-Rom curve through the specified
+// points, which is a loop if closed is true. Alpha is the exponent of the parameterization
+// (CatmullRomUniform, CatmullRomCentripetal or CatmullRomChordal).
+func NewCatmullRom(points []Vector3, closed bool, alpha float32) *CatmullRom {
+
+	c := new(CatmullRom)
+	c.points = make([]Vector3, len(points))
+	copy(c.points, points)
+	c.closed = closed
+	c.alpha = alpha
+	return c
+}
+
+// Points returns the points of the curve.
+func (c *CatmullRom) Points() []Vector3 {
+
+	return c.points
+}
+
+// PointAt satisfies the Curve interface.
+func (c *CatmullRom) PointAt(t float32) Vector3 {
+
+	l := len(c.points)
+	if l == 0 {
+		return Vector3{}
+	}
+	if l == 1 {
+		return c.points[0]
+	}
+
+	// Finds the segment and the weight in the segment
+	segments := l - 1
+	if c.closed {
+		segments = l
+	}
+	p := float32(segments) * Clamp(t, 0, 1)
+	seg := int(Floor(p))
+	weight := p - float32(seg)
+	if seg >= segments {
+		seg = segments - 1
+		weight = 1
+	}
+
+	// Gets the four control points, extrapolating the ends of open curves
+	p1 := c.points[seg%l]
+	p2 := c.points[(seg+1)%l]
+	var p0, p3 Vector3
+	if c.closed || seg > 0 {
+		p0 = c.points[(seg+l-1)%l]
+	} else {
+		p0.SubVectors(&c.points[0], &c.points[1]).Add(&c.points[0])
+	}
+	if c.closed || seg+2 < l {
+		p3 = c.points[(seg+2)%l]
+	} else {
+		p3.SubVectors(&c.points[l-1], &c.points[l-2]).Add(&c.points[l-1])
+	}
+
+	// Computes the knot intervals of the parameterization
+	dt0 := Pow(p0.DistanceToSquared(&p1), c.alpha/2)
+	dt1 := Pow(p1.DistanceToSquared(&p2), c.alpha/2)
+	dt2 := Pow(p2.DistanceToSquared(&p3), c.alpha/2)
+	if dt1 < 1e-4 {
+		dt1 = 1
+	}
+	if dt0 < 1e-4 {
+		dt0 = dt1
+	}
+	if dt2 < 1e-4 {
+		dt2 = dt1
+	}
+	return Vector3{
+		nonUniformCatmullRom(p0.X, p1.X, p2.X, p3.X, dt0, dt1, dt2, weight),
+		nonUniformCatmullRom(p0.Y, p1.Y, p2.Y, p3.Y, dt0, dt1, dt2, weight),
+		nonUniformCatmullRom(p0.Z, p1.Z, p2.Z, p3.Z, dt0, dt1, dt2, weight),
+	}
+}
+
+// TangentAt satisfies the Curve interface.
+func (c *CatmullRom) TangentAt(t float32) Vector3 {
+
+	return curveTangent(c.PointAt, t)
+}
+
+// Length satisfies the Curve interface.
+func (c *CatmullRom) Length() float32 {
+
+	return c.arc.length(c.PointAt)
+}
+
+// UniformParam satisfies the Curve interface.
+func (c *CatmullRom) UniformParam(u float32) float32 {
+
+	return c.arc.param(c.PointAt, u)
+}
+
+// nonUniformCatmullRom interpolates between x1 and x2 at the weight t with
+// a Catmull-Rom spline with the specified knot intervals.
+func nonUniformCatmullRom(x0, x1, x2, x3, dt0, dt1, dt2, t float32) float32 {
+
+	// Tangents at x1 and x2 scaled to the [0, 1] interval
+	t1 := ((x1-x0)/dt0 - (x2-x0)/(dt0+dt1) + (x2-x1)/dt1) * dt1
+	t2 := ((x2-x1)/dt1 - (x3-x1)/(dt1+dt2) + (x3-x2)/dt2) * dt1
+
+	// Cubic Hermite polynomial
+	c2 := -3*x1 + 3*x2 - 2*t1 - t2
+	c3 := 2*x1 - 2*x2 + t1 + t2
+	return x1 + t1*t + c2*t*t + c3*t*t*t
+}
@@ -0,0 +1,149 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// Curve is the interface for parametric 3D curves with the parameter t in the range [0, 1].
+type Curve interface {
+	PointAt(t float32) Vector3      // Point of the curve at t
+	TangentAt(t float32) Vector3    // Unit tangent of the curve at t
+	Length() float32                // Approximate length of the curve
+	UniformParam(u float32) float32 // Parameter t at the fraction u of the curve length
+}
+
+// arcLengthDivisions is the number of samples used to compute the arc length of curves.
+const arcLengthDivisions = 200
+
+// arcLength caches the cumulative arc lengths of a curve at uniformly spaced parameters.
+type arcLength struct {
+	lengths []float32
+}
+
+// table returns the cumulative arc lengths of the curve with the specified point function,
+// computing them on the first call.
+func (a *arcLength) table(point func(t float32) Vector3) []float32 {
+
+	if a.lengths != nil {
+		return a.lengths
+	}
+	a.lengths = make([]float32, arcLengthDivisions+1)
+	prev := point(0)
+	for i := 1; i <= arcLengthDivisions; i++ {
+		p := point(float32(i) / arcLengthDivisions)
+		a.lengths[i] = a.lengths[i-1] + p.DistanceTo(&prev)
+		prev = p
+	}
+	return a.lengths
+}
+
+// length returns the total arc length of the curve with the specified point function.
+func (a *arcLength) length(point func(t float32) Vector3) float32 {
+
+	lengths := a.table(point)
+	return lengths[len(lengths)-1]
+}
+
+// param returns the parameter at the fraction u of the arc length of the curve
+// with the specified point function.
+func (a *arcLength) param(point func(t float32) Vector3, u float32) float32 {
+
+	lengths := a.table(point)
+	target := Clamp(u, 0, 1) * lengths[len(lengths)-1]
+
+	// Binary search of the last sample with length not greater than the target
+	lo, hi := 0, len(lengths)-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if lengths[mid] <= target {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	if lo == len(lengths)-1 {
+		return 1
+	}
+	seg := lengths[lo+1] - lengths[lo]
+	frac := float32(0)
+	if seg > 0 {
+		frac = (target - lengths[lo]) / seg
+	}
+	return (float32(lo) + frac) / arcLengthDivisions
+}
+
+// curveTangent returns the unit tangent at t of the curve with the
+// specified point function computed by finite differences.
+func curveTangent(point func(t float32) Vector3, t float32) Vector3 {
+
+	const delta = 1e-4
+	p0 := point(Max(t-delta, 0))
+	p1 := point(Min(t+delta, 1))
+	p1.Sub(&p0).Normalize()
+	return p1
+}
+
+// UniformPoints returns the specified number of divisions plus one points
+// of the curve spaced at equal distances along the curve.
+func UniformPoints(c Curve, divisions int) []Vector3 {
+
+	points := make([]Vector3, divisions+1)
+	for i := range points {
+		points[i] = c.PointAt(c.UniformParam(float32(i) / float32(divisions)))
+	}
+	return points
+}
+
+// FrenetFrames returns the unit tangents, normals and binormals of the curve at the specified
+// number of segments plus one uniformly spaced parameters. The frames are computed by parallel
+// transport of the first one, which doesn't twist as true Frenet frames do at inflection points.
+// If closed is true the frames are progressively twisted so the last one matches the first.
+func FrenetFrames(c Curve, segments int, closed bool) (tangents, normals, binormals []Vector3) {
+
+	n := segments + 1
+	tangents = make([]Vector3, n)
+	normals = make([]Vector3, n)
+	binormals = make([]Vector3, n)
+	for i := range tangents {
+		tangents[i] = c.TangentAt(float32(i) / float32(segments))
+	}
+
+	// Initial normal orthogonal to the first tangent, away from its largest component
+	t0 := &tangents[0]
+	if Abs(t0.X) > 0.9 {
+		normals[0].Set(0, 1, 0)
+	} else {
+		normals[0].Set(1, 0, 0)
+	}
+	normals[0].Sub(t0.Clone().MultiplyScalar(t0.Dot(&normals[0]))).Normalize()
+	binormals[0].CrossVectors(t0, &normals[0])
+
+	var axis Vector3
+	var q Quaternion
+	for i := 1; i < n; i++ {
+		normals[i] = normals[i-1]
+		axis.CrossVectors(&tangents[i-1], &tangents[i])
+		if axis.Length() > 1e-6 {
+			axis.Normalize()
+			angle := Acos(Clamp(tangents[i-1].Dot(&tangents[i]), -1, 1))
+			q.SetFromAxisAngle(&axis, angle)
+			normals[i].ApplyQuaternion(&q)
+		}
+		binormals[i].CrossVectors(&tangents[i], &normals[i])
+	}
+
+	// Distributes the twist between the last and first frames along the curve
+	if closed && n > 1 {
+		theta := Acos(Clamp(normals[0].Dot(&normals[n-1]), -1, 1)) / float32(n-1)
+		axis.CrossVectors(&normals[0], &normals[n-1])
+		if tangents[0].Dot(&axis) > 0 {
+			theta = -theta
+		}
+		for i := 1; i < n; i++ {
+			q.SetFromAxisAngle(&tangents[i], theta*float32(i))
+			normals[i].ApplyQuaternion(&q)
+			binormals[i].CrossVectors(&tangents[i], &normals[i])
+		}
+	}
+	return tangents, normals, binormals
+}
@@ -0,0 +1,130 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// NURBS is a non-uniform rational B-spline curve.
+type NURBS struct {
+	degree  int       // degree of the basis functions
+	knots   []float32 // knot vector with len(points)+degree+1 values
+	points  []Vector3 // control points
+	weights []float32 // weights of the control points
+	arc     arcLength // arc length cache
+}
+
+// NewNURBS creates and returns a pointer to a new NURBS curve with the specified degree,
+// knot vector, control points and weights. The knot vector must have len(points)+degree+1
+// non decreasing values. If weights is nil all the weights are 1 which makes a B-spline.
+func NewNURBS(degree int, knots []float32, points []Vector3, weights []float32) *NURBS {
+
+	if degree < 1 || len(points) <= degree || len(knots) != len(points)+degree+1 {
+		panic("Invalid NURBS: the knot vector must have len(points)+degree+1 values")
+	}
+	if weights != nil && len(weights) != len(points) {
+		panic("Invalid NURBS: there must be one weight per control point")
+	}
+	c := new(NURBS)
+	c.degree = degree
+	c.knots = append([]float32(nil), knots...)
+	c.points = append([]Vector3(nil), points...)
+	c.weights = make([]float32, len(points))
+	for i := range c.weights {
+		c.weights[i] = 1
+		if weights != nil {
+			c.weights[i] = weights[i]
+		}
+	}
+	return c
+}
+
+// NewBSpline creates and returns a pointer to a new uniform B-spline curve with the specified
+// control points and degree. An open curve starts and ends at its first and last points, and
+// a closed curve is a smooth loop. There must be more control points than the degree.
+func NewBSpline(points []Vector3, degree int, closed bool) *NURBS {
+
+	if closed {
+		// Repeats the first points and uses a uniform knot vector
+		wrapped := append(append([]Vector3(nil), points...), points[:degree]...)
+		knots := make([]float32, len(wrapped)+degree+1)
+		for i := range knots {
+			knots[i] = float32(i)
+		}
+		return NewNURBS(degree, knots, wrapped, nil)
+	}
+
+	// Clamped uniform knot vector
+	n := len(points)
+	knots := make([]float32, n+degree+1)
+	for i := range knots {
+		switch {
+		case i <= degree:
+			knots[i] = 0
+		case i >= n:
+			knots[i] = float32(n - degree)
+		default:
+			knots[i] = float32(i - degree)
+		}
+	}
+	return NewNURBS(degree, knots, points, nil)
+}
+
+// PointAt satisfies the Curve interface.
+func (c *NURBS) PointAt(t float32) Vector3 {
+
+	p := c.degree
+	n := len(c.points)
+	lo, hi := c.knots[p], c.knots[n]
+	u := lo + Clamp(t, 0, 1)*(hi-lo)
+
+	// Finds the knot span containing u
+	k := p
+	for k < n-1 && c.knots[k+1] <= u {
+		k++
+	}
+
+	// De Boor's algorithm on the homogeneous control points
+	d := make([]Vector4, p+1)
+	for j := 0; j <= p; j++ {
+		cp := &c.points[k-p+j]
+		w := c.weights[k-p+j]
+		d[j] = Vector4{cp.X * w, cp.Y * w, cp.Z * w, w}
+	}
+	for r := 1; r <= p; r++ {
+		for j := p; j >= r; j-- {
+			i := k - p + j
+			den := c.knots[i+p-r+1] - c.knots[i]
+			alpha := float32(0)
+			if den != 0 {
+				alpha = (u - c.knots[i]) / den
+			}
+			d[j].X = (1-alpha)*d[j-1].X + alpha*d[j].X
+			d[j].Y = (1-alpha)*d[j-1].Y + alpha*d[j].Y
+			d[j].Z = (1-alpha)*d[j-1].Z + alpha*d[j].Z
+			d[j].W = (1-alpha)*d[j-1].W + alpha*d[j].W
+		}
+	}
+	r := &d[p]
+	if r.W == 0 {
+		return Vector3{r.X, r.Y, r.Z}
+	}
+	return Vector3{r.X / r.W, r.Y / r.W, r.Z / r.W}
+}
+
+// TangentAt satisfies the Curve interface.
+func (c *NURBS) TangentAt(t float32) Vector3 {
+
+	return curveTangent(c.PointAt, t)
+}
+
+// Length satisfies the Curve interface.
+func (c *NURBS) Length() float32 {
+
+	return c.arc.length(c.PointAt)
+}
+
+// UniformParam satisfies the Curve interface.
+func (c *NURBS) UniformParam(u float32) float32 {
+
+	return c.arc.param(c.PointAt, u)
+}
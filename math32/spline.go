@@ -8,8 +8,11 @@ import (
 //"math"
 )
 
+// Spline is a uniform Catmull-Rom spline through a list of points.
+// See CatmullRom for closed curves and other parameterizations.
 type Spline struct {
 	points []Vector3
+	arc    arcLength // arc length cache
 }
 
 func NewSpline(points []Vector3) *Spline {
@@ -28,3 +31,59 @@ func (this *Spline) InitFromArray(a []float32) {
 	//    this.points[ i ] = { x: a[ i ][ 0 ], y: a[ i ][ 1 ], z: a[ i ][ 2 ] };
 	//}
 }
+
+// PointAt satisfies the Curve interface.
+func (this *Spline) PointAt(t float32) Vector3 {
+
+	n := len(this.points)
+	if n == 0 {
+		return Vector3{}
+	}
+	if n == 1 {
+		return this.points[0]
+	}
+	point := float32(n-1) * Clamp(t, 0, 1)
+	intPoint := int(Floor(point))
+	if intPoint > n-2 {
+		intPoint = n - 2
+	}
+	weight := point - float32(intPoint)
+
+	p0 := &this.points[ClampInt(intPoint-1, 0, n-1)]
+	p1 := &this.points[intPoint]
+	p2 := &this.points[ClampInt(intPoint+1, 0, n-1)]
+	p3 := &this.points[ClampInt(intPoint+2, 0, n-1)]
+	return Vector3{
+		catmullRom(p0.X, p1.X, p2.X, p3.X, weight),
+		catmullRom(p0.Y, p1.Y, p2.Y, p3.Y, weight),
+		catmullRom(p0.Z, p1.Z, p2.Z, p3.Z, weight),
+	}
+}
+
+// TangentAt satisfies the Curve interface.
+func (this *Spline) TangentAt(t float32) Vector3 {
+
+	return curveTangent(this.PointAt, t)
+}
+
+// Length satisfies the Curve interface.
+func (this *Spline) Length() float32 {
+
+	return this.arc.length(this.PointAt)
+}
+
+// UniformParam satisfies the Curve interface.
+func (this *Spline) UniformParam(u float32) float32 {
+
+	return this.arc.param(this.PointAt, u)
+}
+
+// catmullRom interpolates between p1 and p2 with a uniform Catmull-Rom spline.
+func catmullRom(p0, p1, p2, p3, t float32) float32 {
+
+	v0 := (p2 - p0) * 0.5
+	v1 := (p3 - p1) * 0.5
+	t2 := t * t
+	t3 := t * t2
+	return (2*p1-2*p2+v0+v1)*t3 + (-3*p1+3*p2-2*v0-v1)*t2 + v0*t + p1
+}
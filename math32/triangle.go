@@ -100,6 +100,60 @@ func ContainsPoint(point, a, b, c *Vector3) bool {
 	return (result.X >= 0) && (result.Y >= 0) && ((result.X + result.Y) <= 1)
 }
 
+// ClosestPointToPoint returns the point of the triangle closest to the specified point.
+func ClosestPointToPoint(point, a, b, c, optionalTarget *Vector3) *Vector3 {
+
+	var result *Vector3
+	if optionalTarget != nil {
+		result = optionalTarget
+	} else {
+		result = NewVector3(0, 0, 0)
+	}
+
+	// Voronoi regions of the vertices, edges and face (Ericson, Real-Time Collision Detection)
+	var ab, ac, ap, bp, cp Vector3
+	ab.SubVectors(b, a)
+	ac.SubVectors(c, a)
+	ap.SubVectors(point, a)
+	d1 := ab.Dot(&ap)
+	d2 := ac.Dot(&ap)
+	if d1 <= 0 && d2 <= 0 {
+		return result.Copy(a)
+	}
+	bp.SubVectors(point, b)
+	d3 := ab.Dot(&bp)
+	d4 := ac.Dot(&bp)
+	if d3 >= 0 && d4 <= d3 {
+		return result.Copy(b)
+	}
+	vc := d1*d4 - d3*d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		v := d1 / (d1 - d3)
+		return result.Copy(&ab).MultiplyScalar(v).Add(a)
+	}
+	cp.SubVectors(point, c)
+	d5 := ab.Dot(&cp)
+	d6 := ac.Dot(&cp)
+	if d6 >= 0 && d5 <= d6 {
+		return result.Copy(c)
+	}
+	vb := d5*d2 - d1*d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		w := d2 / (d2 - d6)
+		return result.Copy(&ac).MultiplyScalar(w).Add(a)
+	}
+	va := d3*d6 - d5*d4
+	if va <= 0 && (d4-d3) >= 0 && (d5-d6) >= 0 {
+		w := (d4 - d3) / ((d4 - d3) + (d5 - d6))
+		return result.SubVectors(c, b).MultiplyScalar(w).Add(b)
+	}
+	denom := 1 / (va + vb + vc)
+	v := vb * denom
+	w := vc * denom
+	ac.MultiplyScalar(w)
+	return result.Copy(&ab).MultiplyScalar(v).Add(&ac).Add(a)
+}
+
 // Set sets the triangle's three vertices.
 func (t *Triangle) Set(a, b, c *Vector3) *Triangle {
 
@@ -172,6 +226,12 @@ func (t *Triangle) BarycoordFromPoint(point, optionalTarget *Vector3) *Vector3 {
 	return BarycoordFromPoint(point, &t.a, &t.b, &t.c, optionalTarget)
 }
 
+// ClosestPointToPoint returns the point of the triangle closest to the specified point.
+func (t *Triangle) ClosestPointToPoint(point, optionalTarget *Vector3) *Vector3 {
+
+	return ClosestPointToPoint(point, &t.a, &t.b, &t.c, optionalTarget)
+}
+
 // ContainsPoint returns whether the triangle contains a point.
 func (t *Triangle) ContainsPoint(point *Vector3) bool {
 
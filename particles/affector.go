@@ -0,0 +1,105 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package particles
+
+import (
+	"math"
+
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture/procedural"
+)
+
+// IAffector is the interface for the forces and other changes applied
+// to the live particles of a system at each step of a CPU simulation.
+// Affect may be called concurrently for different particles.
+type IAffector interface {
+	Affect(p *Particle, dt float32)
+}
+
+// Gravity is an affector which accelerates the particles.
+type Gravity struct {
+	Acceleration math32.Vector3 // Acceleration in world units per second squared
+}
+
+// NewGravity creates and returns a pointer to a new gravity affector with the specified acceleration.
+func NewGravity(acceleration *math32.Vector3) *Gravity {
+
+	return &Gravity{*acceleration}
+}
+
+// Affect satisfies the IAffector interface.
+func (a *Gravity) Affect(p *Particle, dt float32) {
+
+	p.Velocity.X += a.Acceleration.X * dt
+	p.Velocity.Y += a.Acceleration.Y * dt
+	p.Velocity.Z += a.Acceleration.Z * dt
+}
+
+// Drag is an affector which slows down the particles proportionally to their velocity.
+type Drag struct {
+	Coefficient float32 // Fraction of the velocity lost per second
+}
+
+// NewDrag creates and returns a pointer to a new drag affector with the specified coefficient.
+func NewDrag(coefficient float32) *Drag {
+
+	return &Drag{coefficient}
+}
+
+// Affect satisfies the IAffector interface.
+func (a *Drag) Affect(p *Particle, dt float32) {
+
+	p.Velocity.MultiplyScalar(float32(math.Exp(float64(-a.Coefficient * dt))))
+}
+
+// Velocity is an affector which moves the particles with a constant velocity
+// in addition to their own, such as wind.
+type Velocity struct {
+	Velocity math32.Vector3 // Velocity in world units per second
+}
+
+// NewVelocity creates and returns a pointer to a new velocity affector with the specified velocity.
+func NewVelocity(velocity *math32.Vector3) *Velocity {
+
+	return &Velocity{*velocity}
+}
+
+// Affect satisfies the IAffector interface.
+func (a *Velocity) Affect(p *Particle, dt float32) {
+
+	p.Position.X += a.Velocity.X * dt
+	p.Position.Y += a.Velocity.Y * dt
+	p.Position.Z += a.Velocity.Z * dt
+}
+
+// Turbulence is an affector which pushes the particles along a noise field
+// changing over space and with the age of the particles.
+type Turbulence struct {
+	Strength  float32 // Acceleration in world units per second squared
+	Frequency float32 // Spatial frequency of the noise
+	Speed     float32 // Rate of change of the noise with the age
+	noise     *procedural.Noise
+}
+
+// NewTurbulence creates and returns a pointer to a new turbulence affector with
+// the specified strength, spatial frequency and rate of change with the age of the particles.
+func NewTurbulence(strength, frequency, speed float32) *Turbulence {
+
+	return &Turbulence{strength, frequency, speed, procedural.NewNoise(0)}
+}
+
+// Affect satisfies the IAffector interface.
+func (a *Turbulence) Affect(p *Particle, dt float32) {
+
+	// Samples the noise at offset positions for each component of the force
+	x := p.Position.X * a.Frequency
+	y := p.Position.Y * a.Frequency
+	z := p.Position.Z * a.Frequency
+	t := p.Age * a.Speed
+	s := a.Strength * dt
+	p.Velocity.X += a.noise.Simplex3(x+t, y, z) * s
+	p.Velocity.Y += a.noise.Simplex3(x+31.4, y+t, z) * s
+	p.Velocity.Z += a.noise.Simplex3(x, y+47.2, z+t) * s
+}
@@ -0,0 +1,93 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package particles
+
+import (
+	"sort"
+
+	"github.com/g3n/engine/math32"
+)
+
+// CurveKey is a key of a curve with its value at the specified normalized lifetime.
+type CurveKey struct {
+	Time  float32 // Normalized lifetime from 0 to 1
+	Value float32 // Value at the time
+}
+
+// Curve is a scalar value over the normalized lifetime of the particles,
+// linearly interpolated between its keys.
+type Curve struct {
+	keys []CurveKey
+}
+
+// NewCurve creates and returns a pointer to a new curve with the specified keys.
+func NewCurve(keys ...CurveKey) *Curve {
+
+	c := new(Curve)
+	c.keys = append([]CurveKey(nil), keys...)
+	sort.Slice(c.keys, func(i, j int) bool { return c.keys[i].Time < c.keys[j].Time })
+	return c
+}
+
+// Value returns the value of the curve at the specified normalized lifetime.
+// A curve without keys has the value 1.
+func (c *Curve) Value(t float32) float32 {
+
+	if len(c.keys) == 0 {
+		return 1
+	}
+	if t <= c.keys[0].Time {
+		return c.keys[0].Value
+	}
+	for i := 1; i < len(c.keys); i++ {
+		if t < c.keys[i].Time {
+			k0, k1 := c.keys[i-1], c.keys[i]
+			return k0.Value + (k1.Value-k0.Value)*(t-k0.Time)/(k1.Time-k0.Time)
+		}
+	}
+	return c.keys[len(c.keys)-1].Value
+}
+
+// ColorKey is a key of a color curve with its color at the specified normalized lifetime.
+type ColorKey struct {
+	Time  float32      // Normalized lifetime from 0 to 1
+	Color math32.Color // Color at the time
+}
+
+// ColorCurve is a color over the normalized lifetime of the particles,
+// linearly interpolated between its keys.
+type ColorCurve struct {
+	keys []ColorKey
+}
+
+// NewColorCurve creates and returns a pointer to a new color curve with the specified keys.
+func NewColorCurve(keys ...ColorKey) *ColorCurve {
+
+	c := new(ColorCurve)
+	c.keys = append([]ColorKey(nil), keys...)
+	sort.Slice(c.keys, func(i, j int) bool { return c.keys[i].Time < c.keys[j].Time })
+	return c
+}
+
+// Value returns the color of the curve at the specified normalized lifetime.
+// A curve without keys is white.
+func (c *ColorCurve) Value(t float32) math32.Color {
+
+	if len(c.keys) == 0 {
+		return math32.Color{1, 1, 1}
+	}
+	if t <= c.keys[0].Time {
+		return c.keys[0].Color
+	}
+	for i := 1; i < len(c.keys); i++ {
+		if t < c.keys[i].Time {
+			k0, k1 := c.keys[i-1], c.keys[i]
+			color := k0.Color
+			color.Lerp(&k1.Color, (t-k0.Time)/(k1.Time-k0.Time))
+			return color
+		}
+	}
+	return c.keys[len(c.keys)-1].Color
+}
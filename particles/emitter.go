@@ -0,0 +1,175 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package particles
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/math32"
+)
+
+// IEmitter is the interface for the shapes from which particles are emitted.
+// Emit returns the position and the unit direction of a new particle in the
+// local coordinates of the particle system.
+type IEmitter interface {
+	Emit(rnd *rand.Rand) (position, direction math32.Vector3)
+}
+
+// randomDirection returns a random unit vector uniformly distributed over the sphere.
+func randomDirection(rnd *rand.Rand) math32.Vector3 {
+
+	z := rnd.Float32()*2 - 1
+	a := rnd.Float32() * 2 * math32.Pi
+	r := math32.Sqrt(1 - z*z)
+	return math32.Vector3{r * math32.Cos(a), r * math32.Sin(a), z}
+}
+
+// PointEmitter emits particles from its origin in random directions.
+type PointEmitter struct{}
+
+// NewPointEmitter creates and returns a pointer to a new point emitter.
+func NewPointEmitter() *PointEmitter {
+
+	return new(PointEmitter)
+}
+
+// Emit satisfies the IEmitter interface.
+func (e *PointEmitter) Emit(rnd *rand.Rand) (math32.Vector3, math32.Vector3) {
+
+	return math32.Vector3{}, randomDirection(rnd)
+}
+
+// BoxEmitter emits particles from random points inside a box centered at its origin,
+// moving up the Y axis.
+type BoxEmitter struct {
+	Size math32.Vector3 // Size of the box
+}
+
+// NewBoxEmitter creates and returns a pointer to a new box emitter with the specified size.
+func NewBoxEmitter(width, height, length float32) *BoxEmitter {
+
+	return &BoxEmitter{math32.Vector3{width, height, length}}
+}
+
+// Emit satisfies the IEmitter interface.
+func (e *BoxEmitter) Emit(rnd *rand.Rand) (math32.Vector3, math32.Vector3) {
+
+	position := math32.Vector3{
+		(rnd.Float32() - 0.5) * e.Size.X,
+		(rnd.Float32() - 0.5) * e.Size.Y,
+		(rnd.Float32() - 0.5) * e.Size.Z,
+	}
+	return position, math32.Vector3{0, 1, 0}
+}
+
+// SphereEmitter emits particles from random points inside a sphere centered at its origin,
+// or on its surface only, moving away from its center.
+type SphereEmitter struct {
+	Radius  float32 // Radius of the sphere
+	Surface bool    // Emit from the surface only
+}
+
+// NewSphereEmitter creates and returns a pointer to a new sphere emitter with the specified
+// radius, which emits from the surface of the sphere only or from its whole volume.
+func NewSphereEmitter(radius float32, surface bool) *SphereEmitter {
+
+	return &SphereEmitter{radius, surface}
+}
+
+// Emit satisfies the IEmitter interface.
+func (e *SphereEmitter) Emit(rnd *rand.Rand) (math32.Vector3, math32.Vector3) {
+
+	direction := randomDirection(rnd)
+	r := e.Radius
+	if !e.Surface {
+		r *= math32.Pow(rnd.Float32(), 1.0/3)
+	}
+	position := direction
+	position.MultiplyScalar(r)
+	return position, direction
+}
+
+// ConeEmitter emits particles from random points of a disk centered at its origin
+// in the XZ plane, in random directions within a cone around the Y axis.
+type ConeEmitter struct {
+	Angle  float32 // Angle between the axis and the side of the cone in radians
+	Radius float32 // Radius of the base of the cone
+}
+
+// NewConeEmitter creates and returns a pointer to a new cone emitter with the specified
+// angle between its axis and side in radians and radius of its base.
+func NewConeEmitter(angle, radius float32) *ConeEmitter {
+
+	return &ConeEmitter{angle, radius}
+}
+
+// Emit satisfies the IEmitter interface.
+func (e *ConeEmitter) Emit(rnd *rand.Rand) (math32.Vector3, math32.Vector3) {
+
+	// Uniformly distributed over the base and over the spherical cap of the directions
+	a := rnd.Float32() * 2 * math32.Pi
+	r := e.Radius * math32.Sqrt(rnd.Float32())
+	position := math32.Vector3{r * math32.Cos(a), 0, r * math32.Sin(a)}
+	cosAngle := 1 - rnd.Float32()*(1-math32.Cos(e.Angle))
+	sinAngle := math32.Sqrt(1 - cosAngle*cosAngle)
+	b := rnd.Float32() * 2 * math32.Pi
+	return position, math32.Vector3{sinAngle * math32.Cos(b), cosAngle, sinAngle * math32.Sin(b)}
+}
+
+// MeshEmitter emits particles from random points on the surface of a geometry,
+// uniformly distributed by area, moving along the normals of its triangles.
+type MeshEmitter struct {
+	triangles []math32.Vector3 // Vertices of the triangles
+	areas     []float32        // Cumulative areas of the triangles
+}
+
+// NewMeshEmitter creates and returns a pointer to a new mesh emitter for the surface of the specified geometry.
+func NewMeshEmitter(igeom geometry.IGeometry) *MeshEmitter {
+
+	e := new(MeshEmitter)
+	var total float32
+	igeom.GetGeometry().ReadFaces(func(a, b, c math32.Vector3) bool {
+		var e1, e2, n math32.Vector3
+		e1.SubVectors(&b, &a)
+		e2.SubVectors(&c, &a)
+		area := n.CrossVectors(&e1, &e2).Length() / 2
+		if area > 0 {
+			total += area
+			e.triangles = append(e.triangles, a, b, c)
+			e.areas = append(e.areas, total)
+		}
+		return false
+	})
+	return e
+}
+
+// Emit satisfies the IEmitter interface.
+func (e *MeshEmitter) Emit(rnd *rand.Rand) (math32.Vector3, math32.Vector3) {
+
+	if len(e.areas) == 0 {
+		return math32.Vector3{}, randomDirection(rnd)
+	}
+	target := rnd.Float32() * e.areas[len(e.areas)-1]
+	i := sort.Search(len(e.areas), func(i int) bool { return e.areas[i] >= target })
+	if i == len(e.areas) {
+		i--
+	}
+	a, b, c := e.triangles[3*i], e.triangles[3*i+1], e.triangles[3*i+2]
+
+	// Uniform barycentric coordinates reflecting the points outside of the triangle
+	u, v := rnd.Float32(), rnd.Float32()
+	if u+v > 1 {
+		u, v = 1-u, 1-v
+	}
+	var e1, e2, position, normal math32.Vector3
+	e1.SubVectors(&b, &a)
+	e2.SubVectors(&c, &a)
+	normal.CrossVectors(&e1, &e2).Normalize()
+	position = a
+	position.Add(e1.MultiplyScalar(u)).Add(e2.MultiplyScalar(v))
+	return position, normal
+}
@@ -0,0 +1,68 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package particles
+
+import (
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/texture"
+)
+
+// Material is the material of particle systems, which are unlit, transparent,
+// double sided and do not write to the depth buffer. Its optional texture may be
+// an atlas of animation frames configured in the particle system.
+type Material struct {
+	material.Material                    // Embedded material
+	tex               *texture.Texture2D // Optional particle texture
+}
+
+// NewMaterial creates and returns a pointer to a new particle material with the specified
+// texture, which may be nil, and normal blending.
+func NewMaterial(tex *texture.Texture2D) *Material {
+
+	m := new(Material)
+	m.Material.Init()
+	m.SetShader("particle")
+	m.SetUseLights(material.UseLightNone)
+	m.SetSide(material.SideDouble)
+	m.SetTransparent(true)
+	m.SetDepthMask(false)
+	m.SetBlending(material.BlendNormal)
+	m.SetTexture(tex)
+	return m
+}
+
+// SetTexture sets the particle texture. Setting nil removes it.
+func (m *Material) SetTexture(tex *texture.Texture2D) {
+
+	if m.tex != nil {
+		m.RemoveTexture(m.tex)
+	}
+	m.tex = tex
+	if tex != nil {
+		tex.SetUniformNames("uParticleSampler", "uParticleTexParams")
+		m.ShaderDefines.Set("HAS_PARTICLEMAP", "")
+		m.AddTexture(tex)
+	} else {
+		m.ShaderDefines.Unset("HAS_PARTICLEMAP")
+	}
+}
+
+// SetBlending sets the blending mode of the material.
+// Additive particles fade out in the scene fog instead of being tinted with its color.
+func (m *Material) SetBlending(blending material.Blending) {
+
+	m.Material.SetBlending(blending)
+	if blending == material.BlendAdditive {
+		m.ShaderDefines.Set("ADDITIVE_BLENDING", "")
+	} else {
+		m.ShaderDefines.Unset("ADDITIVE_BLENDING")
+	}
+}
+
+// Texture returns the particle texture or nil.
+func (m *Material) Texture() *texture.Texture2D {
+
+	return m.tex
+}
@@ -0,0 +1,685 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package particles implements particle systems with emitters of several shapes,
+// affectors and lifetime curves, simulated on the GPU or on the CPU and rendered
+// as billboarded or stretched quads with optional atlas animation and soft edges.
+package particles
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+// Simulation specifies where the particles are simulated.
+type Simulation int
+
+// The simulation types.
+const (
+	// SimulateGPU computes the motion of the particles in the vertex shader from their
+	// initial state, so the CPU only writes new particles. It supports only the Gravity,
+	// Drag, Velocity and Turbulence affectors, the last one approximated by a different
+	// noise, and falls back to the CPU simulation with any other affector.
+	SimulateGPU = Simulation(iota)
+	// SimulateCPU simulates the particles on the CPU with any affectors,
+	// writing all of them at each update.
+	SimulateCPU
+)
+
+// RenderMode specifies how the particles are rendered.
+type RenderMode int
+
+// The render modes.
+const (
+	Billboard = RenderMode(iota) // Quads facing the camera rotated by the particle rotation
+	Stretched                    // Quads facing the camera stretched along the particle velocity
+)
+
+// Particle is the state of a particle.
+type Particle struct {
+	Position        math32.Vector3 // Position in world coordinates
+	Velocity        math32.Vector3 // Velocity in world units per second
+	Age             float32        // Time since the particle was emitted in seconds
+	Lifetime        float32        // Time the particle lives in seconds
+	Size            float32        // Size scaled by the size curve
+	Rotation        float32        // Rotation around the view direction in radians
+	AngularVelocity float32        // Rotation speed in radians per second
+	Seed            float32        // Random value from 0 to 1
+	birth           float32        // System time of the emission
+	position0       math32.Vector3 // Position at the emission
+	velocity0       math32.Vector3 // Velocity at the emission
+	rotation0       float32        // Rotation at the emission
+}
+
+// Number of floats of each vertex: position(3), velocity(3), corner(2), params(4) and color(4)
+const vertexSize = 16
+
+// Number of samples of the lifetime curves sent to the GPU
+const curveSamples = 16
+
+// Minimum number of live particles to simulate them concurrently
+const concurrentCount = 4096
+
+// quadCorners are the corners of the quad of each particle.
+var quadCorners = [4][2]float32{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}}
+
+// System is a graphic which emits, simulates and renders particles.
+// Particles are emitted from the emitter in the local coordinates of the system and
+// then live in world coordinates, so moving the system leaves a trail behind it.
+// Update must be called at each frame to advance the simulation.
+type System struct {
+	graphic.Graphic                           // Embedded graphic
+	rnd             *rand.Rand                // Random number generator
+	emitter         IEmitter                  // Emitter of the particles
+	affectors       []IAffector               // Affectors of the CPU simulation
+	simulation      Simulation                // Requested simulation
+	gpu             bool                      // Whether the particles are simulated on the GPU
+	particles       []Particle                // Slots of the particles
+	live            []int                     // Slots of the live particles
+	free            []int                     // Free slots
+	keys            []float32                 // Sorting keys of the slots
+	sorted          []int                     // Buffer of the sorted live slots
+	time            float32                   // Total simulated time
+	emitting        bool                      // Whether new particles are emitted
+	rate            float32                   // Particles emitted per second
+	pending         float32                   // Particles waiting to be emitted
+	lifetime        [2]float32                // Range of the lifetimes
+	speed           [2]float32                // Range of the initial speeds
+	size            [2]float32                // Range of the sizes
+	rotation        [2]float32                // Range of the initial rotations
+	angular         [2]float32                // Range of the angular velocities
+	sizeCurve       *Curve                    // Optional size over the lifetime
+	colorCurve      *ColorCurve               // Optional color over the lifetime
+	opacityCurve    *Curve                    // Optional opacity over the lifetime
+	sorting         bool                      // Whether the particles are sorted back to front
+	softness        float32                   // Depth range over which the particles fade near surfaces
+	vbo             *gls.VBO                  // Vertex buffer of the quads
+	udata           [20]float32               // ParticleSystem uniform data (5 vec4)
+	colorData       [4 * curveSamples]float32 // ParticleColorCurve uniform data
+	sizeData        [curveSamples]float32     // ParticleSizeCurve uniform data
+	uniSystem       gls.Uniform               // ParticleSystem uniform location cache
+	uniColorCurve   gls.Uniform               // ParticleColorCurve uniform location cache
+	uniSizeCurve    gls.Uniform               // ParticleSizeCurve uniform location cache
+	uniView         gls.Uniform               // ViewMatrix uniform location cache
+	uniProj         gls.Uniform               // ProjMatrix uniform location cache
+	uniDepth        gls.Uniform               // uDepthSampler uniform location cache
+	gs              *gls.GLS                  // OpenGL state of the depth texture
+	depthTex        uint32                    // Depth texture for soft particles
+	depthWidth      int32                     // Width of the depth texture
+	depthHeight     int32                     // Height of the depth texture
+}
+
+// NewSystem creates and returns a pointer to a new particle system with the specified
+// maximum number of live particles, emitter and material.
+func NewSystem(maxParticles int, emitter IEmitter, mat *Material) *System {
+
+	s := new(System)
+	s.rnd = rand.New(rand.NewSource(1))
+	s.emitter = emitter
+	s.emitting = true
+	s.rate = 10
+	s.lifetime = [2]float32{1, 1}
+	s.speed = [2]float32{1, 1}
+	s.size = [2]float32{1, 1}
+	s.sorting = true
+	s.SetAtlas(1, 1, 1, 0)
+	s.particles = make([]Particle, maxParticles)
+	s.free = make([]int, maxParticles)
+	for i := range s.free {
+		s.free[i] = maxParticles - 1 - i
+	}
+	s.keys = make([]float32, maxParticles)
+
+	// Attributes have fixed locations in the shader so that both simulations use the same vertex array
+	s.vbo = gls.NewVBO(math32.NewArrayF32(maxParticles*4*vertexSize, maxParticles*4*vertexSize))
+	s.vbo.AddCustomAttrib("ParticlePosition", 3)
+	s.vbo.AddCustomAttrib("ParticleVelocity", 3)
+	s.vbo.AddCustomAttrib("ParticleCorner", 2)
+	s.vbo.AddCustomAttrib("ParticleParams", 4)
+	s.vbo.AddCustomAttrib("ParticleColor", 4)
+	s.vbo.SetUsage(gls.DYNAMIC_DRAW)
+	geom := geometry.NewGeometry()
+	geom.AddVBO(s.vbo)
+
+	s.Graphic.Init(s, geom, gls.TRIANGLES)
+	s.AddMaterial(s, mat, 0, 0)
+	s.SetCullable(false)
+	s.SetRenderable(false)
+	s.uniSystem.Init("ParticleSystem")
+	s.uniColorCurve.Init("ParticleColorCurve")
+	s.uniSizeCurve.Init("ParticleSizeCurve")
+	s.uniView.Init("ViewMatrix")
+	s.uniProj.Init("ProjMatrix")
+	s.uniDepth.Init("uDepthSampler")
+	return s
+}
+
+// SetEmitter sets the emitter of the particles.
+func (s *System) SetEmitter(emitter IEmitter) {
+
+	s.emitter = emitter
+}
+
+// Emitter returns the emitter of the particles.
+func (s *System) Emitter() IEmitter {
+
+	return s.emitter
+}
+
+// AddAffector adds an affector to the simulation.
+func (s *System) AddAffector(affector IAffector) {
+
+	s.affectors = append(s.affectors, affector)
+}
+
+// RemoveAffector removes the specified affector from the simulation.
+func (s *System) RemoveAffector(affector IAffector) {
+
+	for i, a := range s.affectors {
+		if a == affector {
+			s.affectors = append(s.affectors[:i], s.affectors[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetSimulation sets where the particles are simulated. The default is SimulateGPU.
+func (s *System) SetSimulation(simulation Simulation) {
+
+	s.simulation = simulation
+}
+
+// Simulation returns where the particles are currently simulated, which is on the CPU
+// if it was requested or if there are affectors which are not supported by the GPU.
+func (s *System) Simulation() Simulation {
+
+	if s.gpu {
+		return SimulateGPU
+	}
+	return SimulateCPU
+}
+
+// SetEmitting sets whether the system emits new particles at its rate.
+func (s *System) SetEmitting(state bool) {
+
+	s.emitting = state
+}
+
+// Emitting returns whether the system emits new particles at its rate.
+func (s *System) Emitting() bool {
+
+	return s.emitting
+}
+
+// SetRate sets the number of particles emitted per second. The default is 10.
+func (s *System) SetRate(rate float32) {
+
+	s.rate = rate
+}
+
+// Burst emits the specified number of particles at the next update.
+func (s *System) Burst(count int) {
+
+	s.pending += float32(count)
+}
+
+// SetLifetime sets the range of the lifetimes of new particles in seconds.
+func (s *System) SetLifetime(min, max float32) {
+
+	s.lifetime = [2]float32{min, max}
+}
+
+// SetSpeed sets the range of the initial speeds of new particles along the direction of the emitter.
+func (s *System) SetSpeed(min, max float32) {
+
+	s.speed = [2]float32{min, max}
+}
+
+// SetSize sets the range of the sizes of new particles, which are scaled by the size curve.
+func (s *System) SetSize(min, max float32) {
+
+	s.size = [2]float32{min, max}
+}
+
+// SetRotation sets the range of the initial rotations of new particles in radians.
+func (s *System) SetRotation(min, max float32) {
+
+	s.rotation = [2]float32{min, max}
+}
+
+// SetAngularVelocity sets the range of the angular velocities of new particles in radians per second.
+func (s *System) SetAngularVelocity(min, max float32) {
+
+	s.angular = [2]float32{min, max}
+}
+
+// SetSizeCurve sets the scale of the size of the particles over their lifetime, or nil for a constant size.
+func (s *System) SetSizeCurve(curve *Curve) {
+
+	s.sizeCurve = curve
+}
+
+// SetColorCurve sets the color of the particles over their lifetime, or nil for white.
+func (s *System) SetColorCurve(curve *ColorCurve) {
+
+	s.colorCurve = curve
+}
+
+// SetOpacityCurve sets the opacity of the particles over their lifetime, or nil for opaque.
+func (s *System) SetOpacityCurve(curve *Curve) {
+
+	s.opacityCurve = curve
+}
+
+// SetRenderMode sets how the particles are rendered and, for stretched particles,
+// the length added to their size per unit of speed.
+func (s *System) SetRenderMode(mode RenderMode, stretch float32) {
+
+	s.udata[11] = float32(mode)
+	s.udata[16] = stretch
+}
+
+// SetAtlas sets the number of columns and rows of the frames in the texture of the material,
+// the number of frames used, from left to right and top to bottom, and the frames per second
+// of the animation of the particles. With zero frames per second the frames are spread over
+// the lifetime of the particles, like the tiles of a texture.Animator.
+func (s *System) SetAtlas(columns, rows, frames int, fps float32) {
+
+	s.udata[12] = float32(columns)
+	s.udata[13] = float32(rows)
+	s.udata[14] = float32(frames)
+	s.udata[15] = fps
+}
+
+// SetSorting sets whether the particles are sorted back to front from the camera
+// position specified to Update, which alpha blending needs. The default is true.
+func (s *System) SetSorting(state bool) {
+
+	s.sorting = state
+}
+
+// SetSoftness sets the depth range over which the particles fade out in front of
+// the opaque surfaces behind them, avoiding hard edges where they intersect. Zero disables it.
+// Soft particles copy the depth buffer of the scene rendered before them to a texture,
+// which needs a perspective camera and is not supported by WebGL or multisampled framebuffers.
+func (s *System) SetSoftness(distance float32) {
+
+	s.softness = distance
+	s.udata[17] = distance
+	if distance > 0 {
+		s.ShaderDefines.Set("SOFT_PARTICLES", "")
+	} else {
+		s.ShaderDefines.Unset("SOFT_PARTICLES")
+	}
+}
+
+// Count returns the number of live particles.
+func (s *System) Count() int {
+
+	return len(s.live)
+}
+
+// Clear removes all the particles.
+func (s *System) Clear() {
+
+	for _, slot := range s.live {
+		s.free = append(s.free, slot)
+	}
+	s.live = s.live[:0]
+	s.pending = 0
+	s.SetRenderable(false)
+}
+
+// gpuAffectors returns the combined parameters of the affectors for the GPU simulation,
+// or false if any of them is not supported by the GPU.
+func (s *System) gpuAffectors() (gravity, velocity math32.Vector3, drag float32, turbulence *Turbulence, ok bool) {
+
+	for _, a := range s.affectors {
+		switch a := a.(type) {
+		case *Gravity:
+			gravity.Add(&a.Acceleration)
+		case *Drag:
+			drag += a.Coefficient
+		case *Velocity:
+			velocity.Add(&a.Velocity)
+		case *Turbulence:
+			if turbulence != nil {
+				return gravity, velocity, drag, nil, false
+			}
+			turbulence = a
+		default:
+			return gravity, velocity, drag, nil, false
+		}
+	}
+	return gravity, velocity, drag, turbulence, true
+}
+
+// random returns a random value in the specified range.
+func (s *System) random(r [2]float32) float32 {
+
+	return r[0] + (r[1]-r[0])*s.rnd.Float32()
+}
+
+// Update advances the simulation by the specified time in seconds, emitting new particles
+// and removing the expired ones. If the camera position in world coordinates is not nil
+// and sorting is enabled the particles are sorted back to front from it.
+func (s *System) Update(dt float32, camPos *math32.Vector3) {
+
+	gravity, velocity, drag, turbulence, ok := s.gpuAffectors()
+	gpu := ok && s.simulation == SimulateGPU
+	rewrite := gpu != s.gpu || !gpu
+	s.gpu = gpu
+	if gpu {
+		s.ShaderDefines.Set("GPU_SIMULATION", "")
+	} else {
+		s.ShaderDefines.Unset("GPU_SIMULATION")
+	}
+	s.time += dt
+
+	// Ages the particles, freeing the expired ones
+	live := s.live[:0]
+	for _, slot := range s.live {
+		p := &s.particles[slot]
+		p.Age += dt
+		if p.Age >= p.Lifetime {
+			s.free = append(s.free, slot)
+			continue
+		}
+		live = append(live, slot)
+	}
+	s.live = live
+
+	// Simulates the live particles on the CPU, concurrently if there are many
+	if !gpu {
+		s.parallel(func(slots []int) {
+			for _, slot := range slots {
+				p := &s.particles[slot]
+				for _, a := range s.affectors {
+					a.Affect(p, dt)
+				}
+				p.Position.X += p.Velocity.X * dt
+				p.Position.Y += p.Velocity.Y * dt
+				p.Position.Z += p.Velocity.Z * dt
+				p.Rotation += p.AngularVelocity * dt
+			}
+		})
+	}
+
+	// Emits the new particles from the emitter transformed to world coordinates
+	if s.emitting {
+		s.pending += s.rate * dt
+	}
+	if s.pending >= 1 && s.emitter != nil {
+		mw := s.MatrixWorld()
+		var rot math32.Matrix4
+		rot.ExtractRotation(&mw)
+		for ; s.pending >= 1 && len(s.free) > 0; s.pending-- {
+			slot := s.free[len(s.free)-1]
+			s.free = s.free[:len(s.free)-1]
+			position, direction := s.emitter.Emit(s.rnd)
+			position.ApplyMatrix4(&mw)
+			direction.ApplyMatrix4(&rot).Normalize()
+			p := &s.particles[slot]
+			p.Position = position
+			p.Velocity = *direction.MultiplyScalar(s.random(s.speed))
+			p.Age = 0
+			p.Lifetime = math32.Max(s.random(s.lifetime), 1e-3)
+			p.Size = s.random(s.size)
+			p.Rotation = s.random(s.rotation)
+			p.AngularVelocity = s.random(s.angular)
+			p.Seed = s.rnd.Float32()
+			p.birth = s.time
+			p.position0 = p.Position
+			p.velocity0 = p.Velocity
+			p.rotation0 = p.Rotation
+			s.live = append(s.live, slot)
+			if gpu && !rewrite {
+				s.writeParticle(slot)
+				s.vbo.UpdateRange(slot*4*vertexSize, 4*vertexSize)
+			}
+		}
+		// Drops the particles which do not fit
+		s.pending -= math32.Floor(s.pending)
+	}
+
+	// Writes the vertices of all the particles when simulated on the CPU or when the simulation changed
+	if rewrite {
+		s.parallel(func(slots []int) {
+			for _, slot := range slots {
+				s.writeParticle(slot)
+			}
+		})
+		s.vbo.Update()
+	}
+
+	// Sorts the particles back to front from the camera
+	if s.sorting && camPos != nil {
+		s.parallel(func(slots []int) {
+			for _, slot := range slots {
+				p := &s.particles[slot]
+				position := p.Position
+				if gpu {
+					position = gpuPosition(p, &gravity, &velocity, drag)
+				}
+				s.keys[slot] = position.DistanceToSquared(camPos)
+			}
+		})
+		s.sortLive()
+	}
+
+	// Indices of the quads of the live particles
+	geom := s.GetGeometry()
+	indices := geom.Indices()[:0]
+	for _, slot := range s.live {
+		v := uint32(slot * 4)
+		indices = append(indices, v, v+1, v+2, v, v+2, v+3)
+	}
+	geom.SetIndices(indices)
+	s.SetRenderable(len(s.live) > 0)
+
+	// Uniform data
+	s.udata[0], s.udata[1], s.udata[2], s.udata[3] = gravity.X, gravity.Y, gravity.Z, drag
+	s.udata[4], s.udata[5], s.udata[6], s.udata[7] = velocity.X, velocity.Y, velocity.Z, s.time
+	s.udata[8], s.udata[9], s.udata[10] = 0, 0, 0
+	if turbulence != nil {
+		s.udata[8], s.udata[9], s.udata[10] = turbulence.Strength, turbulence.Frequency, turbulence.Speed
+	}
+	for i := 0; i < curveSamples; i++ {
+		t := float32(i) / (curveSamples - 1)
+		color, opacity := s.curves(t)
+		s.colorData[4*i], s.colorData[4*i+1], s.colorData[4*i+2], s.colorData[4*i+3] = color.R, color.G, color.B, opacity
+		s.sizeData[i] = 1
+		if s.sizeCurve != nil {
+			s.sizeData[i] = s.sizeCurve.Value(t)
+		}
+	}
+}
+
+// sortLive sorts the live particles by decreasing key with a radix sort
+// of the bits of the keys, which are non negative so their bits have the same order.
+func (s *System) sortLive() {
+
+	n := len(s.live)
+	if cap(s.sorted) < n {
+		s.sorted = make([]int, n)
+	}
+	src, dst := s.live, s.sorted[:n]
+	for shift := uint(0); shift < 32; shift += 8 {
+		var counts [257]int
+		for _, slot := range src {
+			counts[255-(math.Float32bits(s.keys[slot])>>shift&0xFF)+1]++
+		}
+		for i := 1; i < len(counts); i++ {
+			counts[i] += counts[i-1]
+		}
+		for _, slot := range src {
+			digit := 255 - (math.Float32bits(s.keys[slot]) >> shift & 0xFF)
+			dst[counts[digit]] = slot
+			counts[digit]++
+		}
+		src, dst = dst, src
+	}
+	s.live, s.sorted = src, dst
+}
+
+// parallel calls the specified function for the live particles, split among goroutines if there are many.
+func (s *System) parallel(f func(slots []int)) {
+
+	if len(s.live) < concurrentCount {
+		f(s.live)
+		return
+	}
+	workers := runtime.NumCPU()
+	size := (len(s.live) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < len(s.live); start += size {
+		end := start + size
+		if end > len(s.live) {
+			end = len(s.live)
+		}
+		wg.Add(1)
+		go func(slots []int) {
+			defer wg.Done()
+			f(slots)
+		}(s.live[start:end])
+	}
+	wg.Wait()
+}
+
+// curves returns the color and opacity of the curves at the specified normalized lifetime.
+func (s *System) curves(t float32) (math32.Color, float32) {
+
+	color := math32.Color{1, 1, 1}
+	if s.colorCurve != nil {
+		color = s.colorCurve.Value(t)
+	}
+	var opacity float32 = 1
+	if s.opacityCurve != nil {
+		opacity = s.opacityCurve.Value(t)
+	}
+	return color, opacity
+}
+
+// gpuPosition returns the position of a particle simulated on the GPU, without turbulence.
+func gpuPosition(p *Particle, gravity, velocity *math32.Vector3, drag float32) math32.Vector3 {
+
+	age := p.Age
+	position := p.position0
+	if drag > 0 {
+		e := float32(math.Exp(float64(-drag * age)))
+		terminal := *gravity
+		terminal.DivideScalar(drag)
+		var v math32.Vector3
+		v.SubVectors(&p.velocity0, &terminal).MultiplyScalar((1 - e) / drag)
+		position.Add(terminal.MultiplyScalar(age)).Add(&v)
+	} else {
+		v := p.velocity0
+		g := *gravity
+		position.Add(v.MultiplyScalar(age)).Add(g.MultiplyScalar(age * age / 2))
+	}
+	v := *velocity
+	position.Add(v.MultiplyScalar(age))
+	return position
+}
+
+// writeParticle writes the vertices of the quad of the particle in the specified slot.
+// On the GPU the vertices have the initial state of the particle, and on the CPU its current state.
+func (s *System) writeParticle(slot int) {
+
+	p := &s.particles[slot]
+	var params, color [4]float32
+	position, velocity := p.Position, p.Velocity
+	if s.gpu {
+		position, velocity = p.position0, p.velocity0
+		params = [4]float32{p.birth, p.Lifetime, p.Size, p.AngularVelocity}
+		color = [4]float32{p.rotation0, p.Seed, 0, 0}
+	} else {
+		t := p.Age / p.Lifetime
+		size := p.Size
+		if s.sizeCurve != nil {
+			size *= s.sizeCurve.Value(t)
+		}
+		c, opacity := s.curves(t)
+		params = [4]float32{size, p.Rotation, s.frame(p.Age, t), 0}
+		color = [4]float32{c.R, c.G, c.B, opacity}
+	}
+	buffer := *s.vbo.Buffer()
+	for i, corner := range quadCorners {
+		v := buffer[(slot*4+i)*vertexSize : (slot*4+i+1)*vertexSize]
+		v[0], v[1], v[2] = position.X, position.Y, position.Z
+		v[3], v[4], v[5] = velocity.X, velocity.Y, velocity.Z
+		v[6], v[7] = corner[0], corner[1]
+		copy(v[8:12], params[:])
+		copy(v[12:16], color[:])
+	}
+}
+
+// frame returns the atlas frame of a particle with the specified age and normalized lifetime.
+func (s *System) frame(age, t float32) float32 {
+
+	frames, fps := s.udata[14], s.udata[15]
+	if fps > 0 {
+		return math32.Mod(math32.Floor(age*fps), frames)
+	}
+	return math32.Min(math32.Floor(t*frames), frames-1)
+}
+
+// RenderSetup is called by the engine before rendering the particles.
+func (s *System) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+
+	gs.UniformMatrix4fv(s.uniView.Location(gs), 1, false, &rinfo.ViewMatrix[0])
+	gs.UniformMatrix4fv(s.uniProj.Location(gs), 1, false, &rinfo.ProjMatrix[0])
+	gs.Uniform4fv(s.uniSystem.Location(gs), int32(len(s.udata)/4), &s.udata[0])
+	if s.gpu {
+		gs.Uniform4fv(s.uniColorCurve.Location(gs), curveSamples, &s.colorData[0])
+		gs.Uniform1fv(s.uniSizeCurve.Location(gs), curveSamples, &s.sizeData[0])
+	}
+	if s.softness > 0 {
+		s.captureDepth(gs)
+	}
+}
+
+// captureDepth copies the depth buffer of the viewport to the depth texture
+// in the texture unit following the ones of the material.
+func (s *System) captureDepth(gs *gls.GLS) {
+
+	unit := s.Materials()[0].IMaterial().GetMaterial().TextureCount()
+	gs.ActiveTexture(gls.TEXTURE0 + uint32(unit))
+	if s.gs == nil {
+		s.gs = gs
+		s.depthTex = gs.GenTexture()
+	}
+	gs.BindTexture(gls.TEXTURE_2D, s.depthTex)
+	x, y, width, height := gs.GetViewport()
+	if width != s.depthWidth || height != s.depthHeight {
+		gs.TexImage2D(gls.TEXTURE_2D, 0, gls.DEPTH_COMPONENT24, width, height, gls.DEPTH_COMPONENT, gls.UNSIGNED_INT, nil)
+		gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_MIN_FILTER, gls.NEAREST)
+		gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_MAG_FILTER, gls.NEAREST)
+		gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_WRAP_S, gls.CLAMP_TO_EDGE)
+		gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_WRAP_T, gls.CLAMP_TO_EDGE)
+		s.depthWidth, s.depthHeight = width, height
+	}
+	gs.CopyTexSubImage2D(gls.TEXTURE_2D, 0, 0, 0, x, y, width, height)
+	gs.Uniform1i(s.uniDepth.Location(gs), int32(unit))
+	s.udata[18], s.udata[19] = float32(x), float32(y)
+	gs.Uniform4fv(s.uniSystem.Location(gs), int32(len(s.udata)/4), &s.udata[0])
+}
+
+// Dispose releases the depth texture and the resources of the graphic.
+func (s *System) Dispose() {
+
+	if s.gs != nil {
+		s.gs.DeleteTextures(s.depthTex)
+		s.gs = nil
+	}
+	s.Graphic.Dispose()
+}
@@ -0,0 +1,146 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// BakeImpostor renders the specified node from the specified number of angles around the
+// vertical axis through its world position into the cells of a new atlas, lit by the
+// specified lights, and returns the atlas for impostors of the node. The cells are square
+// with the specified size in pixels and the views are rendered with orthographic projections
+// over a transparent background, without fog, reflections or GUI panels.
+// The node is rendered as currently placed in the world, usually at the origin.
+func (r *Renderer) BakeImpostor(inode core.INode, views, cellSize int, lights ...core.INode) (*graphic.ImpostorAtlas, error) {
+
+	if views < 1 || cellSize < 1 {
+		return nil, fmt.Errorf("Invalid impostor views:%d or cell size:%d", views, cellSize)
+	}
+
+	// Bounds of the node around its vertical axis
+	inode.UpdateMatrixWorld()
+	mw := inode.GetNode().MatrixWorld()
+	var origin math32.Vector3
+	origin.SetFromMatrixPosition(&mw)
+	var bbox math32.Box3
+	bbox.MakeEmpty()
+	r.impostorBounds(inode, &bbox)
+	if bbox.Empty() {
+		return nil, fmt.Errorf("Impostor node has no visible graphics")
+	}
+	atlas := new(graphic.ImpostorAtlas)
+	for _, x := range [2]float32{bbox.Min.X, bbox.Max.X} {
+		for _, z := range [2]float32{bbox.Min.Z, bbox.Max.Z} {
+			dx, dz := x-origin.X, z-origin.Z
+			atlas.Radius = math32.Max(atlas.Radius, math32.Sqrt(dx*dx+dz*dz))
+		}
+	}
+	atlas.Bottom = bbox.Min.Y - origin.Y
+	atlas.Top = bbox.Max.Y - origin.Y
+	if atlas.Radius <= 0 || atlas.Top <= atlas.Bottom {
+		return nil, fmt.Errorf("Impostor node has no width or height")
+	}
+	atlas.Views = views
+	atlas.Columns = int(math32.Ceil(math32.Sqrt(float32(views))))
+	atlas.Rows = (views + atlas.Columns - 1) / atlas.Columns
+
+	// Cameras outside of the bounding sphere looking at the vertical axis
+	radius := math32.Sqrt(atlas.Radius*atlas.Radius + math32.Max(atlas.Bottom*atlas.Bottom, atlas.Top*atlas.Top))
+	dist := 2*radius + 1
+	var proj math32.Matrix4
+	proj.MakeOrthographic(-atlas.Radius, atlas.Radius, atlas.Top, atlas.Bottom, dist-radius-0.5, dist+radius+0.5)
+
+	// Renders the views without fog, reflections and panels over a transparent background
+	rt := texture.NewRenderTarget(atlas.Columns*cellSize, atlas.Rows*cellSize, false)
+	defer rt.Dispose()
+	scene := newImpostorScene(inode, lights)
+	fog := r.fog
+	r.fog = nil
+	r.offscreen = true
+	cr, cg, cb, ca := r.gs.GetClearColor()
+	rt.Bind(r.gs)
+	defer func() {
+		rt.Unbind(r.gs)
+		r.gs.ClearColor(cr, cg, cb, ca)
+		r.offscreen = false
+		r.fog = fog
+	}()
+	r.gs.ClearColor(0, 0, 0, 0)
+	r.gs.DepthMask(true)
+	r.gs.Clear(gls.COLOR_BUFFER_BIT | gls.DEPTH_BUFFER_BIT)
+	up := math32.Vector3{0, 1, 0}
+	for i := 0; i < views; i++ {
+		a := 2 * math32.Pi * float32(i) / float32(views)
+		eye := math32.Vector3{origin.X + math32.Sin(a)*dist, origin.Y, origin.Z + math32.Cos(a)*dist}
+		var camWorld math32.Matrix4
+		camWorld.Identity()
+		camWorld.LookAt(&eye, &origin, &up)
+		camWorld.SetPosition(&eye)
+		cam := &matrixCamera{proj: proj}
+		cam.view.GetInverse(&camWorld)
+		r.gs.Viewport(int32((i%atlas.Columns)*cellSize), int32((i/atlas.Columns)*cellSize), int32(cellSize), int32(cellSize))
+		err := r.Render(scene, cam)
+		if err != nil {
+			return nil, err
+		}
+	}
+	atlas.Texture = rt.Texture().Incref()
+	return atlas, nil
+}
+
+// impostorBounds expands the specified box with the world bounding boxes
+// of the visible graphics of the specified node and its descendants.
+func (r *Renderer) impostorBounds(inode core.INode, bbox *math32.Box3) {
+
+	if !inode.Visible() {
+		return
+	}
+	if igr, ok := inode.(graphic.IGraphic); ok && igr.Renderable() {
+		mw := igr.GetGraphic().MatrixWorld()
+		bb := igr.GetGeometry().BoundingBox()
+		bb.ApplyMatrix4(&mw)
+		bbox.Union(&bb)
+	}
+	for _, ichild := range inode.Children() {
+		r.impostorBounds(ichild, bbox)
+	}
+}
+
+// impostorScene is the scene of the views of an impostor, made of the baked node and
+// the lights without changing their parents.
+type impostorScene struct {
+	core.Node              // Embedded node
+	nodes     []core.INode // Baked node and lights
+}
+
+// newImpostorScene creates and returns a pointer to the scene of the specified baked node and lights.
+func newImpostorScene(inode core.INode, lights []core.INode) *impostorScene {
+
+	s := new(impostorScene)
+	s.Node.Init(s)
+	s.nodes = append([]core.INode{inode}, lights...)
+	return s
+}
+
+// Children returns the baked node and the lights.
+func (s *impostorScene) Children() []core.INode {
+
+	return s.nodes
+}
+
+// UpdateMatrixWorld updates the world matrices of the baked node and the lights.
+func (s *impostorScene) UpdateMatrixWorld() {
+
+	for _, inode := range s.nodes {
+		inode.UpdateMatrixWorld()
+	}
+}
@@ -29,6 +29,9 @@ type Renderer struct {
 	specs       ShaderSpecs     // Preallocated Shader specs
 	sortObjects bool            // Flag indicating whether objects should be sorted before rendering
 	stats       Stats           // Renderer statistics
+	offscreen   bool            // Whether the passes of a reflector or the views of an impostor are being rendered
+	camPos      math32.Vector3  // Position of the camera in world coordinates
+	fog         *core.Fog       // Scene fog or nil
 
 	// Populated each frame
 	ambLights    []*light.Ambient           // Ambient lights in the scene
@@ -41,6 +44,7 @@ type Renderer struct {
 	grmatsTransp []*graphic.GraphicMaterial // Transparent graphic materials to be rendered
 	zLayers      map[int][]gui.IPanel       // All IPanels to be rendered organized by Z-layer
 	zLayerKeys   []int                      // Z-layers being used (initially in no particular order, sorted later)
+	reflectors   []graphic.IReflector       // Visible reflectors in the scene
 }
 
 // Stats describes how many objects of each type are being rendered.
@@ -94,15 +98,39 @@ func (r *Renderer) ObjectSorting() bool {
 	return r.sortObjects
 }
 
+// SetFog sets the fog applied to the scenes rendered by the renderer, or removes it if nil.
+func (r *Renderer) SetFog(fog *core.Fog) {
+
+	r.fog = fog
+}
+
+// Fog returns the fog applied to the scenes rendered by the renderer or nil if none.
+func (r *Renderer) Fog() *core.Fog {
+
+	return r.fog
+}
+
 // Render renders the specified scene using the specified camera. Returns an an error.
 func (r *Renderer) Render(scene core.INode, cam camera.ICamera) error {
 
 	// Updates world matrices of all scene nodes
 	scene.UpdateMatrixWorld()
 
+	// Renders the passes of the reflectors before the scene, without nested reflections
+	if !r.offscreen {
+		err := r.renderReflectors(scene, cam)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Build RenderInfo
 	cam.ViewMatrix(&r.rinfo.ViewMatrix)
 	cam.ProjMatrix(&r.rinfo.ProjMatrix)
+	r.rinfo.Fog = r.fog
+	var camWorld math32.Matrix4
+	camWorld.GetInverse(&r.rinfo.ViewMatrix)
+	r.camPos.SetFromMatrixPosition(&camWorld)
 
 	// Clear stats and scene arrays
 	r.stats = Stats{}
@@ -193,9 +221,11 @@ func (r *Renderer) Render(scene core.INode, cam camera.ICamera) error {
 		}
 	}
 
-	// Render other nodes (audio players, etc)
-	for _, inode := range r.others {
-		inode.Render(r.gs)
+	// Render other nodes (audio players, etc) once per frame
+	if !r.offscreen {
+		for _, inode := range r.others {
+			inode.Render(r.gs)
+		}
 	}
 
 	// Enable depth mask so that clearing the depth buffer works
@@ -214,10 +244,15 @@ func (r *Renderer) classifyAndCull(inode core.INode, frustum *math32.Frustum, zL
 	if !inode.Visible() {
 		return
 	}
+	// Show only the level of detail for the camera position
+	if lod, ok := inode.(*graphic.LOD); ok {
+		lod.Select(&r.camPos)
+	}
 	// If node is an IPanel append it to appropriate list
 	if ipan, ok := inode.(gui.IPanel); ok {
 		zLayer += ipan.ZLayerDelta()
-		if ipan.Renderable() {
+		// Panels are not reflected
+		if ipan.Renderable() && !r.offscreen {
 			// TODO cull panels
 			_, ok := r.zLayers[zLayer]
 			if !ok {
@@ -273,6 +308,74 @@ func (r *Renderer) classifyAndCull(inode core.INode, frustum *math32.Frustum, zL
 	}
 }
 
+// renderReflectors renders the passes of the visible reflectors in the scene
+// into their render targets, with the reflectors hidden.
+func (r *Renderer) renderReflectors(scene core.INode, cam camera.ICamera) error {
+
+	r.reflectors = r.reflectors[0:0]
+	r.findReflectors(scene)
+	if len(r.reflectors) == 0 {
+		return nil
+	}
+
+	var view, proj math32.Matrix4
+	cam.ViewMatrix(&view)
+	cam.ProjMatrix(&proj)
+	_, _, width, height := r.gs.GetViewport()
+	r.offscreen = true
+	defer func() { r.offscreen = false }()
+	for _, irefl := range r.reflectors {
+		node := irefl.GetNode()
+		node.SetVisible(false)
+		for _, pass := range irefl.GetReflector().Passes(&view, &proj, int(width), int(height)) {
+			pass.Target.Bind(r.gs)
+			r.gs.DepthMask(true)
+			r.gs.Clear(gls.COLOR_BUFFER_BIT | gls.DEPTH_BUFFER_BIT)
+			err := r.Render(scene, &matrixCamera{pass.ViewMatrix, pass.ProjMatrix})
+			pass.Target.Unbind(r.gs)
+			if err != nil {
+				node.SetVisible(true)
+				return err
+			}
+		}
+		node.SetVisible(true)
+	}
+	return nil
+}
+
+// findReflectors appends the visible reflectors of the specified node and its descendants.
+func (r *Renderer) findReflectors(inode core.INode) {
+
+	if !inode.Visible() {
+		return
+	}
+	if irefl, ok := inode.(graphic.IReflector); ok && irefl.Renderable() {
+		r.reflectors = append(r.reflectors, irefl)
+	}
+	for _, ichild := range inode.Children() {
+		r.findReflectors(ichild)
+	}
+}
+
+// matrixCamera is a camera with fixed view and projection matrices,
+// used to render the passes of the reflectors and the views of the impostors.
+type matrixCamera struct {
+	view math32.Matrix4 // View matrix
+	proj math32.Matrix4 // Projection matrix
+}
+
+// ViewMatrix satisfies the ICamera interface.
+func (c *matrixCamera) ViewMatrix(m *math32.Matrix4) {
+
+	*m = c.view
+}
+
+// ProjMatrix satisfies the ICamera interface.
+func (c *matrixCamera) ProjMatrix(m *math32.Matrix4) {
+
+	*m = c.proj
+}
+
 // zSort sorts a list of graphic materials based on the user-specified render order
 // then based on their Z position relative to the camera, back to front.
 func zSort(grmats []*graphic.GraphicMaterial) {
@@ -308,6 +411,10 @@ func (r *Renderer) renderGraphicMaterial(grmat *graphic.GraphicMaterial) error {
 	r.specs.Defines.Add(&mat.ShaderDefines)
 	r.specs.Defines.Add(&geom.ShaderDefines)
 	r.specs.Defines.Add(&gr.ShaderDefines)
+	useFog := r.fog != nil && mat.UseFog()
+	if useFog {
+		r.specs.Defines.Add(r.fog.Defines())
+	}
 
 	// Set the shader specs for this material and set shader program
 	r.specs.Name = mat.Shader()
@@ -349,6 +456,11 @@ func (r *Renderer) renderGraphicMaterial(grmat *graphic.GraphicMaterial) error {
 		}
 	}
 
+	// Set up the fog
+	if useFog {
+		r.fog.RenderSetup(r.gs, &r.rinfo)
+	}
+
 	// Render this graphic material
 	grmat.Render(r.gs, &r.rinfo)
 
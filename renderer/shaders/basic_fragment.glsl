@@ -1,9 +1,17 @@
 precision highp float;
 
+#include <fog>
+
 in vec3 Color;
+#ifdef FOG
+in vec3 FogPosition;
+#endif
 out vec4 FragColor;
 
 void main() {
 
     FragColor = vec4(Color, 1.0);
+#ifdef FOG
+    FragColor.rgb = applyFog(FragColor.rgb, FogPosition);
+#endif
 }
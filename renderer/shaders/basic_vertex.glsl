@@ -2,6 +2,10 @@
 
 // Model uniforms
 uniform mat4 MVP;
+#ifdef FOG
+uniform mat4 ModelViewMatrix;
+out vec3 FogPosition;
+#endif
 
 // Final output color for fragment shader
 out vec3 Color;
@@ -9,5 +13,8 @@ out vec3 Color;
 void main() {
 
     Color = VertexColor;
+#ifdef FOG
+    FogPosition = (ModelViewMatrix * vec4(VertexPosition, 1.0)).xyz;
+#endif
     gl_Position = MVP * vec4(VertexPosition, 1.0);
 }
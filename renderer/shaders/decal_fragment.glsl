@@ -0,0 +1,54 @@
+precision highp float;
+
+// Decal texture and its offset, repeat and flip
+uniform sampler2D uDecalSampler;
+uniform vec2 uDecalTexParams[3];
+
+// Depth buffer of the scene rendered before the decal
+uniform sampler2D uDepthSampler;
+
+// Inverse projection and model view matrices
+uniform mat4 DecalInvProj;
+uniform mat4 DecalInvModelView;
+
+// Decal uniforms
+uniform vec4 DecalParams[3];
+#define DecalColor          DecalParams[0]
+#define DecalViewport       DecalParams[1]
+#define DecalAxis           DecalParams[2].xyz
+#define DecalMinCos         DecalParams[2].w
+
+// Output
+out vec4 FragColor;
+
+// Returns the texture coordinates of the decal texture from the base coordinates
+// applying the texture's optional Y flip, repeat and offset.
+vec2 mapTexcoord(vec2 uv, vec2 texParams[3]) {
+    if (bool(texParams[2].x)) {
+        uv.y = 1.0 - uv.y;
+    }
+    return uv * texParams[1] + texParams[0];
+}
+
+void main() {
+
+    // Reconstructs the view position of the surface behind the fragment from the depth buffer
+    vec2 frag = gl_FragCoord.xy - DecalViewport.xy;
+    float depth = texelFetch(uDepthSampler, ivec2(frag), 0).r;
+    vec4 ndc = vec4(frag / DecalViewport.zw * 2.0 - 1.0, depth * 2.0 - 1.0, 1.0);
+    vec4 view = DecalInvProj * ndc;
+    view /= view.w;
+
+    // Surface normal facing the camera from the derivatives of the view position
+    vec3 normal = normalize(cross(dFdx(view.xyz), dFdy(view.xyz)));
+    if (dot(normal, view.xyz) > 0.0) {
+        normal = -normal;
+    }
+
+    // Discards the surfaces outside the unit box of the decal or facing away from the projection
+    vec3 box = (DecalInvModelView * vec4(view.xyz, 1.0)).xyz;
+    if (any(greaterThan(abs(box), vec3(0.5))) || dot(normal, DecalAxis) <= DecalMinCos) {
+        discard;
+    }
+    FragColor = texture(uDecalSampler, mapTexcoord(box.xy + 0.5, uDecalTexParams)) * DecalColor;
+}
@@ -0,0 +1,9 @@
+#include <attributes>
+
+// Model uniforms
+uniform mat4 MVP;
+
+void main() {
+
+    gl_Position = MVP * vec4(VertexPosition, 1.0);
+}
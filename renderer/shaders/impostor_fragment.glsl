@@ -0,0 +1,31 @@
+precision highp float;
+
+#include <fog>
+
+// Inputs from the vertex shader
+in vec2 FragTexcoord0;
+in vec2 FragTexcoord1;
+#ifdef FOG
+in vec3 FogPosition;
+#endif
+
+// Impostor parameters (see vertex shader)
+uniform vec4 ImpostorParams[2];
+uniform sampler2D uImpostorSampler;
+
+// Output
+out vec4 FragColor;
+
+void main() {
+
+    // Cross fades the views baked from the two nearest angles
+    vec4 color = mix(texture(uImpostorSampler, FragTexcoord0), texture(uImpostorSampler, FragTexcoord1), ImpostorParams[1].z);
+    if (color.a < ImpostorParams[1].w) {
+        discard;
+    }
+    // The views are rendered over a transparent black background
+    FragColor = vec4(color.rgb / color.a, 1.0);
+#ifdef FOG
+    FragColor.rgb = applyFog(FragColor.rgb, FogPosition);
+#endif
+}
@@ -0,0 +1,28 @@
+#include <attributes>
+
+// Model uniforms
+uniform mat4 MVP;
+#ifdef FOG
+uniform mat4 ModelViewMatrix;
+out vec3 FogPosition;
+#endif
+
+// Impostor parameters:
+// [0] atlas offsets of the cells of the two nearest views
+// [1] atlas size of a cell, blend factor between the two views, alpha cutoff
+uniform vec4 ImpostorParams[2];
+
+// Outputs for the fragment shader
+out vec2 FragTexcoord0;
+out vec2 FragTexcoord1;
+
+void main() {
+
+    vec2 uv = VertexTexcoord * ImpostorParams[1].xy;
+    FragTexcoord0 = ImpostorParams[0].xy + uv;
+    FragTexcoord1 = ImpostorParams[0].zw + uv;
+#ifdef FOG
+    FogPosition = (ModelViewMatrix * vec4(VertexPosition, 1.0)).xyz;
+#endif
+    gl_Position = MVP * vec4(VertexPosition, 1.0);
+}
@@ -0,0 +1,44 @@
+#ifdef FOG
+// Scene fog parameters:
+// [0] color, sine of the elevation up to which skies are blended
+// [1] linear start and end distances, density, height falloff
+// [2] up direction in camera coordinates, camera height above the fog base height
+uniform vec4 FogParams[3];
+
+// Returns the amount of fog, from 0 to 1, of the fragment at the specified position in camera coordinates.
+float fogFactor(vec3 position) {
+
+    float dist = length(position);
+    float scale = 1.0;
+#ifdef FOG_HEIGHT
+    // Average of the density decreasing exponentially with the height along the view ray
+    float falloff = FogParams[1].w;
+    float t = falloff * dot(FogParams[2].xyz, position);
+    scale = exp(-falloff * FogParams[2].w) * (abs(t) > 1e-4 ? (1.0 - exp(-t)) / t : 1.0);
+#endif
+#if defined(FOG_LINEAR)
+    return clamp((dist - FogParams[1].x) / (FogParams[1].y - FogParams[1].x) * scale, 0.0, 1.0);
+#elif defined(FOG_EXP)
+    return 1.0 - exp(-FogParams[1].z * dist * scale);
+#else
+    float d = FogParams[1].z * dist;
+    return 1.0 - exp(-d * d * scale);
+#endif
+}
+
+// Returns the amount of fog of a sky with the specified sine of the elevation,
+// increasing toward the horizon.
+float fogSkyFactor(float elevation) {
+
+    if (FogParams[0].w <= 0.0) {
+        return 0.0;
+    }
+    return 1.0 - smoothstep(0.0, FogParams[0].w, elevation);
+}
+
+// Returns the color of the fragment at the specified position in camera coordinates blended with the fog.
+vec3 applyFog(vec3 color, vec3 position) {
+
+    return mix(color, FogParams[0].rgb, fogFactor(position));
+}
+#endif
@@ -3,7 +3,7 @@
 //
 
 // Material parameters uniform array
-uniform vec3 Material[6];
+uniform vec3 Material[7];
 // Macros to access elements inside the Material array
 #define MatAmbientColor		Material[0]
 #define MatDiffuseColor     Material[1]
@@ -13,6 +13,9 @@ uniform vec3 Material[6];
 #define MatOpacity          Material[4].y
 #define MatPointSize        Material[4].z
 #define MatPointRotationZ   Material[5].x
+#define MatBumpScale        Material[5].y
+#define MatParallaxScale    Material[5].z
+#define MatNormalScale      Material[6].xy
 
 #if MAT_TEXTURES > 0
     // Texture unit sampler array
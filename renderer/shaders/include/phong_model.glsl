@@ -6,6 +6,8 @@
     camDir:     input camera directions
     matAmbient: input material ambient color
     matDiffuse: input material diffuse color
+    matSpecular: input material specular color
+    matEmissive: input material emissive color
     ambdiff:    output ambient+diffuse color
     spec:       output specular color
  Uniforms:
@@ -16,10 +18,9 @@
     PointLightPosition[]
     PointLightLinearDecay[]
     PointLightQuadraticDecay[]
-    MatSpecularColor
     MatShininess
 *****/
-void phongModel(vec4 position, vec3 normal, vec3 camDir, vec3 matAmbient, vec3 matDiffuse, out vec3 ambdiff, out vec3 spec) {
+void phongModel(vec4 position, vec3 normal, vec3 camDir, vec3 matAmbient, vec3 matDiffuse, vec3 matSpecular, vec3 matEmissive, out vec3 ambdiff, out vec3 spec) {
 
     vec3 ambientTotal  = vec3(0.0);
     vec3 diffuseTotal  = vec3(0.0);
@@ -44,7 +45,7 @@ void phongModel(vec4 position, vec3 normal, vec3 camDir, vec3 matAmbient, vec3 m
         float dotNormal = dot(lightDirection, normal); // Dot product between light direction and fragment normal
         if (dotNormal > EPS) { // If the fragment is lit
             diffuseTotal += DirLightColor(i) * matDiffuse * dotNormal;
-            specularTotal += DirLightColor(i) * MatSpecularColor * pow(max(dot(reflect(-lightDirection, normal), camDir), 0.0), MatShininess);
+            specularTotal += DirLightColor(i) * matSpecular * pow(max(dot(reflect(-lightDirection, normal), camDir), 0.0), MatShininess);
         }
     }
 #endif
@@ -61,7 +62,7 @@ void phongModel(vec4 position, vec3 normal, vec3 camDir, vec3 matAmbient, vec3 m
             float attenuation = 1.0 / (1.0 + lightDistance * (PointLightLinearDecay(i) + PointLightQuadraticDecay(i) * lightDistance));
             vec3 attenuatedColor = PointLightColor(i) * attenuation;
             diffuseTotal += attenuatedColor * matDiffuse * dotNormal;
-            specularTotal += attenuatedColor * MatSpecularColor * pow(max(dot(reflect(-lightDirection, normal), camDir), 0.0), MatShininess);
+            specularTotal += attenuatedColor * matSpecular * pow(max(dot(reflect(-lightDirection, normal), camDir), 0.0), MatShininess);
         }
     }
 #endif
@@ -83,7 +84,7 @@ void phongModel(vec4 position, vec3 normal, vec3 camDir, vec3 matAmbient, vec3 m
                 float spotFactor = pow(angleDot, SpotLightAngularDecay(i));
                 vec3 attenuatedColor = SpotLightColor(i) * attenuation * spotFactor;
                 diffuseTotal += attenuatedColor * matDiffuse * dotNormal;
-                specularTotal += attenuatedColor * MatSpecularColor * pow(max(dot(reflect(-lightDirection, normal), camDir), 0.0), MatShininess);
+                specularTotal += attenuatedColor * matSpecular * pow(max(dot(reflect(-lightDirection, normal), camDir), 0.0), MatShininess);
             }
         }
     }
@@ -92,6 +93,6 @@ void phongModel(vec4 position, vec3 normal, vec3 camDir, vec3 matAmbient, vec3 m
         diffuseTotal = matDiffuse;
     }
     // Sets output colors
-    ambdiff = ambientTotal + MatEmissiveColor + diffuseTotal;
+    ambdiff = ambientTotal + matEmissive + diffuseTotal;
     spec = specularTotal;
 }
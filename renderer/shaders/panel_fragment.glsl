@@ -14,7 +14,7 @@ uniform vec2		MatTexinfo[3];
 in vec2 FragTexcoord;
 
 // Input uniform
-uniform vec4 Panel[8];
+uniform vec4 Panel[9];
 #define Bounds			Panel[0]		  // panel bounds in texture coordinates
 #define Border			Panel[1]		  // panel border in texture coordinates
 #define Padding			Panel[2]		  // panel padding in texture coordinates
@@ -23,6 +23,7 @@ uniform vec4 Panel[8];
 #define PaddingColor	Panel[5]		  // panel padding color
 #define ContentColor	Panel[6]		  // panel content color
 #define TextureValid	bool(Panel[7].x)  // texture valid flag
+#define TexRegion		Panel[8]		  // region of the texture displayed in the content area
 
 // Output
 out vec4 FragColor;
@@ -78,7 +79,7 @@ void main() {
             // Adjust texture coordinates to fit texture inside the content area
             vec2 offset = vec2(-Content[0], -Content[1]);
             vec2 factor = vec2(1.0/Content[2], 1.0/Content[3]);
-            vec2 texcoord = (FragTexcoord + offset) * factor;
+            vec2 texcoord = (FragTexcoord + offset) * factor * TexRegion.zw + TexRegion.xy;
             vec4 texColor = texture(MatTexture, texcoord * MatTexRepeat + MatTexOffset);
 
             // Mix content color with texture color.
@@ -0,0 +1,61 @@
+precision highp float;
+
+// Inputs from the vertex shader
+in vec4 Color;
+in vec2 FragTexcoord;
+in float ViewDepth;
+
+#include <fog>
+#ifdef FOG
+in vec3 FogPosition;
+#endif
+
+#ifdef HAS_PARTICLEMAP
+uniform sampler2D uParticleSampler;
+uniform vec2 uParticleTexParams[3];
+
+// Returns the texture coordinates of the particle map from the base coordinates
+// applying the map's optional Y flip, repeat and offset.
+vec2 mapTexcoord(vec2 uv, vec2 texParams[3]) {
+    if (bool(texParams[2].x)) {
+        uv.y = 1.0 - uv.y;
+    }
+    return uv * texParams[1] + texParams[0];
+}
+#endif
+
+#ifdef SOFT_PARTICLES
+// Depth buffer of the scene rendered before the particles
+uniform sampler2D uDepthSampler;
+uniform mat4 ProjMatrix;
+uniform vec4 ParticleSystem[5];
+#endif
+
+// Final fragment color
+out vec4 FragColor;
+
+void main() {
+
+    vec4 color = Color;
+#ifdef HAS_PARTICLEMAP
+    color *= texture(uParticleSampler, mapTexcoord(FragTexcoord, uParticleTexParams));
+#endif
+
+#ifdef SOFT_PARTICLES
+    // Fades out the particle near the surface behind it, comparing their distances to the camera
+    float depth = texelFetch(uDepthSampler, ivec2(gl_FragCoord.xy - ParticleSystem[4].zw), 0).r;
+    float sceneDepth = ProjMatrix[3][2] / ((depth * 2.0 - 1.0) + ProjMatrix[2][2]);
+    color.a *= clamp((sceneDepth - ViewDepth) / ParticleSystem[4].y, 0.0, 1.0);
+#endif
+
+#ifdef FOG
+#ifdef ADDITIVE_BLENDING
+    // Additive particles fade out in the fog instead of adding its color
+    color.rgb *= 1.0 - fogFactor(FogPosition);
+#else
+    color.rgb = applyFog(color.rgb, FogPosition);
+#endif
+#endif
+
+    FragColor = color;
+}
@@ -0,0 +1,140 @@
+// Particle attributes with fixed locations shared by the CPU and GPU simulations.
+// With GPU_SIMULATION the position and velocity are the initial ones, the params are
+// (birth time, lifetime, size, angular velocity) and the color is (initial rotation, seed, 0, 0).
+// Otherwise they are the current ones, the params are (size, rotation, frame, 0) and the color is RGBA.
+layout(location = 0) in vec3 ParticlePosition;
+layout(location = 1) in vec3 ParticleVelocity;
+layout(location = 2) in vec2 ParticleCorner;
+layout(location = 3) in vec4 ParticleParams;
+layout(location = 4) in vec4 ParticleColor;
+
+// Camera uniforms
+uniform mat4 ViewMatrix;
+uniform mat4 ProjMatrix;
+
+// Particle system uniforms
+uniform vec4 ParticleSystem[5];
+#define PsGravity           ParticleSystem[0].xyz
+#define PsDrag              ParticleSystem[0].w
+#define PsVelocity          ParticleSystem[1].xyz
+#define PsTime              ParticleSystem[1].w
+#define PsTurbulence        ParticleSystem[2].x
+#define PsTurbulenceFreq    ParticleSystem[2].y
+#define PsTurbulenceSpeed   ParticleSystem[2].z
+#define PsStretched         ParticleSystem[2].w
+#define PsAtlas             ParticleSystem[3]
+#define PsStretch           ParticleSystem[4].x
+
+#ifdef GPU_SIMULATION
+// Lifetime curves sampled uniformly
+uniform vec4 ParticleColorCurve[16];
+uniform float ParticleSizeCurve[16];
+
+// Returns the color and opacity of the lifetime curve at the normalized lifetime
+vec4 colorCurve(float t) {
+    float x = clamp(t, 0.0, 1.0) * 15.0;
+    int i = min(int(x), 14);
+    return mix(ParticleColorCurve[i], ParticleColorCurve[i + 1], x - float(i));
+}
+
+// Returns the size scale of the lifetime curve at the normalized lifetime
+float sizeCurve(float t) {
+    float x = clamp(t, 0.0, 1.0) * 15.0;
+    int i = min(int(x), 14);
+    return mix(ParticleSizeCurve[i], ParticleSizeCurve[i + 1], x - float(i));
+}
+#endif
+
+// Outputs for the fragment shader
+out vec4 Color;
+out vec2 FragTexcoord;
+out float ViewDepth;
+#ifdef FOG
+out vec3 FogPosition;
+#endif
+
+void main() {
+
+    vec3 position;
+    vec3 velocity;
+    float size;
+    float rotation;
+    float frame;
+#ifdef GPU_SIMULATION
+    float age = PsTime - ParticleParams.x;
+    float lifetime = ParticleParams.y;
+    if (age < 0.0 || age >= lifetime) {
+        // Outside of the clip volume
+        gl_Position = vec4(0.0, 0.0, 2.0, 1.0);
+        return;
+    }
+    float t = age / lifetime;
+
+    // Analytic motion under gravity and linear drag
+    if (PsDrag > 0.0) {
+        float e = exp(-PsDrag * age);
+        vec3 terminal = PsGravity / PsDrag;
+        position = ParticlePosition + terminal * age + (ParticleVelocity - terminal) * (1.0 - e) / PsDrag;
+        velocity = terminal + (ParticleVelocity - terminal) * e;
+    } else {
+        position = ParticlePosition + ParticleVelocity * age + 0.5 * PsGravity * age * age;
+        velocity = ParticleVelocity + PsGravity * age;
+    }
+    position += PsVelocity * age;
+    velocity += PsVelocity;
+
+    // Turbulence approximated by a smooth displacement from the initial position growing with the age
+    if (PsTurbulence > 0.0) {
+        vec3 q = ParticlePosition * PsTurbulenceFreq + ParticleColor.y * 31.4;
+        float s = age * PsTurbulenceSpeed;
+        vec3 noise = vec3(
+            sin(q.y * 1.7 + s) + sin(q.z * 2.3 - s * 1.3),
+            sin(q.z * 1.9 + s * 1.1) + sin(q.x * 2.1 - s * 0.7),
+            sin(q.x * 1.3 + s * 0.9) + sin(q.y * 2.7 - s * 1.7)
+        ) * 0.5;
+        position += noise * PsTurbulence * 0.5 * age * age;
+    }
+
+    size = ParticleParams.z * sizeCurve(t);
+    rotation = ParticleColor.x + ParticleParams.w * age;
+    Color = colorCurve(t);
+    if (PsAtlas.w > 0.0) {
+        frame = mod(floor(age * PsAtlas.w), PsAtlas.z);
+    } else {
+        frame = min(floor(t * PsAtlas.z), PsAtlas.z - 1.0);
+    }
+#else
+    position = ParticlePosition;
+    velocity = ParticleVelocity;
+    size = ParticleParams.x;
+    rotation = ParticleParams.y;
+    frame = ParticleParams.z;
+    Color = ParticleColor;
+#endif
+
+    // Expands the quad in camera coordinates
+    vec4 viewPos = ViewMatrix * vec4(position, 1.0);
+    if (PsStretched > 0.5) {
+        vec3 viewVel = mat3(ViewMatrix) * velocity;
+        float speed = length(viewVel.xy);
+        vec2 axis = speed > 1e-5 ? viewVel.xy / speed : vec2(0.0, 1.0);
+        vec2 side = vec2(axis.y, -axis.x);
+        float len = size + PsStretch * length(viewVel);
+        viewPos.xy += side * ParticleCorner.x * 0.5 * size + axis * ParticleCorner.y * 0.5 * len;
+    } else {
+        float c = cos(rotation);
+        float s = sin(rotation);
+        viewPos.xy += mat2(c, s, -s, c) * ParticleCorner * 0.5 * size;
+    }
+    gl_Position = ProjMatrix * viewPos;
+    ViewDepth = -viewPos.z;
+#ifdef FOG
+    FogPosition = viewPos.xyz;
+#endif
+
+    // Texture coordinates of the frame in the atlas, whose first row is at the top
+    float col = mod(frame, PsAtlas.x);
+    float row = floor(frame / PsAtlas.x);
+    vec2 local = ParticleCorner * 0.5 + 0.5;
+    FragTexcoord = vec2((col + local.x) / PsAtlas.x, 1.0 - (row + 1.0 - local.y) / PsAtlas.y);
+}
@@ -28,37 +28,92 @@ precision highp float;
 
 #ifdef HAS_BASECOLORMAP
 uniform sampler2D uBaseColorSampler;
+uniform vec2 uBaseColorTexParams[3];
 #endif
 #ifdef HAS_METALROUGHNESSMAP
 uniform sampler2D uMetallicRoughnessSampler;
+uniform vec2 uMetallicRoughnessTexParams[3];
 #endif
 #ifdef HAS_NORMALMAP
 uniform sampler2D uNormalSampler;
+uniform vec2 uNormalTexParams[3];
 //uniform float uNormalScale;
 #endif
 #ifdef HAS_EMISSIVEMAP
 uniform sampler2D uEmissiveSampler;
+uniform vec2 uEmissiveTexParams[3];
 #endif
 #ifdef HAS_OCCLUSIONMAP
 uniform sampler2D uOcclusionSampler;
+uniform vec2 uOcclusionTexParams[3];
 uniform float uOcclusionStrength;
 #endif
+#ifdef HAS_CLEARCOATMAP
+uniform sampler2D uClearcoatSampler;
+uniform vec2 uClearcoatTexParams[3];
+#endif
+#ifdef HAS_CLEARCOATROUGHNESSMAP
+uniform sampler2D uClearcoatRoughnessSampler;
+uniform vec2 uClearcoatRoughnessTexParams[3];
+#endif
+#ifdef HAS_CLEARCOATNORMALMAP
+uniform sampler2D uClearcoatNormalSampler;
+uniform vec2 uClearcoatNormalTexParams[3];
+#endif
+#ifdef HAS_SHEENCOLORMAP
+uniform sampler2D uSheenColorSampler;
+uniform vec2 uSheenColorTexParams[3];
+#endif
+#ifdef HAS_SHEENROUGHNESSMAP
+uniform sampler2D uSheenRoughnessSampler;
+uniform vec2 uSheenRoughnessTexParams[3];
+#endif
+#ifdef HAS_TRANSMISSIONMAP
+uniform sampler2D uTransmissionSampler;
+uniform vec2 uTransmissionTexParams[3];
+#endif
 
 // Material parameters uniform array
-uniform vec4 Material[3];
+uniform vec4 Material[8];
 // Macros to access elements inside the Material array
-#define uBaseColor		    Material[0]
-#define uEmissiveColor      Material[1]
-#define uMetallicFactor     Material[2].x
-#define uRoughnessFactor    Material[2].y
+#define uBaseColor		            Material[0]
+#define uEmissiveColor              Material[1]
+#define uMetallicFactor             Material[2].x
+#define uRoughnessFactor            Material[2].y
+#define uIOR                        Material[2].z
+#define uEmissiveStrength           Material[2].w
+#define uClearcoatFactor            Material[3].x
+#define uClearcoatRoughnessFactor   Material[3].y
+#define uTransmissionFactor         Material[3].z
+#define uSheenRoughnessFactor       Material[3].w
+#define uSheenColorFactor           Material[4].rgb
+// Rotation of the texture coordinates of each map
+#define uTexRotation(a)             Material[5+(a)/4][(a)%4]
+
+// Indices of the maps in the texture rotation array
+#define BASECOLOR_MAP               0
+#define METALROUGHNESS_MAP          1
+#define NORMAL_MAP                  2
+#define OCCLUSION_MAP               3
+#define EMISSIVE_MAP                4
+#define CLEARCOAT_MAP               5
+#define CLEARCOATROUGHNESS_MAP      6
+#define CLEARCOATNORMAL_MAP         7
+#define SHEENCOLOR_MAP              8
+#define SHEENROUGHNESS_MAP          9
+#define TRANSMISSION_MAP            10
 
 #include <lights>
+#include <fog>
 
 // Inputs from vertex shader
 in vec3 Position;       // Vertex position in camera coordinates.
 in vec3 Normal;         // Vertex normal in camera coordinates.
 in vec3 CamDir;         // Direction from vertex to camera
 in vec2 FragTexcoord;
+#ifdef HAS_TANGENTS
+in vec4 Tangent;        // Vertex tangent in camera coordinates and bitangent handedness
+#endif
 
 // Final fragment color
 out vec4 FragColor;
@@ -84,6 +139,12 @@ struct PBRInfo
     float alphaRoughness;         // roughness mapped to a more linear change in the roughness (proposed by [2])
     vec3 diffuseColor;            // color contribution from diffuse lighting
     vec3 specularColor;           // color contribution from specular lighting
+    vec3 n;                       // normal at surface point
+    float clearcoat;              // clearcoat layer intensity
+    float clearcoatRoughness;     // clearcoat layer alpha roughness
+    vec3 clearcoatNormal;         // clearcoat layer normal at surface point
+    vec3 sheenColor;              // sheen layer color
+    float sheenRoughness;         // sheen layer alpha roughness
 };
 
 const float M_PI = 3.141592653589793;
@@ -103,34 +164,44 @@ vec4 SRGBtoLINEAR(vec4 srgbIn) {
 //#endif //MANUAL_SRGB
 }
 
-// Find the normal for this fragment, pulling either from a predefined normal map
-// or from the interpolated mesh normal and tangent attributes.
-vec3 getNormal()
+// Returns the texture coordinates for a map, applying the scale (repeat),
+// rotation and offset of the map texture (KHR_texture_transform)
+vec2 texcoord(vec2 texParams[3], float rotation)
+{
+    float c = cos(rotation);
+    float s = sin(rotation);
+    return mat2(c, -s, s, c) * (FragTexcoord * texParams[1]) + texParams[0];
+}
+
+// Returns the tangent space matrix of this fragment
+mat3 getTBN()
 {
     // Retrieve the tangent space matrix
-//#ifndef HAS_TANGENTS
+    vec3 ng = normalize(Normal);
+#ifdef HAS_TANGENTS
+    vec3 t = normalize(Tangent.xyz - ng * dot(ng, Tangent.xyz));
+    vec3 b = cross(ng, t) * Tangent.w;
+#else
     vec3 pos_dx = dFdx(Position);
     vec3 pos_dy = dFdy(Position);
     vec3 tex_dx = dFdx(vec3(FragTexcoord, 0.0));
     vec3 tex_dy = dFdy(vec3(FragTexcoord, 0.0));
     vec3 t = (tex_dy.t * pos_dx - tex_dx.t * pos_dy) / (tex_dx.s * tex_dy.t - tex_dy.s * tex_dx.t);
-
-//#ifdef HAS_NORMALS
-    vec3 ng = normalize(Normal);
-//#else
-//    vec3 ng = cross(pos_dx, pos_dy);
-//#endif
-
     t = normalize(t - ng * dot(ng, t));
     vec3 b = normalize(cross(ng, t));
+#endif
     mat3 tbn = mat3(t, b, ng);
-//#else // HAS_TANGENTS
-//    mat3 tbn = v_TBN;
-//#endif
 
+    return tbn;
+}
+
+// Find the normal for this fragment, pulling either from a predefined normal map
+// or from the interpolated mesh normal and tangent attributes.
+vec3 getNormal(mat3 tbn)
+{
 #ifdef HAS_NORMALMAP
     float uNormalScale = 1.0;
-    vec3 n = texture(uNormalSampler, FragTexcoord).rgb;
+    vec3 n = texture(uNormalSampler, texcoord(uNormalTexParams, uTexRotation(NORMAL_MAP))).rgb;
     n = normalize(tbn * ((2.0 * n - 1.0) * vec3(uNormalScale, uNormalScale, 1.0)));
 #else
     // The tbn matrix is linearly interpolated, so we need to re-normalize
@@ -140,6 +211,20 @@ vec3 getNormal()
     return n;
 }
 
+// Find the clearcoat layer normal for this fragment, which uses its own
+// optional normal map and otherwise the interpolated mesh normal.
+vec3 getClearcoatNormal(mat3 tbn)
+{
+#ifdef HAS_CLEARCOATNORMALMAP
+    vec3 n = texture(uClearcoatNormalSampler, texcoord(uClearcoatNormalTexParams, uTexRotation(CLEARCOATNORMAL_MAP))).rgb;
+    n = normalize(tbn * (2.0 * n - 1.0));
+#else
+    vec3 n = normalize(tbn[2].xyz);
+#endif
+
+    return n;
+}
+
 // Calculation of the lighting contribution from an optional Image Based Light source.
 // Precomputed Environment Maps are required uniform inputs and are computed as outlined in [1].
 // See our README.md on Environment Maps [3] for additional discussion.
@@ -207,9 +292,23 @@ float microfacetDistribution(PBRInfo pbrInputs, PBRLightInfo pbrLight)
     return roughnessSq / (M_PI * f * f);
 }
 
+// Sheen distribution term ("Charlie" sheen) from "Production Friendly Microfacet Sheen BRDF"
+// by Estevez and Kulla, with the visibility term of Neubelt and Pettineo.
+float sheenDistribution(float alphaRoughness, float NdotH)
+{
+    float invR = 1.0 / alphaRoughness;
+    float sin2h = max(1.0 - NdotH * NdotH, 0.0078125);
+    return (2.0 + invR) * pow(sin2h, invR * 0.5) / (2.0 * M_PI);
+}
+
+float sheenVisibility(float NdotL, float NdotV)
+{
+    return 1.0 / (4.0 * (NdotL + NdotV - NdotL * NdotV));
+}
+
 vec3 pbrModel(PBRInfo pbrInputs, vec3 lightColor, vec3 lightDir) {
 
-    vec3 n = getNormal();                             // normal at surface point
+    vec3 n = pbrInputs.n;                             // normal at surface point
     vec3 v = normalize(CamDir);                       // Vector from surface point to camera
     vec3 l = normalize(lightDir);                     // Vector from surface point to light
     vec3 h = normalize(l+v);                          // Half vector between both l and v
@@ -240,6 +339,30 @@ vec3 pbrModel(PBRInfo pbrInputs, vec3 lightColor, vec3 lightDir) {
     // Obtain final intensity as reflectance (BRDF) scaled by the energy of the light (cosine law)
     vec3 color = NdotL * lightColor * (diffuseContrib + specContrib);
 
+#ifdef USE_SHEEN
+    // Sheen layer on top of the base layer, which is scaled by the sheen albedo
+    float sheenD = sheenDistribution(max(pbrInputs.sheenRoughness, 0.07), NdotH);
+    vec3 sheenContrib = pbrInputs.sheenColor * sheenD * sheenVisibility(NdotL, NdotV);
+    float sheenAlbedoScaling = 1.0 - max(max(pbrInputs.sheenColor.r, pbrInputs.sheenColor.g), pbrInputs.sheenColor.b) * 0.157;
+    color = color * sheenAlbedoScaling + NdotL * lightColor * sheenContrib;
+#endif
+
+#ifdef USE_CLEARCOAT
+    // Clearcoat layer: dielectric specular lobe using its own normal and roughness
+    vec3 cn = pbrInputs.clearcoatNormal;
+    float ccNdotL = clamp(dot(cn, l), 0.001, 1.0);
+    float ccNdotV = abs(dot(cn, v)) + 0.001;
+    float ccNdotH = clamp(dot(cn, h), 0.0, 1.0);
+    PBRInfo ccInputs = pbrInputs;
+    ccInputs.reflectance0 = vec3(0.04);
+    ccInputs.reflectance90 = vec3(1.0);
+    ccInputs.alphaRoughness = pbrInputs.clearcoatRoughness;
+    PBRLightInfo ccLight = PBRLightInfo(ccNdotL, ccNdotV, ccNdotH, LdotH, VdotH);
+    vec3 ccF = specularReflection(ccInputs, ccLight);
+    vec3 ccContrib = ccF * geometricOcclusion(ccInputs, ccLight) * microfacetDistribution(ccInputs, ccLight) / (4.0 * ccNdotL * ccNdotV);
+    color = color * (1.0 - pbrInputs.clearcoat * ccF) + pbrInputs.clearcoat * ccNdotL * lightColor * ccContrib;
+#endif
+
     return color;
 }
 
@@ -251,7 +374,7 @@ void main() {
 #ifdef HAS_METALROUGHNESSMAP
     // Roughness is stored in the 'g' channel, metallic is stored in the 'b' channel.
     // This layout intentionally reserves the 'r' channel for (optional) occlusion map data
-    vec4 mrSample = texture(uMetallicRoughnessSampler, FragTexcoord);
+    vec4 mrSample = texture(uMetallicRoughnessSampler, texcoord(uMetallicRoughnessTexParams, uTexRotation(METALROUGHNESS_MAP)));
     perceptualRoughness = mrSample.g * perceptualRoughness;
     metallic = mrSample.b * metallic;
 #endif
@@ -264,15 +387,28 @@ void main() {
 
     // The albedo may be defined from a base texture or a flat color
 #ifdef HAS_BASECOLORMAP
-    vec4 baseColor = SRGBtoLINEAR(texture(uBaseColorSampler, FragTexcoord)) * uBaseColor;
+    vec4 baseColor = SRGBtoLINEAR(texture(uBaseColorSampler, texcoord(uBaseColorTexParams, uTexRotation(BASECOLOR_MAP)))) * uBaseColor;
 #else
     vec4 baseColor = uBaseColor;
 #endif
 
-    vec3 f0 = vec3(0.04);
+    // Reflectance at normal incidence for dielectrics derived from the index of refraction
+    float ior = (uIOR - 1.0) / (uIOR + 1.0);
+    vec3 f0 = vec3(ior * ior);
     vec3 diffuseColor = baseColor.rgb * (vec3(1.0) - f0);
     diffuseColor *= 1.0 - metallic;
 
+#ifdef USE_TRANSMISSION
+    // Transmitted light replaces the diffuse contribution. As the scene behind
+    // the surface is not available it is approximated by reducing the opacity.
+    float transmission = uTransmissionFactor;
+#ifdef HAS_TRANSMISSIONMAP
+    transmission *= texture(uTransmissionSampler, texcoord(uTransmissionTexParams, uTexRotation(TRANSMISSION_MAP))).r;
+#endif
+    diffuseColor *= 1.0 - transmission;
+    baseColor.a *= 1.0 - transmission * (1.0 - metallic);
+#endif
+
     vec3 specularColor = mix(f0, baseColor.rgb, uMetallicFactor);
 
     // Compute reflectance.
@@ -291,9 +427,47 @@ void main() {
         specularEnvironmentR90,
         alphaRoughness,
         diffuseColor,
-        specularColor
+        specularColor,
+        vec3(0.0),
+        0.0,
+        0.0,
+        vec3(0.0),
+        vec3(0.0),
+        0.0
     );
 
+    mat3 tbn = getTBN();
+    pbrInputs.n = getNormal(tbn);
+
+#ifdef USE_CLEARCOAT
+    float clearcoat = uClearcoatFactor;
+    float clearcoatRoughness = uClearcoatRoughnessFactor;
+#ifdef HAS_CLEARCOATMAP
+    clearcoat *= texture(uClearcoatSampler, texcoord(uClearcoatTexParams, uTexRotation(CLEARCOAT_MAP))).r;
+#endif
+#ifdef HAS_CLEARCOATROUGHNESSMAP
+    clearcoatRoughness *= texture(uClearcoatRoughnessSampler, texcoord(uClearcoatRoughnessTexParams, uTexRotation(CLEARCOATROUGHNESS_MAP))).g;
+#endif
+    clearcoatRoughness = clamp(clearcoatRoughness, c_MinRoughness, 1.0);
+    pbrInputs.clearcoat = clearcoat;
+    pbrInputs.clearcoatRoughness = clearcoatRoughness * clearcoatRoughness;
+    pbrInputs.clearcoatNormal = getClearcoatNormal(tbn);
+#endif
+
+#ifdef USE_SHEEN
+    vec3 sheenColor = uSheenColorFactor;
+    float sheenRoughness = uSheenRoughnessFactor;
+#ifdef HAS_SHEENCOLORMAP
+    sheenColor *= SRGBtoLINEAR(texture(uSheenColorSampler, texcoord(uSheenColorTexParams, uTexRotation(SHEENCOLOR_MAP)))).rgb;
+#endif
+#ifdef HAS_SHEENROUGHNESSMAP
+    sheenRoughness *= texture(uSheenRoughnessSampler, texcoord(uSheenRoughnessTexParams, uTexRotation(SHEENROUGHNESS_MAP))).a;
+#endif
+    sheenRoughness = clamp(sheenRoughness, c_MinRoughness, 1.0);
+    pbrInputs.sheenColor = sheenColor;
+    pbrInputs.sheenRoughness = sheenRoughness * sheenRoughness;
+#endif
+
 //    vec3 normal = getNormal();
     vec3 color = vec3(0.0);
 
@@ -367,15 +541,16 @@ void main() {
 
     // Apply optional PBR terms for additional (optional) shading
 #ifdef HAS_OCCLUSIONMAP
-    float ao = texture(uOcclusionSampler, FragTexcoord).r;
+    float ao = texture(uOcclusionSampler, texcoord(uOcclusionTexParams, uTexRotation(OCCLUSION_MAP))).r;
     color = mix(color, color * ao, 1.0);//, uOcclusionStrength);
 #endif
 
 #ifdef HAS_EMISSIVEMAP
-    vec3 emissive = SRGBtoLINEAR(texture(uEmissiveSampler, FragTexcoord)).rgb * vec3(uEmissiveColor);
+    vec3 emissive = SRGBtoLINEAR(texture(uEmissiveSampler, texcoord(uEmissiveTexParams, uTexRotation(EMISSIVE_MAP)))).rgb * vec3(uEmissiveColor);
 #else
     vec3 emissive = vec3(uEmissiveColor);
 #endif
+    emissive *= uEmissiveStrength;
     color += emissive;
 
     // Base Color
@@ -410,4 +585,7 @@ void main() {
 
     // Final fragment color
     FragColor = vec4(pow(color,vec3(1.0/2.2)), baseColor.a);
+#ifdef FOG
+    FragColor.rgb = applyFog(FragColor.rgb, Position);
+#endif
 }
@@ -17,6 +17,10 @@ out vec3 Position;
 out vec3 Normal;
 out vec3 CamDir;
 out vec2 FragTexcoord;
+#ifdef HAS_TANGENTS
+in vec4 VertexTangent;
+out vec4 Tangent;
+#endif
 
 void main() {
 
@@ -33,6 +37,11 @@ void main() {
     // Output texture coordinates to fragment shader
     FragTexcoord = VertexTexcoord;
 
+#ifdef HAS_TANGENTS
+    // Transform this vertex tangent to camera coordinates keeping the bitangent handedness
+    Tangent = vec4(normalize(mat3(ModelViewMatrix) * VertexTangent.xyz), VertexTangent.w);
+#endif
+
     vec3 vPosition = VertexPosition;
     mat4 finalWorld = mat4(1.0);
     #include <morphtarget_vertex>
@@ -1,10 +1,14 @@
 precision highp float;
 
 #include <material>
+#include <fog>
 
 // Inputs from vertex shader
 in vec3 Color;
 flat in mat2 Rotation;
+#ifdef FOG
+in vec3 FogPosition;
+#endif
 
 // Output
 out vec4 FragColor;
@@ -51,4 +55,7 @@ void main() {
 
     // Generates final color
     FragColor = min(vec4(Color, MatOpacity) * texMixed, vec4(1));
+#ifdef FOG
+    FragColor.rgb = applyFog(FragColor.rgb, FogPosition);
+#endif
 }
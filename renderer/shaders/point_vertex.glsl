@@ -10,6 +10,9 @@ uniform mat4 MV;
 // Outputs for fragment shader
 out vec3 Color;
 flat out mat2 Rotation;
+#ifdef FOG
+out vec3 FogPosition;
+#endif
 
 void main() {
 
@@ -25,6 +28,9 @@ void main() {
     // Sets the size of the rasterized point decreasing with distance
     vec4 posMV = MV * vec4(VertexPosition, 1.0);
     gl_PointSize = MatPointSize / -posMV.z;
+#ifdef FOG
+    FogPosition = posMV.xyz;
+#endif
 
     // Outputs color
     Color = MatEmissiveColor;
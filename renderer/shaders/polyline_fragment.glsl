@@ -0,0 +1,95 @@
+precision highp float;
+
+// Inputs from the vertex shader
+in vec4 Color;
+in vec2 Offset;
+in float Distance;
+in float HalfWidth;
+flat in vec4 Dirs;
+flat in vec4 Shape;
+
+// Polyline uniforms
+uniform vec4 LineStyle[6];
+#define LsDashTotal         LineStyle[2].y
+#define LsDashOffset        LineStyle[2].z
+
+// Kinds of quads, joins and caps
+#define KIND_SEGMENT        0
+#define KIND_JOIN           1
+#define JOIN_ROUND          1
+#define JOIN_BEVEL          2
+#define CAP_ROUND           1
+#define CAP_SQUARE          2
+
+// Output
+out vec4 FragColor;
+
+// Returns the coverage of a pixel at the specified signed distance in pixels inside an edge
+float coverage(float d) {
+    return clamp(d + 0.5, 0.0, 1.0);
+}
+
+// Returns the length of the specified dash or gap of the dash pattern
+float dashLength(int i) {
+    vec4 v = LineStyle[3 + i / 4];
+    return v[i % 4];
+}
+
+void main() {
+
+    float hw = HalfWidth;
+    int kind = int(Shape.x + 0.5);
+    float alpha;
+    if (kind == KIND_SEGMENT) {
+        alpha = coverage(hw - abs(Offset.y));
+    } else if (kind == KIND_JOIN) {
+        // Only the wedge outside both segments is drawn by the join
+        vec2 d1 = Dirs.xy;
+        vec2 d2 = Dirs.zw;
+        if (dot(Offset, d1) < 0.0 || dot(Offset, d2) > 0.0) {
+            discard;
+        }
+        int join = int(Shape.y + 0.5);
+        if (join == JOIN_ROUND) {
+            alpha = coverage(hw - length(Offset));
+        } else {
+            vec2 o1 = vec2(-d1.y, d1.x) * Shape.z;
+            vec2 o2 = vec2(-d2.y, d2.x) * Shape.z;
+            alpha = coverage(hw - max(dot(Offset, o1), dot(Offset, o2)));
+            if (join == JOIN_BEVEL) {
+                vec2 bis = o1 + o2;
+                bis = length(bis) > 1e-3 ? normalize(bis) : d1;
+                alpha = min(alpha, coverage(hw * Shape.w - dot(Offset, bis)));
+            }
+        }
+    } else {
+        int cap = int(Shape.y + 0.5);
+        if (cap == CAP_ROUND) {
+            alpha = coverage(hw - length(Offset));
+        } else {
+            float ext = cap == CAP_SQUARE ? hw : 0.0;
+            alpha = min(coverage(hw - abs(dot(Offset, Dirs.zw))), coverage(ext - dot(Offset, Dirs.xy)));
+        }
+    }
+
+    // Discards the gaps of the dash pattern
+    if (LsDashTotal > 0.0) {
+        float p = mod(Distance + LsDashOffset, LsDashTotal);
+        float start = 0.0;
+        for (int i = 0; i < 8; i++) {
+            start += dashLength(i);
+            if (p < start) {
+                if (i % 2 == 1) {
+                    discard;
+                }
+                break;
+            }
+        }
+    }
+
+    float a = Color.a * alpha;
+    if (a < 0.004) {
+        discard;
+    }
+    FragColor = vec4(Color.rgb, a);
+}
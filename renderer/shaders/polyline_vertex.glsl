@@ -0,0 +1,165 @@
+// Polyline attributes with fixed locations.
+// Segments have their start point in LinePoint0 and their end point in VertexPosition.
+// Joins have the previous point in LinePoint0, the joined point in VertexPosition and the next point in LinePoint2.
+// Caps have the neighbour point in LinePoint0 and the end point in VertexPosition.
+// The params are (kind, corner, side, distance along the line).
+layout(location = 0) in vec3 VertexPosition;
+layout(location = 1) in vec3 LinePoint0;
+layout(location = 2) in vec3 LinePoint2;
+layout(location = 3) in vec4 LineParams;
+layout(location = 4) in vec4 LineColor;
+
+// Model view projection matrix
+uniform mat4 MVP;
+
+// Polyline uniforms
+uniform vec4 LineStyle[6];
+#define LsColor             LineStyle[0]
+#define LsWidth             LineStyle[1].x
+#define LsPixelsPerUnit     LineStyle[1].y
+#define LsJoin              LineStyle[1].z
+#define LsCap               LineStyle[1].w
+#define LsMiterLimit        LineStyle[2].x
+#define LsViewport          LineStyle[5].xy
+
+// Kinds of quads, joins and caps
+#define KIND_SEGMENT        0
+#define KIND_JOIN           1
+#define JOIN_MITER          0
+#define JOIN_ROUND          1
+#define JOIN_BEVEL          2
+#define CAP_BUTT            0
+
+// Outputs for the fragment shader
+out vec4 Color;
+out vec2 Offset;
+out float Distance;
+out float HalfWidth;
+flat out vec4 Dirs;
+flat out vec4 Shape;
+
+// Returns the position in pixels in the viewport of the specified clip position
+vec2 toScreen(vec4 clip) {
+    return (clip.xy / clip.w * 0.5 + 0.5) * LsViewport;
+}
+
+// Returns the clip position of the specified position in pixels with the depth of the specified clip position
+vec4 toClip(vec2 screen, vec4 clip) {
+    return vec4((screen / LsViewport * 2.0 - 1.0) * clip.w, clip.z, clip.w);
+}
+
+// Returns the half width of the line in pixels at the specified clip position
+float halfWidth(vec4 clip) {
+    float w = LsWidth * 0.5;
+    if (LsPixelsPerUnit > 0.0) {
+        w *= LsPixelsPerUnit / clip.w;
+    }
+    return w;
+}
+
+// Moves the first clip position towards the second one up to the near plane when it is behind it
+vec4 clipNear(vec4 a, vec4 b) {
+    float da = a.z + a.w;
+    float db = b.z + b.w;
+    if (da < 0.0 && db > 0.0) {
+        return mix(a, b, da / (da - db));
+    }
+    return a;
+}
+
+// Returns the unit direction from a to b
+vec2 direction(vec2 a, vec2 b) {
+    vec2 d = b - a;
+    float l = length(d);
+    return l > 1e-6 ? d / l : vec2(1.0, 0.0);
+}
+
+// Returns the direction rotated 90 degrees counterclockwise
+vec2 perp(vec2 d) {
+    return vec2(-d.y, d.x);
+}
+
+void main() {
+
+    int kind = int(LineParams.x + 0.5);
+    int corner = int(LineParams.y + 0.5);
+    Color = LineColor * LsColor;
+    Distance = LineParams.w;
+    Dirs = vec4(0.0);
+    Shape = vec4(float(kind), 0.0, 0.0, 0.0);
+    vec4 center = MVP * vec4(VertexPosition, 1.0);
+    vec4 prev = MVP * vec4(LinePoint0, 1.0);
+
+    // Segments are quads between their end points expanded by one pixel for antialiasing
+    if (kind == KIND_SEGMENT) {
+        if (prev.z + prev.w < 0.0 && center.z + center.w < 0.0) {
+            gl_Position = vec4(0.0, 0.0, 2.0, 1.0);
+            return;
+        }
+        vec4 a = clipNear(prev, center);
+        vec4 b = clipNear(center, prev);
+        vec2 sa = toScreen(a);
+        vec2 sb = toScreen(b);
+        vec2 n = perp(direction(sa, sb));
+        vec4 clip = corner == 0 ? a : b;
+        float hw = halfWidth(clip);
+        float side = LineParams.z * (hw + 1.0);
+        HalfWidth = hw;
+        Offset = vec2(0.0, side);
+        gl_Position = toClip((corner == 0 ? sa : sb) + n * side, clip);
+        return;
+    }
+
+    // Joins and caps are quads around their center point
+    if (center.z + center.w < 0.0) {
+        gl_Position = vec4(0.0, 0.0, 2.0, 1.0);
+        return;
+    }
+    vec2 sc = toScreen(center);
+    vec2 sp = toScreen(clipNear(prev, center));
+    float hw = halfWidth(center);
+    float e = hw + 1.0;
+    HalfWidth = hw;
+    vec2 offset;
+    if (kind == KIND_JOIN) {
+        // Fills the wedge between the outer sides of the joined segments
+        vec2 sn = toScreen(clipNear(MVP * vec4(LinePoint2, 1.0), center));
+        vec2 d1 = direction(sp, sc);
+        vec2 d2 = direction(sc, sn);
+        float s = d1.x * d2.y - d1.y * d2.x > 0.0 ? -1.0 : 1.0;
+        vec2 o1 = perp(d1) * s;
+        vec2 o2 = perp(d2) * s;
+        vec2 bis = o1 + o2;
+        bis = length(bis) > 1e-3 ? normalize(bis) : d1;
+        float cosHalf = max(dot(bis, o1), 1e-3);
+        int join = int(LsJoin + 0.5);
+        if (join == JOIN_MITER && cosHalf * LsMiterLimit < 1.0) {
+            join = JOIN_BEVEL;
+        }
+        if (join == JOIN_ROUND) {
+            vec2 t = perp(bis) * e;
+            offset = corner == 0 ? -t : corner == 1 ? bis * e - t : corner == 2 ? bis * e + t : t;
+        } else if (corner == 0) {
+            offset = vec2(0.0);
+        } else if (corner == 1) {
+            offset = o1 * e;
+        } else if (corner == 2) {
+            offset = join == JOIN_MITER ? bis * (e / cosHalf) : bis * (hw * cosHalf + 1.0);
+        } else {
+            offset = o2 * e;
+        }
+        Dirs = vec4(d1, d2);
+        Shape = vec4(float(kind), float(join), s, cosHalf);
+    } else {
+        // Extends the line beyond its end point
+        vec2 d = direction(sp, sc);
+        vec2 n = perp(d) * e;
+        int cap = int(LsCap + 0.5);
+        float ext = (cap == CAP_BUTT ? 0.0 : hw) + 1.0;
+        offset = corner == 0 ? -n : corner == 1 ? d * ext - n : corner == 2 ? d * ext + n : n;
+        Dirs = vec4(d, perp(d));
+        Shape = vec4(float(kind), float(cap), 0.0, 0.0);
+    }
+    Offset = offset;
+    gl_Position = toClip(sc + offset, center);
+}
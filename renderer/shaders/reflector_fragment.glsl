@@ -0,0 +1,84 @@
+precision highp float;
+
+// Inputs from the vertex shader
+in vec4 ReflCoord;
+in vec4 ClipPosition;
+in vec3 WorldPosition;
+in vec2 FragTexcoord;
+
+// Reflector parameters:
+// [0] color and opacity
+// [1] wave scale, distortion, fresnel power, fresnel bias
+// [2] offsets of the two normal map layers
+// [3] direction to the sun, shininess
+// [4] sun color, depth fade distance
+// [5] camera position, 0
+// [6] projection [2][2] and [3][2], reflecting, 0
+uniform vec4 ReflectorParams[7];
+uniform mat4 ModelMatrix;
+uniform sampler2D uReflectionSampler;
+#ifdef WATER
+uniform sampler2D uNormalSampler;
+#endif
+#ifdef HAS_REFRACTION
+uniform sampler2D uRefractionSampler;
+uniform sampler2D uRefractionDepthSampler;
+#endif
+
+// Output
+out vec4 FragColor;
+
+#ifdef HAS_REFRACTION
+// Returns the view distance of a depth buffer value
+float viewDepth(float depth) {
+
+    return ReflectorParams[6].y / ((depth * 2.0 - 1.0) + ReflectorParams[6].x);
+}
+#endif
+
+void main() {
+
+    vec4 color = ReflectorParams[0];
+    bool reflecting = ReflectorParams[6].z > 0.5;
+#ifndef WATER
+    vec3 reflection = reflecting ? texture(uReflectionSampler, ReflCoord.xy / ReflCoord.w).rgb : vec3(0.0);
+    FragColor = vec4(reflection * color.rgb, color.a);
+#else
+    // Normal from two scrolling layers of the normal map, in world coordinates
+    vec2 uv = FragTexcoord * ReflectorParams[1].x;
+    vec3 n1 = texture(uNormalSampler, uv + ReflectorParams[2].xy).rgb * 2.0 - 1.0;
+    vec3 n2 = texture(uNormalSampler, uv * 1.37 + ReflectorParams[2].zw).rgb * 2.0 - 1.0;
+    vec3 tn = normalize(n1 + n2);
+    vec3 normal = normalize(mat3(ModelMatrix) * tn);
+    vec2 distortion = tn.xy * ReflectorParams[1].y;
+
+    vec3 reflection = color.rgb;
+    if (reflecting) {
+        reflection = texture(uReflectionSampler, ReflCoord.xy / ReflCoord.w + distortion).rgb;
+    }
+
+    // Scene seen through the water, colored by the thickness of water in front of it
+    vec3 refraction = color.rgb;
+#ifdef HAS_REFRACTION
+    vec2 screen = ClipPosition.xy / ClipPosition.w * 0.5 + 0.5;
+    vec2 refrCoord = screen + distortion;
+    float sceneDepth = viewDepth(texture(uRefractionDepthSampler, refrCoord).r);
+    if (sceneDepth < ClipPosition.w) {
+        // The distorted sample is in front of the water
+        refrCoord = screen;
+        sceneDepth = viewDepth(texture(uRefractionDepthSampler, refrCoord).r);
+    }
+    float thickness = max(sceneDepth - ClipPosition.w, 0.0);
+    float fade = 1.0 - exp(-thickness / max(ReflectorParams[4].w, 1e-4));
+    refraction = mix(texture(uRefractionSampler, refrCoord).rgb, color.rgb, fade * color.a);
+#endif
+
+    // Fresnel blending and sun highlight
+    vec3 viewDir = normalize(ReflectorParams[5].xyz - WorldPosition);
+    float cosTheta = max(dot(viewDir, normal), 0.0);
+    float fresnel = ReflectorParams[1].w + (1.0 - ReflectorParams[1].w) * pow(1.0 - cosTheta, ReflectorParams[1].z);
+    vec3 halfDir = normalize(ReflectorParams[3].xyz + viewDir);
+    float specular = pow(max(dot(normal, halfDir), 0.0), ReflectorParams[3].w);
+    FragColor = vec4(mix(refraction, reflection, clamp(fresnel, 0.0, 1.0)) + specular * ReflectorParams[4].rgb, 1.0);
+#endif
+}
@@ -0,0 +1,21 @@
+#include <attributes>
+
+// Model uniforms
+uniform mat4 MVP;
+uniform mat4 ModelMatrix;
+uniform mat4 ReflTexMatrix;
+
+// Outputs for the fragment shader
+out vec4 ReflCoord;
+out vec4 ClipPosition;
+out vec3 WorldPosition;
+out vec2 FragTexcoord;
+
+void main() {
+
+    ReflCoord = ReflTexMatrix * vec4(VertexPosition, 1.0);
+    WorldPosition = (ModelMatrix * vec4(VertexPosition, 1.0)).xyz;
+    FragTexcoord = VertexTexcoord;
+    ClipPosition = MVP * vec4(VertexPosition, 1.0);
+    gl_Position = ClipPosition;
+}
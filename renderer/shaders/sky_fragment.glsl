@@ -0,0 +1,146 @@
+precision highp float;
+
+// Input from the vertex shader
+in vec3 Direction;
+
+// Sky parameters:
+// [0] direction to the sun, sun intensity
+// [1] Rayleigh scattering, sun fade
+// [2] Mie scattering, Mie directionality
+// [3] fog color, sine of the fog elevation
+// [4] stars brightness, clouds coverage, clouds scale, luminance
+// [5] sunlight color, 0
+// [6] clouds offset, 0, 0
+uniform vec4 SkyParams[7];
+
+#include <fog>
+
+// Output
+out vec4 FragColor;
+
+const float PI = 3.141592653589793;
+const float RAYLEIGH_ZENITH_LENGTH = 8.4E3;
+const float MIE_ZENITH_LENGTH = 1.25E3;
+const float SUN_ANGULAR_DIAMETER_COS = 0.999956676946448;
+
+float rayleighPhase(float cosTheta) {
+
+    return 0.05968310365946075 * (1.0 + pow(cosTheta, 2.0));
+}
+
+float hgPhase(float cosTheta, float g) {
+
+    float g2 = g * g;
+    return 0.07957747154594767 * (1.0 - g2) / pow(1.0 - 2.0 * g * cosTheta + g2, 1.5);
+}
+
+// Uncharted 2 filmic tone mapping
+vec3 tonemap(vec3 x) {
+
+    const float A = 0.15, B = 0.50, C = 0.10, D = 0.20, E = 0.02, F = 0.30;
+    return ((x * (A * x + C * B) + D * E) / (x * (A * x + B) + D * F)) - E / F;
+}
+
+float hash(vec3 p) {
+
+    p = fract(p * 0.3183099 + 0.1);
+    p *= 17.0;
+    return fract(p.x * p.y * p.z * (p.x + p.y + p.z));
+}
+
+float hash(vec2 p) {
+
+    return fract(sin(dot(p, vec2(127.1, 311.7))) * 43758.5453);
+}
+
+float noise(vec2 p) {
+
+    vec2 i = floor(p);
+    vec2 f = fract(p);
+    f = f * f * (3.0 - 2.0 * f);
+    float a = hash(i);
+    float b = hash(i + vec2(1.0, 0.0));
+    float c = hash(i + vec2(0.0, 1.0));
+    float d = hash(i + vec2(1.0, 1.0));
+    return mix(mix(a, b, f.x), mix(c, d, f.x), f.y);
+}
+
+float fbm(vec2 p) {
+
+    float v = 0.0;
+    float a = 0.5;
+    for (int i = 0; i < 5; i++) {
+        v += a * noise(p);
+        p *= 2.0;
+        a *= 0.5;
+    }
+    return v;
+}
+
+void main() {
+
+    vec3 direction = normalize(Direction);
+    vec3 sunDirection = SkyParams[0].xyz;
+    float sunE = SkyParams[0].w;
+    vec3 betaR = SkyParams[1].xyz;
+    float sunFade = SkyParams[1].w;
+    vec3 betaM = SkyParams[2].xyz;
+
+    // Optical length and extinction
+    float zenithAngle = acos(max(0.0, direction.y));
+    float inverse = 1.0 / (cos(zenithAngle) + 0.15 * pow(93.885 - ((zenithAngle * 180.0) / PI), -1.253));
+    vec3 Fex = exp(-(betaR * RAYLEIGH_ZENITH_LENGTH * inverse + betaM * MIE_ZENITH_LENGTH * inverse));
+
+    // In-scattering
+    float cosTheta = dot(direction, sunDirection);
+    vec3 betaTheta = betaR * rayleighPhase(cosTheta * 0.5 + 0.5) + betaM * hgPhase(cosTheta, SkyParams[2].w);
+    vec3 Lin = pow(sunE * (betaTheta / (betaR + betaM)) * (1.0 - Fex), vec3(1.5));
+    Lin *= mix(vec3(1.0), pow(sunE * (betaTheta / (betaR + betaM)) * Fex, vec3(0.5)), clamp(pow(1.0 - sunDirection.y, 5.0), 0.0, 1.0));
+
+    // Sun disk
+    vec3 L0 = vec3(0.1) * Fex;
+    float sundisk = smoothstep(SUN_ANGULAR_DIAMETER_COS, SUN_ANGULAR_DIAMETER_COS + 0.00002, cosTheta);
+    L0 += (sunE * 19000.0 * Fex) * sundisk;
+
+    vec3 texColor = (Lin + L0) * 0.04 + vec3(0.0, 0.0003, 0.00075);
+    vec3 curr = tonemap(log2(2.0 / pow(SkyParams[4].w, 4.0)) * texColor);
+    vec3 color = curr / tonemap(vec3(1000.0));
+    color = pow(color, vec3(1.0 / (1.2 + (1.2 * sunFade))));
+
+    // Clouds layer
+    float density = 0.0;
+    if (SkyParams[4].y > 0.0 && direction.y > 0.0) {
+        vec2 p = direction.xz / max(direction.y, 0.05) * SkyParams[4].z + SkyParams[6].xy;
+        float coverage = SkyParams[4].y;
+        density = smoothstep(1.0 - coverage, 1.0 - coverage + 0.25, fbm(p)) * smoothstep(0.0, 0.15, direction.y);
+    }
+
+    // Stars at night
+    if (SkyParams[4].x > 0.0 && direction.y > 0.0) {
+        vec3 c = direction * 300.0;
+        float h = hash(floor(c));
+        if (h > 0.997) {
+            float star = max(0.0, 1.0 - length(fract(c) - 0.5) * 3.0) * fract(h * 1000.0);
+            float night = smoothstep(0.1, -0.1, sunDirection.y) * smoothstep(0.0, 0.1, direction.y);
+            color += vec3(star * night * SkyParams[4].x * (1.0 - density));
+        }
+    }
+
+    // Clouds lit by the sunlight
+    if (density > 0.0) {
+        vec3 sunColor = SkyParams[5].rgb;
+        vec3 cloudColor = mix(vec3(0.02, 0.02, 0.03), sunColor, clamp(sunDirection.y * 4.0 + 0.2, 0.05, 1.0));
+        cloudColor += sunColor * pow(max(cosTheta, 0.0), 8.0) * 0.3;
+        color = mix(color, cloudColor, density * 0.9);
+    }
+
+    // Fog toward the horizon, from the scene fog if any
+#ifdef FOG
+    color = mix(color, FogParams[0].rgb, fogSkyFactor(direction.y));
+#else
+    if (SkyParams[3].w > 0.0) {
+        color = mix(color, SkyParams[3].rgb, 1.0 - smoothstep(0.0, SkyParams[3].w, direction.y));
+    }
+#endif
+    FragColor = vec4(color, 1.0);
+}
@@ -0,0 +1,15 @@
+#include <attributes>
+
+// Projection and view rotation
+uniform mat4 MVP;
+
+// Output for the fragment shader
+out vec3 Direction;
+
+void main() {
+
+    // On the far plane so that the depth test keeps the sky behind the scene
+    Direction = VertexPosition;
+    vec4 position = MVP * vec4(VertexPosition, 1.0);
+    gl_Position = position.xyww;
+}
@@ -58,6 +58,52 @@ const include_bones_vertex_declaration_source = `#ifdef BONE_INFLUENCERS
 #endif
 `
 
+const include_fog_source = `#ifdef FOG
+// Scene fog parameters:
+// [0] color, sine of the elevation up to which skies are blended
+// [1] linear start and end distances, density, height falloff
+// [2] up direction in camera coordinates, camera height above the fog base height
+uniform vec4 FogParams[3];
+
+// Returns the amount of fog, from 0 to 1, of the fragment at the specified position in camera coordinates.
+float fogFactor(vec3 position) {
+
+    float dist = length(position);
+    float scale = 1.0;
+#ifdef FOG_HEIGHT
+    // Average of the density decreasing exponentially with the height along the view ray
+    float falloff = FogParams[1].w;
+    float t = falloff * dot(FogParams[2].xyz, position);
+    scale = exp(-falloff * FogParams[2].w) * (abs(t) > 1e-4 ? (1.0 - exp(-t)) / t : 1.0);
+#endif
+#if defined(FOG_LINEAR)
+    return clamp((dist - FogParams[1].x) / (FogParams[1].y - FogParams[1].x) * scale, 0.0, 1.0);
+#elif defined(FOG_EXP)
+    return 1.0 - exp(-FogParams[1].z * dist * scale);
+#else
+    float d = FogParams[1].z * dist;
+    return 1.0 - exp(-d * d * scale);
+#endif
+}
+
+// Returns the amount of fog of a sky with the specified sine of the elevation,
+// increasing toward the horizon.
+float fogSkyFactor(float elevation) {
+
+    if (FogParams[0].w <= 0.0) {
+        return 0.0;
+    }
+    return 1.0 - smoothstep(0.0, FogParams[0].w, elevation);
+}
+
+// Returns the color of the fragment at the specified position in camera coordinates blended with the fog.
+vec3 applyFog(vec3 color, vec3 position) {
+
+    return mix(color, FogParams[0].rgb, fogFactor(position));
+}
+#endif
+`
+
 const include_lights_source = `//
 // Lights uniforms
 //
@@ -104,7 +150,7 @@ const include_material_source = `//
 //
 
 // Material parameters uniform array
-uniform vec3 Material[6];
+uniform vec3 Material[7];
 // Macros to access elements inside the Material array
 #define MatAmbientColor		Material[0]
 #define MatDiffuseColor     Material[1]
@@ -114,6 +160,9 @@ uniform vec3 Material[6];
 #define MatOpacity          Material[4].y
 #define MatPointSize        Material[4].z
 #define MatPointRotationZ   Material[5].x
+#define MatBumpScale        Material[5].y
+#define MatParallaxScale    Material[5].z
+#define MatNormalScale      Material[6].xy
 
 #if MAT_TEXTURES > 0
     // Texture unit sampler array
@@ -170,6 +219,8 @@ const include_phong_model_source = `/***
     camDir:     input camera directions
     matAmbient: input material ambient color
     matDiffuse: input material diffuse color
+    matSpecular: input material specular color
+    matEmissive: input material emissive color
     ambdiff:    output ambient+diffuse color
     spec:       output specular color
  Uniforms:
@@ -180,10 +231,9 @@ const include_phong_model_source = `/***
     PointLightPosition[]
     PointLightLinearDecay[]
     PointLightQuadraticDecay[]
-    MatSpecularColor
     MatShininess
 *****/
-void phongModel(vec4 position, vec3 normal, vec3 camDir, vec3 matAmbient, vec3 matDiffuse, out vec3 ambdiff, out vec3 spec) {
+void phongModel(vec4 position, vec3 normal, vec3 camDir, vec3 matAmbient, vec3 matDiffuse, vec3 matSpecular, vec3 matEmissive, out vec3 ambdiff, out vec3 spec) {
 
     vec3 ambientTotal  = vec3(0.0);
     vec3 diffuseTotal  = vec3(0.0);
@@ -208,7 +258,7 @@ void phongModel(vec4 position, vec3 normal, vec3 camDir, vec3 matAmbient, vec3 m
         float dotNormal = dot(lightDirection, normal); // Dot product between light direction and fragment normal
         if (dotNormal > EPS) { // If the fragment is lit
             diffuseTotal += DirLightColor(i) * matDiffuse * dotNormal;
-            specularTotal += DirLightColor(i) * MatSpecularColor * pow(max(dot(reflect(-lightDirection, normal), camDir), 0.0), MatShininess);
+            specularTotal += DirLightColor(i) * matSpecular * pow(max(dot(reflect(-lightDirection, normal), camDir), 0.0), MatShininess);
         }
     }
 #endif
@@ -225,7 +275,7 @@ void phongModel(vec4 position, vec3 normal, vec3 camDir, vec3 matAmbient, vec3 m
             float attenuation = 1.0 / (1.0 + lightDistance * (PointLightLinearDecay(i) + PointLightQuadraticDecay(i) * lightDistance));
             vec3 attenuatedColor = PointLightColor(i) * attenuation;
             diffuseTotal += attenuatedColor * matDiffuse * dotNormal;
-            specularTotal += attenuatedColor * MatSpecularColor * pow(max(dot(reflect(-lightDirection, normal), camDir), 0.0), MatShininess);
+            specularTotal += attenuatedColor * matSpecular * pow(max(dot(reflect(-lightDirection, normal), camDir), 0.0), MatShininess);
         }
     }
 #endif
@@ -247,7 +297,7 @@ void phongModel(vec4 position, vec3 normal, vec3 camDir, vec3 matAmbient, vec3 m
                 float spotFactor = pow(angleDot, SpotLightAngularDecay(i));
                 vec3 attenuatedColor = SpotLightColor(i) * attenuation * spotFactor;
                 diffuseTotal += attenuatedColor * matDiffuse * dotNormal;
-                specularTotal += attenuatedColor * MatSpecularColor * pow(max(dot(reflect(-lightDirection, normal), camDir), 0.0), MatShininess);
+                specularTotal += attenuatedColor * matSpecular * pow(max(dot(reflect(-lightDirection, normal), camDir), 0.0), MatShininess);
             }
         }
     }
@@ -256,19 +306,27 @@ void phongModel(vec4 position, vec3 normal, vec3 camDir, vec3 matAmbient, vec3 m
         diffuseTotal = matDiffuse;
     }
     // Sets output colors
-    ambdiff = ambientTotal + MatEmissiveColor + diffuseTotal;
+    ambdiff = ambientTotal + matEmissive + diffuseTotal;
     spec = specularTotal;
 }
 `
 
 const basic_fragment_source = `precision highp float;
 
+#include <fog>
+
 in vec3 Color;
+#ifdef FOG
+in vec3 FogPosition;
+#endif
 out vec4 FragColor;
 
 void main() {
 
     FragColor = vec4(Color, 1.0);
+#ifdef FOG
+    FragColor.rgb = applyFog(FragColor.rgb, FogPosition);
+#endif
 }
 `
 
@@ -276,6 +334,10 @@ const basic_vertex_source = `#include <attributes>
 
 // Model uniforms
 uniform mat4 MVP;
+#ifdef FOG
+uniform mat4 ModelViewMatrix;
+out vec3 FogPosition;
+#endif
 
 // Final output color for fragment shader
 out vec3 Color;
@@ -283,6 +345,139 @@ out vec3 Color;
 void main() {
 
     Color = VertexColor;
+#ifdef FOG
+    FogPosition = (ModelViewMatrix * vec4(VertexPosition, 1.0)).xyz;
+#endif
+    gl_Position = MVP * vec4(VertexPosition, 1.0);
+}
+`
+
+const decal_fragment_source = `precision highp float;
+
+// Decal texture and its offset, repeat and flip
+uniform sampler2D uDecalSampler;
+uniform vec2 uDecalTexParams[3];
+
+// Depth buffer of the scene rendered before the decal
+uniform sampler2D uDepthSampler;
+
+// Inverse projection and model view matrices
+uniform mat4 DecalInvProj;
+uniform mat4 DecalInvModelView;
+
+// Decal uniforms
+uniform vec4 DecalParams[3];
+#define DecalColor          DecalParams[0]
+#define DecalViewport       DecalParams[1]
+#define DecalAxis           DecalParams[2].xyz
+#define DecalMinCos         DecalParams[2].w
+
+// Output
+out vec4 FragColor;
+
+// Returns the texture coordinates of the decal texture from the base coordinates
+// applying the texture's optional Y flip, repeat and offset.
+vec2 mapTexcoord(vec2 uv, vec2 texParams[3]) {
+    if (bool(texParams[2].x)) {
+        uv.y = 1.0 - uv.y;
+    }
+    return uv * texParams[1] + texParams[0];
+}
+
+void main() {
+
+    // Reconstructs the view position of the surface behind the fragment from the depth buffer
+    vec2 frag = gl_FragCoord.xy - DecalViewport.xy;
+    float depth = texelFetch(uDepthSampler, ivec2(frag), 0).r;
+    vec4 ndc = vec4(frag / DecalViewport.zw * 2.0 - 1.0, depth * 2.0 - 1.0, 1.0);
+    vec4 view = DecalInvProj * ndc;
+    view /= view.w;
+
+    // Surface normal facing the camera from the derivatives of the view position
+    vec3 normal = normalize(cross(dFdx(view.xyz), dFdy(view.xyz)));
+    if (dot(normal, view.xyz) > 0.0) {
+        normal = -normal;
+    }
+
+    // Discards the surfaces outside the unit box of the decal or facing away from the projection
+    vec3 box = (DecalInvModelView * vec4(view.xyz, 1.0)).xyz;
+    if (any(greaterThan(abs(box), vec3(0.5))) || dot(normal, DecalAxis) <= DecalMinCos) {
+        discard;
+    }
+    FragColor = texture(uDecalSampler, mapTexcoord(box.xy + 0.5, uDecalTexParams)) * DecalColor;
+}
+`
+
+const decal_vertex_source = `#include <attributes>
+
+// Model uniforms
+uniform mat4 MVP;
+
+void main() {
+
+    gl_Position = MVP * vec4(VertexPosition, 1.0);
+}
+`
+
+const impostor_fragment_source = `precision highp float;
+
+#include <fog>
+
+// Inputs from the vertex shader
+in vec2 FragTexcoord0;
+in vec2 FragTexcoord1;
+#ifdef FOG
+in vec3 FogPosition;
+#endif
+
+// Impostor parameters (see vertex shader)
+uniform vec4 ImpostorParams[2];
+uniform sampler2D uImpostorSampler;
+
+// Output
+out vec4 FragColor;
+
+void main() {
+
+    // Cross fades the views baked from the two nearest angles
+    vec4 color = mix(texture(uImpostorSampler, FragTexcoord0), texture(uImpostorSampler, FragTexcoord1), ImpostorParams[1].z);
+    if (color.a < ImpostorParams[1].w) {
+        discard;
+    }
+    // The views are rendered over a transparent black background
+    FragColor = vec4(color.rgb / color.a, 1.0);
+#ifdef FOG
+    FragColor.rgb = applyFog(FragColor.rgb, FogPosition);
+#endif
+}
+`
+
+const impostor_vertex_source = `#include <attributes>
+
+// Model uniforms
+uniform mat4 MVP;
+#ifdef FOG
+uniform mat4 ModelViewMatrix;
+out vec3 FogPosition;
+#endif
+
+// Impostor parameters:
+// [0] atlas offsets of the cells of the two nearest views
+// [1] atlas size of a cell, blend factor between the two views, alpha cutoff
+uniform vec4 ImpostorParams[2];
+
+// Outputs for the fragment shader
+out vec2 FragTexcoord0;
+out vec2 FragTexcoord1;
+
+void main() {
+
+    vec2 uv = VertexTexcoord * ImpostorParams[1].xy;
+    FragTexcoord0 = ImpostorParams[0].xy + uv;
+    FragTexcoord1 = ImpostorParams[0].zw + uv;
+#ifdef FOG
+    FogPosition = (ModelViewMatrix * vec4(VertexPosition, 1.0)).xyz;
+#endif
     gl_Position = MVP * vec4(VertexPosition, 1.0);
 }
 `
@@ -303,7 +498,7 @@ uniform vec2		MatTexinfo[3];
 in vec2 FragTexcoord;
 
 // Input uniform
-uniform vec4 Panel[8];
+uniform vec4 Panel[9];
 #define Bounds			Panel[0]		  // panel bounds in texture coordinates
 #define Border			Panel[1]		  // panel border in texture coordinates
 #define Padding			Panel[2]		  // panel padding in texture coordinates
@@ -312,6 +507,7 @@ uniform vec4 Panel[8];
 #define PaddingColor	Panel[5]		  // panel padding color
 #define ContentColor	Panel[6]		  // panel content color
 #define TextureValid	bool(Panel[7].x)  // texture valid flag
+#define TexRegion		Panel[8]		  // region of the texture displayed in the content area
 
 // Output
 out vec4 FragColor;
@@ -367,7 +563,7 @@ void main() {
             // Adjust texture coordinates to fit texture inside the content area
             vec2 offset = vec2(-Content[0], -Content[1]);
             vec2 factor = vec2(1.0/Content[2], 1.0/Content[3]);
-            vec2 texcoord = (FragTexcoord + offset) * factor;
+            vec2 texcoord = (FragTexcoord + offset) * factor * TexRegion.zw + TexRegion.xy;
             vec4 texColor = texture(MatTexture, texcoord * MatTexRepeat + MatTexOffset);
 
             // Mix content color with texture color.
@@ -434,6 +630,211 @@ void main() {
 }
 `
 
+const particle_fragment_source = `precision highp float;
+
+// Inputs from the vertex shader
+in vec4 Color;
+in vec2 FragTexcoord;
+in float ViewDepth;
+
+#include <fog>
+#ifdef FOG
+in vec3 FogPosition;
+#endif
+
+#ifdef HAS_PARTICLEMAP
+uniform sampler2D uParticleSampler;
+uniform vec2 uParticleTexParams[3];
+
+// Returns the texture coordinates of the particle map from the base coordinates
+// applying the map's optional Y flip, repeat and offset.
+vec2 mapTexcoord(vec2 uv, vec2 texParams[3]) {
+    if (bool(texParams[2].x)) {
+        uv.y = 1.0 - uv.y;
+    }
+    return uv * texParams[1] + texParams[0];
+}
+#endif
+
+#ifdef SOFT_PARTICLES
+// Depth buffer of the scene rendered before the particles
+uniform sampler2D uDepthSampler;
+uniform mat4 ProjMatrix;
+uniform vec4 ParticleSystem[5];
+#endif
+
+// Final fragment color
+out vec4 FragColor;
+
+void main() {
+
+    vec4 color = Color;
+#ifdef HAS_PARTICLEMAP
+    color *= texture(uParticleSampler, mapTexcoord(FragTexcoord, uParticleTexParams));
+#endif
+
+#ifdef SOFT_PARTICLES
+    // Fades out the particle near the surface behind it, comparing their distances to the camera
+    float depth = texelFetch(uDepthSampler, ivec2(gl_FragCoord.xy - ParticleSystem[4].zw), 0).r;
+    float sceneDepth = ProjMatrix[3][2] / ((depth * 2.0 - 1.0) + ProjMatrix[2][2]);
+    color.a *= clamp((sceneDepth - ViewDepth) / ParticleSystem[4].y, 0.0, 1.0);
+#endif
+
+#ifdef FOG
+#ifdef ADDITIVE_BLENDING
+    // Additive particles fade out in the fog instead of adding its color
+    color.rgb *= 1.0 - fogFactor(FogPosition);
+#else
+    color.rgb = applyFog(color.rgb, FogPosition);
+#endif
+#endif
+
+    FragColor = color;
+}
+`
+
+const particle_vertex_source = `// Particle attributes with fixed locations shared by the CPU and GPU simulations.
+// With GPU_SIMULATION the position and velocity are the initial ones, the params are
+// (birth time, lifetime, size, angular velocity) and the color is (initial rotation, seed, 0, 0).
+// Otherwise they are the current ones, the params are (size, rotation, frame, 0) and the color is RGBA.
+layout(location = 0) in vec3 ParticlePosition;
+layout(location = 1) in vec3 ParticleVelocity;
+layout(location = 2) in vec2 ParticleCorner;
+layout(location = 3) in vec4 ParticleParams;
+layout(location = 4) in vec4 ParticleColor;
+
+// Camera uniforms
+uniform mat4 ViewMatrix;
+uniform mat4 ProjMatrix;
+
+// Particle system uniforms
+uniform vec4 ParticleSystem[5];
+#define PsGravity           ParticleSystem[0].xyz
+#define PsDrag              ParticleSystem[0].w
+#define PsVelocity          ParticleSystem[1].xyz
+#define PsTime              ParticleSystem[1].w
+#define PsTurbulence        ParticleSystem[2].x
+#define PsTurbulenceFreq    ParticleSystem[2].y
+#define PsTurbulenceSpeed   ParticleSystem[2].z
+#define PsStretched         ParticleSystem[2].w
+#define PsAtlas             ParticleSystem[3]
+#define PsStretch           ParticleSystem[4].x
+
+#ifdef GPU_SIMULATION
+// Lifetime curves sampled uniformly
+uniform vec4 ParticleColorCurve[16];
+uniform float ParticleSizeCurve[16];
+
+// Returns the color and opacity of the lifetime curve at the normalized lifetime
+vec4 colorCurve(float t) {
+    float x = clamp(t, 0.0, 1.0) * 15.0;
+    int i = min(int(x), 14);
+    return mix(ParticleColorCurve[i], ParticleColorCurve[i + 1], x - float(i));
+}
+
+// Returns the size scale of the lifetime curve at the normalized lifetime
+float sizeCurve(float t) {
+    float x = clamp(t, 0.0, 1.0) * 15.0;
+    int i = min(int(x), 14);
+    return mix(ParticleSizeCurve[i], ParticleSizeCurve[i + 1], x - float(i));
+}
+#endif
+
+// Outputs for the fragment shader
+out vec4 Color;
+out vec2 FragTexcoord;
+out float ViewDepth;
+#ifdef FOG
+out vec3 FogPosition;
+#endif
+
+void main() {
+
+    vec3 position;
+    vec3 velocity;
+    float size;
+    float rotation;
+    float frame;
+#ifdef GPU_SIMULATION
+    float age = PsTime - ParticleParams.x;
+    float lifetime = ParticleParams.y;
+    if (age < 0.0 || age >= lifetime) {
+        // Outside of the clip volume
+        gl_Position = vec4(0.0, 0.0, 2.0, 1.0);
+        return;
+    }
+    float t = age / lifetime;
+
+    // Analytic motion under gravity and linear drag
+    if (PsDrag > 0.0) {
+        float e = exp(-PsDrag * age);
+        vec3 terminal = PsGravity / PsDrag;
+        position = ParticlePosition + terminal * age + (ParticleVelocity - terminal) * (1.0 - e) / PsDrag;
+        velocity = terminal + (ParticleVelocity - terminal) * e;
+    } else {
+        position = ParticlePosition + ParticleVelocity * age + 0.5 * PsGravity * age * age;
+        velocity = ParticleVelocity + PsGravity * age;
+    }
+    position += PsVelocity * age;
+    velocity += PsVelocity;
+
+    // Turbulence approximated by a smooth displacement from the initial position growing with the age
+    if (PsTurbulence > 0.0) {
+        vec3 q = ParticlePosition * PsTurbulenceFreq + ParticleColor.y * 31.4;
+        float s = age * PsTurbulenceSpeed;
+        vec3 noise = vec3(
+            sin(q.y * 1.7 + s) + sin(q.z * 2.3 - s * 1.3),
+            sin(q.z * 1.9 + s * 1.1) + sin(q.x * 2.1 - s * 0.7),
+            sin(q.x * 1.3 + s * 0.9) + sin(q.y * 2.7 - s * 1.7)
+        ) * 0.5;
+        position += noise * PsTurbulence * 0.5 * age * age;
+    }
+
+    size = ParticleParams.z * sizeCurve(t);
+    rotation = ParticleColor.x + ParticleParams.w * age;
+    Color = colorCurve(t);
+    if (PsAtlas.w > 0.0) {
+        frame = mod(floor(age * PsAtlas.w), PsAtlas.z);
+    } else {
+        frame = min(floor(t * PsAtlas.z), PsAtlas.z - 1.0);
+    }
+#else
+    position = ParticlePosition;
+    velocity = ParticleVelocity;
+    size = ParticleParams.x;
+    rotation = ParticleParams.y;
+    frame = ParticleParams.z;
+    Color = ParticleColor;
+#endif
+
+    // Expands the quad in camera coordinates
+    vec4 viewPos = ViewMatrix * vec4(position, 1.0);
+    if (PsStretched > 0.5) {
+        vec3 viewVel = mat3(ViewMatrix) * velocity;
+        float speed = length(viewVel.xy);
+        vec2 axis = speed > 1e-5 ? viewVel.xy / speed : vec2(0.0, 1.0);
+        vec2 side = vec2(axis.y, -axis.x);
+        float len = size + PsStretch * length(viewVel);
+        viewPos.xy += side * ParticleCorner.x * 0.5 * size + axis * ParticleCorner.y * 0.5 * len;
+    } else {
+        float c = cos(rotation);
+        float s = sin(rotation);
+        viewPos.xy += mat2(c, s, -s, c) * ParticleCorner * 0.5 * size;
+    }
+    gl_Position = ProjMatrix * viewPos;
+    ViewDepth = -viewPos.z;
+#ifdef FOG
+    FogPosition = viewPos.xyz;
+#endif
+
+    // Texture coordinates of the frame in the atlas, whose first row is at the top
+    float col = mod(frame, PsAtlas.x);
+    float row = floor(frame / PsAtlas.x);
+    vec2 local = ParticleCorner * 0.5 + 0.5;
+    FragTexcoord = vec2((col + local.x) / PsAtlas.x, 1.0 - (row + 1.0 - local.y) / PsAtlas.y);
+}
+`
+
 const physical_fragment_source = `//
 // Physically Based Shading of a microfacet surface material - Fragment Shader
 // Modified from reference implementation at https://github.com/KhronosGroup/glTF-WebGL-PBR
@@ -464,37 +865,92 @@ precision highp float;
 
 #ifdef HAS_BASECOLORMAP
 uniform sampler2D uBaseColorSampler;
+uniform vec2 uBaseColorTexParams[3];
 #endif
 #ifdef HAS_METALROUGHNESSMAP
 uniform sampler2D uMetallicRoughnessSampler;
+uniform vec2 uMetallicRoughnessTexParams[3];
 #endif
 #ifdef HAS_NORMALMAP
 uniform sampler2D uNormalSampler;
+uniform vec2 uNormalTexParams[3];
 //uniform float uNormalScale;
 #endif
 #ifdef HAS_EMISSIVEMAP
 uniform sampler2D uEmissiveSampler;
+uniform vec2 uEmissiveTexParams[3];
 #endif
 #ifdef HAS_OCCLUSIONMAP
 uniform sampler2D uOcclusionSampler;
+uniform vec2 uOcclusionTexParams[3];
 uniform float uOcclusionStrength;
 #endif
+#ifdef HAS_CLEARCOATMAP
+uniform sampler2D uClearcoatSampler;
+uniform vec2 uClearcoatTexParams[3];
+#endif
+#ifdef HAS_CLEARCOATROUGHNESSMAP
+uniform sampler2D uClearcoatRoughnessSampler;
+uniform vec2 uClearcoatRoughnessTexParams[3];
+#endif
+#ifdef HAS_CLEARCOATNORMALMAP
+uniform sampler2D uClearcoatNormalSampler;
+uniform vec2 uClearcoatNormalTexParams[3];
+#endif
+#ifdef HAS_SHEENCOLORMAP
+uniform sampler2D uSheenColorSampler;
+uniform vec2 uSheenColorTexParams[3];
+#endif
+#ifdef HAS_SHEENROUGHNESSMAP
+uniform sampler2D uSheenRoughnessSampler;
+uniform vec2 uSheenRoughnessTexParams[3];
+#endif
+#ifdef HAS_TRANSMISSIONMAP
+uniform sampler2D uTransmissionSampler;
+uniform vec2 uTransmissionTexParams[3];
+#endif
 
 // Material parameters uniform array
-uniform vec4 Material[3];
+uniform vec4 Material[8];
 // Macros to access elements inside the Material array
-#define uBaseColor		    Material[0]
-#define uEmissiveColor      Material[1]
-#define uMetallicFactor     Material[2].x
-#define uRoughnessFactor    Material[2].y
+#define uBaseColor		            Material[0]
+#define uEmissiveColor              Material[1]
+#define uMetallicFactor             Material[2].x
+#define uRoughnessFactor            Material[2].y
+#define uIOR                        Material[2].z
+#define uEmissiveStrength           Material[2].w
+#define uClearcoatFactor            Material[3].x
+#define uClearcoatRoughnessFactor   Material[3].y
+#define uTransmissionFactor         Material[3].z
+#define uSheenRoughnessFactor       Material[3].w
+#define uSheenColorFactor           Material[4].rgb
+// Rotation of the texture coordinates of each map
+#define uTexRotation(a)             Material[5+(a)/4][(a)%4]
+
+// Indices of the maps in the texture rotation array
+#define BASECOLOR_MAP               0
+#define METALROUGHNESS_MAP          1
+#define NORMAL_MAP                  2
+#define OCCLUSION_MAP               3
+#define EMISSIVE_MAP                4
+#define CLEARCOAT_MAP               5
+#define CLEARCOATROUGHNESS_MAP      6
+#define CLEARCOATNORMAL_MAP         7
+#define SHEENCOLOR_MAP              8
+#define SHEENROUGHNESS_MAP          9
+#define TRANSMISSION_MAP            10
 
 #include <lights>
+#include <fog>
 
 // Inputs from vertex shader
 in vec3 Position;       // Vertex position in camera coordinates.
 in vec3 Normal;         // Vertex normal in camera coordinates.
 in vec3 CamDir;         // Direction from vertex to camera
 in vec2 FragTexcoord;
+#ifdef HAS_TANGENTS
+in vec4 Tangent;        // Vertex tangent in camera coordinates and bitangent handedness
+#endif
 
 // Final fragment color
 out vec4 FragColor;
@@ -520,6 +976,12 @@ struct PBRInfo
     float alphaRoughness;         // roughness mapped to a more linear change in the roughness (proposed by [2])
     vec3 diffuseColor;            // color contribution from diffuse lighting
     vec3 specularColor;           // color contribution from specular lighting
+    vec3 n;                       // normal at surface point
+    float clearcoat;              // clearcoat layer intensity
+    float clearcoatRoughness;     // clearcoat layer alpha roughness
+    vec3 clearcoatNormal;         // clearcoat layer normal at surface point
+    vec3 sheenColor;              // sheen layer color
+    float sheenRoughness;         // sheen layer alpha roughness
 };
 
 const float M_PI = 3.141592653589793;
@@ -539,34 +1001,44 @@ vec4 SRGBtoLINEAR(vec4 srgbIn) {
 //#endif //MANUAL_SRGB
 }
 
-// Find the normal for this fragment, pulling either from a predefined normal map
-// or from the interpolated mesh normal and tangent attributes.
-vec3 getNormal()
+// Returns the texture coordinates for a map, applying the scale (repeat),
+// rotation and offset of the map texture (KHR_texture_transform)
+vec2 texcoord(vec2 texParams[3], float rotation)
+{
+    float c = cos(rotation);
+    float s = sin(rotation);
+    return mat2(c, -s, s, c) * (FragTexcoord * texParams[1]) + texParams[0];
+}
+
+// Returns the tangent space matrix of this fragment
+mat3 getTBN()
 {
     // Retrieve the tangent space matrix
-//#ifndef HAS_TANGENTS
+    vec3 ng = normalize(Normal);
+#ifdef HAS_TANGENTS
+    vec3 t = normalize(Tangent.xyz - ng * dot(ng, Tangent.xyz));
+    vec3 b = cross(ng, t) * Tangent.w;
+#else
     vec3 pos_dx = dFdx(Position);
     vec3 pos_dy = dFdy(Position);
     vec3 tex_dx = dFdx(vec3(FragTexcoord, 0.0));
     vec3 tex_dy = dFdy(vec3(FragTexcoord, 0.0));
     vec3 t = (tex_dy.t * pos_dx - tex_dx.t * pos_dy) / (tex_dx.s * tex_dy.t - tex_dy.s * tex_dx.t);
-
-//#ifdef HAS_NORMALS
-    vec3 ng = normalize(Normal);
-//#else
-//    vec3 ng = cross(pos_dx, pos_dy);
-//#endif
-
     t = normalize(t - ng * dot(ng, t));
     vec3 b = normalize(cross(ng, t));
+#endif
     mat3 tbn = mat3(t, b, ng);
-//#else // HAS_TANGENTS
-//    mat3 tbn = v_TBN;
-//#endif
 
+    return tbn;
+}
+
+// Find the normal for this fragment, pulling either from a predefined normal map
+// or from the interpolated mesh normal and tangent attributes.
+vec3 getNormal(mat3 tbn)
+{
 #ifdef HAS_NORMALMAP
     float uNormalScale = 1.0;
-    vec3 n = texture(uNormalSampler, FragTexcoord).rgb;
+    vec3 n = texture(uNormalSampler, texcoord(uNormalTexParams, uTexRotation(NORMAL_MAP))).rgb;
     n = normalize(tbn * ((2.0 * n - 1.0) * vec3(uNormalScale, uNormalScale, 1.0)));
 #else
     // The tbn matrix is linearly interpolated, so we need to re-normalize
@@ -576,6 +1048,20 @@ vec3 getNormal()
     return n;
 }
 
+// Find the clearcoat layer normal for this fragment, which uses its own
+// optional normal map and otherwise the interpolated mesh normal.
+vec3 getClearcoatNormal(mat3 tbn)
+{
+#ifdef HAS_CLEARCOATNORMALMAP
+    vec3 n = texture(uClearcoatNormalSampler, texcoord(uClearcoatNormalTexParams, uTexRotation(CLEARCOATNORMAL_MAP))).rgb;
+    n = normalize(tbn * (2.0 * n - 1.0));
+#else
+    vec3 n = normalize(tbn[2].xyz);
+#endif
+
+    return n;
+}
+
 // Calculation of the lighting contribution from an optional Image Based Light source.
 // Precomputed Environment Maps are required uniform inputs and are computed as outlined in [1].
 // See our README.md on Environment Maps [3] for additional discussion.
@@ -643,9 +1129,23 @@ float microfacetDistribution(PBRInfo pbrInputs, PBRLightInfo pbrLight)
     return roughnessSq / (M_PI * f * f);
 }
 
+// Sheen distribution term ("Charlie" sheen) from "Production Friendly Microfacet Sheen BRDF"
+// by Estevez and Kulla, with the visibility term of Neubelt and Pettineo.
+float sheenDistribution(float alphaRoughness, float NdotH)
+{
+    float invR = 1.0 / alphaRoughness;
+    float sin2h = max(1.0 - NdotH * NdotH, 0.0078125);
+    return (2.0 + invR) * pow(sin2h, invR * 0.5) / (2.0 * M_PI);
+}
+
+float sheenVisibility(float NdotL, float NdotV)
+{
+    return 1.0 / (4.0 * (NdotL + NdotV - NdotL * NdotV));
+}
+
 vec3 pbrModel(PBRInfo pbrInputs, vec3 lightColor, vec3 lightDir) {
 
-    vec3 n = getNormal();                             // normal at surface point
+    vec3 n = pbrInputs.n;                             // normal at surface point
     vec3 v = normalize(CamDir);                       // Vector from surface point to camera
     vec3 l = normalize(lightDir);                     // Vector from surface point to light
     vec3 h = normalize(l+v);                          // Half vector between both l and v
@@ -676,6 +1176,30 @@ vec3 pbrModel(PBRInfo pbrInputs, vec3 lightColor, vec3 lightDir) {
     // Obtain final intensity as reflectance (BRDF) scaled by the energy of the light (cosine law)
     vec3 color = NdotL * lightColor * (diffuseContrib + specContrib);
 
+#ifdef USE_SHEEN
+    // Sheen layer on top of the base layer, which is scaled by the sheen albedo
+    float sheenD = sheenDistribution(max(pbrInputs.sheenRoughness, 0.07), NdotH);
+    vec3 sheenContrib = pbrInputs.sheenColor * sheenD * sheenVisibility(NdotL, NdotV);
+    float sheenAlbedoScaling = 1.0 - max(max(pbrInputs.sheenColor.r, pbrInputs.sheenColor.g), pbrInputs.sheenColor.b) * 0.157;
+    color = color * sheenAlbedoScaling + NdotL * lightColor * sheenContrib;
+#endif
+
+#ifdef USE_CLEARCOAT
+    // Clearcoat layer: dielectric specular lobe using its own normal and roughness
+    vec3 cn = pbrInputs.clearcoatNormal;
+    float ccNdotL = clamp(dot(cn, l), 0.001, 1.0);
+    float ccNdotV = abs(dot(cn, v)) + 0.001;
+    float ccNdotH = clamp(dot(cn, h), 0.0, 1.0);
+    PBRInfo ccInputs = pbrInputs;
+    ccInputs.reflectance0 = vec3(0.04);
+    ccInputs.reflectance90 = vec3(1.0);
+    ccInputs.alphaRoughness = pbrInputs.clearcoatRoughness;
+    PBRLightInfo ccLight = PBRLightInfo(ccNdotL, ccNdotV, ccNdotH, LdotH, VdotH);
+    vec3 ccF = specularReflection(ccInputs, ccLight);
+    vec3 ccContrib = ccF * geometricOcclusion(ccInputs, ccLight) * microfacetDistribution(ccInputs, ccLight) / (4.0 * ccNdotL * ccNdotV);
+    color = color * (1.0 - pbrInputs.clearcoat * ccF) + pbrInputs.clearcoat * ccNdotL * lightColor * ccContrib;
+#endif
+
     return color;
 }
 
@@ -687,7 +1211,7 @@ void main() {
 #ifdef HAS_METALROUGHNESSMAP
     // Roughness is stored in the 'g' channel, metallic is stored in the 'b' channel.
     // This layout intentionally reserves the 'r' channel for (optional) occlusion map data
-    vec4 mrSample = texture(uMetallicRoughnessSampler, FragTexcoord);
+    vec4 mrSample = texture(uMetallicRoughnessSampler, texcoord(uMetallicRoughnessTexParams, uTexRotation(METALROUGHNESS_MAP)));
     perceptualRoughness = mrSample.g * perceptualRoughness;
     metallic = mrSample.b * metallic;
 #endif
@@ -700,15 +1224,28 @@ void main() {
 
     // The albedo may be defined from a base texture or a flat color
 #ifdef HAS_BASECOLORMAP
-    vec4 baseColor = SRGBtoLINEAR(texture(uBaseColorSampler, FragTexcoord)) * uBaseColor;
+    vec4 baseColor = SRGBtoLINEAR(texture(uBaseColorSampler, texcoord(uBaseColorTexParams, uTexRotation(BASECOLOR_MAP)))) * uBaseColor;
 #else
     vec4 baseColor = uBaseColor;
 #endif
 
-    vec3 f0 = vec3(0.04);
+    // Reflectance at normal incidence for dielectrics derived from the index of refraction
+    float ior = (uIOR - 1.0) / (uIOR + 1.0);
+    vec3 f0 = vec3(ior * ior);
     vec3 diffuseColor = baseColor.rgb * (vec3(1.0) - f0);
     diffuseColor *= 1.0 - metallic;
 
+#ifdef USE_TRANSMISSION
+    // Transmitted light replaces the diffuse contribution. As the scene behind
+    // the surface is not available it is approximated by reducing the opacity.
+    float transmission = uTransmissionFactor;
+#ifdef HAS_TRANSMISSIONMAP
+    transmission *= texture(uTransmissionSampler, texcoord(uTransmissionTexParams, uTexRotation(TRANSMISSION_MAP))).r;
+#endif
+    diffuseColor *= 1.0 - transmission;
+    baseColor.a *= 1.0 - transmission * (1.0 - metallic);
+#endif
+
     vec3 specularColor = mix(f0, baseColor.rgb, uMetallicFactor);
 
     // Compute reflectance.
@@ -727,9 +1264,47 @@ void main() {
         specularEnvironmentR90,
         alphaRoughness,
         diffuseColor,
-        specularColor
+        specularColor,
+        vec3(0.0),
+        0.0,
+        0.0,
+        vec3(0.0),
+        vec3(0.0),
+        0.0
     );
 
+    mat3 tbn = getTBN();
+    pbrInputs.n = getNormal(tbn);
+
+#ifdef USE_CLEARCOAT
+    float clearcoat = uClearcoatFactor;
+    float clearcoatRoughness = uClearcoatRoughnessFactor;
+#ifdef HAS_CLEARCOATMAP
+    clearcoat *= texture(uClearcoatSampler, texcoord(uClearcoatTexParams, uTexRotation(CLEARCOAT_MAP))).r;
+#endif
+#ifdef HAS_CLEARCOATROUGHNESSMAP
+    clearcoatRoughness *= texture(uClearcoatRoughnessSampler, texcoord(uClearcoatRoughnessTexParams, uTexRotation(CLEARCOATROUGHNESS_MAP))).g;
+#endif
+    clearcoatRoughness = clamp(clearcoatRoughness, c_MinRoughness, 1.0);
+    pbrInputs.clearcoat = clearcoat;
+    pbrInputs.clearcoatRoughness = clearcoatRoughness * clearcoatRoughness;
+    pbrInputs.clearcoatNormal = getClearcoatNormal(tbn);
+#endif
+
+#ifdef USE_SHEEN
+    vec3 sheenColor = uSheenColorFactor;
+    float sheenRoughness = uSheenRoughnessFactor;
+#ifdef HAS_SHEENCOLORMAP
+    sheenColor *= SRGBtoLINEAR(texture(uSheenColorSampler, texcoord(uSheenColorTexParams, uTexRotation(SHEENCOLOR_MAP)))).rgb;
+#endif
+#ifdef HAS_SHEENROUGHNESSMAP
+    sheenRoughness *= texture(uSheenRoughnessSampler, texcoord(uSheenRoughnessTexParams, uTexRotation(SHEENROUGHNESS_MAP))).a;
+#endif
+    sheenRoughness = clamp(sheenRoughness, c_MinRoughness, 1.0);
+    pbrInputs.sheenColor = sheenColor;
+    pbrInputs.sheenRoughness = sheenRoughness * sheenRoughness;
+#endif
+
 //    vec3 normal = getNormal();
     vec3 color = vec3(0.0);
 
@@ -803,15 +1378,16 @@ void main() {
 
     // Apply optional PBR terms for additional (optional) shading
 #ifdef HAS_OCCLUSIONMAP
-    float ao = texture(uOcclusionSampler, FragTexcoord).r;
+    float ao = texture(uOcclusionSampler, texcoord(uOcclusionTexParams, uTexRotation(OCCLUSION_MAP))).r;
     color = mix(color, color * ao, 1.0);//, uOcclusionStrength);
 #endif
 
 #ifdef HAS_EMISSIVEMAP
-    vec3 emissive = SRGBtoLINEAR(texture(uEmissiveSampler, FragTexcoord)).rgb * vec3(uEmissiveColor);
+    vec3 emissive = SRGBtoLINEAR(texture(uEmissiveSampler, texcoord(uEmissiveTexParams, uTexRotation(EMISSIVE_MAP)))).rgb * vec3(uEmissiveColor);
 #else
     vec3 emissive = vec3(uEmissiveColor);
 #endif
+    emissive *= uEmissiveStrength;
     color += emissive;
 
     // Base Color
@@ -846,6 +1422,9 @@ void main() {
 
     // Final fragment color
     FragColor = vec4(pow(color,vec3(1.0/2.2)), baseColor.a);
+#ifdef FOG
+    FragColor.rgb = applyFog(FragColor.rgb, Position);
+#endif
 }
 `
 
@@ -868,6 +1447,10 @@ out vec3 Position;
 out vec3 Normal;
 out vec3 CamDir;
 out vec2 FragTexcoord;
+#ifdef HAS_TANGENTS
+in vec4 VertexTangent;
+out vec4 Tangent;
+#endif
 
 void main() {
 
@@ -884,6 +1467,11 @@ void main() {
     // Output texture coordinates to fragment shader
     FragTexcoord = VertexTexcoord;
 
+#ifdef HAS_TANGENTS
+    // Transform this vertex tangent to camera coordinates keeping the bitangent handedness
+    Tangent = vec4(normalize(mat3(ModelViewMatrix) * VertexTangent.xyz), VertexTangent.w);
+#endif
+
     vec3 vPosition = VertexPosition;
     mat4 finalWorld = mat4(1.0);
     #include <morphtarget_vertex>
@@ -897,10 +1485,14 @@ void main() {
 const point_fragment_source = `precision highp float;
 
 #include <material>
+#include <fog>
 
 // Inputs from vertex shader
 in vec3 Color;
 flat in mat2 Rotation;
+#ifdef FOG
+in vec3 FogPosition;
+#endif
 
 // Output
 out vec4 FragColor;
@@ -947,6 +1539,9 @@ void main() {
 
     // Generates final color
     FragColor = min(vec4(Color, MatOpacity) * texMixed, vec4(1));
+#ifdef FOG
+    FragColor.rgb = applyFog(FragColor.rgb, FogPosition);
+#endif
 }
 `
 
@@ -962,6 +1557,9 @@ uniform mat4 MV;
 // Outputs for fragment shader
 out vec3 Color;
 flat out mat2 Rotation;
+#ifdef FOG
+out vec3 FogPosition;
+#endif
 
 void main() {
 
@@ -977,6 +1575,9 @@ void main() {
     // Sets the size of the rasterized point decreasing with distance
     vec4 posMV = MV * vec4(VertexPosition, 1.0);
     gl_PointSize = MatPointSize / -posMV.z;
+#ifdef FOG
+    FogPosition = posMV.xyz;
+#endif
 
     // Outputs color
     Color = MatEmissiveColor;
@@ -984,65 +1585,680 @@ void main() {
 
 `
 
-const standard_fragment_source = `precision highp float;
+const polyline_fragment_source = `precision highp float;
+
+// Inputs from the vertex shader
+in vec4 Color;
+in vec2 Offset;
+in float Distance;
+in float HalfWidth;
+flat in vec4 Dirs;
+flat in vec4 Shape;
+
+// Polyline uniforms
+uniform vec4 LineStyle[6];
+#define LsDashTotal         LineStyle[2].y
+#define LsDashOffset        LineStyle[2].z
+
+// Kinds of quads, joins and caps
+#define KIND_SEGMENT        0
+#define KIND_JOIN           1
+#define JOIN_ROUND          1
+#define JOIN_BEVEL          2
+#define CAP_ROUND           1
+#define CAP_SQUARE          2
 
-// Inputs from vertex shader
-in vec4 Position;     // Fragment position in camera coordinates
-in vec3 Normal;       // Fragment normal in camera coordinates
-in vec2 FragTexcoord; // Fragment texture coordinates
+// Output
+out vec4 FragColor;
 
-#include <lights>
-#include <material>
-#include <phong_model>
+// Returns the coverage of a pixel at the specified signed distance in pixels inside an edge
+float coverage(float d) {
+    return clamp(d + 0.5, 0.0, 1.0);
+}
 
-// Final fragment color
-out vec4 FragColor;
+// Returns the length of the specified dash or gap of the dash pattern
+float dashLength(int i) {
+    vec4 v = LineStyle[3 + i / 4];
+    return v[i % 4];
+}
 
 void main() {
 
-    // Compute final texture color
-    vec4 texMixed = vec4(1);
-    #if MAT_TEXTURES > 0
-        bool firstTex = true;
-        if (MatTexVisible(0)) {
-            vec4 texColor = texture(MatTexture[0], FragTexcoord * MatTexRepeat(0) + MatTexOffset(0));
-            if (firstTex) {
-                texMixed = texColor;
-                firstTex = false;
-            } else {
-                texMixed = Blend(texMixed, texColor);
+    float hw = HalfWidth;
+    int kind = int(Shape.x + 0.5);
+    float alpha;
+    if (kind == KIND_SEGMENT) {
+        alpha = coverage(hw - abs(Offset.y));
+    } else if (kind == KIND_JOIN) {
+        // Only the wedge outside both segments is drawn by the join
+        vec2 d1 = Dirs.xy;
+        vec2 d2 = Dirs.zw;
+        if (dot(Offset, d1) < 0.0 || dot(Offset, d2) > 0.0) {
+            discard;
+        }
+        int join = int(Shape.y + 0.5);
+        if (join == JOIN_ROUND) {
+            alpha = coverage(hw - length(Offset));
+        } else {
+            vec2 o1 = vec2(-d1.y, d1.x) * Shape.z;
+            vec2 o2 = vec2(-d2.y, d2.x) * Shape.z;
+            alpha = coverage(hw - max(dot(Offset, o1), dot(Offset, o2)));
+            if (join == JOIN_BEVEL) {
+                vec2 bis = o1 + o2;
+                bis = length(bis) > 1e-3 ? normalize(bis) : d1;
+                alpha = min(alpha, coverage(hw * Shape.w - dot(Offset, bis)));
             }
         }
-        #if MAT_TEXTURES > 1
-            if (MatTexVisible(1)) {
-                vec4 texColor = texture(MatTexture[1], FragTexcoord * MatTexRepeat(1) + MatTexOffset(1));
-                if (firstTex) {
-                    texMixed = texColor;
-                    firstTex = false;
-                } else {
-                    texMixed = Blend(texMixed, texColor);
+    } else {
+        int cap = int(Shape.y + 0.5);
+        if (cap == CAP_ROUND) {
+            alpha = coverage(hw - length(Offset));
+        } else {
+            float ext = cap == CAP_SQUARE ? hw : 0.0;
+            alpha = min(coverage(hw - abs(dot(Offset, Dirs.zw))), coverage(ext - dot(Offset, Dirs.xy)));
+        }
+    }
+
+    // Discards the gaps of the dash pattern
+    if (LsDashTotal > 0.0) {
+        float p = mod(Distance + LsDashOffset, LsDashTotal);
+        float start = 0.0;
+        for (int i = 0; i < 8; i++) {
+            start += dashLength(i);
+            if (p < start) {
+                if (i % 2 == 1) {
+                    discard;
                 }
+                break;
             }
-            #if MAT_TEXTURES > 2
-                if (MatTexVisible(2)) {
-                    vec4 texColor = texture(MatTexture[2], FragTexcoord * MatTexRepeat(2) + MatTexOffset(2));
-                    if (firstTex) {
-                        texMixed = texColor;
-                        firstTex = false;
-                    } else {
-                        texMixed = Blend(texMixed, texColor);
-                    }
-                }
-            #endif
-        #endif
-    #endif
-
-    // Combine material with texture colors
-    vec4 matDiffuse = vec4(MatDiffuseColor, MatOpacity) * texMixed;
-    vec4 matAmbient = vec4(MatAmbientColor, MatOpacity) * texMixed;
+        }
+    }
 
-    // Normalize interpolated normal as it may have shrinked
-    vec3 fragNormal = normalize(Normal);
+    float a = Color.a * alpha;
+    if (a < 0.004) {
+        discard;
+    }
+    FragColor = vec4(Color.rgb, a);
+}
+`
+
+const polyline_vertex_source = `// Polyline attributes with fixed locations.
+// Segments have their start point in LinePoint0 and their end point in VertexPosition.
+// Joins have the previous point in LinePoint0, the joined point in VertexPosition and the next point in LinePoint2.
+// Caps have the neighbour point in LinePoint0 and the end point in VertexPosition.
+// The params are (kind, corner, side, distance along the line).
+layout(location = 0) in vec3 VertexPosition;
+layout(location = 1) in vec3 LinePoint0;
+layout(location = 2) in vec3 LinePoint2;
+layout(location = 3) in vec4 LineParams;
+layout(location = 4) in vec4 LineColor;
+
+// Model view projection matrix
+uniform mat4 MVP;
+
+// Polyline uniforms
+uniform vec4 LineStyle[6];
+#define LsColor             LineStyle[0]
+#define LsWidth             LineStyle[1].x
+#define LsPixelsPerUnit     LineStyle[1].y
+#define LsJoin              LineStyle[1].z
+#define LsCap               LineStyle[1].w
+#define LsMiterLimit        LineStyle[2].x
+#define LsViewport          LineStyle[5].xy
+
+// Kinds of quads, joins and caps
+#define KIND_SEGMENT        0
+#define KIND_JOIN           1
+#define JOIN_MITER          0
+#define JOIN_ROUND          1
+#define JOIN_BEVEL          2
+#define CAP_BUTT            0
+
+// Outputs for the fragment shader
+out vec4 Color;
+out vec2 Offset;
+out float Distance;
+out float HalfWidth;
+flat out vec4 Dirs;
+flat out vec4 Shape;
+
+// Returns the position in pixels in the viewport of the specified clip position
+vec2 toScreen(vec4 clip) {
+    return (clip.xy / clip.w * 0.5 + 0.5) * LsViewport;
+}
+
+// Returns the clip position of the specified position in pixels with the depth of the specified clip position
+vec4 toClip(vec2 screen, vec4 clip) {
+    return vec4((screen / LsViewport * 2.0 - 1.0) * clip.w, clip.z, clip.w);
+}
+
+// Returns the half width of the line in pixels at the specified clip position
+float halfWidth(vec4 clip) {
+    float w = LsWidth * 0.5;
+    if (LsPixelsPerUnit > 0.0) {
+        w *= LsPixelsPerUnit / clip.w;
+    }
+    return w;
+}
+
+// Moves the first clip position towards the second one up to the near plane when it is behind it
+vec4 clipNear(vec4 a, vec4 b) {
+    float da = a.z + a.w;
+    float db = b.z + b.w;
+    if (da < 0.0 && db > 0.0) {
+        return mix(a, b, da / (da - db));
+    }
+    return a;
+}
+
+// Returns the unit direction from a to b
+vec2 direction(vec2 a, vec2 b) {
+    vec2 d = b - a;
+    float l = length(d);
+    return l > 1e-6 ? d / l : vec2(1.0, 0.0);
+}
+
+// Returns the direction rotated 90 degrees counterclockwise
+vec2 perp(vec2 d) {
+    return vec2(-d.y, d.x);
+}
+
+void main() {
+
+    int kind = int(LineParams.x + 0.5);
+    int corner = int(LineParams.y + 0.5);
+    Color = LineColor * LsColor;
+    Distance = LineParams.w;
+    Dirs = vec4(0.0);
+    Shape = vec4(float(kind), 0.0, 0.0, 0.0);
+    vec4 center = MVP * vec4(VertexPosition, 1.0);
+    vec4 prev = MVP * vec4(LinePoint0, 1.0);
+
+    // Segments are quads between their end points expanded by one pixel for antialiasing
+    if (kind == KIND_SEGMENT) {
+        if (prev.z + prev.w < 0.0 && center.z + center.w < 0.0) {
+            gl_Position = vec4(0.0, 0.0, 2.0, 1.0);
+            return;
+        }
+        vec4 a = clipNear(prev, center);
+        vec4 b = clipNear(center, prev);
+        vec2 sa = toScreen(a);
+        vec2 sb = toScreen(b);
+        vec2 n = perp(direction(sa, sb));
+        vec4 clip = corner == 0 ? a : b;
+        float hw = halfWidth(clip);
+        float side = LineParams.z * (hw + 1.0);
+        HalfWidth = hw;
+        Offset = vec2(0.0, side);
+        gl_Position = toClip((corner == 0 ? sa : sb) + n * side, clip);
+        return;
+    }
+
+    // Joins and caps are quads around their center point
+    if (center.z + center.w < 0.0) {
+        gl_Position = vec4(0.0, 0.0, 2.0, 1.0);
+        return;
+    }
+    vec2 sc = toScreen(center);
+    vec2 sp = toScreen(clipNear(prev, center));
+    float hw = halfWidth(center);
+    float e = hw + 1.0;
+    HalfWidth = hw;
+    vec2 offset;
+    if (kind == KIND_JOIN) {
+        // Fills the wedge between the outer sides of the joined segments
+        vec2 sn = toScreen(clipNear(MVP * vec4(LinePoint2, 1.0), center));
+        vec2 d1 = direction(sp, sc);
+        vec2 d2 = direction(sc, sn);
+        float s = d1.x * d2.y - d1.y * d2.x > 0.0 ? -1.0 : 1.0;
+        vec2 o1 = perp(d1) * s;
+        vec2 o2 = perp(d2) * s;
+        vec2 bis = o1 + o2;
+        bis = length(bis) > 1e-3 ? normalize(bis) : d1;
+        float cosHalf = max(dot(bis, o1), 1e-3);
+        int join = int(LsJoin + 0.5);
+        if (join == JOIN_MITER && cosHalf * LsMiterLimit < 1.0) {
+            join = JOIN_BEVEL;
+        }
+        if (join == JOIN_ROUND) {
+            vec2 t = perp(bis) * e;
+            offset = corner == 0 ? -t : corner == 1 ? bis * e - t : corner == 2 ? bis * e + t : t;
+        } else if (corner == 0) {
+            offset = vec2(0.0);
+        } else if (corner == 1) {
+            offset = o1 * e;
+        } else if (corner == 2) {
+            offset = join == JOIN_MITER ? bis * (e / cosHalf) : bis * (hw * cosHalf + 1.0);
+        } else {
+            offset = o2 * e;
+        }
+        Dirs = vec4(d1, d2);
+        Shape = vec4(float(kind), float(join), s, cosHalf);
+    } else {
+        // Extends the line beyond its end point
+        vec2 d = direction(sp, sc);
+        vec2 n = perp(d) * e;
+        int cap = int(LsCap + 0.5);
+        float ext = (cap == CAP_BUTT ? 0.0 : hw) + 1.0;
+        offset = corner == 0 ? -n : corner == 1 ? d * ext - n : corner == 2 ? d * ext + n : n;
+        Dirs = vec4(d, perp(d));
+        Shape = vec4(float(kind), float(cap), 0.0, 0.0);
+    }
+    Offset = offset;
+    gl_Position = toClip(sc + offset, center);
+}
+`
+
+const reflector_fragment_source = `precision highp float;
+
+// Inputs from the vertex shader
+in vec4 ReflCoord;
+in vec4 ClipPosition;
+in vec3 WorldPosition;
+in vec2 FragTexcoord;
+
+// Reflector parameters:
+// [0] color and opacity
+// [1] wave scale, distortion, fresnel power, fresnel bias
+// [2] offsets of the two normal map layers
+// [3] direction to the sun, shininess
+// [4] sun color, depth fade distance
+// [5] camera position, 0
+// [6] projection [2][2] and [3][2], reflecting, 0
+uniform vec4 ReflectorParams[7];
+uniform mat4 ModelMatrix;
+uniform sampler2D uReflectionSampler;
+#ifdef WATER
+uniform sampler2D uNormalSampler;
+#endif
+#ifdef HAS_REFRACTION
+uniform sampler2D uRefractionSampler;
+uniform sampler2D uRefractionDepthSampler;
+#endif
+
+// Output
+out vec4 FragColor;
+
+#ifdef HAS_REFRACTION
+// Returns the view distance of a depth buffer value
+float viewDepth(float depth) {
+
+    return ReflectorParams[6].y / ((depth * 2.0 - 1.0) + ReflectorParams[6].x);
+}
+#endif
+
+void main() {
+
+    vec4 color = ReflectorParams[0];
+    bool reflecting = ReflectorParams[6].z > 0.5;
+#ifndef WATER
+    vec3 reflection = reflecting ? texture(uReflectionSampler, ReflCoord.xy / ReflCoord.w).rgb : vec3(0.0);
+    FragColor = vec4(reflection * color.rgb, color.a);
+#else
+    // Normal from two scrolling layers of the normal map, in world coordinates
+    vec2 uv = FragTexcoord * ReflectorParams[1].x;
+    vec3 n1 = texture(uNormalSampler, uv + ReflectorParams[2].xy).rgb * 2.0 - 1.0;
+    vec3 n2 = texture(uNormalSampler, uv * 1.37 + ReflectorParams[2].zw).rgb * 2.0 - 1.0;
+    vec3 tn = normalize(n1 + n2);
+    vec3 normal = normalize(mat3(ModelMatrix) * tn);
+    vec2 distortion = tn.xy * ReflectorParams[1].y;
+
+    vec3 reflection = color.rgb;
+    if (reflecting) {
+        reflection = texture(uReflectionSampler, ReflCoord.xy / ReflCoord.w + distortion).rgb;
+    }
+
+    // Scene seen through the water, colored by the thickness of water in front of it
+    vec3 refraction = color.rgb;
+#ifdef HAS_REFRACTION
+    vec2 screen = ClipPosition.xy / ClipPosition.w * 0.5 + 0.5;
+    vec2 refrCoord = screen + distortion;
+    float sceneDepth = viewDepth(texture(uRefractionDepthSampler, refrCoord).r);
+    if (sceneDepth < ClipPosition.w) {
+        // The distorted sample is in front of the water
+        refrCoord = screen;
+        sceneDepth = viewDepth(texture(uRefractionDepthSampler, refrCoord).r);
+    }
+    float thickness = max(sceneDepth - ClipPosition.w, 0.0);
+    float fade = 1.0 - exp(-thickness / max(ReflectorParams[4].w, 1e-4));
+    refraction = mix(texture(uRefractionSampler, refrCoord).rgb, color.rgb, fade * color.a);
+#endif
+
+    // Fresnel blending and sun highlight
+    vec3 viewDir = normalize(ReflectorParams[5].xyz - WorldPosition);
+    float cosTheta = max(dot(viewDir, normal), 0.0);
+    float fresnel = ReflectorParams[1].w + (1.0 - ReflectorParams[1].w) * pow(1.0 - cosTheta, ReflectorParams[1].z);
+    vec3 halfDir = normalize(ReflectorParams[3].xyz + viewDir);
+    float specular = pow(max(dot(normal, halfDir), 0.0), ReflectorParams[3].w);
+    FragColor = vec4(mix(refraction, reflection, clamp(fresnel, 0.0, 1.0)) + specular * ReflectorParams[4].rgb, 1.0);
+#endif
+}
+`
+
+const reflector_vertex_source = `#include <attributes>
+
+// Model uniforms
+uniform mat4 MVP;
+uniform mat4 ModelMatrix;
+uniform mat4 ReflTexMatrix;
+
+// Outputs for the fragment shader
+out vec4 ReflCoord;
+out vec4 ClipPosition;
+out vec3 WorldPosition;
+out vec2 FragTexcoord;
+
+void main() {
+
+    ReflCoord = ReflTexMatrix * vec4(VertexPosition, 1.0);
+    WorldPosition = (ModelMatrix * vec4(VertexPosition, 1.0)).xyz;
+    FragTexcoord = VertexTexcoord;
+    ClipPosition = MVP * vec4(VertexPosition, 1.0);
+    gl_Position = ClipPosition;
+}
+`
+
+const sky_fragment_source = `precision highp float;
+
+// Input from the vertex shader
+in vec3 Direction;
+
+// Sky parameters:
+// [0] direction to the sun, sun intensity
+// [1] Rayleigh scattering, sun fade
+// [2] Mie scattering, Mie directionality
+// [3] fog color, sine of the fog elevation
+// [4] stars brightness, clouds coverage, clouds scale, luminance
+// [5] sunlight color, 0
+// [6] clouds offset, 0, 0
+uniform vec4 SkyParams[7];
+
+#include <fog>
+
+// Output
+out vec4 FragColor;
+
+const float PI = 3.141592653589793;
+const float RAYLEIGH_ZENITH_LENGTH = 8.4E3;
+const float MIE_ZENITH_LENGTH = 1.25E3;
+const float SUN_ANGULAR_DIAMETER_COS = 0.999956676946448;
+
+float rayleighPhase(float cosTheta) {
+
+    return 0.05968310365946075 * (1.0 + pow(cosTheta, 2.0));
+}
+
+float hgPhase(float cosTheta, float g) {
+
+    float g2 = g * g;
+    return 0.07957747154594767 * (1.0 - g2) / pow(1.0 - 2.0 * g * cosTheta + g2, 1.5);
+}
+
+// Uncharted 2 filmic tone mapping
+vec3 tonemap(vec3 x) {
+
+    const float A = 0.15, B = 0.50, C = 0.10, D = 0.20, E = 0.02, F = 0.30;
+    return ((x * (A * x + C * B) + D * E) / (x * (A * x + B) + D * F)) - E / F;
+}
+
+float hash(vec3 p) {
+
+    p = fract(p * 0.3183099 + 0.1);
+    p *= 17.0;
+    return fract(p.x * p.y * p.z * (p.x + p.y + p.z));
+}
+
+float hash(vec2 p) {
+
+    return fract(sin(dot(p, vec2(127.1, 311.7))) * 43758.5453);
+}
+
+float noise(vec2 p) {
+
+    vec2 i = floor(p);
+    vec2 f = fract(p);
+    f = f * f * (3.0 - 2.0 * f);
+    float a = hash(i);
+    float b = hash(i + vec2(1.0, 0.0));
+    float c = hash(i + vec2(0.0, 1.0));
+    float d = hash(i + vec2(1.0, 1.0));
+    return mix(mix(a, b, f.x), mix(c, d, f.x), f.y);
+}
+
+float fbm(vec2 p) {
+
+    float v = 0.0;
+    float a = 0.5;
+    for (int i = 0; i < 5; i++) {
+        v += a * noise(p);
+        p *= 2.0;
+        a *= 0.5;
+    }
+    return v;
+}
+
+void main() {
+
+    vec3 direction = normalize(Direction);
+    vec3 sunDirection = SkyParams[0].xyz;
+    float sunE = SkyParams[0].w;
+    vec3 betaR = SkyParams[1].xyz;
+    float sunFade = SkyParams[1].w;
+    vec3 betaM = SkyParams[2].xyz;
+
+    // Optical length and extinction
+    float zenithAngle = acos(max(0.0, direction.y));
+    float inverse = 1.0 / (cos(zenithAngle) + 0.15 * pow(93.885 - ((zenithAngle * 180.0) / PI), -1.253));
+    vec3 Fex = exp(-(betaR * RAYLEIGH_ZENITH_LENGTH * inverse + betaM * MIE_ZENITH_LENGTH * inverse));
+
+    // In-scattering
+    float cosTheta = dot(direction, sunDirection);
+    vec3 betaTheta = betaR * rayleighPhase(cosTheta * 0.5 + 0.5) + betaM * hgPhase(cosTheta, SkyParams[2].w);
+    vec3 Lin = pow(sunE * (betaTheta / (betaR + betaM)) * (1.0 - Fex), vec3(1.5));
+    Lin *= mix(vec3(1.0), pow(sunE * (betaTheta / (betaR + betaM)) * Fex, vec3(0.5)), clamp(pow(1.0 - sunDirection.y, 5.0), 0.0, 1.0));
+
+    // Sun disk
+    vec3 L0 = vec3(0.1) * Fex;
+    float sundisk = smoothstep(SUN_ANGULAR_DIAMETER_COS, SUN_ANGULAR_DIAMETER_COS + 0.00002, cosTheta);
+    L0 += (sunE * 19000.0 * Fex) * sundisk;
+
+    vec3 texColor = (Lin + L0) * 0.04 + vec3(0.0, 0.0003, 0.00075);
+    vec3 curr = tonemap(log2(2.0 / pow(SkyParams[4].w, 4.0)) * texColor);
+    vec3 color = curr / tonemap(vec3(1000.0));
+    color = pow(color, vec3(1.0 / (1.2 + (1.2 * sunFade))));
+
+    // Clouds layer
+    float density = 0.0;
+    if (SkyParams[4].y > 0.0 && direction.y > 0.0) {
+        vec2 p = direction.xz / max(direction.y, 0.05) * SkyParams[4].z + SkyParams[6].xy;
+        float coverage = SkyParams[4].y;
+        density = smoothstep(1.0 - coverage, 1.0 - coverage + 0.25, fbm(p)) * smoothstep(0.0, 0.15, direction.y);
+    }
+
+    // Stars at night
+    if (SkyParams[4].x > 0.0 && direction.y > 0.0) {
+        vec3 c = direction * 300.0;
+        float h = hash(floor(c));
+        if (h > 0.997) {
+            float star = max(0.0, 1.0 - length(fract(c) - 0.5) * 3.0) * fract(h * 1000.0);
+            float night = smoothstep(0.1, -0.1, sunDirection.y) * smoothstep(0.0, 0.1, direction.y);
+            color += vec3(star * night * SkyParams[4].x * (1.0 - density));
+        }
+    }
+
+    // Clouds lit by the sunlight
+    if (density > 0.0) {
+        vec3 sunColor = SkyParams[5].rgb;
+        vec3 cloudColor = mix(vec3(0.02, 0.02, 0.03), sunColor, clamp(sunDirection.y * 4.0 + 0.2, 0.05, 1.0));
+        cloudColor += sunColor * pow(max(cosTheta, 0.0), 8.0) * 0.3;
+        color = mix(color, cloudColor, density * 0.9);
+    }
+
+    // Fog toward the horizon, from the scene fog if any
+#ifdef FOG
+    color = mix(color, FogParams[0].rgb, fogSkyFactor(direction.y));
+#else
+    if (SkyParams[3].w > 0.0) {
+        color = mix(color, SkyParams[3].rgb, 1.0 - smoothstep(0.0, SkyParams[3].w, direction.y));
+    }
+#endif
+    FragColor = vec4(color, 1.0);
+}
+`
+
+const sky_vertex_source = `#include <attributes>
+
+// Projection and view rotation
+uniform mat4 MVP;
+
+// Output for the fragment shader
+out vec3 Direction;
+
+void main() {
+
+    // On the far plane so that the depth test keeps the sky behind the scene
+    Direction = VertexPosition;
+    vec4 position = MVP * vec4(VertexPosition, 1.0);
+    gl_Position = position.xyww;
+}
+`
+
+const standard_fragment_source = `precision highp float;
+
+// Inputs from vertex shader
+in vec4 Position;     // Fragment position in camera coordinates
+in vec3 Normal;       // Fragment normal in camera coordinates
+in vec2 FragTexcoord; // Fragment texture coordinates
+in vec2 MapTexcoord;  // Fragment texture coordinates for the typed maps
+#ifdef HAS_TANGENTS
+in vec4 Tangent;      // Fragment tangent in camera coordinates and bitangent handedness
+#endif
+
+#include <lights>
+#include <material>
+#include <phong_model>
+#include <fog>
+
+// Typed maps samplers and parameters (3*vec2 per texture)
+#ifdef HAS_DIFFUSEMAP
+uniform sampler2D uDiffuseSampler;
+uniform vec2 uDiffuseTexParams[3];
+#endif
+#ifdef HAS_NORMALMAP
+uniform sampler2D uNormalSampler;
+uniform vec2 uNormalTexParams[3];
+#endif
+#ifdef HAS_BUMPMAP
+uniform sampler2D uBumpSampler;
+uniform vec2 uBumpTexParams[3];
+#endif
+#ifdef HAS_SPECULARMAP
+uniform sampler2D uSpecularSampler;
+uniform vec2 uSpecularTexParams[3];
+#endif
+#ifdef HAS_EMISSIVEMAP
+uniform sampler2D uEmissiveSampler;
+uniform vec2 uEmissiveTexParams[3];
+#endif
+#ifdef HAS_ALPHAMAP
+uniform sampler2D uAlphaSampler;
+uniform vec2 uAlphaTexParams[3];
+#endif
+#ifdef HAS_PARALLAXMAP
+uniform sampler2D uParallaxSampler;
+uniform vec2 uParallaxTexParams[3];
+#endif
+
+// Returns the texture coordinates of a typed map from the base coordinates
+// applying the map's optional Y flip, repeat and offset.
+vec2 mapTexcoord(vec2 uv, vec2 texParams[3]) {
+    if (bool(texParams[2].x)) {
+        uv.y = 1.0 - uv.y;
+    }
+    return uv * texParams[1] + texParams[0];
+}
+
+// Returns the tangent space matrix for the specified normal,
+// from the interpolated tangent if available or else from the screen space derivatives.
+mat3 tangentSpace(vec3 normal) {
+#ifdef HAS_TANGENTS
+    vec3 t = normalize(Tangent.xyz - normal * dot(normal, Tangent.xyz));
+    vec3 b = cross(normal, t) * Tangent.w;
+#else
+    vec3 pos_dx = dFdx(Position.xyz);
+    vec3 pos_dy = dFdy(Position.xyz);
+    vec2 tex_dx = dFdx(MapTexcoord);
+    vec2 tex_dy = dFdy(MapTexcoord);
+    vec3 t = (tex_dy.t * pos_dx - tex_dx.t * pos_dy) / (tex_dx.s * tex_dy.t - tex_dy.s * tex_dx.t);
+    t = normalize(t - normal * dot(normal, t));
+    vec3 b = normalize(cross(normal, t));
+#endif
+    return mat3(t, b, normal);
+}
+
+#if defined(HAS_BUMPMAP) && !defined(HAS_NORMALMAP)
+// Perturbs the normal using the screen space derivatives of the bump map heights.
+// "Bump Mapping Unparametrized Surfaces on the GPU" by Morten S. Mikkelsen.
+vec3 bumpNormal(vec3 normal, vec2 uv) {
+    vec2 dSTdx = dFdx(uv);
+    vec2 dSTdy = dFdy(uv);
+    float Hll = MatBumpScale * texture(uBumpSampler, uv).r;
+    float dBx = MatBumpScale * texture(uBumpSampler, uv + dSTdx).r - Hll;
+    float dBy = MatBumpScale * texture(uBumpSampler, uv + dSTdy).r - Hll;
+    vec3 vSigmaX = dFdx(Position.xyz);
+    vec3 vSigmaY = dFdy(Position.xyz);
+    vec3 R1 = cross(vSigmaY, normal);
+    vec3 R2 = cross(normal, vSigmaX);
+    float fDet = dot(vSigmaX, R1);
+    vec3 vGrad = sign(fDet) * (dBx * R1 + dBy * R2);
+    return normalize(abs(fDet) * normal - vGrad);
+}
+#endif
+
+// Final fragment color
+out vec4 FragColor;
+
+void main() {
+
+    // Compute final texture color
+    vec4 texMixed = vec4(1);
+    #if MAT_TEXTURES > 0
+        bool firstTex = true;
+        if (MatTexVisible(0)) {
+            vec4 texColor = texture(MatTexture[0], FragTexcoord * MatTexRepeat(0) + MatTexOffset(0));
+            if (firstTex) {
+                texMixed = texColor;
+                firstTex = false;
+            } else {
+                texMixed = Blend(texMixed, texColor);
+            }
+        }
+        #if MAT_TEXTURES > 1
+            if (MatTexVisible(1)) {
+                vec4 texColor = texture(MatTexture[1], FragTexcoord * MatTexRepeat(1) + MatTexOffset(1));
+                if (firstTex) {
+                    texMixed = texColor;
+                    firstTex = false;
+                } else {
+                    texMixed = Blend(texMixed, texColor);
+                }
+            }
+            #if MAT_TEXTURES > 2
+                if (MatTexVisible(2)) {
+                    vec4 texColor = texture(MatTexture[2], FragTexcoord * MatTexRepeat(2) + MatTexOffset(2));
+                    if (firstTex) {
+                        texMixed = texColor;
+                        firstTex = false;
+                    } else {
+                        texMixed = Blend(texMixed, texColor);
+                    }
+                }
+            #endif
+        #endif
+    #endif
+
+    // Normalize interpolated normal as it may have shrinked
+    vec3 fragNormal = normalize(Normal);
 
     // Calculate the direction vector from the fragment to the camera (origin)
     vec3 camDir = normalize(-Position.xyz);
@@ -1055,12 +2271,57 @@ void main() {
         fragNormal = -fragNormal;
     }
 
+    // Base texture coordinates of the typed maps, optionally offset by the parallax map
+    vec2 uv = MapTexcoord;
+#ifdef HAS_PARALLAXMAP
+    {
+        vec3 viewTS = normalize(transpose(tangentSpace(fragNormal)) * camDir);
+        float height = texture(uParallaxSampler, mapTexcoord(uv, uParallaxTexParams)).r;
+        uv += viewTS.xy / max(viewTS.z, 0.1) * (height * MatParallaxScale);
+    }
+#endif
+
+    // Perturb the normal from the normal or bump maps
+#ifdef HAS_NORMALMAP
+    vec3 mapN = texture(uNormalSampler, mapTexcoord(uv, uNormalTexParams)).rgb * 2.0 - 1.0;
+    mapN.xy *= MatNormalScale;
+    fragNormal = normalize(tangentSpace(fragNormal) * mapN);
+#elif defined(HAS_BUMPMAP)
+    fragNormal = bumpNormal(fragNormal, mapTexcoord(uv, uBumpTexParams));
+#endif
+
+    // Combine material with texture colors
+#ifdef HAS_DIFFUSEMAP
+    texMixed *= texture(uDiffuseSampler, mapTexcoord(uv, uDiffuseTexParams));
+#endif
+    vec4 matDiffuse = vec4(MatDiffuseColor, MatOpacity) * texMixed;
+    vec4 matAmbient = vec4(MatAmbientColor, MatOpacity) * texMixed;
+#ifdef HAS_ALPHAMAP
+    matDiffuse.a *= texture(uAlphaSampler, mapTexcoord(uv, uAlphaTexParams)).r;
+#endif
+    vec3 matSpecular = MatSpecularColor;
+#ifdef HAS_SPECULARMAP
+    matSpecular *= texture(uSpecularSampler, mapTexcoord(uv, uSpecularTexParams)).rgb;
+#endif
+    vec3 matEmissive = MatEmissiveColor;
+#ifdef HAS_EMISSIVEMAP
+    matEmissive *= texture(uEmissiveSampler, mapTexcoord(uv, uEmissiveTexParams)).rgb;
+#endif
+
     // Calculates the Ambient+Diffuse and Specular colors for this fragment using the Phong model.
     vec3 Ambdiff, Spec;
-    phongModel(Position, fragNormal, camDir, vec3(matAmbient), vec3(matDiffuse), Ambdiff, Spec);
+    phongModel(Position, fragNormal, camDir, vec3(matAmbient), vec3(matDiffuse), matSpecular, matEmissive, Ambdiff, Spec);
 
     // Final fragment color
     FragColor = min(vec4(Ambdiff + Spec, matDiffuse.a), vec4(1.0));
+#ifdef FOG
+#ifdef FOG_SKY
+    // Skies are blended toward the horizon instead of with the distance
+    FragColor.rgb = mix(FragColor.rgb, FogParams[0].rgb, fogSkyFactor(dot(FogParams[2].xyz, normalize(Position.xyz))));
+#else
+    FragColor.rgb = applyFog(FragColor.rgb, Position.xyz);
+#endif
+#endif
 }
 `
 
@@ -1075,10 +2336,16 @@ uniform mat4 MVP;
 #include <morphtarget_vertex_declaration>
 #include <bones_vertex_declaration>
 
+#ifdef HAS_TANGENTS
+in vec4 VertexTangent;
+out vec4 Tangent;
+#endif
+
 // Output variables for Fragment shader
 out vec4 Position;
 out vec3 Normal;
 out vec2 FragTexcoord;
+out vec2 MapTexcoord;
 
 void main() {
 
@@ -1088,6 +2355,11 @@ void main() {
     // Transform vertex normal to camera coordinates
     Normal = normalize(NormalMatrix * VertexNormal);
 
+#ifdef HAS_TANGENTS
+    // Transform vertex tangent to camera coordinates keeping its handedness
+    Tangent = vec4(normalize(mat3(ModelViewMatrix) * VertexTangent.xyz), VertexTangent.w);
+#endif
+
     vec2 texcoord = VertexTexcoord;
 #if MAT_TEXTURES > 0
     // Flip texture coordinate Y if requested.
@@ -1096,6 +2368,8 @@ void main() {
     }
 #endif
     FragTexcoord = texcoord;
+    // Texture coordinates for the typed maps which are flipped by each map
+    MapTexcoord = VertexTexcoord;
     vec3 vPosition = VertexPosition;
     mat4 finalWorld = mat4(1.0);
     #include <morphtarget_vertex>
@@ -1106,12 +2380,336 @@ void main() {
 }
 `
 
+const terrain_fragment_source = `precision highp float;
+
+// Inputs from vertex shader
+in vec4 Position;     // Fragment position in camera coordinates
+in vec3 Normal;       // Fragment normal in camera coordinates
+in vec2 MapTexcoord;  // Fragment texture coordinates over the whole terrain
+
+#include <lights>
+#include <material>
+#include <phong_model>
+#include <fog>
+
+// Splat map and layers samplers and parameters (3*vec2 per texture)
+#ifdef HAS_SPLATMAP
+uniform sampler2D uSplatSampler;
+uniform vec2 uSplatTexParams[3];
+#endif
+#ifdef HAS_LAYER0
+uniform sampler2D uLayer0Sampler;
+uniform vec2 uLayer0TexParams[3];
+#endif
+#ifdef HAS_LAYER1
+uniform sampler2D uLayer1Sampler;
+uniform vec2 uLayer1TexParams[3];
+#endif
+#ifdef HAS_LAYER2
+uniform sampler2D uLayer2Sampler;
+uniform vec2 uLayer2TexParams[3];
+#endif
+#ifdef HAS_LAYER3
+uniform sampler2D uLayer3Sampler;
+uniform vec2 uLayer3TexParams[3];
+#endif
+
+// Returns the texture coordinates of a map from the base coordinates
+// applying the map's optional Y flip, repeat and offset.
+vec2 mapTexcoord(vec2 uv, vec2 texParams[3]) {
+    if (bool(texParams[2].x)) {
+        uv.y = 1.0 - uv.y;
+    }
+    return uv * texParams[1] + texParams[0];
+}
+
+// Final fragment color
+out vec4 FragColor;
+
+void main() {
+
+    // Blend the layers with the weights of the channels of the splat map,
+    // or use only the first layer if there is no splat map.
+#ifdef HAS_SPLATMAP
+    vec4 weights = texture(uSplatSampler, mapTexcoord(MapTexcoord, uSplatTexParams));
+#else
+    vec4 weights = vec4(1, 0, 0, 0);
+#endif
+    vec4 texMixed = vec4(0);
+    float total = 0.0;
+#ifdef HAS_LAYER0
+    texMixed += weights.r * texture(uLayer0Sampler, mapTexcoord(MapTexcoord, uLayer0TexParams));
+    total += weights.r;
+#endif
+#ifdef HAS_LAYER1
+    texMixed += weights.g * texture(uLayer1Sampler, mapTexcoord(MapTexcoord, uLayer1TexParams));
+    total += weights.g;
+#endif
+#ifdef HAS_LAYER2
+    texMixed += weights.b * texture(uLayer2Sampler, mapTexcoord(MapTexcoord, uLayer2TexParams));
+    total += weights.b;
+#endif
+#ifdef HAS_LAYER3
+    texMixed += weights.a * texture(uLayer3Sampler, mapTexcoord(MapTexcoord, uLayer3TexParams));
+    total += weights.a;
+#endif
+    if (total > 0.0) {
+        texMixed /= total;
+    } else {
+        texMixed = vec4(1);
+    }
+
+    // Normalize interpolated normal as it may have shrinked
+    vec3 fragNormal = normalize(Normal);
+
+    // Calculate the direction vector from the fragment to the camera (origin)
+    vec3 camDir = normalize(-Position.xyz);
+
+    // Combine material with texture colors
+    vec4 matDiffuse = vec4(MatDiffuseColor, MatOpacity) * texMixed;
+    vec4 matAmbient = vec4(MatAmbientColor, MatOpacity) * texMixed;
+
+    // Calculates the Ambient+Diffuse and Specular colors for this fragment using the Phong model.
+    vec3 Ambdiff, Spec;
+    phongModel(Position, fragNormal, camDir, vec3(matAmbient), vec3(matDiffuse), MatSpecularColor, MatEmissiveColor, Ambdiff, Spec);
+
+    // Final fragment color
+    FragColor = min(vec4(Ambdiff + Spec, matDiffuse.a), vec4(1.0));
+#ifdef FOG
+    FragColor.rgb = applyFog(FragColor.rgb, Position.xyz);
+#endif
+}
+`
+
+const terrain_vertex_source = `#include <attributes>
+
+// Model uniforms
+uniform mat4 ModelViewMatrix;
+uniform mat3 NormalMatrix;
+uniform mat4 MVP;
+
+#include <material>
+
+// Output variables for Fragment shader
+out vec4 Position;
+out vec3 Normal;
+out vec2 MapTexcoord;
+
+void main() {
+
+    // Transform vertex position to camera coordinates
+    Position = ModelViewMatrix * vec4(VertexPosition, 1.0);
+
+    // Transform vertex normal to camera coordinates
+    Normal = normalize(NormalMatrix * VertexNormal);
+
+    // Texture coordinates over the whole terrain which are flipped by each map
+    MapTexcoord = VertexTexcoord;
+
+    // Output projected and transformed vertex position
+    gl_Position = MVP * vec4(VertexPosition, 1.0);
+}
+`
+
+const text_fragment_source = `precision highp float;
+
+// Glyph atlas
+uniform sampler2D uTextSampler;
+
+// Text uniforms
+uniform vec4 TextParams[6];
+#define TextColor           TextParams[0]
+#define TextOutlineColor    TextParams[1]
+#define TextShadowColor     TextParams[2]
+#define TextGlowColor       TextParams[3]
+#define TextOutlineWidth    TextParams[4].x
+#define TextShadowOffset    TextParams[4].yz
+#define TextGlowWidth       TextParams[4].w
+#define TextSpread          TextParams[5].x
+
+#ifdef TEXT_CLIP
+// Clipping rectangle in window coordinates (xmin, ymin, xmax, ymax)
+uniform vec4 TextClip;
+#endif
+
+// Texture coordinates in atlas pixels
+in vec2 vTexcoord;
+
+out vec4 FragColor;
+
+// Atlas pixels per screen pixel
+float texelWidth;
+
+#if defined(SDF) || defined(MSDF)
+
+// Returns the signed distance in atlas pixels to the outline of the glyphs, positive inside.
+// Multi-channel atlases give the distance keeping the corners sharp or the true distance.
+float signedDistance(vec2 p, bool sharp) {
+
+    vec4 s = texture(uTextSampler, p / vec2(textureSize(uTextSampler, 0)));
+    float v = s.a;
+#ifdef MSDF
+    if (sharp) {
+        v = max(min(s.r, s.g), min(max(s.r, s.g), s.b));
+    }
+#endif
+    return (v - 0.5) * 2.0 * TextSpread;
+}
+
+// Returns the coverage of the glyphs dilated by the specified radius in atlas pixels
+float dilated(vec2 p, float radius) {
+
+    float d = signedDistance(p, radius <= 0.0) + radius;
+    return clamp(d / texelWidth + 0.5, 0.0, 1.0);
+}
+
+#else
+
+// Returns the coverage of the glyphs dilated by the specified radius in atlas pixels
+float dilated(vec2 p, float radius) {
+
+    vec2 size = vec2(textureSize(uTextSampler, 0));
+    float c = texture(uTextSampler, p / size).a;
+    if (radius <= 0.0) {
+        return c;
+    }
+    // Samples two rings of directions
+    for (int i = 0; i < 12; i++) {
+        float a = float(i) * 0.5235988;
+        vec2 d = vec2(cos(a), sin(a));
+        c = max(c, texture(uTextSampler, (p + d * radius) / size).a);
+        c = max(c, texture(uTextSampler, (p + d * radius * 0.5) / size).a);
+    }
+    return c;
+}
+
+#endif
+
+// Returns the color with straight alpha of the specified layer over the specified color
+vec4 over(vec4 layer, vec4 color) {
+
+    float a = layer.a + color.a * (1.0 - layer.a);
+    if (a <= 0.0) {
+        return vec4(0.0);
+    }
+    return vec4((layer.rgb * layer.a + color.rgb * color.a * (1.0 - layer.a)) / a, a);
+}
+
+void main() {
+
+    vec2 fw = fwidth(vTexcoord);
+    texelWidth = max(0.5 * (fw.x + fw.y), 1e-4);
+#ifdef TEXT_CLIP
+    if (gl_FragCoord.x < TextClip.x || gl_FragCoord.y < TextClip.y || gl_FragCoord.x > TextClip.z || gl_FragCoord.y > TextClip.w) {
+        discard;
+    }
+#endif
+    vec4 color = vec4(0.0);
+
+#if defined(SDF) || defined(MSDF)
+    // Glow fading out from the outlined glyphs
+    if (TextGlowColor.a > 0.0 && TextGlowWidth > 0.0) {
+        float outside = -(signedDistance(vTexcoord, false) + TextOutlineWidth);
+        float glow = 1.0 - clamp(outside / TextGlowWidth, 0.0, 1.0);
+        color = vec4(TextGlowColor.rgb, TextGlowColor.a * glow * glow);
+    }
+#endif
+
+    // Drop shadow of the outlined glyphs, with the offset Y up and atlas V down
+    if (TextShadowColor.a > 0.0) {
+        vec2 offset = vec2(TextShadowOffset.x, -TextShadowOffset.y);
+        color = over(vec4(TextShadowColor.rgb, TextShadowColor.a * dilated(vTexcoord - offset, TextOutlineWidth)), color);
+    }
+
+    // Outline around the glyphs
+    if (TextOutlineWidth > 0.0) {
+        color = over(vec4(TextOutlineColor.rgb, TextOutlineColor.a * dilated(vTexcoord, TextOutlineWidth)), color);
+    }
+
+    color = over(vec4(TextColor.rgb, TextColor.a * dilated(vTexcoord, 0.0)), color);
+    if (color.a < 0.004) {
+        discard;
+    }
+    FragColor = color;
+}
+`
+
+const text_vertex_source = `#include <attributes>
+
+// Model uniforms
+uniform mat4 MVP;
+
+// Texture coordinates in atlas pixels
+out vec2 vTexcoord;
+
+void main() {
+
+    vTexcoord = VertexTexcoord;
+    gl_Position = MVP * vec4(VertexPosition, 1.0);
+}
+`
+
+const trail_fragment_source = `precision highp float;
+
+// Inputs from the vertex shader
+in vec4 Color;
+in vec2 FragTexcoord;
+
+#ifdef HAS_TRAILMAP
+uniform sampler2D uTrailSampler;
+#endif
+
+// Output
+out vec4 FragColor;
+
+void main() {
+
+    vec4 color = Color;
+#ifdef HAS_TRAILMAP
+    color *= texture(uTrailSampler, FragTexcoord);
+#endif
+    FragColor = color;
+}
+`
+
+const trail_vertex_source = `// Trail attributes with fixed locations.
+// The params are (side, half width, normalized age, 0).
+layout(location = 0) in vec3 VertexPosition;
+layout(location = 1) in vec3 TrailTangent;
+layout(location = 2) in vec4 TrailParams;
+layout(location = 3) in vec4 TrailColor;
+
+// Model uniforms
+uniform mat4 ModelViewMatrix;
+uniform mat4 ProjMatrix;
+
+// Outputs for the fragment shader
+out vec4 Color;
+out vec2 FragTexcoord;
+
+void main() {
+
+    // Expands the ribbon perpendicular to both its tangent and the direction to the camera
+    vec4 position = ModelViewMatrix * vec4(VertexPosition, 1.0);
+    vec3 tangent = mat3(ModelViewMatrix) * TrailTangent;
+    vec3 side = cross(tangent, position.xyz);
+    float l = length(side);
+    if (l > 1e-6) {
+        position.xyz += side / l * TrailParams.x * TrailParams.y;
+    }
+    Color = TrailColor;
+    FragTexcoord = vec2(TrailParams.z, TrailParams.x * 0.5 + 0.5);
+    gl_Position = ProjMatrix * position;
+}
+`
+
 // Maps include name with its source code
 var includeMap = map[string]string{
 
 	"attributes":                      include_attributes_source,
 	"bones_vertex":                    include_bones_vertex_source,
 	"bones_vertex_declaration":        include_bones_vertex_declaration_source,
+	"fog":                             include_fog_source,
 	"lights":                          include_lights_source,
 	"material":                        include_material_source,
 	"morphtarget_vertex":              include_morphtarget_vertex_source,
@@ -1124,24 +2722,51 @@ var includeMap = map[string]string{
 // Maps shader name with its source code
 var shaderMap = map[string]string{
 
-	"basic_fragment":    basic_fragment_source,
-	"basic_vertex":      basic_vertex_source,
-	"panel_fragment":    panel_fragment_source,
-	"panel_vertex":      panel_vertex_source,
-	"physical_fragment": physical_fragment_source,
-	"physical_vertex":   physical_vertex_source,
-	"point_fragment":    point_fragment_source,
-	"point_vertex":      point_vertex_source,
-	"standard_fragment": standard_fragment_source,
-	"standard_vertex":   standard_vertex_source,
+	"basic_fragment":     basic_fragment_source,
+	"basic_vertex":       basic_vertex_source,
+	"decal_fragment":     decal_fragment_source,
+	"decal_vertex":       decal_vertex_source,
+	"impostor_fragment":  impostor_fragment_source,
+	"impostor_vertex":    impostor_vertex_source,
+	"panel_fragment":     panel_fragment_source,
+	"panel_vertex":       panel_vertex_source,
+	"particle_fragment":  particle_fragment_source,
+	"particle_vertex":    particle_vertex_source,
+	"physical_fragment":  physical_fragment_source,
+	"physical_vertex":    physical_vertex_source,
+	"point_fragment":     point_fragment_source,
+	"point_vertex":       point_vertex_source,
+	"polyline_fragment":  polyline_fragment_source,
+	"polyline_vertex":    polyline_vertex_source,
+	"reflector_fragment": reflector_fragment_source,
+	"reflector_vertex":   reflector_vertex_source,
+	"sky_fragment":       sky_fragment_source,
+	"sky_vertex":         sky_vertex_source,
+	"standard_fragment":  standard_fragment_source,
+	"standard_vertex":    standard_vertex_source,
+	"terrain_fragment":   terrain_fragment_source,
+	"terrain_vertex":     terrain_vertex_source,
+	"text_fragment":      text_fragment_source,
+	"text_vertex":        text_vertex_source,
+	"trail_fragment":     trail_fragment_source,
+	"trail_vertex":       trail_vertex_source,
 }
 
 // Maps program name with Proginfo struct with shaders names
 var programMap = map[string]ProgramInfo{
 
-	"basic":    {"basic_vertex", "basic_fragment", ""},
-	"panel":    {"panel_vertex", "panel_fragment", ""},
-	"physical": {"physical_vertex", "physical_fragment", ""},
-	"point":    {"point_vertex", "point_fragment", ""},
-	"standard": {"standard_vertex", "standard_fragment", ""},
+	"basic":     {"basic_vertex", "basic_fragment", ""},
+	"decal":     {"decal_vertex", "decal_fragment", ""},
+	"impostor":  {"impostor_vertex", "impostor_fragment", ""},
+	"panel":     {"panel_vertex", "panel_fragment", ""},
+	"particle":  {"particle_vertex", "particle_fragment", ""},
+	"physical":  {"physical_vertex", "physical_fragment", ""},
+	"point":     {"point_vertex", "point_fragment", ""},
+	"polyline":  {"polyline_vertex", "polyline_fragment", ""},
+	"reflector": {"reflector_vertex", "reflector_fragment", ""},
+	"sky":       {"sky_vertex", "sky_fragment", ""},
+	"standard":  {"standard_vertex", "standard_fragment", ""},
+	"terrain":   {"terrain_vertex", "terrain_fragment", ""},
+	"text":      {"text_vertex", "text_fragment", ""},
+	"trail":     {"trail_vertex", "trail_fragment", ""},
 }
@@ -4,10 +4,91 @@ precision highp float;
 in vec4 Position;     // Fragment position in camera coordinates
 in vec3 Normal;       // Fragment normal in camera coordinates
 in vec2 FragTexcoord; // Fragment texture coordinates
+in vec2 MapTexcoord;  // Fragment texture coordinates for the typed maps
+#ifdef HAS_TANGENTS
+in vec4 Tangent;      // Fragment tangent in camera coordinates and bitangent handedness
+#endif
 
 #include <lights>
 #include <material>
 #include <phong_model>
+#include <fog>
+
+// Typed maps samplers and parameters (3*vec2 per texture)
+#ifdef HAS_DIFFUSEMAP
+uniform sampler2D uDiffuseSampler;
+uniform vec2 uDiffuseTexParams[3];
+#endif
+#ifdef HAS_NORMALMAP
+uniform sampler2D uNormalSampler;
+uniform vec2 uNormalTexParams[3];
+#endif
+#ifdef HAS_BUMPMAP
+uniform sampler2D uBumpSampler;
+uniform vec2 uBumpTexParams[3];
+#endif
+#ifdef HAS_SPECULARMAP
+uniform sampler2D uSpecularSampler;
+uniform vec2 uSpecularTexParams[3];
+#endif
+#ifdef HAS_EMISSIVEMAP
+uniform sampler2D uEmissiveSampler;
+uniform vec2 uEmissiveTexParams[3];
+#endif
+#ifdef HAS_ALPHAMAP
+uniform sampler2D uAlphaSampler;
+uniform vec2 uAlphaTexParams[3];
+#endif
+#ifdef HAS_PARALLAXMAP
+uniform sampler2D uParallaxSampler;
+uniform vec2 uParallaxTexParams[3];
+#endif
+
+// Returns the texture coordinates of a typed map from the base coordinates
+// applying the map's optional Y flip, repeat and offset.
+vec2 mapTexcoord(vec2 uv, vec2 texParams[3]) {
+    if (bool(texParams[2].x)) {
+        uv.y = 1.0 - uv.y;
+    }
+    return uv * texParams[1] + texParams[0];
+}
+
+// Returns the tangent space matrix for the specified normal,
+// from the interpolated tangent if available or else from the screen space derivatives.
+mat3 tangentSpace(vec3 normal) {
+#ifdef HAS_TANGENTS
+    vec3 t = normalize(Tangent.xyz - normal * dot(normal, Tangent.xyz));
+    vec3 b = cross(normal, t) * Tangent.w;
+#else
+    vec3 pos_dx = dFdx(Position.xyz);
+    vec3 pos_dy = dFdy(Position.xyz);
+    vec2 tex_dx = dFdx(MapTexcoord);
+    vec2 tex_dy = dFdy(MapTexcoord);
+    vec3 t = (tex_dy.t * pos_dx - tex_dx.t * pos_dy) / (tex_dx.s * tex_dy.t - tex_dy.s * tex_dx.t);
+    t = normalize(t - normal * dot(normal, t));
+    vec3 b = normalize(cross(normal, t));
+#endif
+    return mat3(t, b, normal);
+}
+
+#if defined(HAS_BUMPMAP) && !defined(HAS_NORMALMAP)
+// Perturbs the normal using the screen space derivatives of the bump map heights.
+// "Bump Mapping Unparametrized Surfaces on the GPU" by Morten S. Mikkelsen.
+vec3 bumpNormal(vec3 normal, vec2 uv) {
+    vec2 dSTdx = dFdx(uv);
+    vec2 dSTdy = dFdy(uv);
+    float Hll = MatBumpScale * texture(uBumpSampler, uv).r;
+    float dBx = MatBumpScale * texture(uBumpSampler, uv + dSTdx).r - Hll;
+    float dBy = MatBumpScale * texture(uBumpSampler, uv + dSTdy).r - Hll;
+    vec3 vSigmaX = dFdx(Position.xyz);
+    vec3 vSigmaY = dFdy(Position.xyz);
+    vec3 R1 = cross(vSigmaY, normal);
+    vec3 R2 = cross(normal, vSigmaX);
+    float fDet = dot(vSigmaX, R1);
+    vec3 vGrad = sign(fDet) * (dBx * R1 + dBy * R2);
+    return normalize(abs(fDet) * normal - vGrad);
+}
+#endif
 
 // Final fragment color
 out vec4 FragColor;
@@ -51,10 +132,6 @@ void main() {
         #endif
     #endif
 
-    // Combine material with texture colors
-    vec4 matDiffuse = vec4(MatDiffuseColor, MatOpacity) * texMixed;
-    vec4 matAmbient = vec4(MatAmbientColor, MatOpacity) * texMixed;
-
     // Normalize interpolated normal as it may have shrinked
     vec3 fragNormal = normalize(Normal);
 
@@ -69,10 +146,55 @@ void main() {
         fragNormal = -fragNormal;
     }
 
+    // Base texture coordinates of the typed maps, optionally offset by the parallax map
+    vec2 uv = MapTexcoord;
+#ifdef HAS_PARALLAXMAP
+    {
+        vec3 viewTS = normalize(transpose(tangentSpace(fragNormal)) * camDir);
+        float height = texture(uParallaxSampler, mapTexcoord(uv, uParallaxTexParams)).r;
+        uv += viewTS.xy / max(viewTS.z, 0.1) * (height * MatParallaxScale);
+    }
+#endif
+
+    // Perturb the normal from the normal or bump maps
+#ifdef HAS_NORMALMAP
+    vec3 mapN = texture(uNormalSampler, mapTexcoord(uv, uNormalTexParams)).rgb * 2.0 - 1.0;
+    mapN.xy *= MatNormalScale;
+    fragNormal = normalize(tangentSpace(fragNormal) * mapN);
+#elif defined(HAS_BUMPMAP)
+    fragNormal = bumpNormal(fragNormal, mapTexcoord(uv, uBumpTexParams));
+#endif
+
+    // Combine material with texture colors
+#ifdef HAS_DIFFUSEMAP
+    texMixed *= texture(uDiffuseSampler, mapTexcoord(uv, uDiffuseTexParams));
+#endif
+    vec4 matDiffuse = vec4(MatDiffuseColor, MatOpacity) * texMixed;
+    vec4 matAmbient = vec4(MatAmbientColor, MatOpacity) * texMixed;
+#ifdef HAS_ALPHAMAP
+    matDiffuse.a *= texture(uAlphaSampler, mapTexcoord(uv, uAlphaTexParams)).r;
+#endif
+    vec3 matSpecular = MatSpecularColor;
+#ifdef HAS_SPECULARMAP
+    matSpecular *= texture(uSpecularSampler, mapTexcoord(uv, uSpecularTexParams)).rgb;
+#endif
+    vec3 matEmissive = MatEmissiveColor;
+#ifdef HAS_EMISSIVEMAP
+    matEmissive *= texture(uEmissiveSampler, mapTexcoord(uv, uEmissiveTexParams)).rgb;
+#endif
+
     // Calculates the Ambient+Diffuse and Specular colors for this fragment using the Phong model.
     vec3 Ambdiff, Spec;
-    phongModel(Position, fragNormal, camDir, vec3(matAmbient), vec3(matDiffuse), Ambdiff, Spec);
+    phongModel(Position, fragNormal, camDir, vec3(matAmbient), vec3(matDiffuse), matSpecular, matEmissive, Ambdiff, Spec);
 
     // Final fragment color
     FragColor = min(vec4(Ambdiff + Spec, matDiffuse.a), vec4(1.0));
+#ifdef FOG
+#ifdef FOG_SKY
+    // Skies are blended toward the horizon instead of with the distance
+    FragColor.rgb = mix(FragColor.rgb, FogParams[0].rgb, fogSkyFactor(dot(FogParams[2].xyz, normalize(Position.xyz))));
+#else
+    FragColor.rgb = applyFog(FragColor.rgb, Position.xyz);
+#endif
+#endif
 }
@@ -9,10 +9,16 @@ uniform mat4 MVP;
 #include <morphtarget_vertex_declaration>
 #include <bones_vertex_declaration>
 
+#ifdef HAS_TANGENTS
+in vec4 VertexTangent;
+out vec4 Tangent;
+#endif
+
 // Output variables for Fragment shader
 out vec4 Position;
 out vec3 Normal;
 out vec2 FragTexcoord;
+out vec2 MapTexcoord;
 
 void main() {
 
@@ -22,6 +28,11 @@ void main() {
     // Transform vertex normal to camera coordinates
     Normal = normalize(NormalMatrix * VertexNormal);
 
+#ifdef HAS_TANGENTS
+    // Transform vertex tangent to camera coordinates keeping its handedness
+    Tangent = vec4(normalize(mat3(ModelViewMatrix) * VertexTangent.xyz), VertexTangent.w);
+#endif
+
     vec2 texcoord = VertexTexcoord;
 #if MAT_TEXTURES > 0
     // Flip texture coordinate Y if requested.
@@ -30,6 +41,8 @@ void main() {
     }
 #endif
     FragTexcoord = texcoord;
+    // Texture coordinates for the typed maps which are flipped by each map
+    MapTexcoord = VertexTexcoord;
     vec3 vPosition = VertexPosition;
     mat4 finalWorld = mat4(1.0);
     #include <morphtarget_vertex>
@@ -0,0 +1,99 @@
+precision highp float;
+
+// Inputs from vertex shader
+in vec4 Position;     // Fragment position in camera coordinates
+in vec3 Normal;       // Fragment normal in camera coordinates
+in vec2 MapTexcoord;  // Fragment texture coordinates over the whole terrain
+
+#include <lights>
+#include <material>
+#include <phong_model>
+#include <fog>
+
+// Splat map and layers samplers and parameters (3*vec2 per texture)
+#ifdef HAS_SPLATMAP
+uniform sampler2D uSplatSampler;
+uniform vec2 uSplatTexParams[3];
+#endif
+#ifdef HAS_LAYER0
+uniform sampler2D uLayer0Sampler;
+uniform vec2 uLayer0TexParams[3];
+#endif
+#ifdef HAS_LAYER1
+uniform sampler2D uLayer1Sampler;
+uniform vec2 uLayer1TexParams[3];
+#endif
+#ifdef HAS_LAYER2
+uniform sampler2D uLayer2Sampler;
+uniform vec2 uLayer2TexParams[3];
+#endif
+#ifdef HAS_LAYER3
+uniform sampler2D uLayer3Sampler;
+uniform vec2 uLayer3TexParams[3];
+#endif
+
+// Returns the texture coordinates of a map from the base coordinates
+// applying the map's optional Y flip, repeat and offset.
+vec2 mapTexcoord(vec2 uv, vec2 texParams[3]) {
+    if (bool(texParams[2].x)) {
+        uv.y = 1.0 - uv.y;
+    }
+    return uv * texParams[1] + texParams[0];
+}
+
+// Final fragment color
+out vec4 FragColor;
+
+void main() {
+
+    // Blend the layers with the weights of the channels of the splat map,
+    // or use only the first layer if there is no splat map.
+#ifdef HAS_SPLATMAP
+    vec4 weights = texture(uSplatSampler, mapTexcoord(MapTexcoord, uSplatTexParams));
+#else
+    vec4 weights = vec4(1, 0, 0, 0);
+#endif
+    vec4 texMixed = vec4(0);
+    float total = 0.0;
+#ifdef HAS_LAYER0
+    texMixed += weights.r * texture(uLayer0Sampler, mapTexcoord(MapTexcoord, uLayer0TexParams));
+    total += weights.r;
+#endif
+#ifdef HAS_LAYER1
+    texMixed += weights.g * texture(uLayer1Sampler, mapTexcoord(MapTexcoord, uLayer1TexParams));
+    total += weights.g;
+#endif
+#ifdef HAS_LAYER2
+    texMixed += weights.b * texture(uLayer2Sampler, mapTexcoord(MapTexcoord, uLayer2TexParams));
+    total += weights.b;
+#endif
+#ifdef HAS_LAYER3
+    texMixed += weights.a * texture(uLayer3Sampler, mapTexcoord(MapTexcoord, uLayer3TexParams));
+    total += weights.a;
+#endif
+    if (total > 0.0) {
+        texMixed /= total;
+    } else {
+        texMixed = vec4(1);
+    }
+
+    // Normalize interpolated normal as it may have shrinked
+    vec3 fragNormal = normalize(Normal);
+
+    // Calculate the direction vector from the fragment to the camera (origin)
+    vec3 camDir = normalize(-Position.xyz);
+
+    // Combine material with texture colors
+    vec4 matDiffuse = vec4(MatDiffuseColor, MatOpacity) * texMixed;
+    vec4 matAmbient = vec4(MatAmbientColor, MatOpacity) * texMixed;
+
+    // Calculates the Ambient+Diffuse and Specular colors for this fragment using the Phong model.
+    vec3 Ambdiff, Spec;
+    phongModel(Position, fragNormal, camDir, vec3(matAmbient), vec3(matDiffuse), MatSpecularColor, MatEmissiveColor, Ambdiff, Spec);
+
+    // Final fragment color
+    FragColor = min(vec4(Ambdiff + Spec, matDiffuse.a), vec4(1.0));
+#ifdef FOG
+    FragColor.rgb = applyFog(FragColor.rgb, Position.xyz);
+#endif
+}
@@ -0,0 +1,28 @@
+#include <attributes>
+
+// Model uniforms
+uniform mat4 ModelViewMatrix;
+uniform mat3 NormalMatrix;
+uniform mat4 MVP;
+
+#include <material>
+
+// Output variables for Fragment shader
+out vec4 Position;
+out vec3 Normal;
+out vec2 MapTexcoord;
+
+void main() {
+
+    // Transform vertex position to camera coordinates
+    Position = ModelViewMatrix * vec4(VertexPosition, 1.0);
+
+    // Transform vertex normal to camera coordinates
+    Normal = normalize(NormalMatrix * VertexNormal);
+
+    // Texture coordinates over the whole terrain which are flipped by each map
+    MapTexcoord = VertexTexcoord;
+
+    // Output projected and transformed vertex position
+    gl_Position = MVP * vec4(VertexPosition, 1.0);
+}
@@ -0,0 +1,121 @@
+precision highp float;
+
+// Glyph atlas
+uniform sampler2D uTextSampler;
+
+// Text uniforms
+uniform vec4 TextParams[6];
+#define TextColor           TextParams[0]
+#define TextOutlineColor    TextParams[1]
+#define TextShadowColor     TextParams[2]
+#define TextGlowColor       TextParams[3]
+#define TextOutlineWidth    TextParams[4].x
+#define TextShadowOffset    TextParams[4].yz
+#define TextGlowWidth       TextParams[4].w
+#define TextSpread          TextParams[5].x
+
+#ifdef TEXT_CLIP
+// Clipping rectangle in window coordinates (xmin, ymin, xmax, ymax)
+uniform vec4 TextClip;
+#endif
+
+// Texture coordinates in atlas pixels
+in vec2 vTexcoord;
+
+out vec4 FragColor;
+
+// Atlas pixels per screen pixel
+float texelWidth;
+
+#if defined(SDF) || defined(MSDF)
+
+// Returns the signed distance in atlas pixels to the outline of the glyphs, positive inside.
+// Multi-channel atlases give the distance keeping the corners sharp or the true distance.
+float signedDistance(vec2 p, bool sharp) {
+
+    vec4 s = texture(uTextSampler, p / vec2(textureSize(uTextSampler, 0)));
+    float v = s.a;
+#ifdef MSDF
+    if (sharp) {
+        v = max(min(s.r, s.g), min(max(s.r, s.g), s.b));
+    }
+#endif
+    return (v - 0.5) * 2.0 * TextSpread;
+}
+
+// Returns the coverage of the glyphs dilated by the specified radius in atlas pixels
+float dilated(vec2 p, float radius) {
+
+    float d = signedDistance(p, radius <= 0.0) + radius;
+    return clamp(d / texelWidth + 0.5, 0.0, 1.0);
+}
+
+#else
+
+// Returns the coverage of the glyphs dilated by the specified radius in atlas pixels
+float dilated(vec2 p, float radius) {
+
+    vec2 size = vec2(textureSize(uTextSampler, 0));
+    float c = texture(uTextSampler, p / size).a;
+    if (radius <= 0.0) {
+        return c;
+    }
+    // Samples two rings of directions
+    for (int i = 0; i < 12; i++) {
+        float a = float(i) * 0.5235988;
+        vec2 d = vec2(cos(a), sin(a));
+        c = max(c, texture(uTextSampler, (p + d * radius) / size).a);
+        c = max(c, texture(uTextSampler, (p + d * radius * 0.5) / size).a);
+    }
+    return c;
+}
+
+#endif
+
+// Returns the color with straight alpha of the specified layer over the specified color
+vec4 over(vec4 layer, vec4 color) {
+
+    float a = layer.a + color.a * (1.0 - layer.a);
+    if (a <= 0.0) {
+        return vec4(0.0);
+    }
+    return vec4((layer.rgb * layer.a + color.rgb * color.a * (1.0 - layer.a)) / a, a);
+}
+
+void main() {
+
+    vec2 fw = fwidth(vTexcoord);
+    texelWidth = max(0.5 * (fw.x + fw.y), 1e-4);
+#ifdef TEXT_CLIP
+    if (gl_FragCoord.x < TextClip.x || gl_FragCoord.y < TextClip.y || gl_FragCoord.x > TextClip.z || gl_FragCoord.y > TextClip.w) {
+        discard;
+    }
+#endif
+    vec4 color = vec4(0.0);
+
+#if defined(SDF) || defined(MSDF)
+    // Glow fading out from the outlined glyphs
+    if (TextGlowColor.a > 0.0 && TextGlowWidth > 0.0) {
+        float outside = -(signedDistance(vTexcoord, false) + TextOutlineWidth);
+        float glow = 1.0 - clamp(outside / TextGlowWidth, 0.0, 1.0);
+        color = vec4(TextGlowColor.rgb, TextGlowColor.a * glow * glow);
+    }
+#endif
+
+    // Drop shadow of the outlined glyphs, with the offset Y up and atlas V down
+    if (TextShadowColor.a > 0.0) {
+        vec2 offset = vec2(TextShadowOffset.x, -TextShadowOffset.y);
+        color = over(vec4(TextShadowColor.rgb, TextShadowColor.a * dilated(vTexcoord - offset, TextOutlineWidth)), color);
+    }
+
+    // Outline around the glyphs
+    if (TextOutlineWidth > 0.0) {
+        color = over(vec4(TextOutlineColor.rgb, TextOutlineColor.a * dilated(vTexcoord, TextOutlineWidth)), color);
+    }
+
+    color = over(vec4(TextColor.rgb, TextColor.a * dilated(vTexcoord, 0.0)), color);
+    if (color.a < 0.004) {
+        discard;
+    }
+    FragColor = color;
+}
@@ -0,0 +1,13 @@
+#include <attributes>
+
+// Model uniforms
+uniform mat4 MVP;
+
+// Texture coordinates in atlas pixels
+out vec2 vTexcoord;
+
+void main() {
+
+    vTexcoord = VertexTexcoord;
+    gl_Position = MVP * vec4(VertexPosition, 1.0);
+}
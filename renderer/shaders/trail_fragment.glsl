@@ -0,0 +1,21 @@
+precision highp float;
+
+// Inputs from the vertex shader
+in vec4 Color;
+in vec2 FragTexcoord;
+
+#ifdef HAS_TRAILMAP
+uniform sampler2D uTrailSampler;
+#endif
+
+// Output
+out vec4 FragColor;
+
+void main() {
+
+    vec4 color = Color;
+#ifdef HAS_TRAILMAP
+    color *= texture(uTrailSampler, FragTexcoord);
+#endif
+    FragColor = color;
+}
@@ -0,0 +1,29 @@
+// Trail attributes with fixed locations.
+// The params are (side, half width, normalized age, 0).
+layout(location = 0) in vec3 VertexPosition;
+layout(location = 1) in vec3 TrailTangent;
+layout(location = 2) in vec4 TrailParams;
+layout(location = 3) in vec4 TrailColor;
+
+// Model uniforms
+uniform mat4 ModelViewMatrix;
+uniform mat4 ProjMatrix;
+
+// Outputs for the fragment shader
+out vec4 Color;
+out vec2 FragTexcoord;
+
+void main() {
+
+    // Expands the ribbon perpendicular to both its tangent and the direction to the camera
+    vec4 position = ModelViewMatrix * vec4(VertexPosition, 1.0);
+    vec3 tangent = mat3(ModelViewMatrix) * TrailTangent;
+    vec3 side = cross(tangent, position.xyz);
+    float l = length(side);
+    if (l > 1e-6) {
+        position.xyz += side / l * TrailParams.x * TrailParams.y;
+    }
+    Color = TrailColor;
+    FragTexcoord = vec2(TrailParams.z, TrailParams.x * 0.5 + 0.5);
+    gl_Position = ProjMatrix * position;
+}
@@ -0,0 +1,81 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package terrain implements large heightfield terrains rendered as chunks of meshes
+// with distance based levels of detail, textured by blending layers with a splat map.
+package terrain
+
+import (
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+)
+
+// Heightmap is a regular grid of normalized heights, usually from 0 to 1,
+// in row major order with the X axis varying fastest.
+type Heightmap struct {
+	Width int       // Number of samples along the X axis
+	Depth int       // Number of samples along the Z axis
+	Data  []float32 // Heights of the samples
+}
+
+// NewHeightmap creates and returns a pointer to a new heightmap with the specified
+// number of samples along the X and Z axes and heights in row major order.
+func NewHeightmap(width, depth int, data []float32) *Heightmap {
+
+	if width < 2 || depth < 2 || len(data) != width*depth {
+		panic("Invalid argument(s). Heightmap needs at least 2x2 samples and width*depth heights.")
+	}
+	return &Heightmap{width, depth, data}
+}
+
+// NewHeightmapFromImage creates and returns a pointer to a new heightmap from the luminance
+// of the pixels of the specified image, from 0 for black to 1 for white.
+// The full precision of 16 bit images is kept. The top row of the image is at the minimum Z.
+func NewHeightmapFromImage(img image.Image) *Heightmap {
+
+	bounds := img.Bounds()
+	h := NewHeightmap(bounds.Dx(), bounds.Dy(), make([]float32, bounds.Dx()*bounds.Dy()))
+	for z := 0; z < h.Depth; z++ {
+		for x := 0; x < h.Width; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+z).RGBA()
+			h.Data[z*h.Width+x] = (0.299*float32(r) + 0.587*float32(g) + 0.114*float32(b)) / 0xFFFF
+		}
+	}
+	return h
+}
+
+// LoadHeightmap decodes the specified image file and returns a pointer to a new heightmap from it.
+func LoadHeightmap(imgfile string) (*Heightmap, error) {
+
+	file, err := os.Open(imgfile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+	return NewHeightmapFromImage(img), nil
+}
+
+// At returns the height of the sample at the specified column and row, clamped to the grid.
+func (h *Heightmap) At(x, z int) float32 {
+
+	if x < 0 {
+		x = 0
+	} else if x >= h.Width {
+		x = h.Width - 1
+	}
+	if z < 0 {
+		z = 0
+	} else if z >= h.Depth {
+		z = h.Depth - 1
+	}
+	return h.Data[z*h.Width+x]
+}
@@ -0,0 +1,86 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package terrain
+
+import (
+	"strconv"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// MaxLayers is the maximum number of texture layers of a splat material.
+const MaxLayers = 4
+
+// SplatMaterial is a standard material for terrains which blends up to four texture
+// layers using the weights in the red, green, blue and alpha channels of a splat map
+// covering the whole terrain. Each layer is repeated over the terrain independently.
+type SplatMaterial struct {
+	material.Standard                               // Embedded standard material
+	splatTex          *texture.Texture2D            // Optional splat map
+	layers            [MaxLayers]*texture.Texture2D // Optional layer textures
+}
+
+// NewSplatMaterial creates and returns a pointer to a new splat material with the specified color.
+func NewSplatMaterial(color *math32.Color) *SplatMaterial {
+
+	m := new(SplatMaterial)
+	m.Standard.Init("terrain", color)
+	return m
+}
+
+// SetSplatMap sets the splat map whose channels are the weights of the layers.
+// Without a splat map only the first layer is used. Setting nil removes it.
+func (m *SplatMaterial) SetSplatMap(tex *texture.Texture2D) {
+
+	m.setMap(&m.splatTex, tex, "Splat", "HAS_SPLATMAP")
+}
+
+// SplatMap returns the splat map or nil.
+func (m *SplatMaterial) SplatMap() *texture.Texture2D {
+
+	return m.splatTex
+}
+
+// SetLayer sets the texture of the specified layer, repeated the specified number
+// of times over the terrain in each direction. Setting nil removes it.
+func (m *SplatMaterial) SetLayer(layer int, tex *texture.Texture2D, repeat float32) {
+
+	if layer < 0 || layer >= MaxLayers {
+		panic("Invalid argument(s). Splat material layer out of range.")
+	}
+	if tex != nil {
+		tex.SetWrapS(gls.REPEAT)
+		tex.SetWrapT(gls.REPEAT)
+		tex.SetRepeat(repeat, repeat)
+	}
+	name := "Layer" + strconv.Itoa(layer)
+	m.setMap(&m.layers[layer], tex, name, "HAS_LAYER"+strconv.Itoa(layer))
+}
+
+// Layer returns the texture of the specified layer or nil.
+func (m *SplatMaterial) Layer(layer int) *texture.Texture2D {
+
+	return m.layers[layer]
+}
+
+// setMap replaces the texture of a typed slot, updating the material
+// textures, the texture uniform names and the shader define.
+func (m *SplatMaterial) setMap(slot **texture.Texture2D, tex *texture.Texture2D, name, define string) {
+
+	if *slot != nil {
+		m.RemoveTexture(*slot)
+	}
+	*slot = tex
+	if tex != nil {
+		tex.SetUniformNames("u"+name+"Sampler", "u"+name+"TexParams")
+		m.ShaderDefines.Set(define, "")
+		m.AddTexture(tex)
+	} else {
+		m.ShaderDefines.Unset(define)
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package terrain
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/experimental/collision/shape"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// Terrain is a node which renders a heightmap as a grid of square chunks of meshes
+// over the XZ plane, centered at the origin. Each chunk has several levels of detail
+// which skip samples of the heightmap by powers of two (geomipmapping), selected by
+// the distance of the chunk to the camera when Update is called.
+// The border of each chunk has a vertical skirt hiding the cracks between chunks
+// with different levels of detail.
+type Terrain struct {
+	core.Node                      // Embedded node
+	heightmap   *Heightmap         // Normalized heights
+	field       *shape.Heightfield // Scaled heights shared with height and normal queries
+	chunkSize   int                // Number of quads along each side of a chunk
+	levels      int                // Number of levels of detail
+	lodDistance float32            // Distance up to which chunks have the full level of detail
+	chunks      []*chunk           // Chunks in row major order
+}
+
+// chunk is a square region of the terrain with its own mesh.
+type chunk struct {
+	mesh  *graphic.Mesh      // Mesh with the indices of all the levels of detail
+	imat  material.IMaterial // Material of the mesh
+	bbox  math32.Box3        // Local bounding box of the chunk
+	lods  [][2]int           // Start and count of the indices of each level of detail
+	level int                // Current level of detail
+}
+
+// NewTerrain creates and returns a pointer to a new terrain from the specified heightmap,
+// with the specified width along the X axis, depth along the Z axis, scale of the heights,
+// number of quads along each side of a chunk and material.
+// The chunk size must be a power of two dividing the number of samples minus one
+// of the heightmap along both axes, as in a 513x513 heightmap with chunks of 64 quads.
+func NewTerrain(hm *Heightmap, width, depth, heightScale float32, chunkSize int, imat material.IMaterial) *Terrain {
+
+	if chunkSize < 1 || chunkSize&(chunkSize-1) != 0 || (hm.Width-1)%chunkSize != 0 || (hm.Depth-1)%chunkSize != 0 {
+		panic("Invalid argument(s). Terrain chunk size must be a power of two dividing the heightmap size minus one.")
+	}
+
+	t := new(Terrain)
+	t.Node.Init(t)
+	t.heightmap = hm
+	t.chunkSize = chunkSize
+	for size := chunkSize; size > 0; size /= 2 {
+		t.levels++
+	}
+	t.lodDistance = 2 * float32(chunkSize) * width / float32(hm.Width-1)
+
+	heights := make([]float32, len(hm.Data))
+	for i, h := range hm.Data {
+		heights[i] = h * heightScale
+	}
+	t.field = shape.NewHeightfield(heights, hm.Width, hm.Depth, width, depth)
+
+	for cz := 0; cz < (hm.Depth-1)/chunkSize; cz++ {
+		for cx := 0; cx < (hm.Width-1)/chunkSize; cx++ {
+			if len(t.chunks) > 0 {
+				imat.GetMaterial().Incref()
+			}
+			c := t.newChunk(cx*chunkSize, cz*chunkSize, imat)
+			t.chunks = append(t.chunks, c)
+			t.Add(c.mesh)
+		}
+	}
+	return t
+}
+
+// newChunk creates the chunk whose first sample is at the specified column and row of the heightmap.
+func (t *Terrain) newChunk(col, row int, imat material.IMaterial) *chunk {
+
+	n := t.chunkSize
+	width, depth := t.field.Dimensions()
+	cols, rows := t.field.Size()
+	dx := width / float32(cols-1)
+	dz := depth / float32(rows-1)
+
+	// Vertices of the grid of the chunk in row major order
+	positions := math32.NewArrayF32(0, ((n+1)*(n+1)+4*n)*3)
+	normals := math32.NewArrayF32(0, ((n+1)*(n+1)+4*n)*3)
+	uvs := math32.NewArrayF32(0, ((n+1)*(n+1)+4*n)*2)
+	minY := math32.Infinity
+	maxY := -math32.Infinity
+	for j := 0; j <= n; j++ {
+		for i := 0; i <= n; i++ {
+			gx, gz := col+i, row+j
+			y := t.field.Sample(gx, gz)
+			minY = math32.Min(minY, y)
+			maxY = math32.Max(maxY, y)
+			normal := math32.Vector3{
+				-(t.field.Sample(gx+1, gz) - t.field.Sample(gx-1, gz)) / (2 * dx),
+				1,
+				-(t.field.Sample(gx, gz+1) - t.field.Sample(gx, gz-1)) / (2 * dz),
+			}
+			normal.Normalize()
+			positions.Append(float32(gx)*dx-width/2, y, float32(gz)*dz-depth/2)
+			normals.AppendVector3(&normal)
+			uvs.Append(float32(gx)/float32(cols-1), 1-float32(gz)/float32(rows-1))
+		}
+	}
+
+	// Border vertices of the grid in order around the chunk, each with a skirt vertex below it.
+	// The skirt is as deep as the height range of the chunk, which is the largest possible crack.
+	vertex := func(i, j int) uint32 { return uint32(j*(n+1) + i) }
+	border := make([]uint32, 0, 4*n)
+	for i := 0; i < n; i++ {
+		border = append(border, vertex(i, 0))
+	}
+	for j := 0; j < n; j++ {
+		border = append(border, vertex(n, j))
+	}
+	for i := n; i > 0; i-- {
+		border = append(border, vertex(i, n))
+	}
+	for j := n; j > 0; j-- {
+		border = append(border, vertex(0, j))
+	}
+	skirt := maxY - minY + math32.Max(dx, dz)
+	for _, v := range border {
+		var p, normal math32.Vector3
+		positions.GetVector3(int(v)*3, &p)
+		normals.GetVector3(int(v)*3, &normal)
+		positions.Append(p.X, p.Y-skirt, p.Z)
+		normals.AppendVector3(&normal)
+		uvs.Append(uvs[v*2], uvs[v*2+1])
+	}
+
+	// Indices of each level of detail, skipping 2^level samples, with their skirts
+	c := new(chunk)
+	indices := math32.NewArrayU32(0, 0)
+	for step := 1; step <= n; step *= 2 {
+		start := indices.Size()
+		for j := 0; j < n; j += step {
+			for i := 0; i < n; i += step {
+				v00 := vertex(i, j)
+				v01 := vertex(i, j+step)
+				v10 := vertex(i+step, j)
+				v11 := vertex(i+step, j+step)
+				indices.Append(v00, v01, v10, v10, v01, v11)
+			}
+		}
+		base := uint32((n + 1) * (n + 1))
+		for k := 0; k < len(border); k += step {
+			next := (k + step) % len(border)
+			indices.Append(border[k], border[next], base+uint32(k), border[next], base+uint32(next), base+uint32(k))
+		}
+		c.lods = append(c.lods, [2]int{start, indices.Size() - start})
+	}
+
+	geom := geometry.NewGeometry()
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	geom.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+
+	c.imat = imat
+	c.mesh = graphic.NewMesh(geom, nil)
+	c.mesh.AddMaterial(imat, c.lods[0][0], c.lods[0][1])
+	c.bbox = math32.Box3{
+		math32.Vector3{float32(col)*dx - width/2, minY, float32(row)*dz - depth/2},
+		math32.Vector3{float32(col+n)*dx - width/2, maxY, float32(row+n)*dz - depth/2},
+	}
+	return c
+}
+
+// SetLODDistance sets the distance from the camera up to which chunks have the full level of detail.
+// Each time the distance doubles the level of detail halves the resolution of the chunks.
+// The default is twice the width of a chunk.
+func (t *Terrain) SetLODDistance(distance float32) {
+
+	t.lodDistance = distance
+}
+
+// LODDistance returns the distance from the camera up to which chunks have the full level of detail.
+func (t *Terrain) LODDistance() float32 {
+
+	return t.lodDistance
+}
+
+// Levels returns the number of levels of detail of the chunks.
+func (t *Terrain) Levels() int {
+
+	return t.levels
+}
+
+// Update selects the level of detail of each chunk from its distance to the specified camera
+// position in world coordinates. It should be called when the camera moves.
+func (t *Terrain) Update(camPos *math32.Vector3) {
+
+	var inv math32.Matrix4
+	mw := t.MatrixWorld()
+	if err := inv.GetInverse(&mw); err != nil {
+		return
+	}
+	local := *camPos
+	local.ApplyMatrix4(&inv)
+	for _, c := range t.chunks {
+		// The level increases each time the distance doubles beyond the LOD distance
+		level := 0
+		dist := c.bbox.DistanceToPoint(&local)
+		for d := t.lodDistance; dist > d && d > 0 && level < t.levels-1; d *= 2 {
+			level++
+		}
+		if level != c.level {
+			c.level = level
+			c.mesh.ClearMaterials()
+			c.mesh.AddMaterial(c.imat, c.lods[level][0], c.lods[level][1])
+		}
+	}
+}
+
+// HeightAt returns the height of the terrain at the specified local X and Z coordinates,
+// interpolated over the triangles of the full level of detail.
+func (t *Terrain) HeightAt(x, z float32) float32 {
+
+	return t.field.HeightAt(x, z)
+}
+
+// NormalAt returns the normal of the terrain at the specified local X and Z coordinates.
+func (t *Terrain) NormalAt(x, z float32) math32.Vector3 {
+
+	return t.field.NormalAt(x, z)
+}
+
+// Heightmap returns the heightmap of the terrain.
+func (t *Terrain) Heightmap() *Heightmap {
+
+	return t.heightmap
+}
+
+// Heightfield returns the collision heightfield of the terrain, which shares its scaled heights.
+func (t *Terrain) Heightfield() *shape.Heightfield {
+
+	return t.field
+}
@@ -25,6 +25,7 @@ type Font struct {
 	fg      *image.Uniform // Text color cache
 	bg      *image.Uniform // Background color cache
 	changed bool           // Whether attributes have changed and the font face needs to be recreated
+	sdf     *GlyphAtlas    // Shared distance field atlas created on demand
 }
 
 // FontAttributes contains tunable attributes of a font.
@@ -195,6 +196,23 @@ func (f *Font) MeasureText(text string) (int, int) {
 	return width, height
 }
 
+// Size in pixels per em and spread in pixels of the shared distance field atlases of the fonts.
+const (
+	sdfAtlasSize   = 48
+	sdfAtlasSpread = 6
+)
+
+// SDFAtlas returns the multi-channel signed distance field atlas of the font, creating it on the first call.
+// The same atlas is returned on each call so all the texts and labels drawn with the font at any size
+// share it. It does not depend on the attributes of the font.
+func (f *Font) SDFAtlas() *GlyphAtlas {
+
+	if f.sdf == nil {
+		f.sdf = NewMSDFAtlas(f, sdfAtlasSize, sdfAtlasSpread)
+	}
+	return f.sdf
+}
+
 // Metrics returns the font metrics.
 func (f *Font) Metrics() font.Metrics {
 
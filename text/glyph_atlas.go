@@ -0,0 +1,214 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/g3n/engine/texture"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Glyph contains the metrics of a character and the location of its image in a GlyphAtlas.
+type Glyph struct {
+	Advance float32 // Horizontal distance to the next character in pixels
+	// Bounds of the glyph image, including the padding, relative to the origin
+	// of the character on the base line in pixels, with Y from top to bottom
+	X0 float32
+	Y0 float32
+	X1 float32
+	Y1 float32
+	// Position of the glyph image in pixels in the atlas image from the top left corner.
+	// Positions do not change when the atlas image grows.
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// AtlasKind specifies the contents of the glyph images of a GlyphAtlas.
+type AtlasKind int
+
+// The kinds of glyph atlases.
+const (
+	AtlasCoverage = AtlasKind(iota) // White glyphs with their coverage in the alpha channel
+	AtlasSDF                        // Signed distance to the outline in the alpha channel
+	AtlasMSDF                       // Multi-channel signed distances in RGB and the true signed distance in alpha
+)
+
+// GlyphAtlas is an image containing the glyphs of a font, rasterized on demand
+// as characters are requested, which can be shared by all the texts using the font.
+type GlyphAtlas struct {
+	Image       *image.RGBA       // Atlas image, which grows as needed
+	kind        AtlasKind         // Contents of the glyph images
+	face        font.Face         // Font face of the glyphs
+	ttf         *truetype.Font    // Font of the outlines of distance field glyphs
+	scale       fixed.Int26_6     // Pixels per em of the outlines
+	buf         truetype.GlyphBuf // Outline of the last loaded glyph
+	glyphs      map[rune]*Glyph   // Rasterized glyphs
+	padding     int               // Empty pixels around each glyph
+	emSize      float32           // Size of the em square in pixels
+	ascent      float32           // Distance from the top of a line to its base line
+	descent     float32           // Distance from the base line to the bottom of a line
+	lineHeight  float32           // Distance between the base lines of two lines
+	x, y, shelf int               // Position and height of the current shelf of glyphs
+	tex         *texture.Texture2D
+}
+
+// NewGlyphAtlas creates and returns a pointer to a new empty coverage glyph atlas
+// with the current attributes of the specified font and the specified padding in
+// pixels around each glyph, which is the room for outlines and shadows.
+func NewGlyphAtlas(f *Font, padding int) *GlyphAtlas {
+
+	a := newGlyphAtlas(AtlasCoverage, truetype.NewFace(f.ttf, &truetype.Options{
+		Size:    f.attrib.PointSize,
+		DPI:     f.attrib.DPI,
+		Hinting: f.attrib.Hinting,
+	}), padding)
+	a.emSize = float32(f.attrib.PointSize * f.attrib.DPI / 72)
+	return a
+}
+
+// newGlyphAtlas creates and returns a pointer to a new empty glyph atlas
+// of the specified kind with the glyphs of the specified face.
+func newGlyphAtlas(kind AtlasKind, face font.Face, padding int) *GlyphAtlas {
+
+	a := new(GlyphAtlas)
+	a.kind = kind
+	a.face = face
+	a.glyphs = make(map[rune]*Glyph)
+	a.padding = padding
+	metrics := a.face.Metrics()
+	a.ascent = float32(metrics.Ascent) / 64
+	a.descent = float32(metrics.Descent) / 64
+	a.lineHeight = a.ascent + a.descent
+
+	// Starts with room for a few lines of glyphs
+	size := 256
+	for size < 8*(int(a.ascent+a.descent)+2*padding) {
+		size *= 2
+	}
+	a.Image = image.NewRGBA(image.Rect(0, 0, size, size/4))
+	return a
+}
+
+// Kind returns the kind of the atlas.
+func (a *GlyphAtlas) Kind() AtlasKind {
+
+	return a.kind
+}
+
+// EmSize returns the size of the em square of the font in pixels,
+// which is the size in pixels at which the glyphs are drawn in the atlas.
+func (a *GlyphAtlas) EmSize() float32 {
+
+	return a.emSize
+}
+
+// Ascent returns the distance from the top of a line to its base line in pixels.
+func (a *GlyphAtlas) Ascent() float32 {
+
+	return a.ascent
+}
+
+// Descent returns the distance from the base line to the bottom of a line in pixels.
+func (a *GlyphAtlas) Descent() float32 {
+
+	return a.descent
+}
+
+// LineHeight returns the distance between the base lines of two consecutive lines in pixels
+// without line spacing, which is applied by Layout.
+func (a *GlyphAtlas) LineHeight() float32 {
+
+	return a.lineHeight
+}
+
+// Padding returns the number of empty pixels around each glyph.
+// It is also the distance in pixels at which the distance fields saturate.
+func (a *GlyphAtlas) Padding() int {
+
+	return a.padding
+}
+
+// Kern returns the horizontal adjustment in pixels between the specified characters.
+func (a *GlyphAtlas) Kern(r0, r1 rune) float32 {
+
+	return float32(a.face.Kern(r0, r1)) / 64
+}
+
+// Glyph returns the glyph of the specified character, adding it to the atlas if necessary.
+func (a *GlyphAtlas) Glyph(r rune) *Glyph {
+
+	if g, ok := a.glyphs[r]; ok {
+		return g
+	}
+	g := new(Glyph)
+	a.glyphs[r] = g
+	if a.kind != AtlasCoverage {
+		a.distanceGlyph(g, r)
+		return g
+	}
+	dr, mask, maskp, advance, ok := a.face.Glyph(fixed.Point26_6{}, r)
+	if !ok {
+		return g
+	}
+	g.Advance = float32(advance) / 64
+	if dr.Empty() {
+		return g
+	}
+
+	// Places the glyph with its padding and draws it
+	g.Width = dr.Dx() + 2*a.padding
+	g.Height = dr.Dy() + 2*a.padding
+	g.X, g.Y = a.place(g.Width, g.Height)
+	g.X0 = float32(dr.Min.X - a.padding)
+	g.Y0 = float32(dr.Min.Y - a.padding)
+	g.X1 = float32(dr.Max.X + a.padding)
+	g.Y1 = float32(dr.Max.Y + a.padding)
+	target := image.Rect(g.X+a.padding, g.Y+a.padding, g.X+g.Width-a.padding, g.Y+g.Height-a.padding)
+	draw.DrawMask(a.Image, target, image.White, image.ZP, mask, maskp, draw.Over)
+	if a.tex != nil {
+		a.tex.SetFromRGBA(a.Image)
+	}
+	return g
+}
+
+// Texture returns the texture with the atlas image, creating it on the first call.
+// The same texture is returned on each call so all its users share it,
+// and it is updated when glyphs are added to the atlas.
+func (a *GlyphAtlas) Texture() *texture.Texture2D {
+
+	if a.tex == nil {
+		a.tex = texture.NewTexture2DFromRGBA(a.Image)
+	}
+	return a.tex
+}
+
+// place returns the position of a new rectangle with the specified size in the atlas image,
+// packing the rectangles in shelves and doubling the height of the image when it is full.
+func (a *GlyphAtlas) place(width, height int) (int, int) {
+
+	bounds := a.Image.Bounds()
+	if a.x+width > bounds.Dx() {
+		a.x = 0
+		a.y += a.shelf
+		a.shelf = 0
+	}
+	for a.y+height > a.Image.Bounds().Dy() {
+		img := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), 2*a.Image.Bounds().Dy()))
+		draw.Draw(img, a.Image.Bounds(), a.Image, image.ZP, draw.Src)
+		a.Image = img
+	}
+	x, y := a.x, a.y
+	a.x += width
+	if height > a.shelf {
+		a.shelf = height
+	}
+	return x, y
+}
@@ -0,0 +1,134 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"strings"
+)
+
+// Align specifies the horizontal alignment of the lines of a text.
+type Align int
+
+// The horizontal alignments of the lines of a text.
+const (
+	AlignLeft   = Align(iota) // Lines aligned at the left
+	AlignCenter               // Lines centered
+	AlignRight                // Lines aligned at the right
+)
+
+// GlyphQuad is a glyph positioned in a text layout.
+type GlyphQuad struct {
+	Glyph *Glyph // Glyph of the character
+	// Bounds of the glyph image in pixels from the top left corner of the text, with Y from top to bottom
+	X0 float32
+	Y0 float32
+	X1 float32
+	Y1 float32
+}
+
+// Layout positions the glyphs of the specified text, which can contain line breaks (\n),
+// aligning its lines and wrapping them at spaces if they are wider than the specified
+// width in pixels, when it is greater than zero. The base lines are separated by the line
+// height of the atlas times the specified line spacing, usually 1. It returns the quads of
+// the visible glyphs and the width and height of the text in pixels.
+func (a *GlyphAtlas) Layout(text string, maxWidth float32, align Align, lineSpacing float32) ([]GlyphQuad, float32, float32) {
+
+	// Breaks the text into lines
+	var lines [][]rune
+	for _, paragraph := range strings.Split(text, "\n") {
+		lines = append(lines, a.wrap([]rune(paragraph), maxWidth)...)
+	}
+
+	// Measures the lines without their trailing spaces
+	widths := make([]float32, len(lines))
+	var width float32
+	for i, line := range lines {
+		for len(line) > 0 && line[len(line)-1] == ' ' {
+			line = line[:len(line)-1]
+		}
+		lines[i] = line
+		widths[i] = a.measure(line)
+		if widths[i] > width {
+			width = widths[i]
+		}
+	}
+
+	// Positions the glyphs of each line on its base line
+	lineHeight := a.lineHeight * lineSpacing
+	var quads []GlyphQuad
+	for i, line := range lines {
+		var x float32
+		switch align {
+		case AlignCenter:
+			x = (width - widths[i]) / 2
+		case AlignRight:
+			x = width - widths[i]
+		}
+		y := a.ascent + float32(i)*lineHeight
+		for j, r := range line {
+			if j > 0 {
+				x += a.Kern(line[j-1], r)
+			}
+			g := a.Glyph(r)
+			if g.Width > 0 {
+				quads = append(quads, GlyphQuad{g, x + g.X0, y + g.Y0, x + g.X1, y + g.Y1})
+			}
+			x += g.Advance
+		}
+	}
+	height := float32(len(lines)-1)*lineHeight + a.ascent + a.descent
+	return quads, width, height
+}
+
+// wrap breaks the specified line at spaces into lines not wider than the specified width,
+// if greater than zero. Words wider than the width are broken between characters.
+func (a *GlyphAtlas) wrap(line []rune, maxWidth float32) [][]rune {
+
+	var lines [][]rune
+	for {
+		var width float32
+		space := -1
+		end := 0
+		for ; end < len(line); end++ {
+			r := line[end]
+			if r == ' ' {
+				space = end
+				width += a.Glyph(r).Advance
+				continue
+			}
+			advance := a.Glyph(r).Advance
+			if end > 0 {
+				advance += a.Kern(line[end-1], r)
+			}
+			if maxWidth > 0 && end > 0 && width+advance > maxWidth {
+				break
+			}
+			width += advance
+		}
+		if end == len(line) {
+			return append(lines, line)
+		}
+		if space > 0 {
+			lines = append(lines, line[:space])
+			line = line[space+1:]
+		} else {
+			lines = append(lines, line[:end])
+			line = line[end:]
+		}
+	}
+}
+
+// measure returns the width in pixels of the specified line.
+func (a *GlyphAtlas) measure(line []rune) float32 {
+
+	var width float32
+	for i, r := range line {
+		if i > 0 {
+			width += a.Kern(line[i-1], r)
+		}
+		width += a.Glyph(r).Advance
+	}
+	return width
+}
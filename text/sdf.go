@@ -0,0 +1,408 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"github.com/g3n/engine/math32"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// NewSDFAtlas creates and returns a pointer to a new empty glyph atlas of signed distance fields
+// of the glyphs of the specified font, generated from their outlines at the specified size in
+// pixels per em and saturating at the specified distance in pixels, which is also the padding
+// around the glyphs. Texts using the atlas stay sharp when drawn at any size.
+func NewSDFAtlas(f *Font, size float64, spread int) *GlyphAtlas {
+
+	return newDistanceAtlas(AtlasSDF, f, size, spread)
+}
+
+// NewMSDFAtlas creates and returns a pointer to a new empty glyph atlas of multi-channel signed
+// distance fields of the glyphs of the specified font, which keep the corners of the glyphs sharp.
+// The alpha channel contains the true signed distance used for outlines and glows.
+// See NewSDFAtlas for the parameters.
+func NewMSDFAtlas(f *Font, size float64, spread int) *GlyphAtlas {
+
+	return newDistanceAtlas(AtlasMSDF, f, size, spread)
+}
+
+// newDistanceAtlas creates and returns a pointer to a new empty distance field atlas.
+func newDistanceAtlas(kind AtlasKind, f *Font, size float64, spread int) *GlyphAtlas {
+
+	a := newGlyphAtlas(kind, truetype.NewFace(f.ttf, &truetype.Options{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingNone,
+	}), spread)
+	a.ttf = f.ttf
+	a.emSize = float32(size)
+	a.scale = fixed.Int26_6(size * 64)
+	return a
+}
+
+// Edge colors of multi-channel distance fields as masks of the RGB channels.
+const (
+	edgeCyan    = 6
+	edgeMagenta = 5
+	edgeYellow  = 3
+	edgeWhite   = 7
+)
+
+// sdfCornerSine is the sine of the smallest angle between the directions
+// of two segments of an outline which is considered a corner.
+const sdfCornerSine = 0.14
+
+// sdfSegment is a segment of a glyph outline, a line or a quadratic curve,
+// in pixels with Y from top to bottom.
+type sdfSegment struct {
+	p0, c, p1 math32.Vector2 // End and control points
+	quad      bool           // Whether it is a quadratic curve
+	color     int            // Channels of its edge
+}
+
+// sdfLine is a straight piece of a flattened glyph outline.
+type sdfLine struct {
+	a, b        math32.Vector2 // End points
+	dir         math32.Vector2 // Unit direction
+	length      float32        // Length
+	color       int            // Channels of its edge
+	first, last bool           // Whether it starts or ends its edge at a corner
+}
+
+// distanceGlyph generates the distance field of the glyph of the specified character.
+func (a *GlyphAtlas) distanceGlyph(g *Glyph, r rune) {
+
+	index := a.ttf.Index(r)
+	if a.buf.Load(a.ttf, a.scale, index, font.HintingNone) != nil {
+		return
+	}
+	g.Advance = float32(a.buf.AdvanceWidth) / 64
+	if len(a.buf.Ends) == 0 {
+		return
+	}
+
+	// Bounds in pixels with Y down, including the padding
+	bounds := a.buf.Bounds
+	x0 := bounds.Min.X.Floor() - a.padding
+	x1 := bounds.Max.X.Ceil() + a.padding
+	y0 := -bounds.Max.Y.Ceil() - a.padding
+	y1 := -bounds.Min.Y.Floor() + a.padding
+	g.Width = x1 - x0
+	g.Height = y1 - y0
+	g.X0, g.Y0, g.X1, g.Y1 = float32(x0), float32(y0), float32(x1), float32(y1)
+	g.X, g.Y = a.place(g.Width, g.Height)
+
+	// Flattens the colored contours
+	var lines []sdfLine
+	start := 0
+	for _, end := range a.buf.Ends {
+		segments := sdfContour(a.buf.Points[start:end])
+		if a.kind == AtlasMSDF {
+			sdfColorEdges(segments)
+		}
+		lines = sdfFlatten(segments, lines)
+		start = end
+	}
+
+	// Computes the distances at the centers of the pixels
+	spread := float32(a.padding)
+	if spread <= 0 {
+		spread = 1
+	}
+	encode := func(d float32) uint8 {
+		return uint8(math32.Clamp(0.5+d/(2*spread), 0, 1)*255 + 0.5)
+	}
+	for j := 0; j < g.Height; j++ {
+		for i := 0; i < g.Width; i++ {
+			p := math32.Vector2{float32(x0+i) + 0.5, float32(y0+j) + 0.5}
+			d, channels := sdfDistances(lines, p, a.kind == AtlasMSDF)
+			offset := a.Image.PixOffset(g.X+i, g.Y+j)
+			pix := a.Image.Pix[offset : offset+4]
+			pix[3] = encode(d)
+			if a.kind == AtlasMSDF {
+				// Where the median has the wrong sign the true distance is used
+				m := sdfMedian(channels[0], channels[1], channels[2])
+				if (m > 0) != (d > 0) {
+					channels = [3]float32{d, d, d}
+				}
+				pix[0], pix[1], pix[2] = encode(channels[0]), encode(channels[1]), encode(channels[2])
+			}
+		}
+	}
+	if a.tex != nil {
+		a.tex.SetFromRGBA(a.Image)
+	}
+}
+
+// sdfContour returns the segments of the specified contour of a glyph.
+// Two consecutive points off the curve imply a point on the curve in the middle.
+func sdfContour(points []truetype.Point) []sdfSegment {
+
+	if len(points) == 0 {
+		return nil
+	}
+	pos := func(p truetype.Point) math32.Vector2 {
+		return math32.Vector2{float32(p.X) / 64, -float32(p.Y) / 64}
+	}
+	on := func(p truetype.Point) bool { return p.Flags&0x01 != 0 }
+
+	// Starts at a point on the curve
+	first := pos(points[0])
+	others := points[1:]
+	if !on(points[0]) {
+		last := points[len(points)-1]
+		if on(last) {
+			first = pos(last)
+			others = points[:len(points)-1]
+		} else {
+			end := pos(last)
+			first.Lerp(&end, 0.5)
+			others = points
+		}
+	}
+
+	var segments []sdfSegment
+	add := func(p0, c, p1 math32.Vector2, quad bool) {
+		if p0 != p1 {
+			segments = append(segments, sdfSegment{p0, c, p1, quad, edgeWhite})
+		}
+	}
+	current, control, pending := first, math32.Vector2{}, false
+	for _, point := range others {
+		p := pos(point)
+		if on(point) {
+			add(current, control, p, pending)
+			current, pending = p, false
+			continue
+		}
+		if pending {
+			mid := control
+			mid.Lerp(&p, 0.5)
+			add(current, control, mid, true)
+			current = mid
+		}
+		control, pending = p, true
+	}
+	add(current, control, first, pending)
+	return segments
+}
+
+// tangents returns the unit directions of the segment at its start and end points.
+func (s *sdfSegment) tangents() (math32.Vector2, math32.Vector2) {
+
+	var t0, t1 math32.Vector2
+	if s.quad && s.c != s.p0 && s.c != s.p1 {
+		t0.SubVectors(&s.c, &s.p0)
+		t1.SubVectors(&s.p1, &s.c)
+	} else {
+		t0.SubVectors(&s.p1, &s.p0)
+		t1 = t0
+	}
+	return *t0.Normalize(), *t1.Normalize()
+}
+
+// split returns the two halves of the segment.
+func (s *sdfSegment) split() (sdfSegment, sdfSegment) {
+
+	if !s.quad {
+		mid := s.p0
+		mid.Lerp(&s.p1, 0.5)
+		return sdfSegment{s.p0, s.p0, mid, false, s.color}, sdfSegment{mid, mid, s.p1, false, s.color}
+	}
+	c0, c1 := s.p0, s.c
+	c0.Lerp(&s.c, 0.5)
+	c1.Lerp(&s.p1, 0.5)
+	mid := c0
+	mid.Lerp(&c1, 0.5)
+	return sdfSegment{s.p0, c0, mid, true, s.color}, sdfSegment{mid, c1, s.p1, true, s.color}
+}
+
+// sdfCorner returns whether the outline has a corner between the specified directions.
+func sdfCorner(d0, d1 math32.Vector2) bool {
+
+	return d0.Dot(&d1) <= 0 || math32.Abs(d0.X*d1.Y-d0.Y*d1.X) > sdfCornerSine
+}
+
+// sdfColorEdges colors the edges between the corners of a contour so that the edges meeting
+// at each corner have different colors sharing one channel. Smooth contours are white.
+func sdfColorEdges(segments []sdfSegment) {
+
+	n := len(segments)
+	var corners []int
+	for i := range segments {
+		_, prev := segments[(i+n-1)%n].tangents()
+		next, _ := segments[i].tangents()
+		if sdfCorner(prev, next) {
+			corners = append(corners, i)
+		}
+	}
+	switch len(corners) {
+	case 0:
+		for i := range segments {
+			segments[i].color = edgeWhite
+		}
+	case 1:
+		// A single corner splits the contour in three edges starting at the corner
+		colors := [3]int{edgeMagenta, edgeWhite, edgeYellow}
+		for i := range segments {
+			k := (i - corners[0] + n) % n
+			segments[i].color = colors[3*k/n]
+		}
+	default:
+		// Cycles the colors of the edges, changing the last one if it matches the first one
+		cycle := [3]int{edgeCyan, edgeMagenta, edgeYellow}
+		edges := len(corners)
+		for e := 0; e < edges; e++ {
+			color := cycle[e%3]
+			if e == edges-1 && e%3 == 0 {
+				color = cycle[1]
+			}
+			for i := corners[e]; i != corners[(e+1)%edges]; i = (i + 1) % n {
+				segments[i].color = color
+			}
+		}
+	}
+}
+
+// sdfFlatten appends the straight lines approximating the specified contour segments
+// to the specified lines and returns the resulting slice.
+func sdfFlatten(segments []sdfSegment, lines []sdfLine) []sdfLine {
+
+	// A single corner needs at least three segments to color three edges
+	for len(segments) > 0 && len(segments) < 3 {
+		var split []sdfSegment
+		for _, s := range segments {
+			s0, s1 := s.split()
+			split = append(split, s0, s1)
+		}
+		segments = split
+		sdfColorEdges(segments)
+	}
+
+	start := len(lines)
+	for _, s := range segments {
+		pieces := 1
+		if s.quad {
+			length := s.p0.DistanceTo(&s.c) + s.c.DistanceTo(&s.p1)
+			pieces = int(math32.Clamp(math32.Ceil(length/1.5), 1, 32))
+		}
+		prev := s.p0
+		for k := 1; k <= pieces; k++ {
+			t := float32(k) / float32(pieces)
+			next := s.p1
+			if s.quad && k < pieces {
+				u := 1 - t
+				next.X = u*u*s.p0.X + 2*u*t*s.c.X + t*t*s.p1.X
+				next.Y = u*u*s.p0.Y + 2*u*t*s.c.Y + t*t*s.p1.Y
+			}
+			var dir math32.Vector2
+			dir.SubVectors(&next, &prev)
+			length := dir.Length()
+			if length > 0 {
+				dir.DivideScalar(length)
+				lines = append(lines, sdfLine{a: prev, b: next, dir: dir, length: length, color: s.color})
+			}
+			prev = next
+		}
+	}
+
+	// Marks the lines at the ends of the edges, where the colors change
+	contour := lines[start:]
+	for i := range contour {
+		next := &contour[(i+1)%len(contour)]
+		if next.color != contour[i].color {
+			contour[i].last = true
+			next.first = true
+		}
+	}
+	return lines
+}
+
+// sdfDistances returns the true signed distance from the specified point to the outline
+// of the specified lines, positive inside, and optionally the signed pseudo distances
+// to the closest edges of each channel.
+func sdfDistances(lines []sdfLine, p math32.Vector2, channels bool) (float32, [3]float32) {
+
+	best := math32.Inf(1)
+	var closest [3]int
+	var closestDist, closestOrtho [3]float32
+	for c := range closestDist {
+		closestDist[c] = math32.Inf(1)
+		closest[c] = -1
+	}
+	winding := 0
+	for i := range lines {
+		l := &lines[i]
+		dx, dy := p.X-l.a.X, p.Y-l.a.Y
+
+		// Nonzero winding rule for the sign
+		cross := l.dir.X*dy - l.dir.Y*dx
+		if l.a.Y <= p.Y && l.b.Y > p.Y && cross > 0 {
+			winding++
+		} else if l.b.Y <= p.Y && l.a.Y > p.Y && cross < 0 {
+			winding--
+		}
+
+		// Distance to the closest point of the line
+		t := math32.Clamp(dx*l.dir.X+dy*l.dir.Y, 0, l.length)
+		ex, ey := dx-l.dir.X*t, dy-l.dir.Y*t
+		d := math32.Sqrt(ex*ex + ey*ey)
+		if d < best {
+			best = d
+		}
+		if !channels {
+			continue
+		}
+
+		// Closest line of each channel, preferring the most orthogonal between equally close lines
+		ortho := float32(0)
+		if d > 0 {
+			ortho = math32.Abs(l.dir.X*ey-l.dir.Y*ex) / d
+		}
+		for c := 0; c < 3; c++ {
+			if l.color&(1<<uint(c)) == 0 {
+				continue
+			}
+			if d < closestDist[c]-1e-4 || (d < closestDist[c]+1e-4 && ortho > closestOrtho[c]) {
+				closest[c], closestDist[c], closestOrtho[c] = i, d, ortho
+			}
+		}
+	}
+	if winding == 0 {
+		best = -best
+	}
+
+	// Signed pseudo distances, extending the lines at the ends of the edges
+	var pseudo [3]float32
+	if channels {
+		for c := 0; c < 3; c++ {
+			if closest[c] < 0 {
+				pseudo[c] = best
+				continue
+			}
+			l := &lines[closest[c]]
+			dx, dy := p.X-l.a.X, p.Y-l.a.Y
+			along := dx*l.dir.X + dy*l.dir.Y
+			side := l.dir.X*dy - l.dir.Y*dx
+			d := closestDist[c]
+			if (along < 0 && l.first) || (along > l.length && l.last) {
+				d = math32.Min(d, math32.Abs(side))
+			}
+			// Glyph outlines have the inside on their right in pixels with Y down
+			if side < 0 {
+				d = -d
+			}
+			pseudo[c] = d
+		}
+	}
+	return best, pseudo
+}
+
+// sdfMedian returns the median of the specified values.
+func sdfMedian(a, b, c float32) float32 {
+
+	return math32.Max(math32.Min(a, b), math32.Min(math32.Max(a, b), c))
+}
@@ -0,0 +1,179 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// AtlasRegion is a named rectangle of an atlas image.
+type AtlasRegion struct {
+	Name   string // Region name
+	X      int    // Position X in pixels in the atlas image from left to right
+	Y      int    // Position Y in pixels in the atlas image from top to bottom
+	Width  int    // Region width in pixels
+	Height int    // Region height in pixels
+	// Normalized position of the region in the image, with V from top to bottom
+	U0 float32
+	V0 float32
+	U1 float32
+	V1 float32
+}
+
+// Atlas is an image combining several images (regions) which can share a single texture.
+type Atlas struct {
+	Image   *image.RGBA             // Atlas image
+	regions map[string]*AtlasRegion // Regions by name
+	names   []string                // Region names in insertion order
+	tex     *Texture2D              // Atlas texture created on demand
+}
+
+// NewAtlas creates and returns a pointer to a new empty atlas using the specified image.
+func NewAtlas(img *image.RGBA) *Atlas {
+
+	a := new(Atlas)
+	a.Image = img
+	a.regions = make(map[string]*AtlasRegion)
+	return a
+}
+
+// AddRegion adds a region with the specified name and rectangle in pixels
+// and returns a pointer to it. A region with the same name is replaced.
+func (a *Atlas) AddRegion(name string, x, y, width, height int) *AtlasRegion {
+
+	w := float32(a.Image.Bounds().Dx())
+	h := float32(a.Image.Bounds().Dy())
+	r := &AtlasRegion{
+		Name:   name,
+		X:      x,
+		Y:      y,
+		Width:  width,
+		Height: height,
+		U0:     float32(x) / w,
+		V0:     float32(y) / h,
+		U1:     float32(x+width) / w,
+		V1:     float32(y+height) / h,
+	}
+	if _, ok := a.regions[name]; !ok {
+		a.names = append(a.names, name)
+	}
+	a.regions[name] = r
+	return r
+}
+
+// Region returns a pointer to the region with the specified name or nil if not found.
+func (a *Atlas) Region(name string) *AtlasRegion {
+
+	return a.regions[name]
+}
+
+// Regions returns the regions of this atlas in the order they were added.
+func (a *Atlas) Regions() []*AtlasRegion {
+
+	regions := make([]*AtlasRegion, 0, len(a.names))
+	for _, name := range a.names {
+		regions = append(regions, a.regions[name])
+	}
+	return regions
+}
+
+// Texture returns the texture with the atlas image, creating it on the first call.
+// The same texture is returned on each call so all its users share it.
+func (a *Atlas) Texture() *Texture2D {
+
+	if a.tex == nil {
+		a.tex = NewTexture2DFromRGBA(a.Image)
+	}
+	return a.tex
+}
+
+// texturePackerRect is a rectangle in a TexturePacker JSON file.
+type texturePackerRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// texturePackerFrame describes a frame in a TexturePacker JSON file.
+type texturePackerFrame struct {
+	Filename string            `json:"filename"`
+	Frame    texturePackerRect `json:"frame"`
+	Rotated  bool              `json:"rotated"`
+}
+
+// texturePackerFile is the content of a TexturePacker JSON file.
+type texturePackerFile struct {
+	Frames json.RawMessage `json:"frames"`
+	Meta   struct {
+		Image string `json:"image"`
+	} `json:"meta"`
+}
+
+// LoadAtlas loads an atlas from a TexturePacker JSON file, in either the "hash" or
+// the "array" format, and the image it references, which is relative to the JSON file.
+// Trimmed frames are loaded with their packed size. Rotated frames are not supported.
+func LoadAtlas(jsonfile string) (*Atlas, error) {
+
+	data, err := ioutil.ReadFile(jsonfile)
+	if err != nil {
+		return nil, err
+	}
+	var tp texturePackerFile
+	err = json.Unmarshal(data, &tp)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decodes frames from an array or from an object keyed by file name
+	var frames []texturePackerFrame
+	if err = json.Unmarshal(tp.Frames, &frames); err != nil {
+		hash := make(map[string]texturePackerFrame)
+		if err = json.Unmarshal(tp.Frames, &hash); err != nil {
+			return nil, fmt.Errorf("invalid atlas frames: %v", err)
+		}
+		for name, f := range hash {
+			f.Filename = name
+			frames = append(frames, f)
+		}
+		sort.Slice(frames, func(i, j int) bool { return frames[i].Filename < frames[j].Filename })
+	}
+
+	// Loads the atlas image
+	if tp.Meta.Image == "" {
+		return nil, fmt.Errorf("atlas has no image")
+	}
+	imgfile := tp.Meta.Image
+	if !filepath.IsAbs(imgfile) {
+		imgfile = filepath.Join(filepath.Dir(jsonfile), imgfile)
+	}
+	file, err := os.Open(imgfile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	a := NewAtlas(rgba)
+	for _, f := range frames {
+		if f.Rotated {
+			return nil, fmt.Errorf("atlas frame %q is rotated which is not supported", f.Filename)
+		}
+		a.AddRegion(f.Filename, f.Frame.X, f.Frame.Y, f.Frame.W, f.Frame.H)
+	}
+	return a, nil
+}
@@ -0,0 +1,261 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"os"
+	"sort"
+)
+
+// AtlasBuilder packs several images into a single atlas image
+// using the MaxRects algorithm with the best short side fit heuristic.
+type AtlasBuilder struct {
+	maxWidth  int          // maximum atlas width in pixels
+	maxHeight int          // maximum atlas height in pixels
+	padding   int          // transparent pixels between images
+	extrude   int          // number of times the border pixels of each image are repeated
+	images    []atlasImage // images to pack
+}
+
+// atlasImage is a named image to be packed.
+type atlasImage struct {
+	name string
+	img  image.Image
+}
+
+// NewAtlasBuilder creates and returns a pointer to a new atlas builder which
+// generates atlas images with power of two sizes up to the specified maximum size.
+func NewAtlasBuilder(maxWidth, maxHeight int) *AtlasBuilder {
+
+	b := new(AtlasBuilder)
+	b.maxWidth = maxWidth
+	b.maxHeight = maxHeight
+	b.padding = 1
+	return b
+}
+
+// SetPadding sets the number of transparent pixels between the packed images.
+// The default value is 1.
+func (b *AtlasBuilder) SetPadding(pixels int) {
+
+	b.padding = pixels
+}
+
+// SetExtrude sets the number of times the border pixels of each packed image are
+// repeated around it, which avoids bleeding of adjacent images when filtering.
+// The default value is 0.
+func (b *AtlasBuilder) SetExtrude(pixels int) {
+
+	b.extrude = pixels
+}
+
+// Add adds an image with the specified region name to be packed.
+func (b *AtlasBuilder) Add(name string, img image.Image) {
+
+	b.images = append(b.images, atlasImage{name, img})
+}
+
+// AddFile adds the image from the specified file with the specified region name to be packed.
+func (b *AtlasBuilder) AddFile(name, imgfile string) error {
+
+	file, err := os.Open(imgfile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return err
+	}
+	b.Add(name, img)
+	return nil
+}
+
+// Build packs all the added images and returns the resulting atlas.
+// The atlas has the smallest power of two size in which the images fit.
+// Returns an error if the images don't fit in the maximum size.
+func (b *AtlasBuilder) Build() (*Atlas, error) {
+
+	// Sorts the images by decreasing longest side which packs better
+	order := make([]int, len(b.images))
+	area := 0
+	border := 2*b.extrude + b.padding
+	for i, ai := range b.images {
+		order[i] = i
+		size := ai.img.Bounds().Size()
+		area += (size.X + border) * (size.Y + border)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		si := b.images[order[i]].img.Bounds().Size()
+		sj := b.images[order[j]].img.Bounds().Size()
+		return maxInt(si.X, si.Y) > maxInt(sj.X, sj.Y)
+	})
+
+	// Tries increasing power of two sizes starting from one which may hold the total area
+	width, height := 1, 1
+	for width*height < area {
+		if width <= height {
+			width *= 2
+		} else {
+			height *= 2
+		}
+	}
+	for {
+		w, h := minInt(width, b.maxWidth), minInt(height, b.maxHeight)
+		if places, ok := b.pack(order, w, h); ok {
+			return b.compose(places, w, h), nil
+		}
+		if w == b.maxWidth && h == b.maxHeight {
+			return nil, fmt.Errorf("images don't fit in atlas of %dx%d", b.maxWidth, b.maxHeight)
+		}
+		if (width <= height || height >= b.maxHeight) && width < b.maxWidth {
+			width *= 2
+		} else {
+			height *= 2
+		}
+	}
+}
+
+// pack tries to pack the images in the specified order in an area with the specified size
+// and returns the position of each image cell by image index.
+func (b *AtlasBuilder) pack(order []int, width, height int) ([]image.Point, bool) {
+
+	// The padding is only needed between cells so the area is extended to fit the last ones
+	border := 2*b.extrude + b.padding
+	packer := newMaxRects(width+b.padding, height+b.padding)
+	places := make([]image.Point, len(b.images))
+	for _, i := range order {
+		size := b.images[i].img.Bounds().Size()
+		p, ok := packer.insert(size.X+border, size.Y+border)
+		if !ok {
+			return nil, false
+		}
+		places[i] = p
+	}
+	return places, true
+}
+
+// compose draws the images in their packed positions and returns the atlas.
+func (b *AtlasBuilder) compose(places []image.Point, width, height int) *Atlas {
+
+	rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+	a := NewAtlas(rgba)
+	e := b.extrude
+	for i, ai := range b.images {
+		bounds := ai.img.Bounds()
+		size := bounds.Size()
+		x, y := places[i].X+e, places[i].Y+e
+		draw.Draw(rgba, image.Rect(x, y, x+size.X, y+size.Y), ai.img, bounds.Min, draw.Src)
+
+		// Repeats the border pixels of the image
+		if e > 0 {
+			for k := 1; k <= e; k++ {
+				for px := 0; px < size.X; px++ {
+					rgba.Set(x+px, y-k, rgba.At(x+px, y))
+					rgba.Set(x+px, y+size.Y-1+k, rgba.At(x+px, y+size.Y-1))
+				}
+			}
+			for py := y - e; py < y+size.Y+e; py++ {
+				for k := 1; k <= e; k++ {
+					rgba.Set(x-k, py, rgba.At(x, py))
+					rgba.Set(x+size.X-1+k, py, rgba.At(x+size.X-1, py))
+				}
+			}
+		}
+		a.AddRegion(ai.name, x, y, size.X, size.Y)
+	}
+	return a
+}
+
+// maxRects keeps the list of maximal free rectangles of a packing area.
+type maxRects struct {
+	free []image.Rectangle
+}
+
+// newMaxRects creates and returns a pointer to a new packer with the specified free area.
+func newMaxRects(width, height int) *maxRects {
+
+	return &maxRects{free: []image.Rectangle{image.Rect(0, 0, width, height)}}
+}
+
+// insert finds a place for a rectangle with the specified size, marks it as used
+// and returns its position. Returns false if the rectangle doesn't fit.
+func (m *maxRects) insert(width, height int) (image.Point, bool) {
+
+	// Chooses the free rectangle which leaves the shortest side remainder
+	best := -1
+	bestShort, bestLong := 0, 0
+	for i, f := range m.free {
+		dw, dh := f.Dx()-width, f.Dy()-height
+		if dw < 0 || dh < 0 {
+			continue
+		}
+		short, long := minInt(dw, dh), maxInt(dw, dh)
+		if best < 0 || short < bestShort || (short == bestShort && long < bestLong) {
+			best, bestShort, bestLong = i, short, long
+		}
+	}
+	if best < 0 {
+		return image.Point{}, false
+	}
+	pos := m.free[best].Min
+	used := image.Rect(pos.X, pos.Y, pos.X+width, pos.Y+height)
+
+	// Splits the free rectangles which intersect the used one
+	var free []image.Rectangle
+	for _, f := range m.free {
+		if !f.Overlaps(used) {
+			free = append(free, f)
+			continue
+		}
+		if used.Min.X > f.Min.X {
+			free = append(free, image.Rect(f.Min.X, f.Min.Y, used.Min.X, f.Max.Y))
+		}
+		if used.Max.X < f.Max.X {
+			free = append(free, image.Rect(used.Max.X, f.Min.Y, f.Max.X, f.Max.Y))
+		}
+		if used.Min.Y > f.Min.Y {
+			free = append(free, image.Rect(f.Min.X, f.Min.Y, f.Max.X, used.Min.Y))
+		}
+		if used.Max.Y < f.Max.Y {
+			free = append(free, image.Rect(f.Min.X, used.Max.Y, f.Max.X, f.Max.Y))
+		}
+	}
+
+	// Removes the free rectangles contained in others
+	m.free = m.free[:0]
+	for i, f := range free {
+		contained := false
+		for j, g := range free {
+			if i != j && f.In(g) && (f != g || j < i) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			m.free = append(m.free, f)
+		}
+	}
+	return pos, true
+}
+
+func minInt(a, b int) int {
+
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,139 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package procedural
+
+import (
+	"image"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// Field is a rectangular grid of float values, such as a height map.
+// Rows are stored from top to bottom as in images.
+type Field struct {
+	Width  int       // Number of columns
+	Height int       // Number of rows
+	Data   []float32 // Values stored row by row
+}
+
+// NewField creates and returns a pointer to a new field with all values zero.
+func NewField(width, height int) *Field {
+
+	return &Field{Width: width, Height: height, Data: make([]float32, width*height)}
+}
+
+// NewFieldFunc creates and returns a pointer to a new field with the values returned by
+// the specified function for the normalized coordinates (u, v) of each cell center,
+// where u goes from 0 at the left to 1 at the right and v from 0 at the top to 1 at the bottom.
+func NewFieldFunc(width, height int, f func(u, v float32) float32) *Field {
+
+	field := NewField(width, height)
+	for y := 0; y < height; y++ {
+		v := (float32(y) + 0.5) / float32(height)
+		for x := 0; x < width; x++ {
+			u := (float32(x) + 0.5) / float32(width)
+			field.Data[y*width+x] = f(u, v)
+		}
+	}
+	return field
+}
+
+// NewNoiseField creates and returns a pointer to a new field with fractional Brownian motion
+// of the specified 2D noise function in the range [0, 1]. The scale is the number of noise
+// cells across the field for the first octave; each further octave doubles the frequency
+// and halves the amplitude.
+func NewNoiseField(width, height int, noise func(x, y float32) float32, scale float32, octaves int) *Field {
+
+	return NewFieldFunc(width, height, func(u, v float32) float32 {
+		return 0.5 + 0.5*FBM2(noise, u*scale, v*scale, octaves, 2, 0.5)
+	})
+}
+
+// At returns the value at the specified column and row clamped to the field.
+func (f *Field) At(x, y int) float32 {
+
+	x = math32.ClampInt(x, 0, f.Width-1)
+	y = math32.ClampInt(y, 0, f.Height-1)
+	return f.Data[y*f.Width+x]
+}
+
+// Set sets the value at the specified column and row.
+func (f *Field) Set(x, y int, value float32) {
+
+	f.Data[y*f.Width+x] = value
+}
+
+// Sample returns the bilinearly interpolated value at the specified normalized
+// coordinates, using the same convention as NewFieldFunc.
+func (f *Field) Sample(u, v float32) float32 {
+
+	fx := u*float32(f.Width) - 0.5
+	fy := v*float32(f.Height) - 0.5
+	x0, y0 := math32.Floor(fx), math32.Floor(fy)
+	tx, ty := fx-x0, fy-y0
+	x, y := int(x0), int(y0)
+	top := lerp(tx, f.At(x, y), f.At(x+1, y))
+	bottom := lerp(tx, f.At(x, y+1), f.At(x+1, y+1))
+	return lerp(ty, top, bottom)
+}
+
+// Range returns the minimum and maximum values of the field.
+func (f *Field) Range() (min, max float32) {
+
+	if len(f.Data) == 0 {
+		return 0, 0
+	}
+	min, max = f.Data[0], f.Data[0]
+	for _, v := range f.Data {
+		min = math32.Min(min, v)
+		max = math32.Max(max, v)
+	}
+	return min, max
+}
+
+// Normalize linearly rescales the values of the field to the range [0, 1]
+// and returns the pointer to this field.
+func (f *Field) Normalize() *Field {
+
+	min, max := f.Range()
+	scale := float32(0)
+	if max > min {
+		scale = 1 / (max - min)
+	}
+	for i, v := range f.Data {
+		f.Data[i] = (v - min) * scale
+	}
+	return f
+}
+
+// Image returns an image with the values of the field in the range [0, 1] mapped through
+// the specified gradient, or as gray levels if the gradient is nil.
+func (f *Field) Image(g *Gradient) *image.RGBA {
+
+	img := image.NewRGBA(image.Rect(0, 0, f.Width, f.Height))
+	for y := 0; y < f.Height; y++ {
+		for x := 0; x < f.Width; x++ {
+			v := f.Data[y*f.Width+x]
+			if g != nil {
+				c := g.At(v)
+				img.SetRGBA(x, y, toRGBA(&c))
+			} else {
+				img.SetRGBA(x, y, toRGBA(&math32.Color4{v, v, v, 1}))
+			}
+		}
+	}
+	return img
+}
+
+// Texture creates and returns a pointer to a new single channel float texture with the
+// values of the field, which may be sampled in shaders from the red component.
+func (f *Field) Texture() *texture.Texture2D {
+
+	data := make([]float32, len(f.Data))
+	copy(data, f.Data)
+	return texture.NewTexture2DFromData(f.Width, f.Height, gls.RED, gls.FLOAT, gls.R32F, data)
+}
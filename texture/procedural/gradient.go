@@ -0,0 +1,116 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package procedural
+
+import (
+	"image"
+	"image/color"
+	"sort"
+
+	"github.com/g3n/engine/math32"
+)
+
+// GradientStop is a color at a position of a gradient.
+type GradientStop struct {
+	Pos   float32       // Position in the range [0, 1]
+	Color math32.Color4 // Color at the position
+}
+
+// Gradient interpolates colors between a list of stops.
+type Gradient struct {
+	stops []GradientStop // Stops sorted by position
+}
+
+// NewGradient creates and returns a pointer to a new gradient from the first color at position 0
+// to the second color at position 1.
+func NewGradient(from, to *math32.Color4) *Gradient {
+
+	g := new(Gradient)
+	g.AddStop(0, from)
+	g.AddStop(1, to)
+	return g
+}
+
+// AddStop adds a color stop at the specified position and returns the pointer to this gradient.
+func (g *Gradient) AddStop(pos float32, c *math32.Color4) *Gradient {
+
+	g.stops = append(g.stops, GradientStop{pos, *c})
+	sort.SliceStable(g.stops, func(i, j int) bool { return g.stops[i].Pos < g.stops[j].Pos })
+	return g
+}
+
+// Stops returns the color stops of this gradient sorted by position.
+func (g *Gradient) Stops() []GradientStop {
+
+	return g.stops
+}
+
+// At returns the color of the gradient at the specified position.
+// Positions before the first stop or after the last one return the color of that stop.
+func (g *Gradient) At(pos float32) math32.Color4 {
+
+	if len(g.stops) == 0 {
+		return math32.Color4{0, 0, 0, 1}
+	}
+	if pos <= g.stops[0].Pos {
+		return g.stops[0].Color
+	}
+	for i := 1; i < len(g.stops); i++ {
+		s0, s1 := &g.stops[i-1], &g.stops[i]
+		if pos > s1.Pos {
+			continue
+		}
+		t := float32(0)
+		if s1.Pos > s0.Pos {
+			t = (pos - s0.Pos) / (s1.Pos - s0.Pos)
+		}
+		return math32.Color4{
+			lerp(t, s0.Color.R, s1.Color.R),
+			lerp(t, s0.Color.G, s1.Color.G),
+			lerp(t, s0.Color.B, s1.Color.B),
+			lerp(t, s0.Color.A, s1.Color.A),
+		}
+	}
+	return g.stops[len(g.stops)-1].Color
+}
+
+// LinearGradient returns an image filled with the specified gradient along the direction
+// with the specified angle in radians, where 0 goes from left to right and Pi/2 from bottom to top.
+func LinearGradient(width, height int, g *Gradient, angle float32) *image.RGBA {
+
+	dx, dy := math32.Cos(angle), -math32.Sin(angle)
+	// Projections of the image corners on the direction to map them to [0, 1]
+	min, max := math32.Infinity, -math32.Infinity
+	for _, c := range [][2]float32{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+		p := c[0]*dx + c[1]*dy
+		min = math32.Min(min, p)
+		max = math32.Max(max, p)
+	}
+	f := NewFieldFunc(width, height, func(u, v float32) float32 {
+		return (u*dx + v*dy - min) / (max - min)
+	})
+	return f.Image(g)
+}
+
+// RadialGradient returns an image filled with the specified gradient from
+// the center of the image at position 0 to its corners at position 1.
+func RadialGradient(width, height int, g *Gradient) *image.RGBA {
+
+	f := NewFieldFunc(width, height, func(u, v float32) float32 {
+		du, dv := u-0.5, v-0.5
+		return math32.Sqrt(2 * (du*du + dv*dv))
+	})
+	return f.Image(g)
+}
+
+// toRGBA converts a color with components in the range [0, 1] to an image color.
+// The components are not premultiplied by alpha as textures are blended with straight alpha.
+func toRGBA(c *math32.Color4) color.RGBA {
+
+	conv := func(v float32) uint8 {
+		return uint8(math32.Clamp(v, 0, 1)*255 + 0.5)
+	}
+	return color.RGBA{conv(c.R), conv(c.G), conv(c.B), conv(c.A)}
+}
@@ -0,0 +1,271 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package procedural generates images and float data for textures,
+// such as noise, gradients, patterns and normal maps.
+// All the generators are deterministic for a given seed.
+package procedural
+
+import (
+	"math/rand"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Noise generates coherent gradient and cellular noise.
+// The values only depend on the seed used to create the generator.
+type Noise struct {
+	seed int64
+	perm [512]uint8 // Permutation table repeated twice to avoid index wrapping
+}
+
+// NewNoise creates and returns a pointer to a new noise generator with the specified seed.
+func NewNoise(seed int64) *Noise {
+
+	n := new(Noise)
+	n.seed = seed
+	rnd := rand.New(rand.NewSource(seed))
+	p := rnd.Perm(256)
+	for i := 0; i < 256; i++ {
+		n.perm[i] = uint8(p[i])
+		n.perm[i+256] = uint8(p[i])
+	}
+	return n
+}
+
+// Seed returns the seed of this noise generator.
+func (n *Noise) Seed() int64 {
+
+	return n.seed
+}
+
+// Perlin2 returns the 2D Perlin noise value at the specified coordinates in the range [-1, 1].
+func (n *Noise) Perlin2(x, y float32) float32 {
+
+	return n.Perlin3(x, y, 0)
+}
+
+// Perlin3 returns the 3D Perlin noise value at the specified coordinates in the range [-1, 1].
+func (n *Noise) Perlin3(x, y, z float32) float32 {
+
+	fx, fy, fz := math32.Floor(x), math32.Floor(y), math32.Floor(z)
+	xi, yi, zi := int(fx)&255, int(fy)&255, int(fz)&255
+	x, y, z = x-fx, y-fy, z-fz
+	u, v, w := fade(x), fade(y), fade(z)
+
+	p := &n.perm
+	a := int(p[xi]) + yi
+	aa := int(p[a]) + zi
+	ab := int(p[a+1]) + zi
+	b := int(p[xi+1]) + yi
+	ba := int(p[b]) + zi
+	bb := int(p[b+1]) + zi
+
+	return lerp(w,
+		lerp(v,
+			lerp(u, grad3(p[aa], x, y, z), grad3(p[ba], x-1, y, z)),
+			lerp(u, grad3(p[ab], x, y-1, z), grad3(p[bb], x-1, y-1, z))),
+		lerp(v,
+			lerp(u, grad3(p[aa+1], x, y, z-1), grad3(p[ba+1], x-1, y, z-1)),
+			lerp(u, grad3(p[ab+1], x, y-1, z-1), grad3(p[bb+1], x-1, y-1, z-1))))
+}
+
+// Skew and unskew factors of the simplex grids
+const (
+	f2 = 0.36602540378 // (sqrt(3)-1)/2
+	g2 = 0.21132486540 // (3-sqrt(3))/6
+	f3 = 1.0 / 3.0
+	g3 = 1.0 / 6.0
+)
+
+// Simplex2 returns the 2D simplex noise value at the specified coordinates in the range [-1, 1].
+func (n *Noise) Simplex2(x, y float32) float32 {
+
+	// Finds the simplex cell and the coordinates relative to its origin
+	s := (x + y) * f2
+	i, j := math32.Floor(x+s), math32.Floor(y+s)
+	t := (i + j) * g2
+	x0, y0 := x-(i-t), y-(j-t)
+	var i1, j1 int
+	if x0 > y0 {
+		i1 = 1
+	} else {
+		j1 = 1
+	}
+	x1, y1 := x0-float32(i1)+g2, y0-float32(j1)+g2
+	x2, y2 := x0-1+2*g2, y0-1+2*g2
+
+	ii, jj := int(i)&255, int(j)&255
+	p := &n.perm
+	corner := func(h uint8, x, y float32) float32 {
+		t := 0.5 - x*x - y*y
+		if t < 0 {
+			return 0
+		}
+		t *= t
+		return t * t * grad3(h, x, y, 0)
+	}
+	n0 := corner(p[ii+int(p[jj])], x0, y0)
+	n1 := corner(p[ii+i1+int(p[jj+j1])], x1, y1)
+	n2 := corner(p[ii+1+int(p[jj+1])], x2, y2)
+	return math32.Clamp(70*(n0+n1+n2), -1, 1)
+}
+
+// Simplex3 returns the 3D simplex noise value at the specified coordinates in the range [-1, 1].
+func (n *Noise) Simplex3(x, y, z float32) float32 {
+
+	// Finds the simplex cell and the coordinates relative to its origin
+	s := (x + y + z) * f3
+	i, j, k := math32.Floor(x+s), math32.Floor(y+s), math32.Floor(z+s)
+	t := (i + j + k) * g3
+	x0, y0, z0 := x-(i-t), y-(j-t), z-(k-t)
+
+	// Determines which simplex of the cell contains the point
+	var i1, j1, k1, i2, j2, k2 int
+	if x0 >= y0 {
+		if y0 >= z0 {
+			i1, i2, j2 = 1, 1, 1
+		} else if x0 >= z0 {
+			i1, i2, k2 = 1, 1, 1
+		} else {
+			k1, i2, k2 = 1, 1, 1
+		}
+	} else {
+		if y0 < z0 {
+			k1, j2, k2 = 1, 1, 1
+		} else if x0 < z0 {
+			j1, j2, k2 = 1, 1, 1
+		} else {
+			j1, i2, j2 = 1, 1, 1
+		}
+	}
+	x1, y1, z1 := x0-float32(i1)+g3, y0-float32(j1)+g3, z0-float32(k1)+g3
+	x2, y2, z2 := x0-float32(i2)+2*g3, y0-float32(j2)+2*g3, z0-float32(k2)+2*g3
+	x3, y3, z3 := x0-1+3*g3, y0-1+3*g3, z0-1+3*g3
+
+	ii, jj, kk := int(i)&255, int(j)&255, int(k)&255
+	p := &n.perm
+	corner := func(h uint8, x, y, z float32) float32 {
+		t := 0.6 - x*x - y*y - z*z
+		if t < 0 {
+			return 0
+		}
+		t *= t
+		return t * t * grad3(h, x, y, z)
+	}
+	n0 := corner(p[ii+int(p[jj+int(p[kk])])], x0, y0, z0)
+	n1 := corner(p[ii+i1+int(p[jj+j1+int(p[kk+k1])])], x1, y1, z1)
+	n2 := corner(p[ii+i2+int(p[jj+j2+int(p[kk+k2])])], x2, y2, z2)
+	n3 := corner(p[ii+1+int(p[jj+1+int(p[kk+1])])], x3, y3, z3)
+	return math32.Clamp(32*(n0+n1+n2+n3), -1, 1)
+}
+
+// Worley2 returns the distances from the specified coordinates to the nearest (f1) and
+// second nearest (f2) feature points of a cellular (Worley) noise with one random
+// feature point per unit cell.
+func (n *Noise) Worley2(x, y float32) (f1, f2 float32) {
+
+	cx, cy := int(math32.Floor(x)), int(math32.Floor(y))
+	f1, f2 = math32.Infinity, math32.Infinity
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			px, py := n.featurePoint(cx+dx, cy+dy)
+			ddx, ddy := px-x, py-y
+			d := math32.Sqrt(ddx*ddx + ddy*ddy)
+			if d < f1 {
+				f1, f2 = d, f1
+			} else if d < f2 {
+				f2 = d
+			}
+		}
+	}
+	return f1, f2
+}
+
+// featurePoint returns the position of the feature point of the specified cell.
+func (n *Noise) featurePoint(cx, cy int) (float32, float32) {
+
+	p := &n.perm
+	h := p[int(p[cx&255])+cy&255]
+	h2 := p[int(p[(cy+101)&255])+cx&255]
+	return float32(cx) + float32(h)/256 + 0.5/256, float32(cy) + float32(h2)/256 + 0.5/256
+}
+
+// FBM2 returns the fractional Brownian motion of the specified 2D noise function at the
+// specified coordinates: the sum of the specified number of octaves, each with its frequency
+// multiplied by lacunarity and its amplitude multiplied by gain relative to the previous one.
+// The result is normalized to the range of the noise function.
+func FBM2(noise func(x, y float32) float32, x, y float32, octaves int, lacunarity, gain float32) float32 {
+
+	var sum, norm float32
+	amp := float32(1)
+	for i := 0; i < octaves; i++ {
+		sum += amp * noise(x, y)
+		norm += amp
+		x *= lacunarity
+		y *= lacunarity
+		amp *= gain
+	}
+	if norm == 0 {
+		return 0
+	}
+	return sum / norm
+}
+
+// FBM3 returns the fractional Brownian motion of the specified 3D noise function.
+// See FBM2.
+func FBM3(noise func(x, y, z float32) float32, x, y, z float32, octaves int, lacunarity, gain float32) float32 {
+
+	var sum, norm float32
+	amp := float32(1)
+	for i := 0; i < octaves; i++ {
+		sum += amp * noise(x, y, z)
+		norm += amp
+		x *= lacunarity
+		y *= lacunarity
+		z *= lacunarity
+		amp *= gain
+	}
+	if norm == 0 {
+		return 0
+	}
+	return sum / norm
+}
+
+// fade is the quintic interpolation curve of Perlin's improved noise.
+func fade(t float32) float32 {
+
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+// lerp linearly interpolates between a and b.
+func lerp(t, a, b float32) float32 {
+
+	return a + t*(b-a)
+}
+
+// grad3 returns the dot product of the gradient selected by the hash with the specified vector.
+func grad3(hash uint8, x, y, z float32) float32 {
+
+	h := hash & 15
+	u := y
+	if h < 8 {
+		u = x
+	}
+	var v float32
+	if h < 4 {
+		v = y
+	} else if h == 12 || h == 14 {
+		v = x
+	} else {
+		v = z
+	}
+	if h&1 != 0 {
+		u = -u
+	}
+	if h&2 != 0 {
+		v = -v
+	}
+	return u + v
+}
@@ -0,0 +1,128 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package procedural
+
+import (
+	"image"
+	"math/rand"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Checker returns an image with a checker pattern of the specified number of
+// cells in each direction, starting with color c1 at the top left cell.
+func Checker(width, height, cellsX, cellsY int, c1, c2 *math32.Color4) *image.RGBA {
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	rgba1, rgba2 := toRGBA(c1), toRGBA(c2)
+	for y := 0; y < height; y++ {
+		cy := y * cellsY / height
+		for x := 0; x < width; x++ {
+			cx := x * cellsX / width
+			if (cx+cy)%2 == 0 {
+				img.SetRGBA(x, y, rgba1)
+			} else {
+				img.SetRGBA(x, y, rgba2)
+			}
+		}
+	}
+	return img
+}
+
+// Grid returns an image with lines of the specified width in pixels
+// separating the specified number of cells in each direction.
+// The lines are centered on the cell borders so the image tiles seamlessly.
+func Grid(width, height, cellsX, cellsY int, lineWidth float32, background, line *math32.Color4) *image.RGBA {
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	bg, fg := toRGBA(background), toRGBA(line)
+	cellW := float32(width) / float32(cellsX)
+	cellH := float32(height) / float32(cellsY)
+	half := lineWidth / 2
+	onLine := func(p, cell float32) bool {
+		d := p - cell*math32.Floor(p/cell+0.5)
+		return math32.Abs(d) < half
+	}
+	for y := 0; y < height; y++ {
+		ly := onLine(float32(y)+0.5, cellH)
+		for x := 0; x < width; x++ {
+			if ly || onLine(float32(x)+0.5, cellW) {
+				img.SetRGBA(x, y, fg)
+			} else {
+				img.SetRGBA(x, y, bg)
+			}
+		}
+	}
+	return img
+}
+
+// Brick returns an image with the specified number of rows and columns of bricks in a
+// running bond, with alternate rows offset by half a brick. The mortar is the width of the
+// joints as a fraction of the brick height. The color of each brick is darkened by a random
+// amount up to variation, from the specified seed. The image tiles seamlessly if rows is even.
+func Brick(width, height, cols, rows int, mortar float32, brick, mortarColor *math32.Color4, variation float32, seed int64) *image.RGBA {
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	rgbaMortar := toRGBA(mortarColor)
+
+	// Generates the color of each brick
+	rnd := rand.New(rand.NewSource(seed))
+	colors := make([][]math32.Color4, rows)
+	for r := range colors {
+		colors[r] = make([]math32.Color4, cols)
+		for c := range colors[r] {
+			k := 1 - variation*rnd.Float32()
+			colors[r][c] = math32.Color4{brick.R * k, brick.G * k, brick.B * k, brick.A}
+		}
+	}
+
+	brickH := float32(height) / float32(rows)
+	brickW := float32(width) / float32(cols)
+	half := mortar * brickH / 2
+	for y := 0; y < height; y++ {
+		fy := float32(y) + 0.5
+		row := int(fy / brickH)
+		dy := fy - float32(row)*brickH
+		offset := float32(0)
+		if row%2 == 1 {
+			offset = brickW / 2
+		}
+		for x := 0; x < width; x++ {
+			fx := float32(x) + 0.5 + offset
+			col := int(fx / brickW)
+			dx := fx - float32(col)*brickW
+			if dy < half || dy > brickH-half || dx < half || dx > brickW-half {
+				img.SetRGBA(x, y, rgbaMortar)
+				continue
+			}
+			c := colors[row%rows][col%cols]
+			img.SetRGBA(x, y, toRGBA(&c))
+		}
+	}
+	return img
+}
+
+// NormalMap returns a tangent space normal map image computed from the specified height field.
+// The strength scales the slopes of the heights between adjacent cells. The borders wrap
+// around so tileable height fields generate tileable normal maps. The green component
+// points towards the top of the image as expected by the engine's shaders.
+func NormalMap(heights *Field, strength float32) *image.RGBA {
+
+	w, h := heights.Width, heights.Height
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	at := func(x, y int) float32 {
+		return heights.Data[((y+h)%h)*w+(x+w)%w]
+	}
+	var n math32.Vector3
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			du := (at(x+1, y) - at(x-1, y)) * strength / 2
+			dv := (at(x, y-1) - at(x, y+1)) * strength / 2
+			n.Set(-du, -dv, 1).Normalize()
+			img.SetRGBA(x, y, toRGBA(&math32.Color4{n.X*0.5 + 0.5, n.Y*0.5 + 0.5, n.Z*0.5 + 0.5, 1}))
+		}
+	}
+	return img
+}
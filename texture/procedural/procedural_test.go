@@ -0,0 +1,87 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package procedural
+
+import "testing"
+
+// Test that noise generators with the same seed return the same values
+func TestNoiseSeed(t *testing.T) {
+
+	n1 := NewNoise(42)
+	n2 := NewNoise(42)
+	n3 := NewNoise(43)
+	differs := false
+	for i := 0; i < 100; i++ {
+		x, y, z := float32(i)*0.37, float32(i)*0.61, float32(i)*0.13
+		if n1.Perlin2(x, y) != n2.Perlin2(x, y) {
+			t.Errorf("Perlin2(%v, %v) differs with the same seed", x, y)
+		}
+		if n1.Perlin3(x, y, z) != n2.Perlin3(x, y, z) {
+			t.Errorf("Perlin3(%v, %v, %v) differs with the same seed", x, y, z)
+		}
+		if n1.Simplex2(x, y) != n2.Simplex2(x, y) {
+			t.Errorf("Simplex2(%v, %v) differs with the same seed", x, y)
+		}
+		if n1.Simplex3(x, y, z) != n2.Simplex3(x, y, z) {
+			t.Errorf("Simplex3(%v, %v, %v) differs with the same seed", x, y, z)
+		}
+		a1, a2 := n1.Worley2(x, y)
+		b1, b2 := n2.Worley2(x, y)
+		if a1 != b1 || a2 != b2 {
+			t.Errorf("Worley2(%v, %v) differs with the same seed", x, y)
+		}
+		if n1.Perlin2(x, y) != n3.Perlin2(x, y) {
+			differs = true
+		}
+	}
+	if !differs {
+		t.Error("Perlin2 is the same with different seeds")
+	}
+}
+
+// Test that fractional Brownian motion with the same seed returns the same values
+func TestFBMSeed(t *testing.T) {
+
+	n1 := NewNoise(7)
+	n2 := NewNoise(7)
+	for i := 0; i < 100; i++ {
+		x, y, z := float32(i)*0.29, float32(i)*0.53, float32(i)*0.71
+		v1 := FBM2(n1.Simplex2, x, y, 5, 2, 0.5)
+		v2 := FBM2(n2.Simplex2, x, y, 5, 2, 0.5)
+		if v1 != v2 {
+			t.Errorf("FBM2(%v, %v) differs with the same seed: %v != %v", x, y, v1, v2)
+		}
+		if v1 < -1 || v1 > 1 {
+			t.Errorf("FBM2(%v, %v) out of range: %v", x, y, v1)
+		}
+		w1 := FBM3(n1.Perlin3, x, y, z, 4, 2, 0.5)
+		w2 := FBM3(n2.Perlin3, x, y, z, 4, 2, 0.5)
+		if w1 != w2 {
+			t.Errorf("FBM3(%v, %v, %v) differs with the same seed: %v != %v", x, y, z, w1, w2)
+		}
+	}
+	f1 := NewNoiseField(16, 16, n1.Perlin2, 4, 3)
+	f2 := NewNoiseField(16, 16, n2.Perlin2, 4, 3)
+	for i := range f1.Data {
+		if f1.Data[i] != f2.Data[i] {
+			t.Fatalf("Noise fields differ with the same seed at %d", i)
+		}
+	}
+}
+
+// Test that the normal map of a flat height field points straight up
+func TestNormalMapFlat(t *testing.T) {
+
+	heights := NewFieldFunc(8, 8, func(u, v float32) float32 { return 0.5 })
+	img := NormalMap(heights, 4)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			c := img.RGBAAt(x, y)
+			if c.R != 128 || c.G != 128 || c.B != 255 || c.A != 255 {
+				t.Fatalf("NormalMap(%d, %d) = %v, expected (128, 128, 255, 255)", x, y, c)
+			}
+		}
+	}
+}
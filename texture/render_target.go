@@ -0,0 +1,153 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"github.com/g3n/engine/gls"
+)
+
+// RenderTarget is an offscreen framebuffer into which scenes can be rendered,
+// with a color texture and a depth buffer which can also be a texture,
+// so that the rendered images can be used by materials.
+type RenderTarget struct {
+	gs       *gls.GLS   // OpenGL state of the framebuffer
+	fb       uint32     // Framebuffer handle
+	depthRb  uint32     // Depth renderbuffer handle if the depth is not a texture
+	color    *Texture2D // Color texture
+	depth    *Texture2D // Optional depth texture
+	width    int32      // Width in pixels
+	height   int32      // Height in pixels
+	resized  bool       // The attachments need to be reallocated
+	viewport [4]int32   // Viewport saved by Bind
+}
+
+// NewRenderTarget creates and returns a pointer to a new render target with the
+// specified size in pixels and a depth texture if depthTexture is true.
+func NewRenderTarget(width, height int, depthTexture bool) *RenderTarget {
+
+	rt := new(RenderTarget)
+	rt.color = newTargetTexture(gls.LINEAR)
+	if depthTexture {
+		rt.depth = newTargetTexture(gls.NEAREST)
+	}
+	rt.SetSize(width, height)
+	return rt
+}
+
+// newTargetTexture creates and returns a pointer to a new texture without data
+// or mipmaps for a render target, with the specified filter.
+func newTargetTexture(filter uint32) *Texture2D {
+
+	t := newTexture2D()
+	t.genMipmap = false
+	t.SetMagFilter(filter)
+	t.SetMinFilter(filter)
+	t.SetFlipY(false)
+	return t
+}
+
+// Texture returns the color texture of the render target.
+// It should be incremented with Incref when added to materials.
+func (rt *RenderTarget) Texture() *Texture2D {
+
+	return rt.color
+}
+
+// DepthTexture returns the depth texture of the render target or nil if it has none.
+// It should be incremented with Incref when added to materials.
+func (rt *RenderTarget) DepthTexture() *Texture2D {
+
+	return rt.depth
+}
+
+// SetSize sets the size of the render target in pixels.
+// The textures are reallocated on the next Bind if the size changed.
+func (rt *RenderTarget) SetSize(width, height int) {
+
+	if int32(width) == rt.width && int32(height) == rt.height {
+		return
+	}
+	rt.width = int32(width)
+	rt.height = int32(height)
+	rt.resized = true
+}
+
+// Width returns the width of the render target in pixels.
+func (rt *RenderTarget) Width() int {
+
+	return int(rt.width)
+}
+
+// Height returns the height of the render target in pixels.
+func (rt *RenderTarget) Height() int {
+
+	return int(rt.height)
+}
+
+// Bind makes the render target the destination of the rendering, saving the
+// current viewport and setting it to the whole render target.
+func (rt *RenderTarget) Bind(gs *gls.GLS) {
+
+	if rt.gs == nil {
+		rt.gs = gs
+		rt.fb = gs.GenFramebuffer()
+	}
+	gs.BindFramebuffer(gls.FRAMEBUFFER, rt.fb)
+	if rt.resized {
+		rt.allocate(gs)
+	}
+	x, y, width, height := gs.GetViewport()
+	rt.viewport = [4]int32{x, y, width, height}
+	gs.Viewport(0, 0, rt.width, rt.height)
+}
+
+// Unbind makes the default framebuffer the destination of the rendering again
+// and restores the viewport saved by Bind.
+func (rt *RenderTarget) Unbind(gs *gls.GLS) {
+
+	gs.BindFramebuffer(gls.FRAMEBUFFER, 0)
+	gs.Viewport(rt.viewport[0], rt.viewport[1], rt.viewport[2], rt.viewport[3])
+}
+
+// allocate (re)allocates the attachments of the bound framebuffer with the current size.
+func (rt *RenderTarget) allocate(gs *gls.GLS) {
+
+	gs.ActiveTexture(gls.TEXTURE0)
+	rt.color.SetData(int(rt.width), int(rt.height), gls.RGBA, gls.UNSIGNED_BYTE, gls.RGBA8, nil)
+	rt.color.bind(gs)
+	gs.FramebufferTexture2D(gls.FRAMEBUFFER, gls.COLOR_ATTACHMENT0, gls.TEXTURE_2D, rt.color.texname, 0)
+	if rt.depth != nil {
+		rt.depth.SetData(int(rt.width), int(rt.height), gls.DEPTH_COMPONENT, gls.UNSIGNED_INT, gls.DEPTH_COMPONENT24, nil)
+		rt.depth.bind(gs)
+		gs.FramebufferTexture2D(gls.FRAMEBUFFER, gls.DEPTH_ATTACHMENT, gls.TEXTURE_2D, rt.depth.texname, 0)
+	} else {
+		if rt.depthRb == 0 {
+			rt.depthRb = gs.GenRenderbuffer()
+		}
+		gs.BindRenderbuffer(gls.RENDERBUFFER, rt.depthRb)
+		gs.RenderbufferStorage(gls.RENDERBUFFER, gls.DEPTH_COMPONENT24, rt.width, rt.height)
+		gs.FramebufferRenderbuffer(gls.FRAMEBUFFER, gls.DEPTH_ATTACHMENT, gls.RENDERBUFFER, rt.depthRb)
+	}
+	if status := gs.CheckFramebufferStatus(gls.FRAMEBUFFER); status != gls.FRAMEBUFFER_COMPLETE {
+		log.Error("Incomplete render target framebuffer: 0x%X", status)
+	}
+	rt.resized = false
+}
+
+// Dispose releases the framebuffer and the textures of the render target.
+func (rt *RenderTarget) Dispose() {
+
+	if rt.gs != nil {
+		rt.gs.DeleteFramebuffers(rt.fb)
+		if rt.depthRb != 0 {
+			rt.gs.DeleteRenderbuffers(rt.depthRb)
+		}
+		rt.gs = nil
+	}
+	rt.color.Dispose()
+	if rt.depth != nil {
+		rt.depth.Dispose()
+	}
+}
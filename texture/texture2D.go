@@ -48,7 +48,8 @@ type Texture2D struct {
 		flipY   float32
 		visible float32
 	}
-	RGBA *image.RGBA
+	rotation float32 // texture coordinates rotation angle in radians
+	RGBA     *image.RGBA
 }
 
 func newTexture2D() *Texture2D {
@@ -277,6 +278,20 @@ func (t *Texture2D) Offset() (float32, float32) {
 	return t.udata.offsetX, t.udata.offsetY
 }
 
+// SetRotation sets the counter-clockwise rotation angle in radians applied
+// to the texture coordinates after the repeat factor and before the offset.
+// Currently the rotation is only honored by the physical material.
+func (t *Texture2D) SetRotation(angle float32) {
+
+	t.rotation = angle
+}
+
+// Rotation returns the current texture coordinates rotation angle in radians
+func (t *Texture2D) Rotation() float32 {
+
+	return t.rotation
+}
+
 // SetFlipY set the state for flipping the Y coordinate
 func (t *Texture2D) SetFlipY(state bool) {
 
@@ -360,14 +375,34 @@ func DecodeImageSection(imgfile string, section *image.Rectangle) (*image.RGBA,
 // RenderSetup is called by the material render setup
 func (t *Texture2D) RenderSetup(gs *gls.GLS, slotIdx, uniIdx int) { // Could have as input - TEXTURE0 (slot) and uni location
 
+	// Sets the texture unit for this texture
+	gs.ActiveTexture(uint32(gls.TEXTURE0 + slotIdx))
+	t.bind(gs)
+
+	// Transfer texture unit uniform
+	var location int32
+	if uniIdx == 0 {
+		location = t.uniUnit.Location(gs)
+	} else {
+		location = t.uniUnit.LocationIdx(gs, int32(uniIdx))
+	}
+	gs.Uniform1i(location, int32(slotIdx))
+
+	// Transfer texture info combined uniform
+	const vec2count = 3
+	location = t.uniInfo.LocationIdx(gs, vec2count*int32(uniIdx))
+	gs.Uniform2fv(location, vec2count, &t.udata.offsetX)
+}
+
+// bind binds the texture to the active texture unit, creating it on the
+// first call, and transfers its data and parameters if they changed.
+func (t *Texture2D) bind(gs *gls.GLS) {
+
 	// One time initialization
 	if t.gs == nil {
 		t.texname = gs.GenTexture()
 		t.gs = gs
 	}
-
-	// Sets the texture unit for this texture
-	gs.ActiveTexture(uint32(gls.TEXTURE0 + slotIdx))
 	gs.BindTexture(gls.TEXTURE_2D, t.texname)
 
 	// Transfer texture data to OpenGL if necessary
@@ -398,18 +433,4 @@ func (t *Texture2D) RenderSetup(gs *gls.GLS, slotIdx, uniIdx int) { // Could hav
 		gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_WRAP_T, int32(t.wrapT))
 		t.updateParams = false
 	}
-
-	// Transfer texture unit uniform
-	var location int32
-	if uniIdx == 0 {
-		location = t.uniUnit.Location(gs)
-	} else {
-		location = t.uniUnit.LocationIdx(gs, int32(uniIdx))
-	}
-	gs.Uniform1i(location, int32(slotIdx))
-
-	// Transfer texture info combined uniform
-	const vec2count = 3
-	location = t.uniInfo.LocationIdx(gs, vec2count*int32(uniIdx))
-	gs.Uniform2fv(location, vec2count, &t.udata.offsetX)
 }